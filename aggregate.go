@@ -0,0 +1,144 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RepoSummary is one snapshot's headline numbers in an organization rollup.
+type RepoSummary struct {
+	Name                           string
+	Modules, Edges, ExternalCrates int
+}
+
+// SharedCrate is an external crate and how widely it is used across repositories.
+type SharedCrate struct {
+	Name         string
+	Repos        []string
+	Files, Items int // importing files and distinct items, summed over repositories
+}
+
+// HotItem is an internal item ranked by importers across all repositories.
+type HotItem struct {
+	Repo, Module, Item string
+	Importers          int
+}
+
+// ExternalItem is a third-party item ranked by how widely it is used across repositories.
+type ExternalItem struct {
+	Crate, Item string
+	Repos       []string
+	Files       int // importing files, summed over repositories
+}
+
+// OrgRollup aggregates snapshots from many repositories.
+type OrgRollup struct {
+	Repos        []RepoSummary
+	TotalModules int
+	TotalEdges   int
+	SharedCrates []SharedCrate // used by at least two repositories
+	HotItems     []HotItem
+	TopExternal  []ExternalItem // the external items used most across repositories
+}
+
+func repoName(s *Snapshot) string {
+	if s.Crate != "" { return s.Crate }
+	return filepath.Base(s.Root)
+}
+
+func aggregateSnapshots(snaps []*Snapshot, top int) OrgRollup {
+	var rollup OrgRollup
+	crates := make(map[string]*SharedCrate)
+	externalItems := make(map[[2]string]*ExternalItem)
+	for _, s := range snaps {
+		repo := repoName(s)
+		rollup.Repos = append(rollup.Repos, RepoSummary{Name: repo, Modules: len(s.Modules), Edges: len(s.Edges), ExternalCrates: len(s.External)})
+		rollup.TotalModules += len(s.Modules)
+		rollup.TotalEdges += len(s.Edges)
+		for _, c := range s.External {
+			sc := crates[c.Name]
+			if sc == nil { sc = &SharedCrate{Name: c.Name}; crates[c.Name] = sc }
+			sc.Repos = append(sc.Repos, repo)
+			files := make(map[string]struct{})
+			for _, item := range c.Items {
+				for _, f := range item.Files { files[f] = struct{}{} }
+				key := [2]string{c.Name, item.Name}
+				ei := externalItems[key]
+				if ei == nil { ei = &ExternalItem{Crate: c.Name, Item: item.Name}; externalItems[key] = ei }
+				ei.Repos = append(ei.Repos, repo)
+				ei.Files += len(item.Files)
+			}
+			sc.Files += len(files)
+			sc.Items += len(c.Items)
+		}
+		for _, m := range s.Modules {
+			for _, item := range m.Items { rollup.HotItems = append(rollup.HotItems, HotItem{Repo: repo, Module: m.Name, Item: item.Name, Importers: len(item.Files)}) }
+		}
+	}
+	for _, sc := range crates { if len(sc.Repos) >= 2 { sort.Strings(sc.Repos); rollup.SharedCrates = append(rollup.SharedCrates, *sc) } }
+	sort.Slice(rollup.SharedCrates, func(i, j int) bool {
+		a, b := rollup.SharedCrates[i], rollup.SharedCrates[j]
+		if len(a.Repos) != len(b.Repos) { return len(a.Repos) > len(b.Repos) }
+		if a.Files != b.Files { return a.Files > b.Files }
+		return a.Name < b.Name
+	})
+	sort.Slice(rollup.HotItems, func(i, j int) bool {
+		a, b := rollup.HotItems[i], rollup.HotItems[j]
+		if a.Importers != b.Importers { return a.Importers > b.Importers }
+		if a.Repo != b.Repo { return a.Repo < b.Repo }
+		if a.Module != b.Module { return a.Module < b.Module }
+		return a.Item < b.Item
+	})
+	if len(rollup.HotItems) > top { rollup.HotItems = rollup.HotItems[:top] }
+	for _, ei := range externalItems { sort.Strings(ei.Repos); rollup.TopExternal = append(rollup.TopExternal, *ei) }
+	sort.Slice(rollup.TopExternal, func(i, j int) bool {
+		a, b := rollup.TopExternal[i], rollup.TopExternal[j]
+		if len(a.Repos) != len(b.Repos) { return len(a.Repos) > len(b.Repos) }
+		if a.Files != b.Files { return a.Files > b.Files }
+		if a.Crate != b.Crate { return a.Crate < b.Crate }
+		return a.Item < b.Item
+	})
+	if len(rollup.TopExternal) > top { rollup.TopExternal = rollup.TopExternal[:top] }
+	return rollup
+}
+
+func writeRollupMarkdown(w io.Writer, r OrgRollup) {
+	fmt.Fprintf(w, "# Organization Dependency Rollup\n\n%d repositories, %d modules, %d module edges.\n", len(r.Repos), r.TotalModules, r.TotalEdges)
+	fmt.Fprintln(w, "\n## Repositories\n\n| Repository | Modules | Edges | External Crates |\n|---|---:|---:|---:|")
+	for _, repo := range r.Repos { fmt.Fprintf(w, "| %s | %d | %d | %d |\n", repo.Name, repo.Modules, repo.Edges, repo.ExternalCrates) }
+	fmt.Fprintf(w, "\n## Shared External Crates (%d)\n\n", len(r.SharedCrates))
+	if len(r.SharedCrates) == 0 { fmt.Fprintln(w, "_No crate is used by more than one repository._") } else {
+		fmt.Fprintln(w, "| Crate | Repositories | Importing Files | Items |\n|---|---|---:|---:|")
+		for _, c := range r.SharedCrates { fmt.Fprintf(w, "| %s | %d (%s) | %d | %d |\n", c.Name, len(c.Repos), strings.Join(c.Repos, ", "), c.Files, c.Items) }
+	}
+	fmt.Fprintf(w, "\n## Hot Items Across Repositories\n\n")
+	if len(r.HotItems) == 0 { fmt.Fprintln(w, "_No item imports found._") } else {
+		fmt.Fprintln(w, "| Item | Repository | Importers |\n|---|---|---:|")
+		for _, h := range r.HotItems { fmt.Fprintf(w, "| `%s::%s` | %s | %d |\n", h.Module, h.Item, h.Repo, h.Importers) }
+	}
+	fmt.Fprintf(w, "\n## Top External Items Across Repositories\n\n")
+	if len(r.TopExternal) == 0 { fmt.Fprintln(w, "_No external item imports found._"); return }
+	fmt.Fprintln(w, "| Item | Repositories | Importing Files |\n|---|---|---:|")
+	for _, e := range r.TopExternal { fmt.Fprintf(w, "| `%s::%s` | %d (%s) | %d |\n", e.Crate, e.Item, len(e.Repos), strings.Join(e.Repos, ", "), e.Files) }
+}
+
+func runAggregate(args []string) {
+	fs := flag.NewFlagSet("aggregate", flag.ExitOnError)
+	top := fs.Int("top", 20, "number of hot internal and top external items to list")
+	fs.Usage = func() { fmt.Println("Usage: dependant aggregate [flags] <snapshot.json>..."); fs.PrintDefaults() }
+	fs.Parse(args)
+	if fs.NArg() == 0 { fs.Usage(); os.Exit(1) }
+	var snaps []*Snapshot
+	for _, path := range fs.Args() {
+		s, err := readSnapshot(path)
+		if err != nil { log.Fatalf("Error reading snapshot: %v", err) }
+		snaps = append(snaps, s)
+	}
+	writeRollupMarkdown(os.Stdout, aggregateSnapshots(snaps, *top))
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// APIChange is one public item whose presence or importer count differs between two snapshots.
+type APIChange struct {
+	Module, Item       string
+	OldCount, NewCount int    // files importing the item
+	RenamedTo          string // for removals: an added item in the same module that took over most importers
+}
+
+// APIDiff is the API-evolution report between an old and a new snapshot.
+type APIDiff struct {
+	Added, Removed, Changed []APIChange
+	Breaking                []APIChange // removed or renamed items that had importers
+	Library                 bool
+	Version                 string // version of the old snapshot's crate
+}
+
+// diffPublicAPI compares public item sets and flags importer-count changes of at least
+// threshold (relative, e.g. 0.5 for ±50%) that also move by at least two files.
+func diffPublicAPI(before, after *Snapshot, threshold float64) APIDiff {
+	type key struct{ module, item string }
+	index := func(s *Snapshot) (map[key]struct{}, map[key][]string) {
+		public, importers := make(map[key]struct{}), make(map[key][]string)
+		for _, m := range s.Modules {
+			for _, item := range m.PublicItems { public[key{m.Name, item}] = struct{}{} }
+			for _, item := range m.Items { importers[key{m.Name, item.Name}] = item.Files }
+		}
+		return public, importers
+	}
+	oldPublic, oldImporters := index(before)
+	newPublic, newImporters := index(after)
+
+	diff := APIDiff{Library: before.Library || after.Library, Version: before.Version}
+	for k := range newPublic {
+		if _, ok := oldPublic[k]; !ok { diff.Added = append(diff.Added, APIChange{Module: k.module, Item: k.item, NewCount: len(newImporters[k])}) }
+	}
+	for k := range oldPublic {
+		c := APIChange{Module: k.module, Item: k.item, OldCount: len(oldImporters[k]), NewCount: len(newImporters[k])}
+		if _, ok := newPublic[k]; !ok {
+			c.RenamedTo = likelyRename(oldImporters[k], k.module, diff.Added, func(item string) []string { return newImporters[key{k.module, item}] })
+			diff.Removed = append(diff.Removed, c)
+			if c.OldCount > 0 { diff.Breaking = append(diff.Breaking, c) }
+			continue
+		}
+		delta := math.Abs(float64(c.NewCount - c.OldCount))
+		if delta >= 2 && delta >= threshold*math.Max(float64(c.OldCount), 1) { diff.Changed = append(diff.Changed, c) }
+	}
+	for _, list := range [][]APIChange{diff.Added, diff.Removed, diff.Changed, diff.Breaking} {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Module != list[j].Module { return list[i].Module < list[j].Module }
+			return list[i].Item < list[j].Item
+		})
+	}
+	return diff
+}
+
+// likelyRename picks the added item of the same module whose importers cover at least half of the removed item's importers.
+func likelyRename(oldFiles []string, module string, added []APIChange, importersOf func(string) []string) string {
+	if len(oldFiles) == 0 { return "" }
+	best, bestOverlap := "", 0
+	for _, a := range added {
+		if a.Module != module { continue }
+		overlap := 0
+		newFiles := make(map[string]struct{}); for _, f := range importersOf(a.Item) { newFiles[f] = struct{}{} }
+		for _, f := range oldFiles { if _, ok := newFiles[f]; ok { overlap++ } }
+		if overlap > bestOverlap || (overlap == bestOverlap && overlap > 0 && a.Item < best) { best, bestOverlap = a.Item, overlap }
+	}
+	if bestOverlap*2 < len(oldFiles) { return "" }
+	return best
+}
+
+// suggestBump returns the semver level implied by the diff and, when the old version parses, the next version.
+// Pre-1.0 crates shift one level down, as Cargo treats 0.x minor bumps as breaking.
+func suggestBump(diff APIDiff) (level, next string) {
+	switch {
+	case len(diff.Breaking) > 0: level = "major"
+	case len(diff.Added) > 0 || len(diff.Removed) > 0: level = "minor"
+	default: level = "patch"
+	}
+	parts := strings.SplitN(strings.SplitN(diff.Version, "-", 2)[0], ".", 3)
+	if len(parts) != 3 { return level, "" }
+	var v [3]int
+	for i, p := range parts { n, err := strconv.Atoi(p); if err != nil { return level, "" }; v[i] = n }
+	if v[0] == 0 && level != "patch" { if level == "major" { level = "minor" } else { level = "patch" } }
+	switch level {
+	case "major": v = [3]int{v[0] + 1, 0, 0}
+	case "minor": v = [3]int{v[0], v[1] + 1, 0}
+	default: v[2]++
+	}
+	return level, fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
+
+func writeAPIDiffMarkdown(w io.Writer, diff APIDiff) {
+	fmt.Fprintln(w, "# Public API Changes")
+	if diff.Library {
+		level, next := suggestBump(diff)
+		fmt.Fprintf(w, "\n**Suggested semver bump: %s**", level)
+		if next != "" { fmt.Fprintf(w, " (%s → %s)", diff.Version, next) }
+		fmt.Fprintf(w, "\n\n%d breaking, %d additive change(s). Removed items without internal importers may still break external users.\n", len(diff.Breaking), len(diff.Added))
+	}
+	section := func(title string, changes []APIChange, line func(APIChange) string) {
+		fmt.Fprintf(w, "\n## %s (%d)\n\n", title, len(changes))
+		if len(changes) == 0 { fmt.Fprintln(w, "_None._"); return }
+		for _, c := range changes { fmt.Fprintf(w, "- `%s::%s` %s\n", c.Module, c.Item, line(c)) }
+	}
+	renamed := func(c APIChange) string { if c.RenamedTo != "" { return fmt.Sprintf(", likely renamed to `%s`", c.RenamedTo) }; return "" }
+	section("Breaking", diff.Breaking, func(c APIChange) string { return fmt.Sprintf("(had %d importers%s)", c.OldCount, renamed(c)) })
+	section("Added", diff.Added, func(c APIChange) string { return fmt.Sprintf("(%d importers)", c.NewCount) })
+	section("Removed", diff.Removed, func(c APIChange) string { return fmt.Sprintf("(had %d importers%s)", c.OldCount, renamed(c)) })
+	section("Importer Count Changes", diff.Changed, func(c APIChange) string { return fmt.Sprintf("%d → %d importers", c.OldCount, c.NewCount) })
+}
+
+func runAPIDiff(args []string) {
+	fs := flag.NewFlagSet("api-diff", flag.ExitOnError)
+	threshold := fs.Float64("threshold", 0.5, "relative importer-count change that counts as dramatic")
+	fs.Usage = func() { fmt.Println("Usage: dependant api-diff [flags] <old.json> <new.json>"); fs.PrintDefaults() }
+	fs.Parse(args)
+	if fs.NArg() != 2 { fs.Usage(); os.Exit(1) }
+
+	before, err := readSnapshot(fs.Arg(0))
+	if err != nil { log.Fatalf("Error reading snapshot: %v", err) }
+	after, err := readSnapshot(fs.Arg(1))
+	if err != nil { log.Fatalf("Error reading snapshot: %v", err) }
+	writeAPIDiffMarkdown(os.Stdout, diffPublicAPI(before, after, *threshold))
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// BenchPhase is what one analysis phase cost on one repository: the median wall time over the runs, the bytes it
+// allocated, and the live heap when it finished.
+type BenchPhase struct {
+	Phase  string  `json:"phase"`
+	Millis float64 `json:"ms"`
+	Alloc  uint64  `json:"allocBytes"`
+	Heap   uint64  `json:"heapBytes"`
+}
+
+type BenchResult struct {
+	Repo     string       `json:"repo"`
+	Language string       `json:"language"`
+	Modules  int          `json:"modules"`
+	Phases   []BenchPhase `json:"phases"`
+}
+
+// BenchBaseline is a stored bench run, the reference later runs are compared against. Timings only compare on
+// similar machines, so it records where it was taken.
+type BenchBaseline struct {
+	CreatedAt time.Time     `json:"createdAt"`
+	Platform  string        `json:"platform"`
+	Results   []BenchResult `json:"results"`
+}
+
+// benchNoiseMillis and benchNoiseBytes are the growth below which a phase never counts as regressed, however large in
+// percent.
+const (
+	benchNoiseMillis = 20
+	benchNoiseBytes  = 1 << 20
+)
+
+// corpusRepo is one line of <corpus>/corpus.txt: `<name> <git url> [<rev>]`.
+type corpusRepo struct{ Name, URL, Rev string }
+
+func readCorpusList(path string) ([]corpusRepo, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) { return nil, nil }
+	if err != nil { return nil, err }
+	defer file.Close()
+	var repos []corpusRepo
+	lines := bufio.NewScanner(file)
+	for n := 1; lines.Scan(); n++ {
+		line, _, _ := strings.Cut(lines.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 { continue }
+		if len(fields) > 3 || len(fields) < 2 { return nil, fmt.Errorf("%s:%d: expected <name> <git url> [<rev>]", path, n) }
+		repo := corpusRepo{Name: fields[0], URL: fields[1]}
+		if len(fields) == 3 { repo.Rev = fields[2] }
+		repos = append(repos, repo)
+	}
+	return repos, lines.Err()
+}
+
+// fetchCorpusRepo checks repo out into dir at its pinned revision (or the default branch's head), fetching only that
+// commit, so every machine benchmarks the same sources.
+func fetchCorpusRepo(dir string, repo corpusRepo) error {
+	rev := repo.Rev
+	if rev == "" { rev = "HEAD" }
+	if err := os.MkdirAll(dir, 0o755); err != nil { return err }
+	for _, args := range [][]string{{"init", "--quiet"}, {"remote", "add", "origin", repo.URL}, {"fetch", "--quiet", "--depth", "1", "origin", rev}, {"checkout", "--quiet", "FETCH_HEAD"}} {
+		if _, err := gitOutput(dir, args...); err != nil { os.RemoveAll(dir); return err }
+	}
+	return nil
+}
+
+// benchRepo analyzes root runs times, without the cache, and keeps each phase's median time and allocation. A first
+// analysis warms up the process (file cache, lazily built tables) and is not measured.
+func benchRepo(root string, runs int) (BenchResult, error) {
+	times, allocs, heaps := make(map[string][]float64), make(map[string][]uint64), make(map[string]uint64)
+	a, err := analyzer.Analyze(root, analyzer.Options{})
+	if err != nil { return BenchResult{}, err }
+	for i := 0; i < runs; i++ {
+		runtime.GC()
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+		start, allocated := time.Now(), mem.TotalAlloc
+		a, err = (&analyzer.Analyzer{Progress: func(phase string) {
+			elapsed := time.Since(start)
+			runtime.ReadMemStats(&mem)
+			times[phase] = append(times[phase], float64(elapsed.Microseconds())/1000)
+			allocs[phase] = append(allocs[phase], mem.TotalAlloc-allocated)
+			heaps[phase] = max(heaps[phase], mem.HeapAlloc)
+			start, allocated = time.Now(), mem.TotalAlloc
+		}}).Analyze(root)
+		if err != nil { return BenchResult{}, err }
+	}
+	result := BenchResult{Repo: filepath.Base(root), Language: a.Language, Modules: len(a.SymbolTable)}
+	for _, phase := range analyzer.Phases {
+		sort.Float64s(times[phase])
+		sort.Slice(allocs[phase], func(i, j int) bool { return allocs[phase][i] < allocs[phase][j] })
+		result.Phases = append(result.Phases, BenchPhase{Phase: phase, Millis: times[phase][runs/2], Alloc: allocs[phase][runs/2], Heap: heaps[phase]})
+	}
+	return result, nil
+}
+
+// benchDelta compares a phase to its baseline, returning the change for display and whether it is a regression.
+func benchDelta(now, base BenchPhase, tolerance float64) (string, bool) {
+	pct := func(now, base float64) float64 { if base == 0 { return 0 }; return 100 * (now - base) / base }
+	timePct, allocPct := pct(now.Millis, base.Millis), pct(float64(now.Alloc), float64(base.Alloc))
+	slower := timePct > tolerance && now.Millis-base.Millis > benchNoiseMillis
+	bigger := allocPct > tolerance && now.Alloc > base.Alloc+benchNoiseBytes
+	delta := fmt.Sprintf("%+.0f%% time, %+.0f%% alloc", timePct, allocPct)
+	if slower || bigger { delta = "❌ " + delta + fmt.Sprintf(" (baseline %.1f ms, %s)", base.Millis, formatBytes(base.Alloc)) }
+	return delta, slower || bigger
+}
+
+func formatBytes(n uint64) string {
+	switch {
+	case n >= 1<<30: return fmt.Sprintf("%.1f GiB", float64(n)/(1<<30))
+	case n >= 1<<20: return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10: return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// runBench analyzes every repository in a corpus directory, phase by phase, and compares the costs with a stored
+// baseline, exiting non-zero when a phase got slower or allocates more by over --tolerance percent. Repositories
+// listed in <corpus>/corpus.txt are fetched at their pinned revisions when missing.
+func runBench(args []string) {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	corpus := fs.String("corpus", "", "directory holding one checked-out repository per subdirectory, plus an optional corpus.txt of \"<name> <git url> [<rev>]\" lines to fetch")
+	runs := fs.Int("runs", 3, "analyses per repository; the median of each phase is kept")
+	baselinePath := fs.String("baseline", "", "baseline to compare against (default <corpus>/bench-baseline.json, when it exists)")
+	save := fs.Bool("save", false, "store this run as the baseline")
+	tolerance := fs.Float64("tolerance", 20, "percent a phase may get slower or allocate more before it counts as a regression")
+	fs.Usage = func() { fmt.Println("Usage: dependant bench --corpus <dir> [flags]"); fs.PrintDefaults() }
+	fs.Parse(args)
+	if *corpus == "" || fs.NArg() != 0 || *runs < 1 { fs.Usage(); os.Exit(1) }
+	if *baselinePath == "" { *baselinePath = filepath.Join(*corpus, "bench-baseline.json") }
+
+	listed, err := readCorpusList(filepath.Join(*corpus, "corpus.txt"))
+	if err != nil { log.Fatalf("Error reading corpus list: %v", err) }
+	for _, repo := range listed {
+		dir := filepath.Join(*corpus, repo.Name)
+		if _, err := os.Stat(dir); err == nil { continue }
+		log.Printf("Fetching %s from %s", repo.Name, repo.URL)
+		if err := fetchCorpusRepo(dir, repo); err != nil { log.Fatalf("Error fetching %s: %v", repo.Name, err) }
+	}
+	entries, err := os.ReadDir(*corpus)
+	if err != nil { log.Fatalf("Error reading corpus: %v", err) }
+	var repos []string
+	for _, e := range entries { if e.IsDir() && !strings.HasPrefix(e.Name(), ".") { repos = append(repos, e.Name()) } }
+	if len(repos) == 0 { log.Fatalf("No repositories in %s: check some out there, or list them in corpus.txt", *corpus) }
+
+	var baseline *BenchBaseline
+	if content, err := os.ReadFile(*baselinePath); err == nil {
+		baseline = &BenchBaseline{}
+		if err := json.Unmarshal(content, baseline); err != nil { log.Fatalf("Error reading baseline %s: %v", *baselinePath, err) }
+	} else if !errors.Is(err, os.ErrNotExist) {
+		log.Fatalf("Error reading baseline: %v", err)
+	}
+	platform := runtime.GOOS + "/" + runtime.GOARCH + ", " + runtime.Version()
+	if baseline != nil && baseline.Platform != platform { log.Printf("Baseline was taken on %s, this run is on %s; timings may not compare", baseline.Platform, platform) }
+
+	run := BenchBaseline{CreatedAt: time.Now().UTC(), Platform: platform}
+	regressions := 0
+	fmt.Printf("Benchmarking %d repositor%s, %d run%s each\n", len(repos), map[bool]string{true: "y", false: "ies"}[len(repos) == 1], *runs, plural(*runs))
+	for _, name := range repos {
+		result, err := benchRepo(filepath.Join(*corpus, name), *runs)
+		if err != nil { log.Fatalf("Error analyzing %s: %v", name, err) }
+		run.Results = append(run.Results, result)
+		var before map[string]BenchPhase
+		if baseline != nil {
+			for _, r := range baseline.Results {
+				if r.Repo != name { continue }
+				before = make(map[string]BenchPhase)
+				for _, p := range r.Phases { before[p.Phase] = p }
+			}
+		}
+		fmt.Printf("\n%s (%s, %d modules)\n", name, result.Language, result.Modules)
+		for _, p := range result.Phases {
+			line := fmt.Sprintf("  %-13s %9.1f ms  %10s alloc  %10s heap", p.Phase, p.Millis, formatBytes(p.Alloc), formatBytes(p.Heap))
+			if base, ok := before[p.Phase]; ok {
+				delta, regressed := benchDelta(p, base, *tolerance)
+				line += "  " + delta
+				if regressed { regressions++ }
+			} else if baseline != nil {
+				line += "  (not in baseline)"
+			}
+			fmt.Println(line)
+		}
+	}
+
+	if *save {
+		content, err := json.MarshalIndent(run, "", "  ")
+		if err == nil { err = os.WriteFile(*baselinePath, append(content, '\n'), 0o644) }
+		if err != nil { log.Fatalf("Error saving baseline: %v", err) }
+		fmt.Printf("\n✅ Saved baseline to %s\n", *baselinePath)
+	}
+	if baseline == nil { return }
+	if regressions > 0 {
+		fmt.Printf("\n❌ %d phase%s regressed by more than %g%%\n", regressions, plural(regressions), *tolerance)
+		os.Exit(1)
+	}
+	fmt.Printf("\n✅ No phase regressed by more than %g%%\n", *tolerance)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// bitbucketPR addresses one pull request through the Bitbucket Cloud REST API (2.0).
+// It is configured from Bitbucket Pipelines' BITBUCKET_REPO_FULL_NAME and BITBUCKET_PR_ID, authenticating with
+// BITBUCKET_TOKEN (a repository or workspace access token) or BITBUCKET_USERNAME and BITBUCKET_APP_PASSWORD.
+type bitbucketPR struct {
+	api    *apiClient
+	repo   string
+	number int
+}
+
+func bitbucketFromEnv(number int) (*bitbucketPR, error) {
+	base, repo := os.Getenv("BITBUCKET_API_URL"), os.Getenv("BITBUCKET_REPO_FULL_NAME")
+	if base == "" { base = "https://api.bitbucket.org/2.0" }
+	if repo == "" { return nil, errors.New("BITBUCKET_REPO_FULL_NAME is not set (expected workspace/repo)") }
+	if number == 0 { number, _ = strconv.Atoi(os.Getenv("BITBUCKET_PR_ID")) }
+	if number == 0 { return nil, errors.New("no pull request: pass --pr or run in a pull-requests pipeline") }
+	var authorize func(*http.Request)
+	if token := os.Getenv("BITBUCKET_TOKEN"); token != "" {
+		authorize = func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+token) }
+	} else if user, password := os.Getenv("BITBUCKET_USERNAME"), os.Getenv("BITBUCKET_APP_PASSWORD"); user != "" && password != "" {
+		authorize = func(req *http.Request) { req.SetBasicAuth(user, password) }
+	} else {
+		return nil, errors.New("set BITBUCKET_TOKEN, or BITBUCKET_USERNAME and BITBUCKET_APP_PASSWORD")
+	}
+	return &bitbucketPR{api: newAPIClient(base, authorize), repo: repo, number: number}, nil
+}
+
+func (bb *bitbucketPR) upsertComment(body string) (string, error) {
+	type comment struct {
+		ID      int64 `json:"id"`
+		Content struct { Raw string `json:"raw"` } `json:"content"`
+		Links   struct { HTML struct { Href string `json:"href"` } `json:"html"` } `json:"links"`
+	}
+	comments := fmt.Sprintf("/repositories/%s/pullrequests/%d/comments", bb.repo, bb.number)
+	var existing comment
+	for next := comments + "?pagelen=100"; next != "" && existing.ID == 0; {
+		var page struct {
+			Values []comment `json:"values"`
+			Next   string    `json:"next"`
+		}
+		if err := bb.api.do("GET", next, nil, &page); err != nil { return "", err }
+		for _, c := range page.Values { if strings.Contains(c.Content.Raw, prCommentMarker) { existing = c; break } }
+		next = page.Next
+	}
+	var result comment
+	payload := map[string]any{"content": map[string]string{"raw": body}}
+	if existing.ID != 0 {
+		return existing.Links.HTML.Href, bb.api.do("PUT", fmt.Sprintf("%s/%d", comments, existing.ID), payload, &result)
+	}
+	err := bb.api.do("POST", comments, payload, &result)
+	return result.Links.HTML.Href, err
+}
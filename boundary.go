@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// BoundaryLeak is a crate-visible item imported from outside the top-level module that defines it. Strict boundary
+// mode (--strict-boundaries or strict_boundaries in dependant.toml) reports these as soft violations: warnings that
+// never fail a check.
+type BoundaryLeak struct {
+	Module     string
+	Item       string
+	Visibility string   // as written, e.g. "pub(crate)"
+	Importers  []string // modules importing it
+	Files      []string // importing files, relative to the root
+}
+
+// findBoundaryLeaks lists the restricted items imported across a module boundary, most widely leaked first.
+// Generated modules are skipped unless [generated] enforce is set.
+func findBoundaryLeaks(a *analyzer.Report) []BoundaryLeak {
+	var leaks []BoundaryLeak
+	for _, module := range sortedKeys(a.Facts.Restricted) {
+		if !a.Enforced(module) { continue }
+		for _, item := range sortedKeys(a.Facts.Restricted[module]) {
+			leak := BoundaryLeak{Module: module, Item: item, Visibility: a.Facts.Restricted[module][item]}
+			importers := make(map[string]struct{})
+			for file := range a.Graph.ItemImports[module][item] {
+				from := a.ModuleName(file)
+				if from == module { continue }
+				importers[from] = struct{}{}
+				rel, err := filepath.Rel(a.Root, file)
+				if err != nil { rel = file }
+				leak.Files = append(leak.Files, filepath.ToSlash(rel))
+			}
+			if len(importers) == 0 { continue }
+			leak.Importers = sortedKeys(importers)
+			sort.Strings(leak.Files)
+			leaks = append(leaks, leak)
+		}
+	}
+	sort.SliceStable(leaks, func(i, j int) bool { return len(leaks[i].Files) > len(leaks[j].Files) })
+	return leaks
+}
+
+func writeBoundaryReport(w io.Writer, leaks []BoundaryLeak) {
+	for _, l := range leaks {
+		fmt.Fprintf(w, "⚠️  %s::%s is %s but imported by %s\n", l.Module, l.Item, l.Visibility, strings.Join(l.Importers, ", "))
+		for _, f := range l.Files { fmt.Fprintf(w, "      %s\n", f) }
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// BudgetStatus is one module's usage against its budget.
+type BudgetStatus struct {
+	Module            string
+	Budget            analyzer.Budget
+	Outbound, Inbound int
+	Public            int  // public items the module declares
+	PreviousPublic    int  // public items in the previous run; -1 when no baseline or history records the module
+	Skipped           bool // generated module, exempt unless [generated] enforce is set
+	Unknown           bool // no such module in the tree, most likely a typo in the config
+}
+
+// Over reports whether any limit is exceeded.
+func (s BudgetStatus) Over() bool {
+	return !s.Skipped && (exceeds(s.Outbound, s.Budget.MaxOutbound) || exceeds(s.Inbound, s.Budget.MaxInbound) || exceeds(s.Public, s.Budget.MaxPublic) || s.PreviousPublic >= 0 && exceeds(s.Public-s.PreviousPublic, s.Budget.MaxPublicGrowth))
+}
+
+func exceeds(used, limit int) bool { return limit >= 0 && used > limit }
+
+// checkBudgets measures every budgeted module on the edges selected by scope ("prod" or "all"). previous holds the
+// public item count of each module in the previous run, or is nil when there is none to grow from.
+func checkBudgets(a *analyzer.Report, scope string, previous map[string]int) []BudgetStatus {
+	deps := a.Graph.Deps
+	if scope == "prod" { deps = a.Graph.ProdDeps }
+	measured := make(map[string]ModuleMetrics)
+	for _, m := range computeModuleMetrics(a, deps) { measured[m.Name] = m }
+	var statuses []BudgetStatus
+	for module, budget := range a.Config.Budgets {
+		m := measured[module]
+		items, known := a.SymbolTable[module]
+		s := BudgetStatus{Module: module, Budget: budget, Outbound: m.FanOut, Inbound: m.FanIn, Public: len(items), PreviousPublic: -1, Skipped: !a.Enforced(module), Unknown: !known}
+		if n, ok := previous[module]; ok { s.PreviousPublic = n }
+		statuses = append(statuses, s)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Module < statuses[j].Module })
+	return statuses
+}
+
+// previousPublicCounts reads the public item count of each module in the previous run: the baseline snapshot when
+// there is one, else the last history record that counted them. It returns nil when neither does.
+func previousPublicCounts(baseline *Snapshot, store HistoryStore) (map[string]int, error) {
+	if baseline != nil {
+		counts := make(map[string]int)
+		for _, m := range baseline.Modules { counts[m.Name] = len(m.PublicItems) }
+		return counts, nil
+	}
+	if store == nil { return nil, nil }
+	records, err := store.Records()
+	if err != nil { return nil, err }
+	for i := len(records) - 1; i >= 0; i-- { if records[i].Public != nil { return records[i].Public, nil } }
+	return nil, nil
+}
+
+// budgetUsage renders "used/limit (headroom)" for one side of a budget.
+func budgetUsage(used, limit int) string {
+	if limit < 0 { return fmt.Sprintf("%d", used) }
+	if used > limit { return fmt.Sprintf("%d/%d (%d over)", used, limit, used-limit) }
+	return fmt.Sprintf("%d/%d (%d left)", used, limit, limit-used)
+}
+
+// publicUsage renders the public item count against max_public, with an arrow for the change since the previous run
+// and the growth against max_public_growth.
+func publicUsage(s BudgetStatus) string {
+	usage := budgetUsage(s.Public, s.Budget.MaxPublic)
+	if s.PreviousPublic < 0 { return usage }
+	growth := s.Public - s.PreviousPublic
+	switch {
+	case growth > 0: usage += fmt.Sprintf(" ↑%d", growth)
+	case growth < 0: usage += fmt.Sprintf(" ↓%d", -growth)
+	default: usage += " →"
+	}
+	if exceeds(growth, s.Budget.MaxPublicGrowth) { usage += fmt.Sprintf(" (growth %d over)", growth-s.Budget.MaxPublicGrowth) }
+	return usage
+}
+
+// budgetMessage describes an over-budget module for check's findings, naming only the limits it exceeds.
+func budgetMessage(s BudgetStatus) string {
+	var exceeded []string
+	if exceeds(s.Outbound, s.Budget.MaxOutbound) { exceeded = append(exceeded, "outbound "+budgetUsage(s.Outbound, s.Budget.MaxOutbound)) }
+	if exceeds(s.Inbound, s.Budget.MaxInbound) { exceeded = append(exceeded, "inbound "+budgetUsage(s.Inbound, s.Budget.MaxInbound)) }
+	if exceeds(s.Public, s.Budget.MaxPublic) { exceeded = append(exceeded, "public items "+budgetUsage(s.Public, s.Budget.MaxPublic)) }
+	if s.PreviousPublic >= 0 && exceeds(s.Public-s.PreviousPublic, s.Budget.MaxPublicGrowth) {
+		exceeded = append(exceeded, fmt.Sprintf("public items grew by %d, %d more than max_public_growth allows", s.Public-s.PreviousPublic, s.Public-s.PreviousPublic-s.Budget.MaxPublicGrowth))
+	}
+	return fmt.Sprintf("%s is over budget: %s", s.Module, strings.Join(exceeded, ", "))
+}
+
+func writeBudgetReport(w io.Writer, statuses []BudgetStatus) (over int) {
+	width, publicWidth := len("Module"), len("Public")
+	for _, s := range statuses { width, publicWidth = max(width, len(s.Module)), max(publicWidth, len([]rune(publicUsage(s)))) }
+	fmt.Fprintf(w, "%-*s  %-18s  %-18s  %-*s  %s\n", width, "Module", "Outbound", "Inbound", publicWidth, "Public", "Status")
+	for _, s := range statuses {
+		status := "ok"
+		switch {
+		case s.Unknown: status = "unknown module"
+		case s.Skipped: status = "skipped (generated)"
+		case s.Over(): status = "OVER BUDGET"; over++
+		}
+		public := publicUsage(s)
+		fmt.Fprintf(w, "%-*s  %-18s  %-18s  %s%s  %s\n", width, s.Module, budgetUsage(s.Outbound, s.Budget.MaxOutbound), budgetUsage(s.Inbound, s.Budget.MaxInbound), public, strings.Repeat(" ", publicWidth-len([]rune(public))), status)
+	}
+	return over
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// cacheFormat is bumped whenever Analysis changes shape, which invalidates every cache entry.
+const cacheFormat = 26
+
+// cacheEntry is one cached analysis, stored as JSON under cacheDir(). Entries are content-addressed: the file name
+// ends in a hash of the tool, config, options and source contents, so a tree checked out afresh, as on an ephemeral CI
+// runner, finds the entry an earlier build left in a restored cache directory, wherever that build checked the tree
+// out: the analysis is stored with its file paths relative to the root. The name starts with a hash of the absolute
+// root, so each checkout keeps only its latest entry.
+type cacheEntry struct {
+	Root        string    `json:"root"` // the checkout that wrote the entry, absolute
+	Format      int       `json:"format"`
+	Tool        string    `json:"tool"`
+	Config      string    `json:"config"`
+	Fingerprint string    `json:"fingerprint"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Analysis    *analyzer.Report `json:"analysis"`
+}
+
+// cacheDirFlag is the --cache-dir flag, which takes precedence over $DEPENDANT_CACHE_DIR.
+var cacheDirFlag string
+
+// cacheDir is --cache-dir, $DEPENDANT_CACHE_DIR, or dependant/ under the user cache directory.
+func cacheDir() (string, error) {
+	if cacheDirFlag != "" { return cacheDirFlag, nil }
+	if dir := os.Getenv("DEPENDANT_CACHE_DIR"); dir != "" { return dir, nil }
+	base, err := os.UserCacheDir()
+	if err != nil { return "", err }
+	return filepath.Join(base, "dependant"), nil
+}
+
+// cacheRootKey is the prefix of root's cache entries.
+func cacheRootKey(root string) (string, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil { return "", err }
+	sum := sha256.Sum256([]byte(abs))
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// cacheContentKey addresses an analysis by everything it is computed from.
+func cacheContentKey(tool, config, fingerprint string, opts analyzer.Options) string {
+	options, _ := json.Marshal(opts)
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d\x00%s\x00%s\x00%s\x00%s", cacheFormat, tool, config, options, fingerprint)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// contentFingerprint hashes the relative path and content of every source file the analysis reads. Unlike
+// treeFingerprint it ignores mtimes, which a fresh checkout resets, and where the tree is checked out.
+func contentFingerprint(root string, filter *analyzer.PathFilter) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil { return err }
+		if filter.Skip(path, d.IsDir()) { if d.IsDir() { return filepath.SkipDir }; return nil }
+		if d.IsDir() || !isSourceFile(d.Name()) { return nil }
+		content, err := os.ReadFile(path)
+		if err != nil { return err }
+		sum := sha256.Sum256(content)
+		fmt.Fprintf(h, "%s\x00%x\n", relSlash(root, path), sum)
+		return nil
+	})
+	return hex.EncodeToString(h.Sum(nil)), err
+}
+
+// toolFingerprint hashes the running binary, so rebuilding dependant with different code invalidates its caches.
+func toolFingerprint() string {
+	exe, err := os.Executable()
+	if err != nil { return "unknown" }
+	content, err := os.ReadFile(exe)
+	if err != nil { return "unknown" }
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// configFingerprint hashes dependant.toml and dependant.yaml; "none" when the tree has neither.
+func configFingerprint(root string) string {
+	h, found := sha256.New(), false
+	for _, name := range []string{analyzer.ConfigFileName, analyzer.LayersFileName} {
+		content, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil { content = nil } else { found = true }
+		fmt.Fprintf(h, "%s %d\n", name, len(content))
+		h.Write(content)
+	}
+	if !found { return "none" }
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// staleReason explains why an entry cannot be reused, or returns "" when it can.
+func (e *cacheEntry) staleReason(opts analyzer.Options, tool, config, fingerprint string) string {
+	switch {
+	case e.Format != cacheFormat: return "cache format changed"
+	case e.Tool != tool: return "dependant was rebuilt"
+	case e.Config != config: return analyzer.ConfigFileName + " or " + analyzer.LayersFileName + " changed"
+	case e.Analysis == nil: return "no analysis"
+	case !e.Analysis.Options.Equal(opts): return "analysis options changed"
+	case e.Fingerprint != fingerprint: return "sources changed"
+	}
+	return ""
+}
+
+// analyzeCached reuses a cached analysis of root when nothing it depends on has changed, and refreshes the cache otherwise.
+// Cache failures never fail the analysis; they are reported and the tree is analyzed from scratch.
+func analyzeCached(root string, opts analyzer.Options, useCache bool) (*analyzer.Report, error) {
+	if !useCache { return analyzer.Analyze(root, opts) }
+	dir, err := cacheDir()
+	var rootKey string
+	if err == nil { rootKey, err = cacheRootKey(root) }
+	if err != nil { log.Printf("Cache unavailable: %v", err); return analyzer.Analyze(root, opts) }
+	cfg, err := analyzer.LoadConfig(root)
+	if err != nil { return nil, fmt.Errorf("loading config: %w", err) }
+	fingerprint, err := contentFingerprint(root, cfg.PathFilter(root, opts.Exclude...))
+	if err != nil { return nil, err }
+	tool, config := toolFingerprint(), configFingerprint(root)
+	key := cacheContentKey(tool, config, fingerprint, opts)
+
+	// Any checkout's entry for the same contents will do, this one's first.
+	matches, _ := filepath.Glob(filepath.Join(dir, "*-"+key+".json"))
+	sort.SliceStable(matches, func(i, j int) bool { return strings.HasPrefix(filepath.Base(matches[i]), rootKey+"-") && !strings.HasPrefix(filepath.Base(matches[j]), rootKey+"-") })
+	for _, path := range matches {
+		content, err := os.ReadFile(path)
+		if err != nil { continue }
+		var entry cacheEntry
+		if err := json.Unmarshal(content, &entry); err != nil {
+			log.Printf("Ignoring unreadable cache %s: %v", path, err)
+		} else if reason := entry.staleReason(opts, tool, config, fingerprint); reason != "" {
+			log.Printf("Ignoring cache %s (%s)", filepath.Base(path), reason)
+		} else {
+			entry.Analysis.Rebase(root)
+			analyzer.ConfigureModuleNaming(entry.Analysis)
+			return entry.Analysis, nil
+		}
+	}
+
+	a, err := analyzer.Analyze(root, opts)
+	if err != nil { return nil, err }
+	path := filepath.Join(dir, rootKey+"-"+key+".json")
+	stored := *a
+	stored.Rebase("")
+	abs, err := filepath.Abs(root)
+	if err != nil { abs = root }
+	content, err := json.Marshal(cacheEntry{Root: abs, Format: cacheFormat, Tool: tool, Config: config, Fingerprint: fingerprint, CreatedAt: time.Now().UTC(), Analysis: &stored})
+	if err == nil { err = os.MkdirAll(dir, 0o755) }
+	if err == nil { err = os.WriteFile(path, content, 0o644) }
+	if err != nil { log.Printf("Could not write cache: %v", err); return a, nil }
+	old, _ := filepath.Glob(filepath.Join(dir, rootKey+"*.json"))
+	for _, p := range old { if p != path { os.Remove(p) } }
+	return a, nil
+}
+
+func runCache(args []string) {
+	usage := func() { fmt.Println("Usage: dependant cache stats [--cache-dir dir]\n       dependant cache clear [--cache-dir dir] [directory]"); os.Exit(1) }
+	if len(args) < 1 { usage() }
+	flags := flag.NewFlagSet("cache "+args[0], flag.ExitOnError)
+	flags.StringVar(&cacheDirFlag, "cache-dir", "", "cache directory (default $DEPENDANT_CACHE_DIR, else dependant/ under the user cache directory)")
+	flags.Parse(args[1:])
+	dir, err := cacheDir()
+	if err != nil { log.Fatalf("Error locating cache: %v", err) }
+	switch args[0] {
+	case "clear":
+		if flags.NArg() > 0 {
+			rootKey, err := cacheRootKey(flags.Arg(0))
+			if err != nil { log.Fatalf("Error locating cache: %v", err) }
+			paths, _ := filepath.Glob(filepath.Join(dir, rootKey+"*.json"))
+			for _, path := range paths {
+				if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) { log.Fatalf("Error clearing cache: %v", err) }
+			}
+			fmt.Printf("✅ Cleared cache for %s\n", flags.Arg(0))
+			return
+		}
+		if err := os.RemoveAll(dir); err != nil { log.Fatalf("Error clearing cache: %v", err) }
+		fmt.Printf("✅ Cleared %s\n", dir)
+	case "stats":
+		entries, err := os.ReadDir(dir)
+		if err != nil && !errors.Is(err, os.ErrNotExist) { log.Fatalf("Error reading cache: %v", err) }
+		var total int64
+		fmt.Printf("Cache directory: %s\n", dir)
+		tool := toolFingerprint()
+		for _, e := range entries {
+			info, err := e.Info()
+			if err != nil || !strings.HasSuffix(e.Name(), ".json") { continue }
+			total += info.Size()
+			content, err := os.ReadFile(filepath.Join(dir, e.Name()))
+			var entry cacheEntry
+			if err == nil { err = json.Unmarshal(content, &entry) }
+			if err != nil || entry.Analysis == nil { fmt.Printf("  %s  %7d bytes  unreadable\n", e.Name(), info.Size()); continue }
+			status := "valid"
+			if cfg, err := analyzer.LoadConfig(entry.Root); err != nil {
+				status = "stale (" + err.Error() + ")"
+			} else if fingerprint, err := contentFingerprint(entry.Root, cfg.PathFilter(entry.Root, entry.Analysis.Options.Exclude...)); err != nil {
+				status = "stale (root unreadable)"
+			} else if reason := entry.staleReason(entry.Analysis.Options, tool, configFingerprint(entry.Root), fingerprint); reason != "" {
+				status = "stale (" + reason + ")"
+			}
+			fmt.Printf("  %s  %7d bytes  %s old  %s\n", entry.Root, info.Size(), time.Since(entry.CreatedAt).Round(time.Second), status)
+		}
+		fmt.Printf("%d entr%s, %d bytes\n", len(entries), map[bool]string{true: "y", false: "ies"}[len(entries) == 1], total)
+	default:
+		usage()
+	}
+}
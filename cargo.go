@@ -0,0 +1,15 @@
+package main
+
+import (
+	"strings"
+)
+
+// sourceKind summarizes a Cargo.lock source URL.
+func sourceKind(source string) string {
+	switch {
+	case source == "": return "path"
+	case strings.Contains(source, "crates.io-index"), strings.Contains(source, "index.crates.io"): return "crates.io"
+	case strings.HasPrefix(source, "git+"): return "git"
+	default: return "registry"
+	}
+}
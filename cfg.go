@@ -0,0 +1,46 @@
+package main
+
+import (
+	"sort"
+)
+
+// CfgCount is the number of files importing a module under one cfg configuration.
+type CfgCount struct {
+	Predicate string
+	Files     []string
+}
+
+// ConditionalInfo splits a module's importing files into unconditional and cfg-gated ones.
+type ConditionalInfo struct {
+	Name, Tag            string
+	Unconditional, Gated int
+	Breakdown            []CfgCount
+}
+
+// computeConditionalImports counts, per module, files importing it unconditionally and files whose
+// only imports are behind cfg attributes, with a per-configuration breakdown of the latter.
+func computeConditionalImports(conditions map[string]map[string]map[string]struct{}, tags map[string]string) []ConditionalInfo {
+	var infos []ConditionalInfo
+	for module, byCfg := range conditions {
+		if module == "" { continue }
+		info := ConditionalInfo{Name: module, Tag: tags[module], Unconditional: len(byCfg[""])}
+		gated := make(map[string]struct{})
+		for pred, files := range byCfg {
+			if pred == "" { continue }
+			var names []string
+			for f := range files {
+				if _, ok := byCfg[""][f]; !ok { gated[f] = struct{}{} }
+				names = append(names, f)
+			}
+			info.Breakdown = append(info.Breakdown, CfgCount{Predicate: pred, Files: shortFileNames(names)})
+		}
+		info.Gated = len(gated)
+		sort.Slice(info.Breakdown, func(i, j int) bool { return info.Breakdown[i].Predicate < info.Breakdown[j].Predicate })
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Gated != infos[j].Gated { return infos[i].Gated > infos[j].Gated }
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// runCheck enforces the rules in dependant.toml, plus the thresholds given as flags, and exits non-zero when any is
+// violated, for CI. --format sarif prints the violations as a SARIF log instead of text, for code-scanning services.
+// With --fail-on, only the selected violations fail the run, so each pipeline can gate on its own subset. Pass or
+// fail, it writes a JSON summary (--summary) for later pipeline steps.
+func runCheck(args []string) {
+	start := time.Now()
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	f := addAnalyzeFlags(fs)
+	baseline := fs.String("baseline", "", "snapshot of a previous run; stable modules are checked against it, public item growth is measured from it, and --fail-on-cycles fails only on cycles it does not have")
+	f.history = fs.String("history", "", "without --baseline, measure max_public_growth budgets from the last record of this history, as daemon --history writes it: a SQLite file (the default), a .jsonl file or a postgres:// URL")
+	maxDependents := fs.Int("max-dependents", 0, "fail when a module has more dependent modules than this (0: no limit)")
+	failOnCycles := fs.Bool("fail-on-cycles", false, "fail when modules depend on each other in a cycle")
+	format := fs.String("format", "text", "output format: text or sarif")
+	summary := fs.String("summary", "dependant-summary.json", "write counts per rule, headline metrics and the duration as JSON to this file, pass or fail (empty: none)")
+	var failOn globList
+	fs.Var(&failOn, "fail-on", "fail only on these violations, as rule[:module=<pattern>|tag=<tag>][>N], e.g. cycles,new-edges:module=core,glob-imports>10 (repeatable or comma-separated; rules: "+strings.Join(failOnRules, ", ")+")")
+	fs.Usage = func() { fmt.Println("Usage: dependant check [flags] <directory>"); fs.PrintDefaults() }
+	fs.Parse(args)
+	if fs.NArg() != 1 { fs.Usage(); os.Exit(1) }
+	out := io.Writer(os.Stdout)
+	switch *format {
+	case "text":
+	case "sarif": out = io.Discard
+	default: f.fatalf("Unknown format %q: expected text or sarif", *format)
+	}
+	var selectors []failSelector
+	for _, text := range failOn {
+		s, err := parseFailSelector(text)
+		if err != nil { f.fatalf("%v", err) }
+		selectors = append(selectors, s)
+	}
+	selected := func(rule string) bool { return slices.ContainsFunc(selectors, func(s failSelector) bool { return s.Rule == rule }) }
+	if selected("max-dependents") && *maxDependents == 0 { f.fatalf("--fail-on max-dependents needs --max-dependents") }
+	if (selected("new-edges") || selected("stability")) && *baseline == "" { f.fatalf("--fail-on new-edges and stability need a --baseline to compare with") }
+	analysis := f.analyze(fs.Arg(0))
+	defer f.close()
+	for rule, configured := range map[string]bool{"layers": len(analysis.Config.Layers) > 0, "test-imports": len(analysis.Config.TestModules) > 0, "importers": len(analysis.Config.Importers) > 0, "budgets": len(analysis.Config.Budgets) > 0, "stability": len(analysis.Config.Stable) > 0} {
+		where := analyzer.ConfigFileName
+		if rule == "layers" { where += " or " + analyzer.LayersFileName }
+		if selected(rule) && !configured { f.fatalf("--fail-on %s: %s declares no %s", rule, where, rule) }
+	}
+	strict := *f.strict || analysis.Config.StrictBoundaries || selected("boundaries")
+	var findings []checkFinding
+	var hits []checkHit
+	deps := analysis.Graph.Deps
+	if *f.metricsScope == "prod" { deps = analysis.Graph.ProdDeps }
+	writeSummary := func(violations int) {
+		if *summary == "" { return }
+		if err := writeCheckSummary(*summary, buildCheckSummary(analysis, deps, hits, findings, violations, time.Since(start))); err != nil { f.fatalf("Error writing summary: %v", err) }
+	}
+	if len(analysis.Config.Budgets) == 0 && len(analysis.Config.Stable) == 0 && len(analysis.Config.TestModules) == 0 && len(analysis.Config.Layers) == 0 && len(analysis.Config.Importers) == 0 && !strict && *maxDependents == 0 && !*failOnCycles && len(selectors) == 0 {
+		fmt.Fprintf(out, "Nothing to check; add [budgets], [importers], stable, layers or test_modules to %s (layers may also go in %s), or pass --max-dependents, --fail-on-cycles or --strict-boundaries.\n", analyzer.ConfigFileName, analyzer.LayersFileName)
+		if *format == "sarif" { writeSARIF(os.Stdout, nil) }
+		writeSummary(0)
+		return
+	}
+	var before *Snapshot
+	if *baseline != "" {
+		var err error
+		if before, err = readSnapshot(*baseline); err != nil { f.fatalf("Error reading baseline: %v", err) }
+	}
+
+	violations, sections := 0, 0
+	section := func(title string) {
+		if sections++; sections > 1 { fmt.Fprintln(out) }
+		fmt.Fprintln(out, title)
+	}
+	if len(analysis.Config.Layers) > 0 {
+		section("Layers:")
+		upward := findLayerViolations(analysis)
+		writeLayerReport(out, upward)
+		if len(upward) > 0 { fmt.Fprintf(out, "❌ %d import%s against the declared layers\n", len(upward), plural(len(upward))) } else { fmt.Fprintln(out, "✅ All imports follow the declared layers") }
+		for _, v := range upward {
+			hits = append(hits, checkHit{"layers", []string{v.From, v.To}})
+			for _, file := range v.Files { findings = append(findings, checkFinding{Rule: "layers", File: file, Message: fmt.Sprintf("%s (layer %s) imports %s from layer %s above it", v.From, v.FromLayer, v.To, v.ToLayer)}) }
+		}
+		violations += len(upward)
+	}
+	if len(analysis.Config.TestModules) > 0 {
+		section("Test imports:")
+		found, err := findTestImports(analysis)
+		if err != nil { f.fatalf("Error reading imports: %v", err) }
+		writeTestImportReport(out, found)
+		if len(found) > 0 { fmt.Fprintf(out, "❌ %d import%s of test modules in production code\n", len(found), plural(len(found))) } else { fmt.Fprintln(out, "✅ No production code imports test modules") }
+		for _, t := range found {
+			hits = append(hits, checkHit{"test-imports", []string{t.Importer, t.Module}})
+			findings = append(findings, checkFinding{Rule: "test-imports", File: t.File, Line: t.Line, Message: fmt.Sprintf("%s imports test module %s", t.Importer, t.Module)})
+		}
+		violations += len(found)
+	}
+	if len(analysis.Config.Importers) > 0 {
+		section("Importers:")
+		found, err := findImporterViolations(analysis)
+		if err != nil { f.fatalf("Error reading imports: %v", err) }
+		writeImporterReport(out, found, analysis.Config.Importers)
+		if len(found) > 0 { fmt.Fprintf(out, "❌ %d import%s of restricted modules by modules not allowed to\n", len(found), plural(len(found))) } else { fmt.Fprintln(out, "✅ Restricted modules are only imported by the modules allowed to") }
+		for _, v := range found {
+			hits = append(hits, checkHit{"importers", []string{v.Importer, v.Module}})
+			findings = append(findings, importerFinding(v))
+		}
+		violations += len(found)
+	}
+	previous, err := previousPublicCounts(before, f.historyStore)
+	if err != nil { f.fatalf("Error reading history: %v", err) }
+	if statuses := checkBudgets(analysis, *f.metricsScope, previous); len(statuses) > 0 {
+		section("Budgets:")
+		over := writeBudgetReport(out, statuses)
+		if over > 0 { fmt.Fprintf(out, "❌ %d module%s over budget\n", over, plural(over)) } else { fmt.Fprintln(out, "✅ All modules within budget") }
+		for _, s := range statuses {
+			if !s.Over() || s.Unknown || s.Skipped { continue }
+			hits = append(hits, checkHit{"budgets", []string{s.Module}})
+			findings = append(findings, checkFinding{Rule: "budgets", Message: budgetMessage(s)})
+		}
+		violations += over
+	}
+	if len(analysis.Config.Stable) > 0 {
+		section("Stable modules:")
+		if before == nil {
+			fmt.Fprintln(out, "   (skipped: pass --baseline <snapshot.json> from a previous run to check stability contracts)")
+		} else {
+			changes := checkStability(analysis, before, buildSnapshot(analysis))
+			broken := writeStabilityReport(out, changes)
+			if broken > 0 { fmt.Fprintf(out, "❌ %d stability contract%s broken\n", broken, plural(broken)) } else { fmt.Fprintln(out, "✅ All stability contracts hold") }
+			for _, c := range changes {
+				if !c.Broken() { continue }
+				hits = append(hits, checkHit{"stability", []string{c.Module}})
+				findings = append(findings, checkFinding{Rule: "stability", Message: fmt.Sprintf("stability contract of %s broken", c.Module)})
+			}
+			violations += broken
+		}
+	}
+	if *maxDependents > 0 {
+		section("Dependents:")
+		metrics := computeModuleMetrics(analysis, deps)
+		sort.SliceStable(metrics, func(i, j int) bool { return metrics[i].FanIn > metrics[j].FanIn })
+		over := 0
+		for _, m := range metrics {
+			if m.FanIn <= *maxDependents || !analysis.Enforced(m.Name) { continue }
+			fmt.Fprintf(out, "❌ %s has %d dependent modules (limit %d)\n", m.Name, m.FanIn, *maxDependents)
+			hits = append(hits, checkHit{"max-dependents", []string{m.Name}})
+			findings = append(findings, checkFinding{Rule: "max-dependents", Message: fmt.Sprintf("%s has %d dependent modules, over the limit of %d", m.Name, m.FanIn, *maxDependents)})
+			over++
+		}
+		if over > 0 { fmt.Fprintf(out, "❌ %d module%s over %d dependents\n", over, plural(over), *maxDependents) } else { fmt.Fprintf(out, "✅ No module has more than %d dependents\n", *maxDependents) }
+		violations += over
+	}
+	if *failOnCycles || selected("cycles") {
+		section("Cycles:")
+		baselineGroup := make(map[string]int) // module -> 1 + index of its cycle group in the baseline
+		if before != nil {
+			graph := make(map[string]map[string]struct{})
+			for _, e := range before.Edges {
+				if graph[e.From] == nil { graph[e.From] = make(map[string]struct{}) }
+				graph[e.From][e.To] = struct{}{}
+			}
+			for i, c := range findCycles(graph) { for _, m := range c { baselineGroup[m] = i + 1 } }
+		}
+		edgeFiles := moduleEdgeFiles(analysis, deps)
+		cycles := 0
+		for _, c := range computeCycles(analysis, deps) {
+			if inBaselineCycle(c.Modules, baselineGroup) { continue }
+			cycles++
+			hits = append(hits, checkHit{"cycles", c.Modules})
+			fmt.Fprintf(out, "❌ %s\n", strings.Join(c.Chain, " → "))
+			for _, e := range c.Edges {
+				files := edgeFiles[[2]string{e.From, e.To}]
+				fmt.Fprintf(out, "      %s → %s: %s\n", e.From, e.To, strings.Join(files, ", "))
+				for _, file := range files { findings = append(findings, checkFinding{Rule: "cycles", File: file, Message: fmt.Sprintf("%s imports %s, part of the cycle %s", e.From, e.To, strings.Join(c.Chain, " → "))}) }
+			}
+		}
+		qualifier := ""
+		if before != nil { qualifier = "new " }
+		if cycles > 0 { fmt.Fprintf(out, "❌ %d %scycle%s\n", cycles, qualifier, plural(cycles)) } else { fmt.Fprintf(out, "✅ No %scycles\n", qualifier) }
+		violations += cycles
+	}
+	if strict {
+		section("Module boundaries:")
+		leaks := findBoundaryLeaks(analysis)
+		writeBoundaryReport(out, leaks)
+		if len(leaks) > 0 { fmt.Fprintf(out, "⚠️  %d crate-visible item%s imported across module boundaries (warning only)\n", len(leaks), plural(len(leaks))) } else { fmt.Fprintln(out, "✅ No crate-visible items leak across module boundaries") }
+		for _, l := range leaks {
+			hits = append(hits, checkHit{"boundaries", append([]string{l.Module}, l.Importers...)})
+			for _, file := range l.Files { findings = append(findings, checkFinding{Rule: "boundaries", Level: "warning", File: file, Message: fmt.Sprintf("%s::%s is %s but imported from outside %s", l.Module, l.Item, l.Visibility, l.Module)}) }
+		}
+	}
+	if selected("new-edges") {
+		section("New edges:")
+		had := make(map[[2]string]bool)
+		for _, e := range before.Edges { had[[2]string{e.From, e.To}] = true }
+		edgeFiles := moduleEdgeFiles(analysis, deps)
+		moduleGraph, added := analysis.BuildModuleGraph(deps), 0
+		for _, from := range sortedKeys(moduleGraph) {
+			for _, to := range sortedKeys(moduleGraph[from]) {
+				if had[[2]string{from, to}] { continue }
+				added++
+				files := edgeFiles[[2]string{from, to}]
+				fmt.Fprintf(out, "   %s → %s: %s\n", from, to, strings.Join(files, ", "))
+				hits = append(hits, checkHit{"new-edges", []string{from, to}})
+				for _, file := range files { findings = append(findings, checkFinding{Rule: "new-edges", Level: "warning", File: file, Message: fmt.Sprintf("%s now imports %s, which the baseline did not", from, to)}) }
+			}
+		}
+		if added > 0 { fmt.Fprintf(out, "⚠️  %d module edge%s not in the baseline\n", added, plural(added)) } else { fmt.Fprintln(out, "✅ No module edges beyond the baseline") }
+	}
+	if selected("glob-imports") {
+		section("Glob imports:")
+		globs := findGlobImports(analysis)
+		for _, g := range globs {
+			fmt.Fprintf(out, "   %s: %s::*\n", g.File, g.Module)
+			hits = append(hits, checkHit{"glob-imports", []string{g.Importer, g.Module}})
+			findings = append(findings, checkFinding{Rule: "glob-imports", Level: "note", File: g.File, Message: fmt.Sprintf("%s imports %s through a glob", g.Importer, g.Module)})
+		}
+		if len(globs) > 0 { fmt.Fprintf(out, "⚠️  %d glob import%s\n", len(globs), plural(len(globs))) } else { fmt.Fprintln(out, "✅ No glob imports") }
+	}
+	if len(selectors) > 0 {
+		section("Gates (--fail-on):")
+		violations = writeGates(out, selectors, hits, analysis.Facts.Tags)
+	}
+	if *format == "sarif" {
+		if err := writeSARIF(os.Stdout, findings); err != nil { f.fatalf("Error writing SARIF: %v", err) }
+	}
+	writeSummary(violations)
+	if violations > 0 { f.close(); os.Exit(1) }
+}
+
+// inBaselineCycle reports whether a cycle group lies within one cycle group of the baseline: a cycle that was
+// already there, or has since lost members, is not new.
+func inBaselineCycle(modules []string, baselineGroup map[string]int) bool {
+	group := baselineGroup[modules[0]]
+	for _, m := range modules { if baselineGroup[m] != group { return false } }
+	return group > 0
+}
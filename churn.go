@@ -0,0 +1,141 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// apiFingerprint identifies a module's public item set, so history records can tell when it changed without storing it.
+func apiFingerprint(items []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(items, "\n")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// apiObservation is one point in the history of the tree's APIs: each module's public item fingerprint and how many
+// modules depended on it then.
+type apiObservation struct {
+	Time  time.Time
+	API   map[string]string
+	FanIn map[string]int
+}
+
+// APIChurn is how often a module's public items changed between consecutive observations it appears in. Risk weighs
+// the churn by the module's current dependents: an interface that keeps changing under many importers.
+type APIChurn struct {
+	Module      string  `json:"module"`
+	Changes     int     `json:"changes"`
+	Transitions int     `json:"transitions"` // consecutive observations both holding the module
+	Churn       float64 `json:"churn"`       // Changes / Transitions
+	FanIn       int     `json:"fanIn"`       // dependent modules at the latest observation
+	Risk        float64 `json:"risk"`        // Churn × FanIn
+	Unstable    bool    `json:"unstable"`    // churns and is widely depended upon, per the thresholds
+}
+
+func computeAPIChurn(observations []apiObservation, minChurn float64, minDependents int) []APIChurn {
+	sort.SliceStable(observations, func(i, j int) bool { return observations[i].Time.Before(observations[j].Time) })
+	churn := make(map[string]*APIChurn)
+	for i, obs := range observations {
+		for module, print := range obs.API {
+			c := churn[module]
+			if c == nil { c = &APIChurn{Module: module}; churn[module] = c }
+			c.FanIn = obs.FanIn[module]
+			if i == 0 { continue }
+			before, ok := observations[i-1].API[module]
+			if !ok { continue }
+			c.Transitions++
+			if before != print { c.Changes++ }
+		}
+	}
+	var out []APIChurn
+	for _, c := range churn {
+		if c.Transitions == 0 { continue }
+		c.Churn = float64(c.Changes) / float64(c.Transitions)
+		c.Risk = c.Churn * float64(c.FanIn)
+		c.Unstable = c.Changes > 0 && c.Churn >= minChurn && c.FanIn >= minDependents
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Risk != out[j].Risk { return out[i].Risk > out[j].Risk }
+		if out[i].Changes != out[j].Changes { return out[i].Changes > out[j].Changes }
+		return out[i].Module < out[j].Module
+	})
+	return out
+}
+
+// snapshotObservation reads the APIs out of a snapshot; dependents are the modules whose imports name the module.
+func snapshotObservation(snap *Snapshot) apiObservation {
+	obs := apiObservation{Time: snap.CreatedAt, API: make(map[string]string), FanIn: make(map[string]int)}
+	for _, m := range snap.Modules {
+		obs.API[m.Name] = apiFingerprint(m.PublicItems)
+		for _, to := range m.Imports { if to != m.Name { obs.FanIn[to]++ } }
+	}
+	return obs
+}
+
+// runAPIChurn reports API churn over a daemon history (--history) and/or snapshots given as arguments, flagging the
+// modules whose public items keep changing although many modules depend on them.
+func runAPIChurn(args []string) {
+	fs := flag.NewFlagSet("api-churn", flag.ExitOnError)
+	historyPath := fs.String("history", "", "daemon history to read: a SQLite file (the default), a .jsonl file or a postgres:// URL")
+	minChurn := fs.Float64("min-churn", 0.2, "share of observed transitions in which the API changed for a module to count as unstable")
+	minDependents := fs.Int("min-dependents", 3, "dependent modules for a churning module to count as widely depended upon")
+	format := fs.String("format", "text", "output format: text or json")
+	fs.Usage = func() { fmt.Println("Usage: dependant api-churn [--history <location>] [flags] [snapshot.json...]"); fs.PrintDefaults() }
+	fs.Parse(args)
+	if *historyPath == "" && fs.NArg() == 0 { fs.Usage(); os.Exit(1) }
+	if *format != "text" && *format != "json" { log.Fatalf("Unknown --format %q: expected text or json", *format) }
+
+	var observations []apiObservation
+	if *historyPath != "" {
+		store, err := openHistoryStore(*historyPath)
+		if err != nil { log.Fatalf("Error opening history %s: %v", *historyPath, err) }
+		records, err := store.Records()
+		store.Close()
+		if err != nil { log.Fatalf("Error reading history: %v", err) }
+		skipped := 0
+		for _, rec := range records {
+			if rec.API == nil { skipped++; continue }
+			observations = append(observations, apiObservation{Time: rec.Time, API: rec.API, FanIn: rec.FanIn})
+		}
+		if skipped > 0 { log.Printf("Skipped %d history record%s written before API fingerprints were recorded", skipped, plural(skipped)) }
+	}
+	for _, path := range fs.Args() {
+		snap, err := readSnapshot(path)
+		if err != nil { log.Fatalf("Error reading %s: %v", path, err) }
+		observations = append(observations, snapshotObservation(snap))
+	}
+	if len(observations) < 2 { log.Fatalf("API churn needs at least two observations; found %d", len(observations)) }
+	churn := computeAPIChurn(observations, *minChurn, *minDependents)
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if churn == nil { churn = []APIChurn{} }
+		if err := enc.Encode(churn); err != nil { log.Fatalf("Error writing JSON: %v", err) }
+		return
+	}
+	first, last := observations[0].Time, observations[len(observations)-1].Time
+	fmt.Printf("API churn over %d observations (%s → %s)\n\n", len(observations), first.Format("2006-01-02"), last.Format("2006-01-02"))
+	width := len("Module")
+	for _, c := range churn { width = max(width, len(c.Module)) }
+	fmt.Printf("   %-*s  %9s  %6s  %10s  %6s\n", width, "Module", "Changes", "Churn", "Dependents", "Risk")
+	unstable := 0
+	for _, c := range churn {
+		marker := "  "
+		if c.Unstable { marker = "⚠️"; unstable++ }
+		fmt.Printf("%s %-*s  %9s  %5.0f%%  %10d  %6.1f\n", marker, width, c.Module, fmt.Sprintf("%d/%d", c.Changes, c.Transitions), 100*c.Churn, c.FanIn, c.Risk)
+	}
+	if unstable > 0 {
+		fmt.Printf("\n⚠️  %d module%s changed %s API in at least %.0f%% of consecutive observations while %d or more modules depend on %s\n", unstable, plural(unstable), map[bool]string{true: "its", false: "their"}[unstable == 1], 100**minChurn, *minDependents, map[bool]string{true: "it", false: "them"}[unstable == 1])
+	} else {
+		fmt.Println("\n✅ No widely depended-upon module has an unstable API")
+	}
+}
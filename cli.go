@@ -0,0 +1,384 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+const usageText = `Usage: dependant <command> [flags] <directory>
+
+Commands:
+  analyze      analyze a tree and open the report (the default when no command is given)
+  serve        analyze a tree, or read a snapshot with --from, and keep serving the report until interrupted; notes can be added from the page
+  export       analyze a tree once and write artifacts (--format json,html,dot,mermaid,csv,scip,outbound,sarif,shape)
+  diff         show how modules, edges, dependents and item imports moved between two snapshots (markdown or HTML)
+  api-diff     compare the public API of two snapshots and suggest a semver bump
+  api-churn    score how often each module's public items changed across daemon history or snapshots
+  history      replay the analysis over sampled git commits and chart how many files import each module
+  edges        list module edges from a history with when each first appeared and was last verified
+  what-if      simulate merging or splitting modules and show how fan-in, fan-out, cycles and inferred layers change
+  init         write a starter dependant.toml
+  aggregate    combine snapshots of several repositories
+  validate     check snapshots against the schema
+  schema       print the snapshot JSON Schema
+  daemon       keep an analysis warm and answer queries
+  host         serve always-current reports for several repositories, each under its own URL
+  cache        inspect or clear the analysis cache (stats|clear)
+  pr-comment   summarize a branch's architectural changes on its pull request
+  docs         seed per-module Markdown docs
+  check        enforce the rules in dependant.toml and CI thresholds; exits non-zero on violations, or those --fail-on selects (text or SARIF), writing a JSON summary for later steps
+  rename-impact
+               list the lines renaming or moving an item touches (text, JSON or a patch)
+  bench        time the analyzer phase by phase over a corpus of repositories and compare against a baseline
+  who-uses, impact, explain, imports
+               query the daemon (or a one-off analysis)
+
+Run 'dependant <command> -h' for a command's flags.`
+
+// globList is a repeatable flag whose values may also be comma-separated.
+type globList []string
+
+func (g *globList) String() string { return strings.Join(*g, ",") }
+
+func (g *globList) Set(value string) error {
+	for _, glob := range strings.Split(value, ",") { if glob = strings.TrimSpace(glob); glob != "" { *g = append(*g, glob) } }
+	return nil
+}
+
+// featureList is --features: once given, even empty, it selects the configuration with just those features on.
+type featureList struct {
+	set   bool
+	names globList
+}
+
+func (f *featureList) String() string { return f.names.String() }
+
+func (f *featureList) Set(value string) error { f.set = true; return f.names.Set(value) }
+
+// analyzeFlags are the flags shared by every command that analyzes a tree, plus those of commands that also render a report.
+type analyzeFlags struct {
+	metricsScope, aggregate, reExports, cacheDir *string
+	tests                                        *string  // include, exclude or separate; see options and reportOptions
+	rev                                          *string  // commit to analyze instead of the working tree; see analyzeRev
+	depth                                        *int
+	noCache, strict, expand, includeTests, mixed *bool
+	exclude                                      globList
+	features                                     featureList // see options
+	sections, layout                             *string  // report flags; see addReportFlags
+	history                                      *string  // history store dating module edges in the report
+	historyStore                                 HistoryStore
+	minimal                                      *bool
+	live                                         bool     // the report is served by --watch and reloads itself on change
+	notesAPI                                     bool     // the report is served by serve and can add notes through /api/notes
+	sourceLinks                                  bool     // the report is served by serve with its tree, whose files /source/ shows
+	snapshot                                     bool     // the report is rendered from a snapshot, which holds only snapshotSections
+	top                                          int      // modules kept in a mermaid export, by inbound count; 0 keeps all
+	cleanups                                     []func() // temporary copies of --rev commits and --expand, and the --history store, released by close
+}
+
+func addAnalyzeFlags(fs *flag.FlagSet) *analyzeFlags {
+	f := &analyzeFlags{
+		metricsScope: fs.String("metrics-scope", "all", `edges used for coupling metrics: "prod" (exclude test code) or "all"`),
+		aggregate:    fs.String("aggregate", "module", `unit of analysis: "module", or "dir" for each top-level directory under src/`),
+		reExports:    fs.String("reexports", "original", `attribute items imported through a "pub use" re-export to the "original" defining module or to the "facade"`),
+		depth:        fs.Int("depth", 0, "report modules at most this many path segments deep, e.g. 1 counts net::http as net (0 = every level)"),
+		noCache:      fs.Bool("no-cache", false, "ignore and do not update the analysis cache"),
+		cacheDir:     fs.String("cache-dir", "", "analysis cache directory, e.g. one a CI cache step restores (default $DEPENDANT_CACHE_DIR, else dependant/ under the user cache directory)"),
+		strict:       fs.Bool("strict-boundaries", false, "report pub(crate) items imported across top-level modules as soft violations"),
+		rev:          fs.String("rev", "", "analyze the tree as committed at this revision (branch, tag or hash), read from git without touching the working tree"),
+		tests:        fs.String("tests", "include", `test code (#[cfg(test)] modules, tests/, _test.go, *.test.ts, test_*.py): "include" it like production code, "exclude" it from the analysis, or "separate" production and test importers in the report`),
+		mixed:        fs.Bool("mixed", false, "analyze every language found under the directory (by manifest) into one report, with a languages section and the edges between them: Rust or cgo calls into C headers, Python imports of pyo3 modules"),
+		expand:       fs.Bool("expand", false, "analyze the Rust source as cargo expand prints it, so imports that macros generate are seen (needs cargo-expand; slower, and never cached)"),
+		sections:     new(string), layout: new(string), minimal: new(bool), history: new(string),
+	}
+	fs.Var(&f.exclude, "exclude", "glob to skip, in addition to dependant.toml's exclude (repeatable or comma-separated)")
+	fs.Var(&f.features, "features", `compute the Rust graph for these Cargo features (comma-separated; "" for none) rather than every #[cfg] at once, leaving out imports behind other features and test code`)
+	f.includeTests = fs.Bool("include-tests", false, "with --features, keep #[cfg(test)] code and tests/ in the graph; alone, selects no features with tests")
+	return f
+}
+
+func (f *analyzeFlags) addReportFlags(fs *flag.FlagSet) *analyzeFlags {
+	f.sections = fs.String("sections", "", "comma-separated report sections to include (default all): "+strings.Join(reportSections, ","))
+	f.layout = fs.String("layout", "", "embed a graph layout downloaded from the report")
+	f.minimal = fs.Bool("minimal-report", false, "self-contained report that makes no external requests (system fonts, inline SVG icons, strict CSP)")
+	f.history = fs.String("history", "", "date each module edge in the report from this history, as daemon --history writes it: a SQLite file (the default), a .jsonl file or a postgres:// URL")
+	return f
+}
+
+// analyze validates the flags and analyzes root, exiting on any error.
+func (f *analyzeFlags) analyze(root string) *analyzer.Report {
+	if *f.metricsScope != "prod" && *f.metricsScope != "all" { f.fatalf("Invalid --metrics-scope %q: expected prod or all", *f.metricsScope) }
+	if *f.aggregate != "module" && *f.aggregate != "dir" { f.fatalf("Invalid --aggregate %q: expected module or dir", *f.aggregate) }
+	if *f.reExports != "original" && *f.reExports != "facade" { f.fatalf("Invalid --reexports %q: expected original or facade", *f.reExports) }
+	if *f.depth < 0 { f.fatalf("Invalid --depth %d: expected 0 or more", *f.depth) }
+	if *f.tests != "include" && *f.tests != "exclude" && *f.tests != "separate" { f.fatalf("Invalid --tests %q: expected include, exclude or separate", *f.tests) }
+	if *f.tests == "exclude" && *f.includeTests { f.fatalf("--include-tests keeps the test code --tests exclude leaves out; pass one of them") }
+	cacheDirFlag = *f.cacheDir
+	f.openHistory()
+	if *f.mixed && *f.expand { f.fatalf("--expand runs cargo expand on one crate; it cannot be combined with --mixed") }
+	if *f.rev != "" && *f.expand { f.fatalf("--expand runs cargo on the working tree; it cannot be combined with --rev") }
+	if *f.rev != "" { return f.analyzeRev(root, *f.rev) }
+	if *f.expand { return f.analyzeExpanded(root) }
+	analysis, err := analyzeCached(root, f.options(), !*f.noCache)
+	if err != nil { f.fatalf("Error analyzing %s: %v", root, err) }
+	if analysis.Options.Features != nil && analysis.Language != "rust" && analysis.Language != "mixed" { log.Printf("--features and --include-tests only apply to Rust; every import of this %s tree is kept", analysis.Language) }
+	for _, u := range analysis.Graph.Unparsed { log.Print(relUnparsed(analysis.Root, u)) }
+	return analysis
+}
+
+func (f *analyzeFlags) openHistory() {
+	if *f.history == "" { return }
+	store, err := openHistoryStore(*f.history)
+	if err != nil { f.fatalf("Error opening history %s: %v", *f.history, err) }
+	f.historyStore = store
+	f.cleanups = append(f.cleanups, func() { store.Close() })
+}
+
+// fromSnapshot rebuilds a report from a snapshot file instead of analyzing a tree; see reportFromSnapshot.
+func (f *analyzeFlags) fromSnapshot(path string) *analyzer.Report {
+	snap, err := readSnapshot(path)
+	if err != nil { f.fatalf("Error reading snapshot: %v", err) }
+	analysis, err := reportFromSnapshot(snap)
+	if err != nil { f.fatalf("Error reading snapshot %s: %v", path, err) }
+	f.snapshot = true
+	f.openHistory()
+	return analysis
+}
+
+// analyzeRev analyzes root as committed at rev, from a temporary copy that lives until close. The copy's paths change
+// every run, so it skips the cache.
+func (f *analyzeFlags) analyzeRev(root, rev string) *analyzer.Report {
+	commit, err := gitOutput(root, "rev-parse", "--verify", "--end-of-options", rev+"^{commit}")
+	if err != nil { f.fatalf("Error resolving --rev %s: %v", rev, err) }
+	dir, cleanup, err := checkoutRev(root, commit)
+	if err != nil { f.fatalf("Error reading %s at %s: %v", root, rev, err) }
+	f.cleanups = append(f.cleanups, cleanup)
+	analysis, err := analyzer.Analyze(dir, f.options())
+	if err != nil { f.fatalf("Error analyzing %s at %s: %v", root, rev, err) }
+	analysis.Origin, analysis.Rev = root, commit
+	for _, u := range analysis.Graph.Unparsed { log.Print(relUnparsed(analysis.Root, u)) }
+	return analysis
+}
+
+// analyzeExpanded analyzes root's macro-expanded source from a temporary copy that lives until close; see expandCrate.
+func (f *analyzeFlags) analyzeExpanded(root string) *analyzer.Report {
+	dir, cleanup, err := expandCrate(root)
+	if err != nil { f.fatalf("Error expanding %s: %v", root, err) }
+	f.cleanups = append(f.cleanups, cleanup)
+	analysis, err := analyzer.Analyze(dir, f.options())
+	if err != nil { f.fatalf("Error analyzing the expansion of %s: %v", root, err) }
+	analysis.Origin = root
+	for _, u := range analysis.Graph.Unparsed { log.Print(relUnparsed(analysis.Root, u)) }
+	return analysis
+}
+
+// close removes the temporary copies analyzeRev and analyzeExpanded made and closes the --history store; commands analyzing with these flags defer it.
+func (f *analyzeFlags) close() {
+	for _, cleanup := range f.cleanups { cleanup() }
+	f.cleanups = nil
+}
+
+// fatalf is log.Fatalf for commands analyzing with these flags: os.Exit skips deferred calls, so it runs close first.
+func (f *analyzeFlags) fatalf(format string, args ...any) {
+	f.close()
+	log.Fatalf(format, args...)
+}
+
+func (f *analyzeFlags) options() analyzer.Options {
+	opts := analyzer.Options{Exclude: f.exclude, Depth: *f.depth}
+	if *f.aggregate == "dir" { opts.Aggregate = "dir" }
+	if *f.reExports == "facade" { opts.ReExports = "facade" }
+	opts.NoTests, opts.Mixed = *f.tests == "exclude", *f.mixed
+	if f.features.set || *f.includeTests { opts.Features = &analyzer.FeatureSet{Enabled: uniqueSorted(f.features.names), Tests: *f.includeTests} }
+	return opts
+}
+
+// watch waits for changes to the analyzed tree (see treeChanges) and, whenever a file the analysis reads changes,
+// re-analyzes it and pushes the new report to live. It never returns; failures are logged and the previous report
+// stays up.
+func (f *analyzeFlags) watch(a *analyzer.Report, live *liveReport) {
+	root, opts := a.Root, f.options()
+	print, _ := treeFingerprint(root, a.Config.PathFilter(root))
+	print += notesStamp(root)
+	for range treeChanges(root, a.Config.PathFilter(root), time.Second) {
+		next, err := treeFingerprint(root, a.Config.PathFilter(root))
+		if next += notesStamp(root); err != nil || next == print { continue }
+		print = next
+		start := time.Now()
+		updated, err := analyzeCached(root, opts, !*f.noCache)
+		if err != nil { log.Printf("Re-analysis failed: %v", err); continue }
+		html, err := generateHTMLReport(updated, f.reportOptions(root))
+		if err != nil { log.Printf("Error generating HTML report: %v", err); continue }
+		a = updated // the config may have changed what the walk skips
+		live.update(html)
+		log.Printf("Re-analyzed %s in %v", root, time.Since(start).Round(time.Millisecond))
+	}
+}
+
+func (f *analyzeFlags) reportOptions(root string) ReportOptions {
+	opts := ReportOptions{RootDir: root, MetricsScope: *f.metricsScope, Minimal: *f.minimal, Strict: *f.strict, Live: f.live, NotesAPI: f.notesAPI, SourceLinks: f.sourceLinks, Snapshot: f.snapshot, SeparateTests: *f.tests == "separate"}
+	if *f.sections != "" {
+		opts.Sections = make(map[string]bool)
+		for _, name := range strings.Split(*f.sections, ",") {
+			name = strings.TrimSpace(name)
+			if !slices.Contains(reportSections, name) { f.fatalf("Unknown section %q: expected one of %s", name, strings.Join(reportSections, ", ")) }
+			opts.Sections[name] = true
+		}
+	}
+	if f.snapshot {
+		available := make(map[string]bool)
+		for _, name := range snapshotSections { if opts.Sections == nil || opts.Sections[name] { available[name] = true } }
+		opts.Sections = available
+	}
+	if *f.layout != "" {
+		var err error
+		if opts.Layout, err = readLayout(*f.layout); err != nil { f.fatalf("Error reading layout: %v", err) }
+	}
+	opts.History = f.historyStore
+	return opts
+}
+
+func (f *analyzeFlags) report(a *analyzer.Report) string {
+	htmlContent, err := generateHTMLReport(a, f.reportOptions(a.Root))
+	if err != nil { f.fatalf("Error generating HTML report: %v", err) }
+	return htmlContent
+}
+
+// writeReport writes the report to path as a self-contained file, as with --minimal-report: an archived artifact or a
+// headless CI run cannot count on reaching the font CDN.
+func (f *analyzeFlags) writeReport(a *analyzer.Report, path string) error {
+	standalone, minimal := *f, true
+	standalone.minimal = &minimal
+	return os.WriteFile(path, []byte(standalone.report(a)), 0o644)
+}
+
+// runAnalyze is the default command: analyze, write any side artifacts, then either write the report to
+// --output or serve it until the browser has loaded it.
+func runAnalyze(args []string) {
+	fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+	f := addAnalyzeFlags(fs).addReportFlags(fs)
+	output := fs.String("output", "", "write a self-contained HTML report to this file instead of serving it")
+	port := fs.Int("port", 0, "port to serve the report on (default: any free port)")
+	noBrowser := fs.Bool("no-browser", false, "print the report URL instead of opening a browser")
+	snapshotPath := fs.String("snapshot", "", "also write a JSON snapshot of the analysis to this file")
+	outboundPath := fs.String("outbound", "", "also write each file's outbound imports to this file (.csv or .json)")
+	scipPath := fs.String("scip", "", "also write a SCIP index of definitions and references to this file, for Sourcegraph and similar tools")
+	watch := fs.Bool("watch", false, "keep serving, re-analyze when the tree changes and reload the report in the browser")
+	fs.Usage = func() { fmt.Println("Usage: dependant analyze [flags] <directory>"); fs.PrintDefaults() }
+	fs.Parse(args)
+	if fs.NArg() != 1 { fs.Usage(); os.Exit(1) }
+	if *watch && *output != "" { f.fatalf("--watch serves the report; it cannot be combined with --output") }
+	if *watch && *f.rev != "" { f.fatalf("--rev analyzes a commit, which never changes; it cannot be combined with --watch") }
+	if *watch && *f.expand { f.fatalf("--expand analyzes a one-off expansion; it cannot be combined with --watch") }
+	f.live = *watch
+
+	analysis := f.analyze(fs.Arg(0))
+	defer f.close()
+	for _, side := range []struct{ format, path string }{{"json", *snapshotPath}, {"scip", *scipPath}, {"outbound", *outboundPath}} {
+		if side.path == "" { continue }
+		if err := exportAnalysis(analysis, f, side.format, side.path); err != nil { f.fatalf("Error writing %s: %v", side.path, err) }
+	}
+	if *output != "" {
+		if err := f.writeReport(analysis, *output); err != nil { f.fatalf("Error writing report: %v", err) }
+		fmt.Printf("✅ Analysis complete. Report written to %s\n", *output)
+		return
+	}
+	htmlContent := f.report(analysis)
+	opts := serveOptions{Port: *port, NoBrowser: *noBrowser}
+	if *watch { opts.Live = newLiveReport(htmlContent); go f.watch(analysis, opts.Live) }
+	serveAndOpen(htmlContent, opts)
+}
+
+// runServe serves the report until interrupted, for sharing it on a fixed port.
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	f := addAnalyzeFlags(fs).addReportFlags(fs)
+	port := fs.Int("port", 8080, "port to serve the report on")
+	noBrowser := fs.Bool("no-browser", false, "print the report URL instead of opening a browser")
+	watch := fs.Bool("watch", false, "re-analyze when the tree changes and reload the report in open browsers")
+	from := fs.String("from", "", "serve the report of a snapshot exported earlier (export --format json) instead of analyzing a directory; sections that need the source are left out")
+	fs.Usage = func() { fmt.Println("Usage: dependant serve [flags] <directory>\n       dependant serve [flags] --from <snapshot.json>"); fs.PrintDefaults() }
+	fs.Parse(args)
+	if *from != "" {
+		if fs.NArg() != 0 { fs.Usage(); os.Exit(1) }
+		if *watch || *f.rev != "" || *f.expand { f.fatalf("--from serves a snapshot; it cannot be combined with --watch, --rev or --expand") }
+		analysis := f.fromSnapshot(*from)
+		defer f.close()
+		serveAndOpen(f.report(analysis), serveOptions{Port: *port, NoBrowser: *noBrowser, Persist: true})
+		return
+	}
+	if fs.NArg() != 1 { fs.Usage(); os.Exit(1) }
+	if *watch && *f.rev != "" { f.fatalf("--rev analyzes a commit, which never changes; it cannot be combined with --watch") }
+	if *watch && *f.expand { f.fatalf("--expand analyzes a one-off expansion; it cannot be combined with --watch") }
+	f.live, f.notesAPI, f.sourceLinks = *watch, *f.rev == "" && !*f.expand, true // notes on a commit or an expansion would land in its temporary copy
+	analysis := f.analyze(fs.Arg(0))
+	defer f.close()
+	htmlContent := f.report(analysis)
+	opts := serveOptions{Port: *port, NoBrowser: *noBrowser, Persist: true, Source: analysis.Root, SourceFilter: analysis.Config.PathFilter(analysis.Root, analysis.Options.Exclude...)}
+	if f.notesAPI { opts.Notes = &notesAPI{root: analysis.Root} }
+	if f.notesAPI && !*watch { opts.Notes.render = func() (string, error) { return generateHTMLReport(analysis, f.reportOptions(analysis.Root)) } }
+	if *watch { opts.Live = newLiveReport(htmlContent); go f.watch(analysis, opts.Live) }
+	serveAndOpen(htmlContent, opts)
+}
+
+// exportFormats are the artifacts export can write, with the file name each gets in --output-dir.
+var exportFormats = []string{"json", "html", "dot", "mermaid", "csv", "scip", "outbound", "sarif", "shape"}
+var exportFileNames = map[string]string{"json": "snapshot.json", "html": "report.html", "dot": "modules.dot", "mermaid": "modules.mmd", "csv": "modules.csv", "scip": "index.scip", "outbound": "outbound.csv", "sarif": "findings.sarif", "shape": "shape.json"}
+
+func exportAnalysis(a *analyzer.Report, f *analyzeFlags, format, path string) error {
+	switch format {
+	case "json": return writeSnapshot(path, buildSnapshot(a))
+	case "html": return f.writeReport(a, path)
+	case "dot": return writeDOT(path, a)
+	case "mermaid": return writeMermaid(path, a, f.top)
+	case "csv": return writeCSV(path, buildSnapshot(a))
+	case "scip": return writeSCIP(path, a)
+	case "outbound": return writeFileOutbound(path, computeFileOutbound(a, a.Graph, a.Facts.Tags))
+	case "sarif": return writeFindingsSARIF(path, a)
+	case "shape": return writeShape(path, a)
+	}
+	return fmt.Errorf("unknown format %q: expected one of %s", format, strings.Join(exportFormats, ", "))
+}
+
+// runExport writes one or more artifacts from a single analysis, so CI does not pay for an analysis per format.
+func runExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	f := addAnalyzeFlags(fs).addReportFlags(fs)
+	formatList := fs.String("format", "json", "comma-separated artifacts to write: "+strings.Join(exportFormats, ", ")+" (json is the snapshot; outbound is CSV or JSON by extension; mermaid is fenced for a .md file; csv also writes <name>.items.csv; sarif lists cycles, layer violations, god modules and unused public items for code scanning; shape is anonymized statistics with no names, for sharing)")
+	output := fs.String("output", "", "file to write, for a single format")
+	fs.IntVar(&f.top, "top", 0, "keep only the N modules with the most inbound dependencies in the mermaid graph (0 = all)")
+	var names []string
+	for _, format := range exportFormats { names = append(names, exportFileNames[format]) }
+	outputDir := fs.String("output-dir", "", "directory to write every format into, as "+strings.Join(names, ", "))
+	fs.Usage = func() { fmt.Println("Usage: dependant export --format <formats> (--output <file> | --output-dir <dir>) [flags] <directory>"); fs.PrintDefaults() }
+	fs.Parse(args)
+	var formats []string
+	for _, format := range strings.Split(*formatList, ",") {
+		format = strings.TrimSpace(format)
+		if !slices.Contains(exportFormats, format) { f.fatalf("Unknown --format %q: expected one of %s", format, strings.Join(exportFormats, ", ")) }
+		if !slices.Contains(formats, format) { formats = append(formats, format) }
+	}
+	if fs.NArg() != 1 || (*output == "") == (*outputDir == "") { fs.Usage(); os.Exit(1) }
+	if *output != "" && len(formats) > 1 { f.fatalf("--output takes a single format; use --output-dir for %s", strings.Join(formats, ", ")) }
+	if *outputDir != "" {
+		if err := os.MkdirAll(*outputDir, 0o755); err != nil { f.fatalf("Error creating %s: %v", *outputDir, err) }
+	}
+
+	analysis := f.analyze(fs.Arg(0))
+	defer f.close()
+	for _, format := range formats {
+		path := *output
+		if *outputDir != "" { path = filepath.Join(*outputDir, exportFileNames[format]) }
+		if err := exportAnalysis(analysis, f, format, path); err != nil { f.fatalf("Error writing %s: %v", path, err) }
+		fmt.Printf("✅ Wrote %s\n", path)
+		if format == "csv" { fmt.Printf("✅ Wrote %s\n", csvItemsPath(path)) }
+	}
+}
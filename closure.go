@@ -0,0 +1,67 @@
+package main
+
+import (
+	"sort"
+)
+
+// ClosureInfo is the transitive closure of one module: every module it reaches through its imports, directly or
+// indirectly, by the fewest hops, and the other way round how far a change to it can propagate through its dependents.
+type ClosureInfo struct {
+	Name, Tag   string
+	Reaches     []HopGroup
+	Reached     int // modules in the closure
+	Depth       int // hops to the furthest module it reaches
+	Impacted    int // modules whose closure holds this one
+	ImpactDepth int // hops to the furthest of them
+}
+
+// HopGroup is the modules a closure reaches in the same number of hops.
+type HopGroup struct {
+	Hops    int
+	Modules []string
+}
+
+// forwardReach is the fewest hops from one module to each module it reaches, the mirror of reverseReach.
+func forwardReach(moduleGraph map[string]map[string]struct{}, from string) map[string]int {
+	depth := make(map[string]int)
+	for frontier, d := []string{from}, 1; len(frontier) > 0; d++ {
+		var next []string
+		for _, m := range frontier {
+			for dep := range moduleGraph[m] {
+				if _, seen := depth[dep]; seen || dep == from { continue }
+				depth[dep] = d
+				next = append(next, dep)
+			}
+		}
+		frontier = next
+	}
+	return depth
+}
+
+// computeClosures lists the closure of every module with an edge, those a change propagates furthest from first.
+func computeClosures(moduleGraph map[string]map[string]struct{}, tags map[string]string) []ClosureInfo {
+	modules := make(map[string]struct{})
+	for from, tos := range moduleGraph {
+		modules[from] = struct{}{}
+		for to := range tos { modules[to] = struct{}{} }
+	}
+	var out []ClosureInfo
+	for _, m := range sortedKeys(modules) {
+		info := ClosureInfo{Name: m, Tag: tags[m]}
+		reach := forwardReach(moduleGraph, m)
+		byHops := make(map[int][]string)
+		for dep, hops := range reach { byHops[hops] = append(byHops[hops], dep); info.Depth = max(info.Depth, hops) }
+		for hops := 1; hops <= info.Depth; hops++ {
+			sort.Strings(byHops[hops])
+			info.Reaches = append(info.Reaches, HopGroup{hops, byHops[hops]})
+		}
+		info.Reached = len(reach)
+		for _, hops := range reverseReach(moduleGraph, m) { info.Impacted++; info.ImpactDepth = max(info.ImpactDepth, hops) }
+		out = append(out, info)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].ImpactDepth != out[j].ImpactDepth { return out[i].ImpactDepth > out[j].ImpactDepth }
+		return out[i].Impacted > out[j].Impacted
+	})
+	return out
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"html"
+	"html/template"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// CopyForm is one way the report can put a module or item reference on the clipboard.
+type CopyForm struct{ Label, Text string }
+
+// copyForms lists the references to module, or to its item when item is not "", that a reader is likely to paste
+// next: the path code names it by, an import of it in the tree's language, and the queries that answer questions
+// about it.
+func copyForms(a *analyzer.Report, module, item string) []CopyForm {
+	var forms []CopyForm
+	declared := ""
+	for modPath, m := range a.Facts.ModulePaths {
+		if m == module && (declared == "" || len(modPath) < len(declared) || len(modPath) == len(declared) && modPath < declared) { declared = modPath }
+	}
+	switch a.Language {
+	case "go":
+		importPath := module
+		if a.Manifest != nil && a.Manifest.Name != "" {
+			if declared != "" { importPath = a.Manifest.Name + "/" + strings.ReplaceAll(declared, "::", "/") } else if slices.Contains(a.RootModules(), module) { importPath = a.Manifest.Name }
+		}
+		if item != "" { forms = append(forms, CopyForm{"path", module + "." + item}) }
+		forms = append(forms, CopyForm{"import", "import " + strconv.Quote(importPath)})
+	case "js":
+		if item != "" {
+			forms = append(forms, CopyForm{"import", "import { " + item + " } from " + strconv.Quote(module) + ";"})
+		} else {
+			forms = append(forms, CopyForm{"import", "import " + strconv.Quote(module) + ";"})
+		}
+	case "python":
+		if item != "" {
+			forms = append(forms, CopyForm{"path", module + "." + item}, CopyForm{"import", "from " + module + " import " + item})
+		} else {
+			forms = append(forms, CopyForm{"path", module}, CopyForm{"import", "import " + module})
+		}
+	default:
+		path := "crate::" + module
+		if declared != "" { path = "crate::" + declared }
+		if slices.Contains(a.RootModules(), module) { path = "crate" }
+		if item != "" { path += "::" + item }
+		forms = append(forms, CopyForm{"path", path})
+		if path != "crate" { forms = append(forms, CopyForm{"use", "use " + path + ";"}) }
+	}
+
+	query := "dependant who-uses "
+	if root := a.DisplayRoot(); root != "." && root != "" { query = "dependant who-uses --root " + shellQuote(root) + " " }
+	if item != "" { return append(forms, CopyForm{"who-uses", query + shellQuote(module+"::"+item)}) }
+	forms = append(forms, CopyForm{"who-uses", query + shellQuote(module)})
+	return append(forms, CopyForm{"impact", strings.Replace(query, "who-uses", "impact", 1) + shellQuote(module)})
+}
+
+// shellQuote quotes s for a POSIX shell when it holds anything beyond the characters of paths and module names.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-./:@") == "" { return s }
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// copyAsHTML renders the "copy as" menu of a report row: a button that reveals one button per form.
+func copyAsHTML(forms []CopyForm) template.HTML {
+	var b strings.Builder
+	b.WriteString(`<span class="copy-as"><button type="button" title="Copy as…">⧉</button><span class="copy-menu">`)
+	for _, f := range forms {
+		b.WriteString(`<button type="button" data-copy="` + html.EscapeString(f.Text) + `" title="` + html.EscapeString(f.Text) + `">` + html.EscapeString(f.Label) + `</button>`)
+	}
+	b.WriteString(`</span></span>`)
+	return template.HTML(b.String())
+}
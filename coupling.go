@@ -0,0 +1,73 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+const (
+	couplingCommits    = 1000 // most recent commits mined for change coupling
+	couplingMinShared  = 3    // commits two modules must share before the pair is reported
+	couplingMaxModules = 20   // commits touching more modules (formatting sweeps, mass renames) say nothing about coupling
+)
+
+// ChangeCoupling is a pair of modules that keep changing in the same commits although neither imports the other:
+// a dependency the source does not show, such as a shared file format or protocol.
+type ChangeCoupling struct {
+	A, B       string
+	Shared     int // commits touching both
+	ACommits   int // commits touching A
+	BCommits   int // commits touching B
+	Confidence int // Shared as a percentage of the less often changed module's commits
+}
+
+// computeChangeCoupling mines the git history of root for module pairs that change together at least
+// couplingMinShared times with no static edge between them in either direction. It also returns the number of
+// commits read, which is 0 when root is not in a git repository. With --rev the history is the commit's.
+func computeChangeCoupling(a *analyzer.Report) ([]ChangeCoupling, int) {
+	dir, args := a.DisplayRoot(), []string{"-c", "core.quotePath=false", "log", "--no-merges", "--relative", "--name-only", "--format=%x1e", "-n", strconv.Itoa(couplingCommits)}
+	if a.Rev != "" { args = append(args, a.Rev) }
+	out, err := gitOutput(dir, args...)
+	if err != nil { return nil, 0 }
+	graph := a.BuildModuleGraph(a.Graph.Deps)
+	revisions := make(map[string]int)
+	shared := make(map[[2]string]int)
+	commits := 0
+	for _, entry := range strings.Split(out, "\x1e") {
+		modules := make(map[string]struct{})
+		for _, file := range strings.Split(entry, "\n") {
+			file = strings.TrimSpace(file)
+			if !strings.HasSuffix(file, ".rs") && !strings.HasSuffix(file, ".go") && !analyzer.IsJSFile(file) && !analyzer.IsPythonFile(file) { continue }
+			module := a.ModuleName(filepath.Join(a.Root, filepath.FromSlash(file)))
+			if _, known := a.SymbolTable[module]; known && a.Enforced(module) { modules[module] = struct{}{} }
+		}
+		if strings.TrimSpace(entry) != "" { commits++ }
+		if len(modules) > couplingMaxModules { continue }
+		names := sortedKeys(modules)
+		for i, m := range names {
+			revisions[m]++
+			for _, n := range names[i+1:] { shared[[2]string{m, n}]++ }
+		}
+	}
+
+	var pairs []ChangeCoupling
+	for pair, n := range shared {
+		if n < couplingMinShared { continue }
+		if _, ok := graph[pair[0]][pair[1]]; ok { continue }
+		if _, ok := graph[pair[1]][pair[0]]; ok { continue }
+		c := ChangeCoupling{A: pair[0], B: pair[1], Shared: n, ACommits: revisions[pair[0]], BCommits: revisions[pair[1]]}
+		c.Confidence = 100 * n / min(c.ACommits, c.BCommits)
+		pairs = append(pairs, c)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Shared != pairs[j].Shared { return pairs[i].Shared > pairs[j].Shared }
+		if pairs[i].Confidence != pairs[j].Confidence { return pairs[i].Confidence > pairs[j].Confidence }
+		if pairs[i].A != pairs[j].A { return pairs[i].A < pairs[j].A }
+		return pairs[i].B < pairs[j].B
+	})
+	return pairs, commits
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// csvItemsPath is where the item-level CSV goes next to the module-level one: modules.csv -> modules.items.csv.
+func csvItemsPath(path string) string { return strings.TrimSuffix(path, ".csv") + ".items.csv" }
+
+// writeCSV exports the snapshot for spreadsheets and BI tools as two tables: one row per module with its dependents
+// at path, and one row per imported item with the files importing it at csvItemsPath(path). Lists are space-separated.
+func writeCSV(path string, s *Snapshot) error {
+	dependents := moduleDependents(s)
+	err := writeCSVFile(path, []string{"module", "tag", "public_items", "dependent_modules", "dependent_files", "dependents", "files"}, func(w *csv.Writer) {
+		for _, m := range s.Modules {
+			modules := sortedKeys(dependents[m.Name])
+			w.Write([]string{m.Name, m.Tag, strconv.Itoa(len(m.PublicItems)), strconv.Itoa(len(modules)), strconv.Itoa(len(m.Dependents)), strings.Join(modules, " "), strings.Join(m.Dependents, " ")})
+		}
+	})
+	if err != nil { return err }
+	return writeCSVFile(csvItemsPath(path), []string{"module", "item", "files", "file_list"}, func(w *csv.Writer) {
+		for _, m := range s.Modules {
+			for _, item := range m.Items { w.Write([]string{m.Name, item.Name, strconv.Itoa(len(item.Files)), strings.Join(item.Files, " ")}) }
+		}
+	})
+}
+
+func writeCSVFile(path string, header []string, rows func(*csv.Writer)) error {
+	f, err := os.Create(path)
+	if err != nil { return err }
+	defer f.Close()
+	w := csv.NewWriter(f)
+	w.Write(header)
+	rows(w)
+	w.Flush()
+	return w.Error()
+}
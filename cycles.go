@@ -0,0 +1,99 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// findCycles returns the strongly connected components of a module graph that contain a cycle,
+// each sorted by name, largest first.
+func findCycles(graph map[string]map[string]struct{}) [][]string {
+	index, low, onStack := make(map[string]int), make(map[string]int), make(map[string]bool)
+	var stack []string
+	var cycles [][]string
+	var strongConnect func(v string)
+	strongConnect = func(v string) {
+		index[v], low[v] = len(index), len(index)
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range sortedKeys(graph[v]) {
+			if _, seen := index[w]; !seen {
+				strongConnect(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] != index[v] { return }
+		var component []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			component = append(component, w)
+			if w == v { break }
+		}
+		if len(component) > 1 { sort.Strings(component); cycles = append(cycles, component) }
+	}
+	for _, v := range sortedKeys(graph) { if _, seen := index[v]; !seen { strongConnect(v) } }
+	sort.SliceStable(cycles, func(i, j int) bool { return len(cycles[i]) > len(cycles[j]) })
+	return cycles
+}
+
+// CycleInfo is one group of mutually dependent modules with a concrete cycle through it and the files behind each edge.
+type CycleInfo struct {
+	Modules []string
+	Chain   []string    // a shortest cycle through the first module, ending where it starts
+	Others  []string    // members of the group that Chain does not pass through
+	Edges   []CycleEdge // the edges of Chain, in order; the group's other edges are left out
+}
+
+type CycleEdge struct {
+	From, To string
+	Files    []string // making the edge, relative to the root
+}
+
+func computeCycles(a *analyzer.Report, fileDeps map[string]map[string]struct{}) []CycleInfo {
+	graph, edgeFiles := a.BuildModuleGraph(fileDeps), moduleEdgeFiles(a, fileDeps)
+	var cycles []CycleInfo
+	for _, scc := range findCycles(graph) {
+		members := make(map[string]bool)
+		for _, m := range scc { members[m] = true }
+		sub := make(map[string]map[string]struct{})
+		info := CycleInfo{Modules: scc}
+		for _, from := range scc {
+			for _, to := range sortedKeys(graph[from]) {
+				if !members[to] { continue }
+				if sub[from] == nil { sub[from] = make(map[string]struct{}) }
+				sub[from][to] = struct{}{}
+			}
+		}
+		start := scc[0]
+		for _, next := range sortedKeys(sub[start]) {
+			if path := shortestPath(sub, next, start); path != nil && (info.Chain == nil || len(path)+1 < len(info.Chain)) {
+				info.Chain = append([]string{start}, path...)
+			}
+		}
+		for i := 1; i < len(info.Chain); i++ {
+			from, to := info.Chain[i-1], info.Chain[i]
+			info.Edges = append(info.Edges, CycleEdge{From: from, To: to, Files: edgeFiles[[2]string{from, to}]})
+		}
+		onChain := make(map[string]bool)
+		for _, m := range info.Chain { onChain[m] = true }
+		for _, m := range scc { if !onChain[m] { info.Others = append(info.Others, m) } }
+		cycles = append(cycles, info)
+	}
+	return cycles
+}
+
+// moduleEdgeFiles lists, for each module edge, the files making it, relative to a's root.
+func moduleEdgeFiles(a *analyzer.Report, fileDeps map[string]map[string]struct{}) map[[2]string][]string {
+	files := make(map[[2]string][]string)
+	for file, modules := range fileDeps {
+		from := a.ModuleName(file)
+		for to := range modules { files[[2]string{from, to}] = append(files[[2]string{from, to}], relSlash(a.Root, file)) }
+	}
+	for edge := range files { files[edge] = uniqueSorted(files[edge]) }
+	return files
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// queryRequest and queryResponse are exchanged as single JSON lines over the daemon's Unix socket.
+type queryRequest struct {
+	Query string   `json:"query"`
+	Args  []string `json:"args"`
+}
+
+type queryResponse struct {
+	Lines []string `json:"lines,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+// defaultSocketPath is derived from the absolute root so clients find the daemon for their tree.
+func defaultSocketPath(root string) string {
+	abs, err := filepath.Abs(root)
+	if err != nil { abs = root }
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(os.TempDir(), "dependant-"+hex.EncodeToString(sum[:6])+".sock")
+}
+
+// treeFingerprint summarizes every file the analysis reads, so a poll or a file-system event can tell whether any changed.
+func treeFingerprint(root string, filter *analyzer.PathFilter) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil { return err }
+		if filter.Skip(path, d.IsDir()) { if d.IsDir() { return filepath.SkipDir }; return nil }
+		if d.IsDir() || !isSourceFile(d.Name()) { return nil }
+		info, err := d.Info()
+		if err != nil { return err }
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return hex.EncodeToString(h.Sum(nil)), err
+}
+
+// daemon keeps one analysis warm and swaps in a fresh one whenever the tree changes.
+type daemon struct {
+	root       string
+	mu         sync.RWMutex
+	analysis   *analyzer.Report
+	print      string         // fingerprint of the tree the analysis was built from
+	reportOpts *ReportOptions // set when the daemon also serves the HTML report
+	report     string
+	lastRun    *archView // view at the previous scheduled run, for regression checks
+}
+
+func (d *daemon) refresh() error {
+	d.mu.RLock()
+	filter := analyzer.NewPathFilter(d.root, nil, true)
+	if d.analysis != nil { filter = d.analysis.Config.PathFilter(d.root) }
+	current := d.print
+	d.mu.RUnlock()
+	print, err := treeFingerprint(d.root, filter)
+	if print += notesStamp(d.root); err != nil || print == current { return err }
+	start := time.Now()
+	a, err := analyzer.Analyze(d.root, analyzer.Options{})
+	if err != nil { return err }
+	var report string
+	if d.reportOpts != nil {
+		if report, err = generateHTMLReport(a, *d.reportOpts); err != nil { return fmt.Errorf("generating report: %w", err) }
+	}
+	d.mu.Lock()
+	d.analysis, d.print, d.report = a, print, report
+	d.mu.Unlock()
+	log.Printf("Re-analyzed %s in %v", d.root, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// scheduledRun records the current analysis in the history store, when there is one, and alerts the webhook when the module
+// graph gained cycles or edges since the previous scheduled run.
+func (d *daemon) scheduledRun(history HistoryStore, webhook string) error {
+	if err := d.refresh(); err != nil { return err }
+	d.mu.RLock()
+	view := viewOf(d.analysis)
+	d.mu.RUnlock()
+	if history != nil {
+		if err := history.Append(historyRecordOf(d.root, view, time.Now())); err != nil { return fmt.Errorf("appending history: %w", err) }
+	}
+	previous := d.lastRun
+	d.lastRun = &view
+	if previous == nil || webhook == "" { return nil }
+	diff := diffArchitecture(*previous, view)
+	if len(diff.NewCycles) == 0 && len(diff.NewEdges) == 0 { return nil }
+	var text bytes.Buffer
+	writePRCommentMarkdown(&text, diff, "the previous scheduled run of "+d.root)
+	alert := map[string]any{"text": text.String(), "root": d.root, "newCycles": diff.NewCycles, "newEdges": diff.NewEdges}
+	if err := newAPIClient(webhook, func(*http.Request) {}).do("POST", webhook, alert, nil); err != nil { return fmt.Errorf("webhook: %w", err) }
+	log.Printf("Regression in %s: %d new cycle(s), %d new edge(s); webhook notified", d.root, len(diff.NewCycles), len(diff.NewEdges))
+	return nil
+}
+
+// serveReport serves the latest report until the daemon exits; unlike serveAndOpen it stays up for every visitor.
+func (d *daemon) serveReport(addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", d.reportHandler)
+	return listenPersistent(addr, mux)
+}
+
+// reportHandler serves the latest report at the root of whatever path the handler is mounted on.
+func (d *daemon) reportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" { http.NotFound(w, r); return }
+	if !allowMethods(w, r, http.MethodGet, http.MethodHead) { return }
+	d.mu.RLock()
+	report := d.report
+	d.mu.RUnlock()
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	io.WriteString(w, report)
+}
+
+// listenPersistent serves mux on addr until the process exits, with the routes every report page requests and a
+// Host check when addr is loopback.
+func listenPersistent(addr string, mux *http.ServeMux) error {
+	mux.HandleFunc("/loaded", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	mux.HandleFunc("/favicon.ico", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	var hosts []string
+	if host, port, err := net.SplitHostPort(addr); err == nil && (host == "127.0.0.1" || host == "localhost") {
+		hosts = []string{"127.0.0.1:" + port, "localhost:" + port}
+	}
+	server := &http.Server{Addr: addr, Handler: hardened(mux, hosts...), ReadHeaderTimeout: 5 * time.Second, ReadTimeout: 10 * time.Second, WriteTimeout: 30 * time.Second, IdleTimeout: 60 * time.Second, MaxHeaderBytes: 16 << 10}
+	return server.ListenAndServe()
+}
+
+func (d *daemon) serve(conn net.Conn) {
+	defer conn.Close()
+	var req queryRequest
+	var resp queryResponse
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		resp.Error = "bad request: " + err.Error()
+	} else {
+		d.mu.RLock()
+		lines, err := answerQuery(d.analysis, req.Query, req.Args)
+		d.mu.RUnlock()
+		if err != nil { resp.Error = err.Error() } else { resp.Lines = lines }
+	}
+	json.NewEncoder(conn).Encode(resp)
+}
+
+func runDaemon(args []string) {
+	fs := flag.NewFlagSet("daemon", flag.ExitOnError)
+	interval := fs.Duration("interval", time.Second, "how often to check the tree for changes")
+	socket := fs.String("socket", "", "Unix socket to listen on (default: derived from the directory)")
+	every := fs.Duration("every", 0, "also run on this schedule (e.g. 1h): re-analyze, append to --history and check for regressions")
+	historyPath := fs.String("history", "", "where each scheduled run is recorded: a SQLite file (the default), a .jsonl file or a postgres:// URL")
+	webhook := fs.String("webhook", "", "URL to POST a JSON alert to when a scheduled run finds new cycles or module edges")
+	serveAddr := fs.String("serve", "", "also serve the always-current HTML report on this address, e.g. 127.0.0.1:8080")
+	fs.Usage = func() { fmt.Println("Usage: dependant daemon [flags] <directory>"); fs.PrintDefaults() }
+	fs.Parse(args)
+	if fs.NArg() != 1 { fs.Usage(); os.Exit(1) }
+	if (*historyPath != "" || *webhook != "") && *every == 0 { log.Fatalf("--history and --webhook need a schedule; add --every") }
+	var history HistoryStore
+	if *historyPath != "" {
+		var err error
+		if history, err = openHistoryStore(*historyPath); err != nil { log.Fatalf("Error opening history %s: %v", *historyPath, err) }
+		defer history.Close()
+	}
+	d := &daemon{root: fs.Arg(0)}
+	if *serveAddr != "" { d.reportOpts = &ReportOptions{RootDir: d.root, MetricsScope: "all", History: history} }
+	if err := d.refresh(); err != nil { log.Fatalf("Error analyzing %s: %v", d.root, err) }
+
+	if *socket == "" { *socket = defaultSocketPath(d.root) }
+	if conn, err := net.Dial("unix", *socket); err == nil { conn.Close(); log.Fatalf("A daemon is already listening on %s", *socket) }
+	os.Remove(*socket) // stale socket from a daemon that did not shut down cleanly
+	listener, err := net.Listen("unix", *socket)
+	if err != nil { log.Fatalf("Could not listen on %s: %v", *socket, err) }
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() { <-stop; listener.Close() }()
+
+	go func() {
+		var schedule <-chan time.Time
+		if *every > 0 {
+			if err := d.scheduledRun(history, *webhook); err != nil { log.Printf("Scheduled run failed: %v", err) }
+			schedule = time.Tick(*every)
+		}
+		poll := time.Tick(*interval)
+		for {
+			select {
+			case <-poll: if err := d.refresh(); err != nil { log.Printf("Re-analysis failed: %v", err) }
+			case <-schedule: if err := d.scheduledRun(history, *webhook); err != nil { log.Printf("Scheduled run failed: %v", err) }
+			}
+		}
+	}()
+	if *serveAddr != "" {
+		go func() { if err := d.serveReport(*serveAddr); err != nil { log.Fatalf("Report server error: %v", err) } }()
+		fmt.Printf("✅ Serving the live report on http://%s\n", *serveAddr)
+	}
+	fmt.Printf("✅ Watching %s; answering queries on %s\n", d.root, *socket)
+	for {
+		conn, err := listener.Accept()
+		if errors.Is(err, net.ErrClosed) { return }
+		if err != nil { log.Printf("Accept failed: %v", err); continue }
+		go d.serve(conn)
+	}
+}
+
+// runQuery asks the daemon for the tree, falling back to a one-off analysis when none is running.
+func runQuery(query string, args []string) {
+	fs := flag.NewFlagSet(query, flag.ExitOnError)
+	root := fs.String("root", ".", "directory the query is about")
+	socket := fs.String("socket", "", "daemon socket (default: derived from --root)")
+	fs.Usage = func() { fmt.Println("Usage: dependant " + queryNames[query]); fs.PrintDefaults() }
+	fs.Parse(args)
+	if *socket == "" { *socket = defaultSocketPath(*root) }
+
+	var resp queryResponse
+	if conn, err := net.DialTimeout("unix", *socket, 200*time.Millisecond); err == nil {
+		defer conn.Close()
+		if err := json.NewEncoder(conn).Encode(queryRequest{Query: query, Args: fs.Args()}); err != nil { log.Fatalf("Error talking to daemon: %v", err) }
+		if err := json.NewDecoder(conn).Decode(&resp); err != nil { log.Fatalf("Error reading daemon response: %v", err) }
+	} else {
+		fmt.Fprintln(os.Stderr, "(no daemon running; analyzing from scratch)")
+		a, err := analyzeCached(*root, analyzer.Options{}, true)
+		if err != nil { log.Fatalf("Error analyzing %s: %v", *root, err) }
+		resp.Lines, err = answerQuery(a, query, fs.Args())
+		if err != nil { resp.Error = err.Error() }
+	}
+	if resp.Error != "" { log.Fatal(resp.Error) }
+	for _, line := range resp.Lines { fmt.Println(line) }
+}
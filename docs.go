@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// moduleDoc is what the docs subcommand knows about one module.
+type moduleDoc struct {
+	Name, Tag    string
+	Public       []string
+	Importers    map[string][]string // public item -> importing files
+	Dependents   map[string][]string // dependent module -> its importing files
+	Dependencies map[string][]string // module used -> items used ("" for bare module imports)
+}
+
+func collectModuleDocs(a *analyzer.Report) map[string]*moduleDoc {
+	docs := make(map[string]*moduleDoc)
+	get := func(name string) *moduleDoc {
+		if docs[name] == nil { docs[name] = &moduleDoc{Name: name, Tag: a.Facts.Tags[name], Importers: map[string][]string{}, Dependents: map[string][]string{}, Dependencies: map[string][]string{}} }
+		return docs[name]
+	}
+	rel := func(file string) string { r, _ := filepath.Rel(a.Root, file); return filepath.ToSlash(r) }
+	for module, items := range a.SymbolTable { get(module).Public = sortedKeys(items) }
+	for file, deps := range a.Graph.Deps {
+		from := a.ModuleName(file)
+		for to := range deps {
+			if to == from { continue }
+			get(to).Dependents[from] = append(get(to).Dependents[from], rel(file))
+			if _, ok := get(from).Dependencies[to]; !ok { get(from).Dependencies[to] = nil }
+		}
+	}
+	for module, items := range a.Graph.ItemImports {
+		for item, files := range items {
+			for file := range files {
+				get(module).Importers[item] = append(get(module).Importers[item], rel(file))
+				if from := a.ModuleName(file); from != module { get(from).Dependencies[module] = append(get(from).Dependencies[module], item) }
+			}
+		}
+	}
+	return docs
+}
+
+// renderModuleDoc writes a README stub seeded from the analysis; the Overview is left for people to fill in.
+func renderModuleDoc(d *moduleDoc) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# `%s`\n\n", d.Name)
+	if d.Tag != "" { fmt.Fprintf(&sb, "Layer: **%s**\n\n", d.Tag) }
+	sb.WriteString("<!-- Seeded by `dependant docs`. Edit freely; rerun with --force to regenerate from the code. -->\n\n")
+	sb.WriteString("## Overview\n\n_What is this module responsible for, and what should not live here?_\n\n")
+	sb.WriteString("## Public API\n\n")
+	if len(d.Public) == 0 { sb.WriteString("No public items.\n\n") } else {
+		sb.WriteString("| Item | Imported by |\n|---|---|\n")
+		for _, item := range d.Public {
+			files := uniqueSorted(d.Importers[item])
+			used := "_unused_"
+			if len(files) > 0 { used = fmt.Sprintf("%d file(s): %s", len(files), strings.Join(files, ", ")) }
+			fmt.Fprintf(&sb, "| `%s` | %s |\n", item, used)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("## Dependents\n\n")
+	if len(d.Dependents) == 0 { sb.WriteString("No other module imports this one.\n\n") } else {
+		for _, m := range sortedKeys(d.Dependents) { fmt.Fprintf(&sb, "- [`%s`](%s.md) — %s\n", m, m, strings.Join(uniqueSorted(d.Dependents[m]), ", ")) }
+		sb.WriteString("\n")
+	}
+	sb.WriteString("## Dependencies\n\n")
+	if len(d.Dependencies) == 0 { sb.WriteString("This module imports nothing from the rest of the crate.\n") } else {
+		for _, m := range sortedKeys(d.Dependencies) {
+			items := uniqueSorted(d.Dependencies[m])
+			if len(items) == 0 { fmt.Fprintf(&sb, "- [`%s`](%s.md)\n", m, m); continue }
+			fmt.Fprintf(&sb, "- [`%s`](%s.md): `%s`\n", m, m, strings.Join(items, "`, `"))
+		}
+	}
+	return sb.String()
+}
+
+func runDocs(args []string) {
+	fs := flag.NewFlagSet("docs", flag.ExitOnError)
+	out := fs.String("out", "docs/modules", "directory to write the Markdown stubs to, relative to the analyzed directory")
+	force := fs.Bool("force", false, "overwrite stubs that already exist")
+	fs.Usage = func() { fmt.Println("Usage: dependant docs [flags] <directory>"); fs.PrintDefaults() }
+	fs.Parse(args)
+	if fs.NArg() != 1 { fs.Usage(); os.Exit(1) }
+	root := fs.Arg(0)
+	a, err := analyzeCached(root, analyzer.Options{}, true)
+	if err != nil { log.Fatalf("Error analyzing %s: %v", root, err) }
+
+	dir := *out
+	if !filepath.IsAbs(dir) { dir = filepath.Join(root, dir) }
+	if err := os.MkdirAll(dir, 0o755); err != nil { log.Fatalf("Error creating %s: %v", dir, err) }
+	docs := collectModuleDocs(a)
+	var index strings.Builder
+	index.WriteString("# Modules\n\n<!-- Seeded by `dependant docs`. -->\n\n| Module | Public items | Dependents | Dependencies |\n|---|---:|---:|---:|\n")
+	written, kept := 0, 0
+	for _, name := range sortedKeys(docs) {
+		d := docs[name]
+		fmt.Fprintf(&index, "| [`%s`](%s.md) | %d | %d | %d |\n", name, name, len(d.Public), len(d.Dependents), len(d.Dependencies))
+		target := filepath.Join(dir, name+".md")
+		if _, err := os.Stat(target); err == nil && !*force { kept++; continue } else if err != nil && !errors.Is(err, os.ErrNotExist) { log.Fatalf("Error checking %s: %v", target, err) }
+		if err := os.WriteFile(target, []byte(renderModuleDoc(d)), 0o644); err != nil { log.Fatalf("Error writing %s: %v", target, err) }
+		written++
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte(index.String()), 0o644); err != nil { log.Fatalf("Error writing index: %v", err) }
+	fmt.Printf("✅ Wrote %d module stub(s) to %s", written, dir)
+	if kept > 0 { fmt.Printf(" (kept %d existing; pass --force to regenerate)", kept) }
+	fmt.Println()
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+)
+
+// EdgeMeta is what a history knows about a module-to-module edge: when it first appeared and when a record last
+// showed it. An edge that disappeared and came back counts from its return, so FirstSeen answers "added when".
+type EdgeMeta struct {
+	From         string    `json:"from"`
+	To           string    `json:"to"`
+	FirstSeen    time.Time `json:"firstSeen"`
+	LastVerified time.Time `json:"lastVerified"`
+	Current      bool      `json:"current"` // in the newest record
+}
+
+// edgeMetadata replays records, oldest first, into each edge's metadata, keyed by [from, to].
+func edgeMetadata(records []HistoryRecord) map[[2]string]EdgeMeta {
+	meta := make(map[[2]string]EdgeMeta)
+	var previous map[[2]string]bool
+	for _, rec := range records {
+		seen := make(map[[2]string]bool, len(rec.Edges))
+		for _, e := range rec.Edges {
+			seen[e] = true
+			m, ok := meta[e]
+			if !ok || !previous[e] { m = EdgeMeta{From: e[0], To: e[1], FirstSeen: rec.Time} }
+			m.LastVerified = rec.Time
+			meta[e] = m
+		}
+		previous = seen
+	}
+	for e, m := range meta { m.Current = previous[e]; meta[e] = m }
+	return meta
+}
+
+// readEdgeMetadata loads a history store's records and replays them; see edgeMetadata.
+func readEdgeMetadata(store HistoryStore) (map[[2]string]EdgeMeta, error) {
+	records, err := store.Records()
+	if err != nil { return nil, fmt.Errorf("reading history: %w", err) }
+	return edgeMetadata(records), nil
+}
+
+// EdgeAge is a module edge of the analyzed tree as the report's edge history lists it; Recorded is false for an edge no
+// history record has shown yet.
+type EdgeAge struct {
+	From, To, Tag string
+	Recorded      bool
+	FirstSeen     time.Time
+	LastVerified  time.Time
+}
+
+func computeEdgeAges(graph map[string]map[string]struct{}, meta map[[2]string]EdgeMeta, tags map[string]string) []EdgeAge {
+	var ages []EdgeAge
+	for _, from := range sortedKeys(graph) {
+		for _, to := range sortedKeys(graph[from]) {
+			age := EdgeAge{From: from, To: to, Tag: tags[from]}
+			if m, ok := meta[[2]string{from, to}]; ok && m.Current { age.Recorded, age.FirstSeen, age.LastVerified = true, m.FirstSeen, m.LastVerified }
+			ages = append(ages, age)
+		}
+	}
+	// Newest first; edges no record has shown yet are newer still.
+	sort.SliceStable(ages, func(i, j int) bool {
+		if ages[i].Recorded != ages[j].Recorded { return !ages[i].Recorded }
+		return ages[i].FirstSeen.After(ages[j].FirstSeen)
+	})
+	return ages
+}
+
+// runEdges lists the module edges a history has recorded with when each first appeared and was last observed,
+// answering "which dependencies were added in the last 30 days" (--added-within 30d) or "which went away" (--removed).
+func runEdges(args []string) {
+	fs := flag.NewFlagSet("edges", flag.ExitOnError)
+	historyPath := fs.String("history", "", "history to read: a SQLite file (the default), a .jsonl file or a postgres:// URL, as written by daemon --history or history --history")
+	addedWithin := fs.String("added-within", "", "only edges first seen within this interval, e.g. 30d, 2w or 720h")
+	removed := fs.Bool("removed", false, "list edges the newest record no longer shows, instead of current ones")
+	format := fs.String("format", "text", "output format: text or json")
+	fs.Usage = func() { fmt.Println("Usage: dependant edges --history <location> [flags]"); fs.PrintDefaults() }
+	fs.Parse(args)
+	if *historyPath == "" || fs.NArg() != 0 { fs.Usage(); os.Exit(1) }
+	if *format != "text" && *format != "json" { log.Fatalf("Unknown --format %q: expected text or json", *format) }
+	var cutoff time.Time
+	if *addedWithin != "" {
+		within, err := parseInterval(*addedWithin)
+		if err != nil { log.Fatalf("Invalid --added-within: %v", err) }
+		cutoff = time.Now().Add(-within)
+	}
+
+	store, err := openHistoryStore(*historyPath)
+	if err != nil { log.Fatalf("Error opening history %s: %v", *historyPath, err) }
+	meta, err := readEdgeMetadata(store)
+	store.Close()
+	if err != nil { log.Fatal(err) }
+	edges := []EdgeMeta{}
+	for _, m := range meta { if m.Current != *removed && !m.FirstSeen.Before(cutoff) { edges = append(edges, m) } }
+	sort.Slice(edges, func(i, j int) bool {
+		if !edges[i].FirstSeen.Equal(edges[j].FirstSeen) { return edges[i].FirstSeen.After(edges[j].FirstSeen) }
+		if edges[i].From != edges[j].From { return edges[i].From < edges[j].From }
+		return edges[i].To < edges[j].To
+	})
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(edges); err != nil { log.Fatalf("Error writing JSON: %v", err) }
+		return
+	}
+	if len(meta) == 0 { fmt.Println("The history records no module edges yet"); return }
+	if len(edges) == 0 { fmt.Println("✅ No edges match"); return }
+	width := len("Edge")
+	for _, e := range edges { width = max(width, len(e.From)+3+len(e.To)) } // fmt pads by rune: " → " is three
+	fmt.Printf("%-*s  %-10s  %s\n", width, "Edge", "First seen", "Last verified")
+	for _, e := range edges {
+		fmt.Printf("%-*s  %s  %s\n", width, e.From+" → "+e.To, e.FirstSeen.Format("2006-01-02"), e.LastVerified.Format("2006-01-02"))
+	}
+	fmt.Printf("\n%d edge%s\n", len(edges), plural(len(edges)))
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// inlineModRegex finds a module declared with its body, as cargo expand prints every module.
+var inlineModRegex = regexp.MustCompile(`\bmod\s+(?:r#)?(\w+)\s*\{`)
+
+// expandCrate copies the Rust crate at root to a temporary directory and replaces each module file reached from the
+// crate root with its source as `cargo expand` prints it, so imports that macros such as cfg_if! or derives generate
+// are read like any other. Files the expansion does not reach, such as #[cfg(test)] modules, keep their source. The
+// expansion drops comments, so each file keeps its leading comment block, where tag and generated markers live.
+func expandCrate(root string) (dir string, cleanup func(), err error) {
+	if _, err := os.Stat(filepath.Join(root, "Cargo.toml")); err != nil { return "", nil, fmt.Errorf("--expand needs a Cargo crate: %w", err) }
+	if _, err := exec.LookPath("cargo-expand"); err != nil { return "", nil, errors.New("cargo expand is not installed; install it with `cargo install cargo-expand`") }
+	rootFile, args := "src/main.rs", []string{"expand", "--color=never"}
+	if _, err := os.Stat(filepath.Join(root, "src", "lib.rs")); err == nil { rootFile, args = "src/lib.rs", append(args, "--lib") }
+	cmd := exec.Command("cargo", args...)
+	cmd.Dir = root
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	expanded, err := cmd.Output()
+	if err != nil { return "", nil, fmt.Errorf("cargo expand: %v: %s", err, strings.TrimSpace(stderr.String())) }
+
+	dir, err = os.MkdirTemp("", "dependant-expand-")
+	if err != nil { return "", nil, err }
+	cleanup = func() { os.RemoveAll(dir) }
+	if err := copyTree(root, dir); err != nil { cleanup(); return "", nil, err }
+	x := expansion{origin: root, dir: dir}
+	if err := x.write(rootFile, "src", string(expanded)); err != nil { cleanup(); return "", nil, err }
+	return dir, cleanup, nil
+}
+
+// copyTree copies the regular files under root that the config does not exclude into dir.
+func copyTree(root, dir string) error {
+	cfg, err := analyzer.LoadConfig(root)
+	if err != nil { return err }
+	filter := cfg.PathFilter(root)
+	return filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil { return err }
+		if d.IsDir() && (d.Name() == ".git" || filter.Skip(path, true)) && path != root { return filepath.SkipDir }
+		if !d.Type().IsRegular() { return nil }
+		rel, _ := filepath.Rel(root, path)
+		target := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil { return err }
+		in, err := os.Open(path)
+		if err != nil { return err }
+		defer in.Close()
+		out, err := os.Create(target)
+		if err != nil { return err }
+		if _, err := io.Copy(out, in); err != nil { out.Close(); return err }
+		return out.Close()
+	})
+}
+
+// expansion writes cargo expand's output, one crate in a single file, back over the copy's module files.
+type expansion struct {
+	origin, dir string
+}
+
+// write writes src, the expansion of the module in file rel whose submodule files live under subdir, moving each
+// inline module that the original tree keeps in a file of its own back into that file. Inline modules of the
+// original source stay inline.
+func (x expansion) write(rel, subdir, src string) error {
+	code := analyzer.StripNonCode(src) // same offsets as src, with braces in strings and comments blanked
+	var out strings.Builder
+	out.WriteString(leadingComments(filepath.Join(x.origin, filepath.FromSlash(rel))))
+	last, scanned, depth := 0, 0, 0
+	for _, m := range inlineModRegex.FindAllStringSubmatchIndex(code, -1) {
+		if m[0] < last { continue } // inside a module already moved out
+		depth += strings.Count(code[scanned:m[0]], "{") - strings.Count(code[scanned:m[0]], "}")
+		if scanned = m[0]; depth != 0 { continue }
+		name, open := code[m[2]:m[3]], m[1]-1
+		end := matchingBrace(code, open)
+		if end < 0 { break }
+		childRel, childSubdir, ok := x.moduleFile(subdir, name)
+		if !ok { continue }
+		out.WriteString(src[last:m[0]])
+		fmt.Fprintf(&out, "mod %s;", strings.TrimSpace(src[m[0]+len("mod"):open]))
+		if err := x.write(childRel, childSubdir, src[open+1:end]); err != nil { return err }
+		last, scanned = end+1, end+1
+	}
+	out.WriteString(src[last:])
+	return os.WriteFile(filepath.Join(x.dir, filepath.FromSlash(rel)), []byte(out.String()), 0o644)
+}
+
+// moduleFile finds the file the original tree keeps submodule name of a module in, with the directory of its own
+// submodules.
+func (x expansion) moduleFile(subdir, name string) (rel, childSubdir string, ok bool) {
+	childSubdir = subdir + "/" + name
+	for _, candidate := range []string{subdir + "/" + name + ".rs", childSubdir + "/mod.rs"} {
+		if info, err := os.Stat(filepath.Join(x.origin, filepath.FromSlash(candidate))); err == nil && info.Mode().IsRegular() { return candidate, childSubdir, true }
+	}
+	return "", "", false
+}
+
+// matchingBrace returns the offset of the brace closing the one at open, or -1.
+func matchingBrace(code string, open int) int {
+	depth := 0
+	for i := open; i < len(code); i++ {
+		switch code[i] {
+		case '{': depth++
+		case '}': if depth--; depth == 0 { return i }
+		}
+	}
+	return -1
+}
+
+// leadingComments returns the comment lines, and blank lines between them, that open the file at path.
+func leadingComments(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil { return "" }
+	var sb strings.Builder
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "//") { break }
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
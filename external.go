@@ -0,0 +1,54 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// ExternalCrateInfo is a usage-weighted view of one third-party crate.
+type ExternalCrateInfo struct {
+	Name, Source string
+	Versions     []string
+	Declared     bool     // listed in Cargo.toml
+	Files        []string // importing files
+	Items        []ItemInfo
+}
+
+// computeCrateAudit joins imported external crates with Cargo.toml declarations and Cargo.lock versions.
+// Declared crates that no file imports are kept (with no files) since they are part of the third-party surface too.
+func computeCrateAudit(external map[string]map[string]map[string]struct{}, manifest *analyzer.CargoManifest, lock []analyzer.LockedPackage) []ExternalCrateInfo {
+	names := make(map[string]struct{})
+	for name := range external { if name != manifest.Name { names[name] = struct{}{} } }
+	for name := range manifest.Dependencies { names[name] = struct{}{} }
+
+	var audit []ExternalCrateInfo
+	for name := range names {
+		pkg, declared := manifest.Dependencies[name]
+		if !declared { pkg = name }
+		info := ExternalCrateInfo{Name: name, Declared: declared, Source: "unknown"}
+		for _, p := range lock {
+			if analyzer.CrateImportName(p.Name) != analyzer.CrateImportName(pkg) { continue }
+			info.Versions = append(info.Versions, p.Version)
+			info.Source = sourceKind(p.Source)
+		}
+		sort.Strings(info.Versions)
+		var files []string
+		for item, fileSet := range external[name] {
+			var itemFiles []string
+			for f := range fileSet { itemFiles = append(itemFiles, f) }
+			files = append(files, itemFiles...)
+			short := shortFileNames(itemFiles)
+			info.Items = append(info.Items, ItemInfo{ModuleName: name, Name: item, CountStr: strconv.Itoa(len(short)), Files: short})
+		}
+		info.Files = shortFileNames(files)
+		sort.Slice(info.Items, func(i, j int) bool { return info.Items[i].Name < info.Items[j].Name })
+		audit = append(audit, info)
+	}
+	sort.Slice(audit, func(i, j int) bool {
+		if len(audit[i].Files) != len(audit[j].Files) { return len(audit[i].Files) > len(audit[j].Files) }
+		return audit[i].Name < audit[j].Name
+	})
+	return audit
+}
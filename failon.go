@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	pathpkg "path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// failOnRules are the rules --fail-on can select: those of check, plus new-edges and glob-imports, which only
+// --fail-on turns on.
+var failOnRules = []string{"layers", "test-imports", "importers", "budgets", "stability", "max-dependents", "cycles", "boundaries", "new-edges", "glob-imports"}
+
+// failSelector is one --fail-on term, `rule[:module=<pattern>|tag=<tag>][>N]`: the check fails when more than N
+// (default 0) of the rule's violations involve a matching module. A module pattern names a module (with its
+// submodules), a tag or a glob of module names, as a layer does.
+type failSelector struct {
+	Text, Rule  string
+	Module, Tag string
+	Max         int
+}
+
+func parseFailSelector(text string) (failSelector, error) {
+	s := failSelector{Text: text}
+	rest := text
+	if head, limit, ok := strings.Cut(rest, ">"); ok {
+		n, err := strconv.Atoi(strings.TrimSpace(limit))
+		if err != nil || n < 0 { return s, fmt.Errorf("--fail-on %s: expected a count after >, as in glob-imports>10", text) }
+		s.Max, rest = n, head
+	}
+	rule, filter, _ := strings.Cut(rest, ":")
+	if s.Rule = strings.TrimSpace(rule); !slices.Contains(failOnRules, s.Rule) { return s, fmt.Errorf("--fail-on %s: unknown rule %q: expected one of %s", text, s.Rule, strings.Join(failOnRules, ", ")) }
+	if filter = strings.TrimSpace(filter); filter == "" { return s, nil }
+	key, value, _ := strings.Cut(filter, "=")
+	switch value = strings.TrimSpace(value); strings.TrimSpace(key) {
+	case "module": s.Module = value
+	case "tag": s.Tag = value
+	default: return s, fmt.Errorf("--fail-on %s: unknown filter %q: expected module=<pattern> or tag=<tag>", text, filter)
+	}
+	if value == "" { return s, fmt.Errorf("--fail-on %s: empty filter", text) }
+	if _, err := pathpkg.Match(value, ""); err != nil { return s, fmt.Errorf("--fail-on %s: bad pattern %q", text, value) }
+	return s, nil
+}
+
+// checkHit is one violation as --fail-on counts it: the rule that found it and the modules it involves.
+type checkHit struct {
+	Rule    string
+	Modules []string
+}
+
+// count is how many hits the selector selects.
+func (s failSelector) count(hits []checkHit, tags map[string]string) int {
+	n := 0
+	for _, h := range hits {
+		if h.Rule != s.Rule { continue }
+		if s.Module == "" && s.Tag == "" { n++; continue }
+		for _, m := range h.Modules {
+			if (s.Module != "" && layerLevel([]string{s.Module}, m, tags[m]) == 0) || (s.Tag != "" && tags[m] == s.Tag) { n++; break }
+		}
+	}
+	return n
+}
+
+// writeGates prints how each selector fared and returns how many failed.
+func writeGates(w io.Writer, selectors []failSelector, hits []checkHit, tags map[string]string) int {
+	failed := 0
+	for _, s := range selectors {
+		n := s.count(hits, tags)
+		if n > s.Max {
+			failed++
+			fmt.Fprintf(w, "❌ %s: %d violation%s, over the limit of %d\n", s.Text, n, plural(n), s.Max)
+		} else {
+			fmt.Fprintf(w, "✅ %s: %d violation%s (limit %d)\n", s.Text, n, plural(n), s.Max)
+		}
+	}
+	return failed
+}
+
+// GlobImport is a file importing a module's items through a glob (`use module::*`, `import * as m`), which hides
+// which items it depends on. Globs whose items the file never mentions are not seen.
+type GlobImport struct {
+	File             string // relative to the root
+	Importer, Module string
+}
+
+func findGlobImports(a *analyzer.Report) []GlobImport {
+	seen := make(map[[2]string]bool)
+	var found []GlobImport
+	for _, module := range sortedKeys(a.Graph.Inferred) {
+		for _, item := range sortedKeys(a.Graph.Inferred[module]) {
+			for file, why := range a.Graph.Inferred[module][item] {
+				if why != analyzer.InferredGlob || seen[[2]string{file, module}] { continue }
+				seen[[2]string{file, module}] = true
+				found = append(found, GlobImport{File: relSlash(a.Root, file), Importer: a.ModuleName(file), Module: module})
+			}
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { if found[i].File != found[j].File { return found[i].File < found[j].File }; return found[i].Module < found[j].Module })
+	return found
+}
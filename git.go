@@ -0,0 +1,56 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// gitOutput runs git in dir and returns its trimmed stdout, with stderr folded into the error.
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil { return "", fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String())) }
+	return strings.TrimSpace(string(out)), nil
+}
+
+// checkoutRev extracts root as it was at rev into a temporary directory, without touching the working tree.
+// root may be a subdirectory of the repository; the same subdirectory is extracted.
+func checkoutRev(root, rev string) (dir string, cleanup func(), err error) {
+	prefix, err := gitOutput(root, "rev-parse", "--show-prefix")
+	if err != nil { return "", nil, err }
+	cmd := exec.Command("git", "-C", root, "archive", "--format=tar", rev+":"+prefix)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	archive, err := cmd.Output()
+	if err != nil { return "", nil, fmt.Errorf("git archive %s: %v: %s", rev, err, strings.TrimSpace(stderr.String())) }
+
+	dir, err = os.MkdirTemp("", "dependant-rev-")
+	if err != nil { return "", nil, err }
+	cleanup = func() { os.RemoveAll(dir) }
+	tr := tar.NewReader(bytes.NewReader(archive))
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) { return dir, cleanup, nil }
+		if err != nil { cleanup(); return "", nil, err }
+		target := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if !strings.HasPrefix(target, dir+string(filepath.Separator)) { continue } // never write outside the temp dir
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil { cleanup(); return "", nil, err }
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil { cleanup(); return "", nil, err }
+			content, err := io.ReadAll(tr)
+			if err == nil { err = os.WriteFile(target, content, 0o644) }
+			if err != nil { cleanup(); return "", nil, err }
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// githubPR addresses one pull request through the GitHub REST API.
+// It is configured from the environment GitHub Actions provides: GITHUB_TOKEN, GITHUB_REPOSITORY,
+// GITHUB_API_URL (for GitHub Enterprise) and, for the PR number, GITHUB_EVENT_PATH or GITHUB_REF.
+type githubPR struct {
+	api    *apiClient
+	repo   string
+	number int
+}
+
+var pullRefRegex = regexp.MustCompile(`^refs/pull/(\d+)/`)
+
+func githubFromEnv(number int) (*githubPR, error) {
+	base, repo, token := os.Getenv("GITHUB_API_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_TOKEN")
+	if base == "" { base = "https://api.github.com" }
+	if token == "" { return nil, errors.New("GITHUB_TOKEN is not set") }
+	if repo == "" { return nil, errors.New("GITHUB_REPOSITORY is not set (expected owner/repo)") }
+	if number == 0 {
+		if content, err := os.ReadFile(os.Getenv("GITHUB_EVENT_PATH")); err == nil {
+			var event struct { PullRequest struct { Number int `json:"number"` } `json:"pull_request"` }
+			if json.Unmarshal(content, &event) == nil { number = event.PullRequest.Number }
+		}
+	}
+	if m := pullRefRegex.FindStringSubmatch(os.Getenv("GITHUB_REF")); number == 0 && m != nil { number, _ = strconv.Atoi(m[1]) }
+	if number == 0 { return nil, errors.New("no pull request number: pass --pr or run from a pull_request workflow") }
+	api := newAPIClient(base, func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	})
+	return &githubPR{api: api, repo: repo, number: number}, nil
+}
+
+func (gh *githubPR) upsertComment(body string) (string, error) {
+	type comment struct {
+		ID      int64  `json:"id"`
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+	}
+	var existing comment
+	for page := 1; existing.ID == 0; page++ {
+		var comments []comment
+		if err := gh.api.do("GET", fmt.Sprintf("/repos/%s/issues/%d/comments?per_page=100&page=%d", gh.repo, gh.number, page), nil, &comments); err != nil { return "", err }
+		for _, c := range comments { if strings.Contains(c.Body, prCommentMarker) { existing = c; break } }
+		if len(comments) < 100 { break }
+	}
+	var result comment
+	payload := map[string]string{"body": body}
+	if existing.ID != 0 {
+		return existing.HTMLURL, gh.api.do("PATCH", fmt.Sprintf("/repos/%s/issues/comments/%d", gh.repo, existing.ID), payload, &result)
+	}
+	err := gh.api.do("POST", fmt.Sprintf("/repos/%s/issues/%d/comments", gh.repo, gh.number), payload, &result)
+	return result.HTMLURL, err
+}
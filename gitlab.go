@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// gitlabMR addresses one merge request through the GitLab REST API (v4).
+// It is configured from GitLab CI's merge request pipeline variables: CI_API_V4_URL, CI_PROJECT_ID,
+// CI_MERGE_REQUEST_IID and CI_MERGE_REQUEST_PROJECT_URL, plus GITLAB_TOKEN, a token allowed to write notes
+// (the job token cannot).
+type gitlabMR struct {
+	api        *apiClient
+	project    string
+	iid        int
+	projectURL string
+}
+
+func gitlabFromEnv(iid int) (*gitlabMR, error) {
+	base, project, token := os.Getenv("CI_API_V4_URL"), os.Getenv("CI_PROJECT_ID"), os.Getenv("GITLAB_TOKEN")
+	if base == "" { base = "https://gitlab.com/api/v4" }
+	if token == "" { return nil, errors.New("GITLAB_TOKEN is not set") }
+	if project == "" { return nil, errors.New("CI_PROJECT_ID is not set (a project ID or group/project path)") }
+	if iid == 0 { iid, _ = strconv.Atoi(os.Getenv("CI_MERGE_REQUEST_IID")) }
+	if iid == 0 { return nil, errors.New("no merge request: pass --pr or run in a merge request pipeline") }
+	api := newAPIClient(base, func(req *http.Request) { req.Header.Set("PRIVATE-TOKEN", token) })
+	return &gitlabMR{api: api, project: url.PathEscape(project), iid: iid, projectURL: os.Getenv("CI_MERGE_REQUEST_PROJECT_URL")}, nil
+}
+
+func (gl *gitlabMR) upsertComment(body string) (string, error) {
+	type note struct {
+		ID   int64  `json:"id"`
+		Body string `json:"body"`
+	}
+	notes := fmt.Sprintf("/projects/%s/merge_requests/%d/notes", gl.project, gl.iid)
+	var existing note
+	for page := 1; existing.ID == 0; page++ {
+		var batch []note
+		if err := gl.api.do("GET", fmt.Sprintf("%s?per_page=100&page=%d", notes, page), nil, &batch); err != nil { return "", err }
+		for _, n := range batch { if strings.Contains(n.Body, prCommentMarker) { existing = n; break } }
+		if len(batch) < 100 { break }
+	}
+	var result note
+	var err error
+	payload := map[string]string{"body": body}
+	if existing.ID != 0 {
+		err = gl.api.do("PUT", fmt.Sprintf("%s/%d", notes, existing.ID), payload, &result)
+	} else {
+		err = gl.api.do("POST", notes, payload, &result)
+	}
+	if err != nil || gl.projectURL == "" { return fmt.Sprintf("note %d", result.ID), err }
+	return fmt.Sprintf("%s/-/merge_requests/%d#note_%d", gl.projectURL, gl.iid, result.ID), nil
+}
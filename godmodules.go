@@ -0,0 +1,68 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// GodModule is a module that many files import many different items from, past the [god_modules] thresholds. Its
+// importers rarely need all of it, so Clusters suggests where to split it.
+type GodModule struct {
+	Name, Tag  string
+	Dependents int // files of other modules importing it
+	Items      int // distinct items they import from it
+	Clusters   []ItemCluster
+	Scattered  int // items whose importers no other item shares, so no cluster holds them
+}
+
+// ItemCluster is a group of a module's items that always appear together: exactly the same files import each of
+// them, which makes the group a candidate module of its own.
+type ItemCluster struct {
+	Items     []string
+	Importers int
+}
+
+// computeGodModules flags the modules past the configured thresholds, most imported first, and clusters each one's
+// items by the set of files importing them.
+func computeGodModules(a *analyzer.Report) []GodModule {
+	limits := a.Config.GodModules
+	dependents := make(map[string]map[string]struct{})
+	for file, deps := range a.Graph.Deps {
+		from := a.ModuleName(file)
+		for to := range deps {
+			if to == from || to == "" { continue }
+			if dependents[to] == nil { dependents[to] = make(map[string]struct{}) }
+			dependents[to][file] = struct{}{}
+		}
+	}
+	var gods []GodModule
+	for module, files := range dependents {
+		items := a.Graph.ItemImports[module]
+		if len(files) < limits.MinDependents || len(items) < limits.MinItems || !a.Enforced(module) { continue }
+		god := GodModule{Name: module, Tag: a.Facts.Tags[module], Dependents: len(files), Items: len(items)}
+		groups := make(map[string][]string) // importing files, joined -> items they all import
+		for item, importers := range items {
+			key := strings.Join(sortedKeys(importers), "\x00")
+			groups[key] = append(groups[key], item)
+		}
+		for key, members := range groups {
+			if len(members) < 2 { god.Scattered++; continue }
+			sort.Strings(members)
+			god.Clusters = append(god.Clusters, ItemCluster{Items: members, Importers: 1 + strings.Count(key, "\x00")})
+		}
+		sort.Slice(god.Clusters, func(i, j int) bool {
+			ci, cj := god.Clusters[i], god.Clusters[j]
+			if len(ci.Items) != len(cj.Items) { return len(ci.Items) > len(cj.Items) }
+			if ci.Importers != cj.Importers { return ci.Importers > cj.Importers }
+			return ci.Items[0] < cj.Items[0]
+		})
+		gods = append(gods, god)
+	}
+	sort.Slice(gods, func(i, j int) bool {
+		if gods[i].Dependents != gods[j].Dependents { return gods[i].Dependents > gods[j].Dependents }
+		return gods[i].Name < gods[j].Name
+	})
+	return gods
+}
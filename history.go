@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HistoryRecord summarizes one analysis run. Records are appended to a history store, one per scheduled daemon run,
+// so architecture trends can be charted and regressions spotted.
+type HistoryRecord struct {
+	Time    time.Time         `json:"time"`
+	Root    string            `json:"root"`
+	Modules int               `json:"modules"`
+	Edges   [][2]string       `json:"edges"`
+	Cycles  [][]string        `json:"cycles"`
+	FanIn   map[string]int    `json:"fanIn"`
+	Inbound map[string]int    `json:"inbound"`       // files importing each module
+	API     map[string]string `json:"api,omitempty"`    // fingerprint of each module's public items; absent before API churn was tracked
+	Public  map[string]int    `json:"public,omitempty"` // public items of each module; absent before public item budgets
+	Rev     string            `json:"rev,omitempty"` // the commit a record replayed by the history command describes
+}
+
+func historyRecordOf(root string, v archView, t time.Time) HistoryRecord {
+	rec := HistoryRecord{Time: t.UTC(), Root: root, Modules: len(v.FanIn), Edges: [][2]string{}, Cycles: findCycles(v.Graph), FanIn: v.FanIn, Inbound: v.Inbound, API: v.API, Public: v.Public}
+	for _, from := range sortedKeys(v.Graph) { for _, to := range sortedKeys(v.Graph[from]) { rec.Edges = append(rec.Edges, [2]string{from, to}) } }
+	if rec.Cycles == nil { rec.Cycles = [][]string{} }
+	return rec
+}
+
+func appendHistory(path string, rec HistoryRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil { return err }
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil { return err }
+	if _, err := f.Write(append(line, '\n')); err != nil { f.Close(); return err }
+	return f.Close()
+}
+
+// readHistory loads every record of a history file, oldest first; a missing file is an empty history.
+func readHistory(path string) ([]HistoryRecord, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) { return nil, nil }
+	if err != nil { return nil, err }
+	defer f.Close()
+	var records []HistoryRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1<<20), 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 { continue }
+		var rec HistoryRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil { return nil, fmt.Errorf("%s:%d: %w", path, line, err) }
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
+
+// HistoryStore keeps the records of scheduled runs. Every backend stores the same records; they differ only in where
+// they live, so a central daemon can keep long-term metrics in the organization's own database.
+type HistoryStore interface {
+	Append(rec HistoryRecord) error
+	Records() ([]HistoryRecord, error) // oldest first
+	Close() error
+}
+
+// openHistoryStore opens the store a --history location names: a postgres:// or postgresql:// URL is a Postgres
+// database, a .jsonl file a JSON-lines file, and any other path a SQLite database (sqlite:<file> spells one out). An
+// existing file that is not a SQLite database is read as JSON lines, as the daemon wrote them before SQLite.
+func openHistoryStore(location string) (HistoryStore, error) {
+	driver, dsn := "sqlite", strings.TrimPrefix(location, "sqlite:")
+	switch {
+	case strings.HasPrefix(location, "postgres://"), strings.HasPrefix(location, "postgresql://"): driver = "postgres"
+	case strings.HasPrefix(location, "sqlite:"):
+	case strings.HasSuffix(location, ".jsonl") || isJSONLinesFile(location): return jsonlHistory(location), nil
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil { return nil, err }
+	store := &sqlHistory{db: db, numbered: driver == "postgres"}
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS dependant_history (recorded_at VARCHAR(40) NOT NULL, root TEXT NOT NULL, modules INTEGER NOT NULL, record TEXT NOT NULL)`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating history table: %w", err)
+	}
+	return store, nil
+}
+
+// isJSONLinesFile reports whether path is an existing, non-empty file without the SQLite header.
+func isJSONLinesFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil { return false }
+	defer f.Close()
+	header := make([]byte, 16)
+	n, _ := io.ReadFull(f, header)
+	return n > 0 && string(header[:n]) != "SQLite format 3\x00"
+}
+
+// jsonlHistory is a history file with one JSON record per line, the format the daemon has always written.
+type jsonlHistory string
+
+func (h jsonlHistory) Append(rec HistoryRecord) error { return appendHistory(string(h), rec) }
+func (h jsonlHistory) Records() ([]HistoryRecord, error) { return readHistory(string(h)) }
+func (h jsonlHistory) Close() error { return nil }
+
+// sqlHistory stores each record as JSON in the dependant_history table, beside the columns worth querying directly.
+// Timestamps are fixed-width UTC text so they sort the same on every database.
+type sqlHistory struct {
+	db       *sql.DB
+	numbered bool // placeholders are $1, $2 (Postgres) rather than ?
+}
+
+const historyTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+func (h *sqlHistory) placeholders(n int) string {
+	marks := make([]string, n)
+	for i := range marks { if marks[i] = "?"; h.numbered { marks[i] = "$" + strconv.Itoa(i+1) } }
+	return strings.Join(marks, ", ")
+}
+
+func (h *sqlHistory) Append(rec HistoryRecord) error {
+	record, err := json.Marshal(rec)
+	if err != nil { return err }
+	_, err = h.db.Exec(`INSERT INTO dependant_history (recorded_at, root, modules, record) VALUES (`+h.placeholders(4)+`)`, rec.Time.UTC().Format(historyTimeFormat), rec.Root, rec.Modules, string(record))
+	return err
+}
+
+func (h *sqlHistory) Records() ([]HistoryRecord, error) {
+	rows, err := h.db.Query(`SELECT record FROM dependant_history ORDER BY recorded_at`)
+	if err != nil { return nil, err }
+	defer rows.Close()
+	var records []HistoryRecord
+	for rows.Next() {
+		var record string
+		if err := rows.Scan(&record); err != nil { return nil, err }
+		var rec HistoryRecord
+		if err := json.Unmarshal([]byte(record), &rec); err != nil { return nil, fmt.Errorf("history record: %w", err) }
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+func (h *sqlHistory) Close() error { return h.db.Close() }
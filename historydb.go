@@ -0,0 +1,10 @@
+//go:build !(js && wasm)
+
+package main
+
+// The database/sql drivers of the history backends (see openHistoryStore). Both are pure Go, so builds stay cgo-free;
+// the WebAssembly build, which has no history, links neither.
+import (
+	_ "github.com/lib/pq"  // postgres://
+	_ "modernc.org/sqlite" // the default
+)
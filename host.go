@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/WillKirkmanM/dependant/internal/toml"
+)
+
+// hostProjectName is what a project may be called: it becomes the first segment of its URL.
+var hostProjectName = regexp.MustCompile(`^[A-Za-z0-9][\w.-]*$`)
+
+// hostProject is one repository served by `dependant host` under /<name>/, with its own analysis and schedule.
+type hostProject struct {
+	Name             string
+	d                *daemon
+	every            time.Duration
+	history, webhook string
+	store            HistoryStore // opened from history by runHost
+	next             time.Time    // next scheduled run; zero without a schedule
+}
+
+// loadHostConfig reads the projects file of `dependant host`:
+//
+//	listen = "127.0.0.1:8080"
+//
+//	[projects.engine]
+//	root    = "/srv/repos/engine"
+//	every   = "1h"                            # optional schedule, as for `daemon --every`
+//	history = "/var/lib/dependant/engine.db"    # SQLite, appended to on every scheduled run; also a .jsonl file or postgres://
+//	webhook = "https://hooks.example.com/arch" # alerted when a scheduled run finds new cycles or edges
+func loadHostConfig(path string) (string, []*hostProject, error) {
+	content, err := os.ReadFile(path)
+	if err != nil { return "", nil, err }
+	doc, err := toml.Parse(string(content))
+	if err != nil { return "", nil, fmt.Errorf("%s: %w", path, err) }
+	listen := toml.String(doc["listen"])
+	var projects []*hostProject
+	for name, v := range toml.Table(doc["projects"]) {
+		entry := toml.Table(v)
+		if entry == nil || !hostProjectName.MatchString(name) { return "", nil, fmt.Errorf("%s: [projects.%s] must be a table named with letters, digits, '.', '-' or '_'", path, name) }
+		p := &hostProject{Name: name, history: toml.String(entry["history"]), webhook: toml.String(entry["webhook"])}
+		root := toml.String(entry["root"])
+		if root == "" { return "", nil, fmt.Errorf("%s: [projects.%s] needs a root", path, name) }
+		p.d = &daemon{root: root, reportOpts: &ReportOptions{RootDir: root, MetricsScope: "all"}}
+		if every := toml.String(entry["every"]); every != "" {
+			if p.every, err = time.ParseDuration(every); err != nil || p.every <= 0 { return "", nil, fmt.Errorf("%s: [projects.%s] every must be a positive duration such as 1h", path, name) }
+		}
+		if (p.history != "" || p.webhook != "") && p.every == 0 { return "", nil, fmt.Errorf("%s: [projects.%s] history and webhook need a schedule; add every", path, name) }
+		projects = append(projects, p)
+	}
+	if len(projects) == 0 { return "", nil, fmt.Errorf("%s: no [projects.<name>] tables", path) }
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Name < projects[j].Name })
+	return listen, projects, nil
+}
+
+var hostIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en"><head><meta charset="UTF-8"><title>Dependant</title>
+<style>body { background-color: #1a1b26; color: #c0caf5; font-family: system-ui, sans-serif; padding: 2rem; } a { color: #7aa2f7; } li { margin: 0.4rem 0; }</style>
+</head><body><h1>Dependant</h1><ul>{{range .}}<li><a href="/{{.Name}}/">{{.Name}}</a></li>{{end}}</ul></body></html>
+`))
+
+// runHost serves the reports of several repositories from one process, for a team dashboard.
+func runHost(args []string) {
+	fs := flag.NewFlagSet("host", flag.ExitOnError)
+	listen := fs.String("listen", "", "address to serve on (default: listen in the projects file, else 127.0.0.1:8080)")
+	interval := fs.Duration("interval", 10*time.Second, "how often to check each project's tree for changes")
+	fs.Usage = func() { fmt.Println("Usage: dependant host [flags] <projects.toml>"); fs.PrintDefaults() }
+	fs.Parse(args)
+	if fs.NArg() != 1 { fs.Usage(); os.Exit(1) }
+	addr, projects, err := loadHostConfig(fs.Arg(0))
+	if err != nil { log.Fatalf("Error reading projects: %v", err) }
+	if *listen != "" { addr = *listen }
+	if addr == "" { addr = "127.0.0.1:8080" }
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" { http.NotFound(w, r); return }
+		if !allowMethods(w, r, http.MethodGet, http.MethodHead) { return }
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		hostIndexTemplate.Execute(w, projects)
+	})
+	for _, p := range projects {
+		if p.history != "" {
+			if p.store, err = openHistoryStore(p.history); err != nil { log.Fatalf("Error opening history of %s: %v", p.Name, err) }
+		}
+		if err := p.d.refresh(); err != nil { log.Fatalf("Error analyzing %s (%s): %v", p.Name, p.d.root, err) }
+		mux.Handle("/"+p.Name+"/", http.StripPrefix("/"+p.Name, http.HandlerFunc(p.d.reportHandler)))
+		if p.every > 0 { p.next = time.Now() }
+	}
+
+	go func() {
+		for ; ; time.Sleep(*interval) {
+			for _, p := range projects {
+				if !p.next.IsZero() && !time.Now().Before(p.next) {
+					if err := p.d.scheduledRun(p.store, p.webhook); err != nil { log.Printf("Scheduled run of %s failed: %v", p.Name, err) }
+					p.next = time.Now().Add(p.every)
+					continue
+				}
+				if err := p.d.refresh(); err != nil { log.Printf("Re-analysis of %s failed: %v", p.Name, err) }
+			}
+		}
+	}()
+	fmt.Printf("✅ Serving %d project%s on http://%s\n", len(projects), plural(len(projects)), addr)
+	for _, p := range projects { fmt.Printf("   %-20s http://%s/%s/\n", p.Name, addr, p.Name) }
+	if err := listenPersistent(addr, mux); err != nil { log.Fatalf("Server error: %v", err) }
+}
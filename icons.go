@@ -0,0 +1,48 @@
+package main
+
+import "html/template"
+
+// reportIcon is how the report marks a heading or link: an emoji glyph, or with --minimal-report an inline SVG, which
+// renders the same everywhere without an emoji font.
+type reportIcon struct {
+	Glyph string
+	SVG   string // the shapes of a 24x24 stroked icon
+}
+
+var reportIcons = map[string]reportIcon{
+	"report":          {"✨", `<path d="M12 3l2 6 6 2-6 2-2 6-2-6-6-2 6-2z"/>`},
+	"violation":       {"❌", `<path d="M6 6l12 12M18 6L6 18"/>`},
+	"done":            {"🎉", `<path d="M5 12l5 5 9-10"/>`},
+	"layers":          {"🧱", `<path d="M12 3l9 5-9 5-9-5z"/><path d="M3 13l9 5 9-5"/>`},
+	"notes":           {"📝", `<path d="M5 3h10l4 4v14H5z"/><path d="M9 12h6M9 16h6"/>`},
+	"languages":       {"🌐", `<circle cx="12" cy="12" r="9"/><path d="M3 12h18M12 3c3 3 3 15 0 18M12 3c-3 3-3 15 0 18"/>`},
+	"top-items":       {"🏆", `<path d="M8 4h8v5a4 4 0 0 1-8 0z"/><path d="M12 13v4M8 21h8M8 6H5a3 3 0 0 0 3 4M16 6h3a3 3 0 0 1-3 4"/>`},
+	"cycles":          {"⚠️", `<path d="M12 3l10 18H2z"/><path d="M12 10v5M12 18v.01"/>`},
+	"modules":         {"📥", `<path d="M12 3v10M8 9l4 4 4-4"/><path d="M3 14h5l1 3h6l1-3h5v6H3z"/>`},
+	"outbound":        {"📤", `<path d="M12 13V3M8 7l4-4 4 4"/><path d="M3 14h5l1 3h6l1-3h5v6H3z"/>`},
+	"graph":           {"🕸️", `<circle cx="6" cy="6" r="2"/><circle cx="18" cy="6" r="2"/><circle cx="12" cy="18" r="2"/><path d="M8 6h8M7 8l4 8M17 8l-4 8"/>`},
+	"treemap":         {"🗺️", `<rect x="3" y="3" width="18" height="18"/><path d="M12 3v18M12 12h9M3 9h9"/>`},
+	"inferred-layers": {"🪜", `<path d="M7 3v18M17 3v18M7 7h10M7 12h10M7 17h10"/>`},
+	"metrics":         {"📐", `<path d="M4 20V4l16 16z"/><path d="M8 16h3v-3"/>`},
+	"god-modules":     {"🐘", `<circle cx="12" cy="12" r="5"/><circle cx="4" cy="4" r="1.5"/><circle cx="20" cy="4" r="1.5"/><circle cx="4" cy="20" r="1.5"/><circle cx="20" cy="20" r="1.5"/><path d="M5 5l3.5 3.5M19 5l-3.5 3.5M5 19l3.5-3.5M19 19l-3.5-3.5"/>`},
+	"closure":         {"🔭", `<circle cx="11" cy="11" r="7"/><path d="M16 16l5 5"/>`},
+	"interfaces":      {"🧩", `<path d="M4 8h4a2 2 0 1 1 4 0h4v4a2 2 0 1 1 0 4v4H4z"/>`},
+	"use-style":       {"✍️", `<path d="M4 20l4-1 11-11-3-3L5 16z"/><path d="M14 6l3 3"/>`},
+	"conditional":     {"🔀", `<circle cx="6" cy="5" r="2"/><circle cx="6" cy="19" r="2"/><circle cx="18" cy="8" r="2"/><path d="M6 7v10M18 10c0 4-6 3-12 7"/>`},
+	"unsafe":          {"☢️", `<circle cx="12" cy="12" r="9"/><path d="M12 7v6M12 16v.01"/>`},
+	"external-crates": {"📦", `<path d="M3 7l9-4 9 4v10l-9 4-9-4z"/><path d="M3 7l9 4 9-4M12 11v10"/>`},
+	"boundaries":      {"🚧", `<rect x="3" y="8" width="18" height="6"/><path d="M6 14v6M18 14v6M7 8l4 6M13 8l4 6"/>`},
+	"coupling":        {"🔗", `<path d="M10 14a4 4 0 0 0 6 0l3-3a4 4 0 0 0-6-6l-1 1"/><path d="M14 10a4 4 0 0 0-6 0l-3 3a4 4 0 0 0 6 6l1-1"/>`},
+	"edge-history":    {"🕰️", `<circle cx="12" cy="12" r="9"/><path d="M12 7v5l3 3"/>`},
+	"mod-tree":        {"🌳", `<rect x="9" y="3" width="6" height="4"/><rect x="3" y="17" width="6" height="4"/><rect x="15" y="17" width="6" height="4"/><path d="M12 7v5M6 17v-5h12v5"/>`},
+	"per-module":      {"📊", `<path d="M4 20V10M10 20V4M16 20v-8M22 20H2"/>`},
+}
+
+// iconFunc returns the report template's icon function, which renders the named reportIcons entry.
+func iconFunc(minimal bool) func(name string) template.HTML {
+	return func(name string) template.HTML {
+		icon := reportIcons[name]
+		if !minimal { return template.HTML(icon.Glyph) }
+		return template.HTML(`<svg class="icon" viewBox="0 0 24 24" aria-hidden="true">` + icon.SVG + `</svg>`)
+	}
+}
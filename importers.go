@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// ImporterViolation is a production import of a module by one its [importers] entry does not allow, such as a UI
+// module reaching into auth_internal when only api may.
+type ImporterViolation struct {
+	File      string // relative to the root
+	Line      int    // 0 when no import line names the module
+	Statement string
+	Importer  string
+	Module    string
+	Owner     string // the [importers] key restricting Module
+}
+
+// findImporterViolations lists the production imports the [importers] table forbids, with the lines that make them.
+// Patterns match a module by its name or its path below the crate root, e.g. api::* matches api::v1, reported as v1;
+// the modules the key itself matches may import each other.
+func findImporterViolations(a *analyzer.Report) ([]ImporterViolation, error) {
+	if len(a.Config.Importers) == 0 { return nil, nil }
+	paths := make(map[string][]string) // module -> its paths below the crate root
+	for modPath, m := range a.Facts.ModulePaths { paths[m] = append(paths[m], modPath) }
+	matches := func(patterns []string, module string) bool {
+		if layerLevel(patterns, module, a.Facts.Tags[module]) >= 0 { return true }
+		for _, p := range paths[module] { if layerLevel(patterns, p, a.Facts.Tags[module]) >= 0 { return true } }
+		return false
+	}
+	owners := sortedKeys(a.Config.Importers)
+	var found []ImporterViolation
+	for _, file := range sortedKeys(a.Graph.ProdDeps) {
+		importer := a.ModuleName(file)
+		var sites func(string) []importSite
+		for _, module := range sortedKeys(a.Graph.ProdDeps[file]) {
+			if module == importer { continue }
+			for _, owner := range owners {
+				if !matches([]string{owner}, module) || matches([]string{owner}, importer) || matches(a.Config.Importers[owner], importer) { continue }
+				if sites == nil {
+					var err error
+					if sites, err = importLines(file); err != nil { return nil, err }
+				}
+				v := ImporterViolation{File: relSlash(a.Root, file), Importer: importer, Module: module, Owner: owner}
+				lines := sites(module)
+				for _, site := range lines { v.Line, v.Statement = site.Line, site.Statement; found = append(found, v) }
+				if len(lines) == 0 { found = append(found, v) }
+				break
+			}
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].File < found[j].File || found[i].File == found[j].File && found[i].Line < found[j].Line })
+	return found, nil
+}
+
+func writeImporterReport(w io.Writer, found []ImporterViolation, importers map[string][]string) {
+	for _, v := range found {
+		allowed := "no other module may import"
+		if patterns := importers[v.Owner]; len(patterns) > 0 { allowed = "only " + strings.Join(patterns, ", ") + " may import" }
+		fmt.Fprintf(w, "❌ %s imports %s, which %s\n", v.Importer, v.Module, allowed)
+		if v.Line == 0 { fmt.Fprintf(w, "      %s\n", v.File); continue }
+		fmt.Fprintf(w, "      %s:%d: %s\n", v.File, v.Line, v.Statement)
+	}
+}
+
+func importerFinding(v ImporterViolation) checkFinding {
+	return checkFinding{Rule: "importers", File: v.File, Line: v.Line, Message: fmt.Sprintf("%s imports %s, which [importers] %s does not allow it to", v.Importer, v.Module, v.Owner)}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/internal/toml"
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// layerKeywords maps common module/directory names to the tag init suggests for them.
+var layerKeywords = map[string]string{
+	"ui": "ui", "view": "ui", "views": "ui", "web": "ui", "frontend": "ui", "cli": "ui", "api": "ui", "handlers": "ui", "routes": "ui",
+	"domain": "domain", "model": "domain", "models": "domain", "entities": "domain", "core": "domain", "business": "domain",
+	"infra": "infra", "db": "infra", "storage": "infra", "persistence": "infra", "repository": "infra", "net": "infra", "adapters": "infra", "io": "infra",
+	"experimental": "experimental", "unstable": "experimental", "labs": "experimental",
+}
+
+// Directory names whose Rust files are usually not hand-written project code.
+var excludeCandidates = map[string]struct{}{"target": {}, "vendor": {}, "third_party": {}, "generated": {}, "gen": {}}
+
+// scaffoldConfig inspects root and renders a starter dependant.toml.
+func scaffoldConfig(root string) (string, error) {
+	excludes := map[string]struct{}{"target": {}}
+	testDirs := make(map[string]struct{})
+	tags := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil { return err }
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") && path != root { return filepath.SkipDir }
+			if _, ok := excludeCandidates[d.Name()]; ok { excludes[d.Name()] = struct{}{}; return filepath.SkipDir }
+			switch d.Name() { case "tests", "benches", "examples": testDirs[d.Name()] = struct{}{} }
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".rs") { return nil }
+		module := (&analyzer.Report{Root: root}).ModuleName(path)
+		if tag, ok := layerKeywords[module]; ok { tags[module] = tag }
+		return nil
+	})
+	if err != nil { return "", err }
+
+	var sb strings.Builder
+	sb.WriteString("# dependant configuration, generated by `dependant init`. Review and adjust before committing.\n")
+	if manifest, err := parseManifestFile(filepath.Join(root, "Cargo.toml")); err == nil {
+		if members := toml.Strings(toml.Table(manifest["workspace"])["members"]); len(members) > 0 {
+			sb.WriteString("#\n# Workspace members: " + strings.Join(members, ", ") + "\n# Analyze each member directory separately for per-crate reports.\n")
+		}
+	}
+	if len(testDirs) > 0 {
+		sb.WriteString("#\n# Test code was found in: " + strings.Join(sortedKeys(testDirs), ", ") + "/.\n# It stays in the report; pass --metrics-scope prod to keep it out of coupling metrics,\n# --tests exclude to leave it out of the analysis or --tests separate to count it apart.\n")
+	}
+	sb.WriteString("\n# Paths skipped entirely (build output, vendored and generated code).\n")
+	fmt.Fprintf(&sb, "exclude = [%s]\n", quoteList(sortedKeys(excludes)))
+	sb.WriteString("\n# Module tags group modules into layers; they color and filter every view of the report.\n# Inferred from module names, so check each one.\n[tags]\n")
+	if len(tags) == 0 { sb.WriteString("# ui = \"ui\"\n# domain = \"domain\"\n# storage = \"infra\"\n") }
+	for _, module := range sortedKeys(tags) { fmt.Fprintf(&sb, "%s = %q\n", module, tags[module]) }
+	return sb.String(), nil
+}
+
+func parseManifestFile(path string) (map[string]any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil { return nil, err }
+	return toml.Parse(string(content))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m { keys = append(keys, k) }
+	sort.Strings(keys)
+	return keys
+}
+
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values { quoted[i] = fmt.Sprintf("%q", v) }
+	return strings.Join(quoted, ", ")
+}
+
+func runInit(args []string) {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	force := fs.Bool("force", false, "overwrite an existing "+analyzer.ConfigFileName)
+	fs.Usage = func() { fmt.Println("Usage: dependant init [flags] <directory>"); fs.PrintDefaults() }
+	fs.Parse(args)
+	if fs.NArg() != 1 { fs.Usage(); os.Exit(1) }
+	root := fs.Arg(0)
+	target := filepath.Join(root, analyzer.ConfigFileName)
+	if _, err := os.Stat(target); err == nil && !*force { log.Fatalf("%s already exists; pass --force to overwrite it", target) } else if err != nil && !errors.Is(err, os.ErrNotExist) { log.Fatalf("Error checking %s: %v", target, err) }
+
+	content, err := scaffoldConfig(root)
+	if err != nil { log.Fatalf("Error inspecting %s: %v", root, err) }
+	if err := os.WriteFile(target, []byte(content), 0o644); err != nil { log.Fatalf("Error writing %s: %v", target, err) }
+	fmt.Printf("✅ Wrote %s\n", target)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// InterfaceInfo splits a module's public items by who imports them: outsiders (its true external
+// interface), only its own submodules (implementation detail exposed as pub), or nobody.
+type InterfaceInfo struct {
+	Name, Tag    string
+	Public       int
+	External     []string
+	InternalOnly []string
+	Unused       []string
+}
+
+// insideModule reports whether file belongs to module: it is the module's own file or lives under a directory of that name.
+func insideModule(a *analyzer.Report, file, module string) bool {
+	if a.ModuleName(file) == module { return true }
+	rel, err := filepath.Rel(a.Root, file)
+	if err != nil { return false }
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(rel)), "/") { if dir == module { return true } }
+	return false
+}
+
+func computeInterfaces(a *analyzer.Report, symbolTable map[string]map[string]struct{}, itemImports map[string]map[string]map[string]struct{}, tags map[string]string) []InterfaceInfo {
+	var out []InterfaceInfo
+	for _, module := range sortedKeys(symbolTable) {
+		public := symbolTable[module]
+		if len(public) == 0 { continue }
+		info := InterfaceInfo{Name: module, Tag: tags[module], Public: len(public)}
+		for _, item := range sortedKeys(public) {
+			files := itemImports[module][item]
+			external := false
+			for f := range files { if !insideModule(a, f, module) { external = true; break } }
+			switch {
+			case external: info.External = append(info.External, item)
+			case len(files) > 0: info.InternalOnly = append(info.InternalOnly, item)
+			default: info.Unused = append(info.Unused, item)
+			}
+		}
+		out = append(out, info)
+	}
+	// Modules exposing the most public API that nobody outside uses come first.
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Public-len(out[i].External) > out[j].Public-len(out[j].External) })
+	return out
+}
@@ -0,0 +1,231 @@
+// Package toml parses the subset of TOML that dependant reads (dependant.toml, Cargo.toml, Cargo.lock,
+// pyproject.toml) and gives typed access to decoded TOML and JSON documents.
+package toml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse understands the subset of TOML used by dependant.toml, Cargo.toml and Cargo.lock:
+// tables, arrays of tables, dotted/quoted keys, strings, integers, floats, booleans, arrays and inline tables.
+func Parse(content string) (map[string]any, error) {
+	root := make(map[string]any)
+	current := root
+	p := &tomlParser{src: content, line: 1}
+	for {
+		p.skipBlank()
+		if p.eof() { return root, nil }
+		line := p.line
+		switch {
+		case p.peek() == '[' && strings.HasPrefix(p.src[p.pos:], "[["):
+			p.pos += 2
+			keys, err := p.parseKey("]]")
+			if err != nil { return nil, err }
+			parent, err := walkTable(root, keys[:len(keys)-1], line)
+			if err != nil { return nil, err }
+			last := keys[len(keys)-1]
+			arr, _ := parent[last].([]any)
+			current = make(map[string]any)
+			parent[last] = append(arr, current)
+		case p.peek() == '[':
+			p.pos++
+			keys, err := p.parseKey("]")
+			if err != nil { return nil, err }
+			if current, err = walkTable(root, keys, line); err != nil { return nil, err }
+		default:
+			if err := p.parseKeyValue(current); err != nil { return nil, err }
+		}
+		p.skipSpace()
+		if !p.eof() && p.peek() != '\n' && p.peek() != '#' && p.peek() != '\r' {
+			return nil, fmt.Errorf("line %d: unexpected trailing content", p.line)
+		}
+	}
+}
+
+// walkTable descends into (creating as needed) the table named by keys, following the last element of arrays of tables.
+func walkTable(root map[string]any, keys []string, line int) (map[string]any, error) {
+	t := root
+	for _, k := range keys {
+		switch v := t[k].(type) {
+		case nil: next := make(map[string]any); t[k] = next; t = next
+		case map[string]any: t = v
+		case []any:
+			if len(v) == 0 { return nil, fmt.Errorf("line %d: %q is not a table", line, k) }
+			last, ok := v[len(v)-1].(map[string]any)
+			if !ok { return nil, fmt.Errorf("line %d: %q is not a table", line, k) }
+			t = last
+		default: return nil, fmt.Errorf("line %d: %q is not a table", line, k)
+		}
+	}
+	return t, nil
+}
+
+type tomlParser struct { src string; pos, line int }
+
+func (p *tomlParser) eof() bool  { return p.pos >= len(p.src) }
+
+func (p *tomlParser) peek() byte { return p.src[p.pos] }
+
+func (p *tomlParser) skipSpace() { for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') { p.pos++ } }
+
+// skipBlank skips whitespace, newlines and comments.
+func (p *tomlParser) skipBlank() {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\r': p.pos++
+		case '\n': p.pos++; p.line++
+		case '#': for !p.eof() && p.peek() != '\n' { p.pos++ }
+		default: return
+		}
+	}
+}
+
+func (p *tomlParser) parseKey(terminator string) ([]string, error) {
+	var keys []string
+	for {
+		p.skipSpace()
+		if p.eof() { return nil, fmt.Errorf("line %d: unterminated key", p.line) }
+		var key string
+		if c := p.peek(); c == '"' || c == '\'' {
+			s, err := p.parseString()
+			if err != nil { return nil, err }
+			key = s
+		} else {
+			start := p.pos
+			for !p.eof() && (isBareKeyChar(p.peek())) { p.pos++ }
+			key = p.src[start:p.pos]
+			if key == "" { return nil, fmt.Errorf("line %d: empty key", p.line) }
+		}
+		keys = append(keys, key)
+		p.skipSpace()
+		if strings.HasPrefix(p.src[p.pos:], terminator) { p.pos += len(terminator); return keys, nil }
+		if p.eof() || p.peek() != '.' { return nil, fmt.Errorf("line %d: expected %q after key", p.line, terminator) }
+		p.pos++
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return c == '_' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func (p *tomlParser) parseKeyValue(table map[string]any) error {
+	keys, err := p.parseKey("=")
+	if err != nil { return err }
+	p.skipSpace()
+	value, err := p.parseValue()
+	if err != nil { return err }
+	t, err := walkTable(table, keys[:len(keys)-1], p.line)
+	if err != nil { return err }
+	t[keys[len(keys)-1]] = value
+	return nil
+}
+
+func (p *tomlParser) parseValue() (any, error) {
+	if p.eof() { return nil, fmt.Errorf("line %d: missing value", p.line) }
+	switch c := p.peek(); {
+	case c == '"' || c == '\'': return p.parseString()
+	case c == '[':
+		p.pos++
+		var arr []any
+		for {
+			p.skipBlank()
+			if p.eof() { return nil, fmt.Errorf("line %d: unterminated array", p.line) }
+			if p.peek() == ']' { p.pos++; return arr, nil }
+			v, err := p.parseValue()
+			if err != nil { return nil, err }
+			arr = append(arr, v)
+			p.skipBlank()
+			if !p.eof() && p.peek() == ',' { p.pos++ }
+		}
+	case c == '{':
+		p.pos++
+		t := make(map[string]any)
+		for {
+			p.skipSpace()
+			if p.eof() { return nil, fmt.Errorf("line %d: unterminated inline table", p.line) }
+			if p.peek() == '}' { p.pos++; return t, nil }
+			if err := p.parseKeyValue(t); err != nil { return nil, err }
+			p.skipSpace()
+			if !p.eof() && p.peek() == ',' { p.pos++ }
+		}
+	default:
+		start := p.pos
+		for !p.eof() && !strings.ContainsRune(" \t\r\n,]}#", rune(p.peek())) { p.pos++ }
+		raw := p.src[start:p.pos]
+		switch raw {
+		case "true": return true, nil
+		case "false": return false, nil
+		}
+		if i, err := strconv.ParseInt(strings.ReplaceAll(raw, "_", ""), 0, 64); err == nil { return i, nil }
+		if f, err := strconv.ParseFloat(strings.ReplaceAll(raw, "_", ""), 64); err == nil { return f, nil }
+		return raw, nil // dates and other scalars are kept verbatim
+	}
+}
+
+func (p *tomlParser) parseString() (string, error) {
+	quote := p.peek()
+	multi := strings.HasPrefix(p.src[p.pos:], strings.Repeat(string(quote), 3))
+	if multi {
+		p.pos += 3
+		if !p.eof() && p.peek() == '\n' { p.pos++; p.line++ }
+	} else {
+		p.pos++
+	}
+	var sb strings.Builder
+	for !p.eof() {
+		c := p.peek()
+		if multi && strings.HasPrefix(p.src[p.pos:], strings.Repeat(string(quote), 3)) { p.pos += 3; return sb.String(), nil }
+		if !multi && c == quote { p.pos++; return sb.String(), nil }
+		if c == '\n' {
+			if !multi { break }
+			p.line++
+		}
+		if c == '\\' && quote == '"' && p.pos+1 < len(p.src) {
+			p.pos++
+			switch e := p.peek(); e {
+			case 'n': sb.WriteByte('\n')
+			case 't': sb.WriteByte('\t')
+			case 'r': sb.WriteByte('\r')
+			case '"', '\\': sb.WriteByte(e)
+			case 'u', 'U':
+				n := 4; if e == 'U' { n = 8 }
+				if p.pos+n >= len(p.src) { return "", fmt.Errorf("line %d: bad unicode escape", p.line) }
+				r, err := strconv.ParseUint(p.src[p.pos+1:p.pos+1+n], 16, 32)
+				if err != nil { return "", fmt.Errorf("line %d: bad unicode escape", p.line) }
+				sb.WriteRune(rune(r)); p.pos += n
+			default: sb.WriteByte('\\'); sb.WriteByte(e)
+			}
+			p.pos++
+			continue
+		}
+		sb.WriteByte(c); p.pos++
+	}
+	return "", fmt.Errorf("line %d: unterminated string", p.line)
+}
+
+// Typed accessors for decoded TOML (and JSON) documents.
+
+func Table(v any) map[string]any { t, _ := v.(map[string]any); return t }
+
+func String(v any) string { s, _ := v.(string); return s }
+
+func Int(v any) (int, bool) {
+	switch n := v.(type) {
+	case int64: return int(n), true
+	case float64: return int(n), n == float64(int(n))
+	}
+	return 0, false
+}
+
+func Strings(v any) []string {
+	switch t := v.(type) {
+	case string: return []string{t}
+	case []any:
+		var out []string
+		for _, e := range t { if s, ok := e.(string); ok { out = append(out, s) } }
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+// Package yaml reads the one-line scalars of the YAML subset dependant's own files use (dependant-notes.yaml,
+// dependant.yaml); the callers parse their line structure themselves.
+package yaml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StripComment drops a # comment, which starts a line or follows a space outside quotes.
+func StripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote == '"' && c == '\\': i++
+		case quote != 0: if c == quote { quote = 0 }
+		case c == '"' || c == '\'': quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'): return line[:i]
+		}
+	}
+	return line
+}
+
+// Scalar decodes a plain, single- or double-quoted scalar.
+func Scalar(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		text, err := strconv.Unquote(value)
+		if err != nil { return "", fmt.Errorf("bad double-quoted string %s", value) }
+		return text, nil
+	case strings.HasPrefix(value, "'"):
+		if len(value) < 2 || !strings.HasSuffix(value, "'") { return "", fmt.Errorf("unterminated single-quoted string %s", value) }
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	}
+	return value, nil
+}
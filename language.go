@@ -0,0 +1,78 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// isSourceFile reports whether a walk should read name as source or as an input that changes the analysis.
+func isSourceFile(name string) bool {
+	switch name {
+	case analyzer.ConfigFileName, analyzer.LayersFileName, "Cargo.toml", "Cargo.lock", "go.mod", "go.sum", "package.json", "package-lock.json", "tsconfig.json", "pyproject.toml", "requirements.txt", "poetry.lock", "uv.lock", ".gitignore": return true
+	}
+	return isCodeFile(name)
+}
+
+// isCodeFile reports whether name is a source file of any supported language, as opposed to a manifest. C headers
+// count, since --mixed links other languages to them.
+func isCodeFile(name string) bool {
+	return strings.HasSuffix(name, ".rs") || strings.HasSuffix(name, ".go") || analyzer.IsJSFile(name) || analyzer.IsPythonFile(name) || analyzer.IsCHeader(name)
+}
+
+// LanguageInfo is one language of a mixed report.
+type LanguageInfo struct {
+	Language          string
+	Dirs              []string // where its manifests are, relative to the root
+	Modules, Files    int
+	LOC               int
+	Edges, CrossEdges int // module edges within the language, and to or from other languages
+}
+
+// CrossLanguageInfo is one module edge between languages, with the files making it.
+type CrossLanguageInfo struct {
+	From, FromLanguage, To, ToLanguage, Via string
+	Items, Files                            []string
+}
+
+// computeLanguages sums up each language of a mixed analysis and groups its cross-language edges by module pair.
+func computeLanguages(a *analyzer.Report) ([]LanguageInfo, []CrossLanguageInfo) {
+	moduleLanguages := a.ModuleLanguages()
+	byLanguage := make(map[string]*LanguageInfo)
+	info := func(language string) *LanguageInfo {
+		if byLanguage[language] == nil { byLanguage[language] = &LanguageInfo{Language: language} }
+		return byLanguage[language]
+	}
+	for _, r := range a.Languages { l := info(r.Language); l.Dirs = append(l.Dirs, r.Dir) }
+	for file := range a.FileModules { if language := analyzer.FileLanguage(file); language != "" { info(language).Files++ } }
+	for module, language := range moduleLanguages { l := info(language); l.Modules++; l.LOC += a.Facts.LOC[module] }
+	for from, deps := range a.Modules() {
+		for to := range deps {
+			fromLanguage, toLanguage := moduleLanguages[from], moduleLanguages[to]
+			if fromLanguage == toLanguage { info(fromLanguage).Edges++; continue }
+			info(fromLanguage).CrossEdges++
+			info(toLanguage).CrossEdges++
+		}
+	}
+	var languages []LanguageInfo
+	for _, name := range sortedKeys(byLanguage) { if name != "" { languages = append(languages, *byLanguage[name]) } }
+
+	var cross []CrossLanguageInfo
+	index := make(map[[3]string]int)
+	for _, e := range a.CrossLanguage {
+		key := [3]string{e.From, e.To, e.Via}
+		i, ok := index[key]
+		if !ok {
+			i, index[key] = len(cross), len(cross)
+			cross = append(cross, CrossLanguageInfo{From: e.From, FromLanguage: moduleLanguages[e.From], To: e.To, ToLanguage: moduleLanguages[e.To], Via: e.Via})
+		}
+		cross[i].Items = uniqueSorted(append(cross[i].Items, e.Items...))
+		cross[i].Files = append(cross[i].Files, e.File)
+	}
+	sort.Slice(cross, func(i, j int) bool {
+		if cross[i].From != cross[j].From { return cross[i].From < cross[j].From }
+		return cross[i].To < cross[j].To
+	})
+	return languages, cross
+}
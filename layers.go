@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	pathpkg "path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// LayerViolation is an import going up a chain of layers: From, in a lower layer, imports To from a higher one.
+type LayerViolation struct {
+	From, To           string
+	FromLayer, ToLayer string
+	Files              []string // importing files, relative to the root
+	Items              []string // items imported; empty when only the module is
+}
+
+// layerLevel is the position of module in chain, -1 when no layer of the chain holds it.
+func layerLevel(chain []string, module, tag string) int {
+	for i, layer := range chain {
+		if tag == layer || module == layer { return i }
+		for _, sep := range []string{"::", "/", "."} { if strings.HasPrefix(module, layer+sep) { return i } }
+		if ok, _ := pathpkg.Match(layer, module); ok { return i }
+	}
+	return -1
+}
+
+// findLayerViolations lists the production imports that go against the declared layers, the most widespread first.
+func findLayerViolations(a *analyzer.Report) []LayerViolation {
+	if len(a.Config.Layers) == 0 { return nil }
+	type edge struct{ from, to string }
+	found := make(map[edge]*LayerViolation)
+	for _, file := range sortedKeys(a.Graph.ProdDeps) {
+		from := a.ModuleName(file)
+		for _, to := range sortedKeys(a.Graph.ProdDeps[file]) {
+			if to == from { continue }
+			for _, chain := range a.Config.Layers {
+				fromLevel, toLevel := layerLevel(chain, from, a.Facts.Tags[from]), layerLevel(chain, to, a.Facts.Tags[to])
+				if fromLevel < 0 || toLevel < 0 || toLevel >= fromLevel { continue }
+				v := found[edge{from, to}]
+				if v == nil {
+					v = &LayerViolation{From: from, To: to, FromLayer: chain[fromLevel], ToLayer: chain[toLevel]}
+					found[edge{from, to}] = v
+				}
+				v.Files = append(v.Files, relSlash(a.Root, file))
+				for item, files := range a.Graph.ItemImports[to] { if _, ok := files[file]; ok && !slices.Contains(v.Items, item) { v.Items = append(v.Items, item) } }
+				break
+			}
+		}
+	}
+	violations := make([]LayerViolation, 0, len(found))
+	for _, v := range found { sort.Strings(v.Items); violations = append(violations, *v) }
+	sort.Slice(violations, func(i, j int) bool {
+		if len(violations[i].Files) != len(violations[j].Files) { return len(violations[i].Files) > len(violations[j].Files) }
+		if violations[i].From != violations[j].From { return violations[i].From < violations[j].From }
+		return violations[i].To < violations[j].To
+	})
+	return violations
+}
+
+func writeLayerReport(w io.Writer, violations []LayerViolation) {
+	for _, v := range violations {
+		fmt.Fprintf(w, "❌ %s (%s) imports %s (%s), a layer above it\n", v.From, v.FromLayer, v.To, v.ToLayer)
+		if len(v.Items) > 0 { fmt.Fprintf(w, "      items: %s\n", strings.Join(v.Items, ", ")) }
+		for _, f := range v.Files { fmt.Fprintf(w, "      %s\n", f) }
+	}
+}
+
+// InferredLayers sorts the module graph into layers, for a tree without declared ones: each module sits one layer
+// above the highest module it imports, so the foundation (Layers[0]) imports nothing and every edge points down. The
+// modules of a cycle have no such order among themselves; each cycle is placed as one unit and its modules marked.
+type InferredLayers struct {
+	Layers      [][]string
+	Cyclic      map[string]bool
+	Independent []string // modules with no edges at all
+}
+
+func inferLayers(graph analyzer.ModuleGraph, modules []string) InferredLayers {
+	inferred := InferredLayers{Cyclic: make(map[string]bool)}
+	group := make(map[string]string) // module -> the first module of its cycle, itself outside one
+	for _, cycle := range findCycles(graph) { for _, m := range cycle { group[m], inferred.Cyclic[m] = cycle[0], true } }
+	unit := func(m string) string { if g, ok := group[m]; ok { return g }; return m }
+	condensed := make(map[string]map[string]struct{})
+	connected := make(map[string]bool)
+	for from, deps := range graph {
+		for to := range deps {
+			connected[from], connected[to] = true, true
+			if unit(from) == unit(to) { continue }
+			if condensed[unit(from)] == nil { condensed[unit(from)] = make(map[string]struct{}) }
+			condensed[unit(from)][unit(to)] = struct{}{}
+		}
+	}
+
+	level := make(map[string]int)
+	var place func(u string) int
+	place = func(u string) int {
+		if l, ok := level[u]; ok { return l }
+		l := 0
+		for to := range condensed[u] { l = max(l, place(to)+1) }
+		level[u] = l
+		return l
+	}
+	all := make(map[string]struct{})
+	for _, m := range modules { all[m] = struct{}{} }
+	for m := range connected { all[m] = struct{}{} }
+	for _, m := range sortedKeys(all) {
+		if !connected[m] { inferred.Independent = append(inferred.Independent, m); continue }
+		l := place(unit(m))
+		for len(inferred.Layers) <= l { inferred.Layers = append(inferred.Layers, nil) }
+		inferred.Layers[l] = append(inferred.Layers[l], m)
+	}
+	return inferred
+}
+
+// LayerRow is one inferred layer as the report draws it: Level 0 is the foundation.
+type LayerRow struct {
+	Level   int
+	Modules []string
+}
+
+// Top lists the layers top down, the order a layered diagram draws them in.
+func (l InferredLayers) Top() []LayerRow {
+	var rows []LayerRow
+	for level := len(l.Layers) - 1; level >= 0; level-- { rows = append(rows, LayerRow{level, l.Layers[level]}) }
+	return rows
+}
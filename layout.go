@@ -0,0 +1,23 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// LayoutPoint is a saved node position in graph (SVG viewBox) coordinates.
+type LayoutPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// readLayout loads a layout downloaded from the report's graph view, so a team's
+// arrangement can be committed and re-embedded with --layout.
+func readLayout(path string) (map[string]LayoutPoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil { return nil, err }
+	var layout struct { Positions map[string]LayoutPoint `json:"positions"` }
+	if err := json.Unmarshal(data, &layout); err != nil { return nil, fmt.Errorf("%s: %w", path, err) }
+	return layout.Positions, nil
+}
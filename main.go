@@ -1,350 +1,949 @@
-package main
-
-import (
-	"bytes"
-	"fmt"
-	"html/template"
-	"log"
-	"net"
-	"net/http"
-	"os"
-	"os/exec"
-	"path/filepath"
-	"regexp"
-	"runtime"
-	"sort"
-	"strconv"
-	"strings"
-	"time"
-)
-
-var (
-	usePathRegex = regexp.MustCompile(`use\s+(crate|super)(::[\s\S]*?;)`)
-	commentRegex = regexp.MustCompile(`//.*`)
-	pubDefRegex  = regexp.MustCompile(`pub\s+(?:struct|enum|fn|trait)\s+(\w+)`)
-)
-
-type ModuleInfo struct { Name, ID, CountStr string; Dependents []string }
-type ItemInfo struct { ModuleName, Name, CountStr string; Files []string }
-type TemplateData struct {
-	TargetDir            string
-	AllModules           []ModuleInfo
-	TopImportedItems     []ItemInfo
-	PerModuleItemImports map[string][]ItemInfo
-}
-
-func main() {
-	if len(os.Args) < 2 { fmt.Println("Usage: go run main.go <directory>"); os.Exit(1) }
-	rootDir := os.Args[1]
-
-	symbolTable, err := buildSymbolTable(rootDir)
-	if err != nil { log.Fatalf("Error building symbol table: %v", err) }
-
-	dependencies, itemImports, err := analyzeDependencies(rootDir, symbolTable)
-	if err != nil { log.Fatalf("Error analyzing dependencies: %v", err) }
-
-	htmlContent, err := generateHTMLReport(dependencies, itemImports, rootDir)
-	if err != nil { log.Fatalf("Error generating HTML report: %v", err) }
-	
-	serveAndOpen(htmlContent)
-}
-
-// --- Pass 1: Symbol Table Builder ---
-func buildSymbolTable(root string) (map[string]map[string]struct{}, error) {
-	table := make(map[string]map[string]struct{})
-	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".rs") { return err }
-		content, err := os.ReadFile(path)
-		if err != nil { return err }
-		moduleName := getModuleNameFromFilePath(path)
-		if _, ok := table[moduleName]; !ok { table[moduleName] = make(map[string]struct{}) }
-		matches := pubDefRegex.FindAllStringSubmatch(string(content), -1)
-		for _, match := range matches { if len(match) > 1 { table[moduleName][match[1]] = struct{}{} } }
-		return nil
-	})
-	return table, err
-}
-
-// --- Pass 2: Dependency Analyzer with NEW Parsing Engine ---
-func analyzeDependencies(root string, symbolTable map[string]map[string]struct{}) (map[string]map[string]struct{}, map[string]map[string]map[string]struct{}, error) {
-	deps := make(map[string]map[string]struct{})
-	itemImports := make(map[string]map[string]map[string]struct{})
-
-	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".rs") { return err }
-		contentBytes, err := os.ReadFile(path)
-		if err != nil { return err }
-
-		fileContent := string(contentBytes)
-		contentWithoutComments := commentRegex.ReplaceAllString(fileContent, "")
-		
-		allMatches := usePathRegex.FindAllStringSubmatch(contentWithoutComments, -1)
-		for _, match := range allMatches {
-			usePrefix := match[1] // "crate" or "super"
-			fullPath := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(match[2], "::"), ";"))
-			
-			var initialPrefix []string
-			if usePrefix == "super" {
-				initialPrefix = []string{filepath.Base(filepath.Dir(path))}
-			}
-
-			// Start the new recursive parsing process
-			parseUsePathRecursive(fullPath, initialPrefix, path, fileContent, deps, itemImports, symbolTable)
-		}
-		return nil
-	})
-	return deps, itemImports, err
-}
-
-func parseUsePathRecursive(pathStr string, prefixParts []string, filePath, fileContent string, deps map[string]map[string]struct{}, itemImports map[string]map[string]map[string]struct{}, symbolTable map[string]map[string]struct{}) {
-	pathStr = strings.TrimSpace(pathStr)
-	if pathStr == "" { return }
-
-	// Handle groups like `{a, b::{c, d}}`
-	if strings.HasPrefix(pathStr, "{") {
-		for _, subPath := range splitUseGroup(pathStr) {
-			parseUsePathRecursive(subPath, prefixParts, filePath, fileContent, deps, itemImports, symbolTable)
-		}
-		return
-	}
-
-	// Handle path segments like `cpu::items::{a, b}`
-	if head, tail, found := strings.Cut(pathStr, "::"); found {
-		newPrefix := append(prefixParts, head)
-		parseUsePathRecursive(tail, newPrefix, filePath, fileContent, deps, itemImports, symbolTable)
-		return
-	}
-
-	// Base case: we have a final item (e.g., `Engine`, `*`, or `self`)
-	itemName := strings.TrimSpace(strings.Split(pathStr, " as ")[0])
-	if itemName == "self" || itemName == "" { return }
-
-	if len(prefixParts) == 0 { return } // Should not happen with `crate` or `super`
-	moduleName := prefixParts[0]
-
-	// Register module dependency
-	if deps[filePath] == nil { deps[filePath] = make(map[string]struct{}) }
-	deps[filePath][moduleName] = struct{}{}
-
-	if _, ok := itemImports[moduleName]; !ok { itemImports[moduleName] = make(map[string]map[string]struct{}) }
-
-	// Handle glob or specific item
-	if itemName == "*" {
-		if publicSymbols, ok := symbolTable[moduleName]; ok {
-			for symbol := range publicSymbols {
-				if r, err := regexp.Compile(`\b` + symbol + `\b`); err == nil && r.MatchString(fileContent) {
-					if _, ok := itemImports[moduleName][symbol]; !ok { itemImports[moduleName][symbol] = make(map[string]struct{}) }
-					itemImports[moduleName][symbol][filePath] = struct{}{}
-				}
-			}
-		}
-	} else {
-		if _, ok := itemImports[moduleName][itemName]; !ok { itemImports[moduleName][itemName] = make(map[string]struct{}) }
-		itemImports[moduleName][itemName][filePath] = struct{}{}
-	}
-}
-
-func splitUseGroup(group string) []string {
-	// Expects input WITH outer braces, e.g., "{ a, b::{c,d}, e, }"
-	if !strings.HasPrefix(group, "{") || !strings.HasSuffix(group, "}") {
-		return []string{group}
-	}
-	content := group[1 : len(group)-1]
-
-	var paths []string
-	braceLevel := 0
-	lastSplit := 0
-	for i, char := range content {
-		switch char {
-		case '{': braceLevel++
-		case '}': braceLevel--
-		case ',':
-			if braceLevel == 0 {
-				paths = append(paths, strings.TrimSpace(content[lastSplit:i]))
-				lastSplit = i + 1
-			}
-		}
-	}
-	// Add the final part of the string after the last comma.
-	if lastSplit <= len(content) {
-		paths = append(paths, strings.TrimSpace(content[lastSplit:]))
-	}
-	
-	var finalPaths []string
-	for _, p := range paths { if p != "" { finalPaths = append(finalPaths, p) } }
-	return finalPaths
-}
-
-func getModuleNameFromFilePath(path string) string {
-	if strings.HasSuffix(path, "mod.rs") || strings.HasSuffix(path, "lib.rs") { return filepath.Base(filepath.Dir(path)) }
-	return strings.TrimSuffix(filepath.Base(path), ".rs")
-}
-
-func generateHTMLReport(dependencies map[string]map[string]struct{}, itemImports map[string]map[string]map[string]struct{}, rootDir string) (string, error) {
-	inbound := make(map[string][]string); for file, deps := range dependencies { for dep := range deps { inbound[dep] = append(inbound[dep], filepath.Base(file)) } }
-	var allModules []ModuleInfo
-	for module, files := range inbound {
-		if module == "" { continue }
-		fileSet := make(map[string]struct{}); for _, f := range files { fileSet[f] = struct{}{} }
-		uniqueFiles := []string{}; for f := range fileSet { uniqueFiles = append(uniqueFiles, f) }
-		sort.Strings(uniqueFiles)
-		allModules = append(allModules, ModuleInfo{Name: module, ID: "module-" + module, CountStr: fmt.Sprintf("%d", len(uniqueFiles)), Dependents: uniqueFiles})
-	}
-	sort.Slice(allModules, func(i, j int) bool {
-		c1, _ := strconv.Atoi(allModules[i].CountStr); c2, _ := strconv.Atoi(allModules[j].CountStr)
-		if c1 != c2 { return c1 > c2 }; return allModules[i].Name < allModules[j].Name
-	})
-
-	var topImportedItems []ItemInfo
-	perModuleItemImports := make(map[string][]ItemInfo)
-	var sortedModuleNames []string
-	for module := range itemImports { if len(itemImports[module]) > 0 { sortedModuleNames = append(sortedModuleNames, module) } }
-	sort.Strings(sortedModuleNames)
-	for _, module := range sortedModuleNames {
-		var items []ItemInfo
-		for name, fileSet := range itemImports[module] {
-			var files []string
-			for f := range fileSet { files = append(files, filepath.Base(f)) }
-			sort.Strings(files)
-			item := ItemInfo{ModuleName: module, Name: name, CountStr: fmt.Sprintf("%d", len(files)), Files: files}
-			items = append(items, item)
-			topImportedItems = append(topImportedItems, item)
-		}
-		sort.Slice(items, func(i, j int) bool {
-			c1, _ := strconv.Atoi(items[i].CountStr); c2, _ := strconv.Atoi(items[j].CountStr)
-			if c1 != c2 { return c1 > c2 }; return items[i].Name < items[j].Name
-		})
-		perModuleItemImports[module] = items
-	}
-	sort.Slice(topImportedItems, func(i, j int) bool {
-		c1, _ := strconv.Atoi(topImportedItems[i].CountStr); c2, _ := strconv.Atoi(topImportedItems[j].CountStr)
-		if c1 != c2 { return c1 > c2 }; return topImportedItems[i].ModuleName < topImportedItems[j].ModuleName
-	})
-
-	data := TemplateData{ TargetDir: rootDir, AllModules: allModules, TopImportedItems: topImportedItems, PerModuleItemImports: perModuleItemImports }
-	tmpl, err := template.New("report").Funcs(template.FuncMap{ "join": func(s []string) string { return strings.Join(s, ", ") }}).Parse(htmlTemplate)
-	if err != nil { return "", err }
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil { return "", err }
-	return buf.String(), nil
-}
-
-func serveAndOpen(htmlContent string) {
-	listener, err := net.Listen("tcp", "127.0.0.1:0")
-	if err != nil { log.Fatalf("Could not find an available port: %v", err) }
-	port := listener.Addr().(*net.TCPAddr).Port
-	url := fmt.Sprintf("http://127.0.0.1:%d", port)
-	shutdown := make(chan struct{})
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "text/html"); fmt.Fprint(w, htmlContent); close(shutdown)
-	})
-	fmt.Printf("✅ Analysis complete. Opening report in your browser at %s\n", url)
-	if err := openBrowser(url); err != nil { log.Printf("Could not open browser automatically: %v. Please open this URL manually: %s", err, url) }
-	go func() { if err := http.Serve(listener, nil); err != http.ErrServerClosed { log.Fatalf("Server error: %v", err) } }()
-	select {
-	case <-shutdown: time.Sleep(100 * time.Millisecond)
-	case <-time.After(30 * time.Second): log.Println("Timed out waiting for page to be loaded.")
-	}
-}
-
-func openBrowser(url string) error {
-	var cmd *exec.Cmd
-	switch runtime.GOOS {
-	case "darwin": cmd = exec.Command("open", url)
-	case "linux": cmd = exec.Command("xdg-open", url)
-	case "windows": cmd = exec.Command("cmd", "/c", "start", strings.Replace(url, "&", "^&", -1))
-	default: return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
-	}
-	return cmd.Run()
-}
-
-const htmlTemplate = `
-<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8"><meta name="viewport" content="width=device-width, initial-scale=1.0"><title>Rust Dependency Analysis Report</title>
-    <link rel="preconnect" href="https://fonts.googleapis.com"><link rel="preconnect" href="https://fonts.gstatic.com" crossorigin>
-    <link href="https://fonts.googleapis.com/css2?family=Inter:wght@400;500;700&family=Fira+Code:wght@400;500&display=swap" rel="stylesheet">
-    <style>
-        :root { --bg-color: #1a1b26; --card-bg: #24283b; --border-color: #3b4261; --text-color: #c0caf5; --heading-color: #ffffff; --green: #9ece6a; --yellow: #e0af68; --blue: #7aa2f7; --magenta: #bb9af7; --cyan: #7dcfff; --font-sans: 'Inter', sans-serif; --font-mono: 'Fira Code', monospace; }
-        html { scroll-behavior: smooth; }
-        body { background-color: var(--bg-color); color: var(--text-color); font-family: var(--font-sans); margin: 0; padding: 2rem; line-height: 1.6; }
-        .container { max-width: 1200px; margin: 0 auto; }
-        header { text-align: center; margin-bottom: 2rem; }
-        header h1 { font-size: 2.5rem; color: var(--heading-color); font-weight: 700; margin: 0; }
-        header .target-dir { font-family: var(--font-mono); color: var(--cyan); background-color: var(--card-bg); padding: 0.25rem 0.5rem; border-radius: 6px; display: inline-block; margin-top: 0.5rem; }
-		nav { background-color: var(--card-bg); border: 1px solid var(--border-color); padding: 1rem 1.5rem; margin-bottom: 2.5rem; border-radius: 8px; }
-		nav h3 { margin: 0 0 0.75rem 0; font-size: 1rem; color: var(--heading-color); text-align: center; }
-		.nav-links { display: flex; flex-wrap: wrap; justify-content: center; gap: 0.4rem 0.8rem; }
-		nav a { color: var(--blue); text-decoration: none; font-size: 0.9rem; font-family: var(--font-mono); transition: color 0.2s; background-color: var(--bg-color); padding: 0.2rem 0.5rem; border-radius: 4px; }
-		nav a:hover { color: var(--cyan); }
-        .analysis-section { background-color: var(--card-bg); border: 1px solid var(--border-color); border-radius: 8px; margin-bottom: 2.5rem; overflow: hidden; }
-        .analysis-section > h2 { font-size: 1.5rem; color: var(--heading-color); margin: 0; padding: 1rem 1.5rem; border-bottom: 1px solid var(--border-color); }
-        .table-container { overflow-x: auto; padding: 0.5rem 0 0.5rem 0; }
-		.table-container table { margin: 0 1.5rem; width: calc(100% - 3rem); }
-        table { border-collapse: collapse; font-size: 0.95rem; }
-        th, td { padding: 0.85rem 1rem; text-align: left; border-bottom: 1px solid var(--border-color); }
-        thead th { font-weight: 500; color: var(--heading-color); font-size: 1rem; white-space: nowrap; }
-        tbody tr:last-child td { border-bottom: none; }
-        .module-name, .item-name { color: var(--yellow); font-family: var(--font-mono); }
-        .dep-count { color: var(--green); font-weight: 500; font-family: var(--font-mono); text-align: center; white-space: nowrap; }
-        .used-by-files { color: var(--blue); font-family: var(--font-mono); white-space: normal; max-width: 60ch; }
-		details { cursor: pointer; }
-		summary { list-style: none; display: flex; align-items: center; justify-content: space-between; }
-		summary::-webkit-details-marker { display: none; }
-		summary .item-name { flex-grow: 1; }
-		summary .dep-count { padding-left: 1rem; }
-		summary::before { content: '▸'; color: var(--cyan); margin-right: 0.5rem; font-size: 0.8em; transition: transform 0.2s; }
-		details[open] > summary::before { transform: rotate(90deg); }
-		.details-content { padding: 0.75rem 1rem; margin-top: 0.5rem; background-color: var(--bg-color); border-radius: 4px; font-size: 0.9em; }
-		.details-content ul { margin: 0; padding-left: 1.2rem; }
-		.module-header { color: var(--magenta); margin: 0; padding: 1rem 1.5rem; border-bottom: 1px solid var(--border-color); border-top: 2px solid var(--border-color); }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <header><h1>✨ Rust Dependency Analysis Report</h1><p>Target Directory: <span class="target-dir">{{ .TargetDir }}</span></p></header>
-		<nav>
-			<h3>Quick Navigation</h3>
-			<div class="nav-links">
-				<a href="#top-items">🏆 Top Items</a>
-				<a href="#inbound-deps">📥 All Modules</a>
-				{{range .AllModules}}<a href="#{{.ID}}">{{.Name}}</a>{{end}}
-			</div>
-		</nav>
-        <main>
-			<section class="analysis-section" id="top-items">
-				<h2>🏆 Top Imported Items (All Modules)</h2>
-				<div class="table-container"><table><thead><tr><th>Item</th><th>From Module</th><th style="text-align: center;">Total Imports</th></tr></thead><tbody>
-				{{range .TopImportedItems}}<tr><td class="item-name">{{.Name}}</td><td class="module-name">{{.ModuleName}}</td><td class="dep-count">{{.CountStr}}</td></tr>{{else}}<tr><td colspan="3">No items found.</td></tr>{{end}}
-				</tbody></table></div>
-			</section>
-            <section class="analysis-section" id="inbound-deps">
-                <h2>📥 Inbound Module Dependencies</h2>
-				<div class="table-container"><table><thead><tr><th>Module</th><th style="text-align: center;">Used by # Files</th><th>Used By Files</th></tr></thead><tbody>
-				{{range .AllModules}}<tr><td class="module-name">{{.Name}}</td><td class="dep-count">{{.CountStr}}</td><td class="used-by-files">{{join .Dependents}}</td></tr>{{else}}<tr><td colspan="3">No module dependencies found.</td></tr>{{end}}
-				</tbody></table></div>
-            </section>
-			<section class="analysis-section" id="per-module-analysis">
-				<h2 style="border-bottom: none;">📊 Per-Module Item Frequency</h2>
-				{{if not .PerModuleItemImports}}<div style="padding: 1.5rem;">No specific item imports found.</div>{{else}}
-                    {{range $module, $items := .PerModuleItemImports}}
-                    <h3 class="module-header" id="module-{{$module}}">Module: {{$module}}</h3>
-					<div class="table-container"><table><thead><tr><th style="width: 100%;">Item & (Click to expand)</th><th style="text-align: center;">Import Count</th></tr></thead><tbody>
-					{{range $items}}
-					<tr><td colspan="2" style="padding: 0.5rem 1rem;">
-						<details>
-							<summary><span class="item-name">{{.Name}}</span><span class="dep-count">{{.CountStr}}</span></summary>
-							<div class="details-content"><strong>Imported in:</strong><ul>{{range .Files}}<li>{{.}}</li>{{end}}</ul></div>
-						</details>
-					</td></tr>
-					{{end}}
-					</tbody></table></div>
-                    {{end}}
-                {{end}}
-			</section>
-        </main>
-    </div>
-</body>
-</html>
-`
\ No newline at end of file
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// Well-known tags keep a fixed color; any other tag gets a stable color hashed from its name.
+var knownTagColors = map[string]string{"ui": "#7aa2f7", "domain": "#9ece6a", "infra": "#e0af68", "experimental": "#bb9af7"}
+var tagPalette = []string{"#7dcfff", "#f7768e", "#ff9e64", "#73daca", "#2ac3de", "#c0caf5"}
+
+type ModuleInfo struct { Name, ID, CountStr, Tag string; Dependents, ProdDependents, TestDependents []string }
+type ItemInfo struct { ModuleName, Name, CountStr, Tag string; Files []string; Sites []ImportSite; Provenance *analyzer.Provenance }
+type TagInfo struct { Name, Color string }
+
+// GraphData feeds the interactive module graph; it is embedded in the page as JSON.
+type GraphData struct {
+	Nodes  []GraphNode            `json:"nodes"`
+	Edges  []ModuleEdge           `json:"edges"`
+	Layout map[string]LayoutPoint `json:"layout,omitempty"` // from --layout; overridden by a layout saved in the browser
+}
+type GraphNode struct {
+	ID        string `json:"id"`
+	Tag       string `json:"tag"`
+	Color     string `json:"color"`
+	FanIn     int    `json:"fanIn"`
+	Generated bool   `json:"generated,omitempty"`
+}
+type TemplateData struct {
+	TargetDir            string
+	Minimal              bool
+	Live                 bool
+	NotesAPI             bool
+	SourceLinks          bool // served by serve: import lines link to /source/
+	Summary              *ReportSummary
+	Notes                []NoteInfo
+	Languages            []LanguageInfo // set for a --mixed analysis of several languages
+	CrossLanguage        []CrossLanguageInfo
+	Tags                 []TagInfo
+	MetricsScope         string
+	SeparateTests        bool // --tests separate: the modules table counts production and test importers apart
+	Metrics              []ModuleMetrics
+	GodModules           []GodModule
+	GodModuleLimits      analyzer.GodModuleLimits
+	Closures             []ClosureInfo
+	Treemap              []TreemapCell
+	Conditional          []ConditionalInfo
+	UnsafeHotspots       []UnsafeHotspot
+	ExternalCrates       []ExternalCrateInfo
+	Outbound             []FileOutbound
+	Cycles               []CycleInfo
+	Coupling             []ChangeCoupling
+	CouplingCommits      int // commits mined for Coupling; 0 without git history
+	EdgeHistory          bool // a history store was given, so EdgeAges is meaningful
+	EdgeAges             []EdgeAge
+	Strict               bool
+	BoundaryLeaks        []BoundaryLeak
+	Layers               [][]string
+	LayerViolations      []LayerViolation
+	InferredLayers       InferredLayers
+	Interfaces           []InterfaceInfo
+	UseStyles            []UseStyleInfo // per module; empty outside Rust
+	UseStyleTotal        UseStyleInfo
+	ModTree              *analyzer.ModTree
+	Graph                GraphData
+	AllModules           []ModuleInfo
+	TopImportedItems     []ItemInfo
+	PerModuleItemImports map[string][]ItemInfo
+}
+
+// embeddedMain, when a build sets it, replaces the CLI for a run without arguments (see wasm.go).
+var embeddedMain func()
+
+func main() {
+	flag.Usage = func() { fmt.Println(usageText) }
+	if len(os.Args) < 2 && embeddedMain != nil { embeddedMain(); return }
+	if len(os.Args) < 2 { flag.Usage(); os.Exit(1) }
+	switch os.Args[1] {
+	case "analyze": runAnalyze(os.Args[2:])
+	case "serve": runServe(os.Args[2:])
+	case "export": runExport(os.Args[2:])
+	case "diff": runDiff(os.Args[2:])
+	case "api-diff": runAPIDiff(os.Args[2:])
+	case "validate": runValidate(os.Args[2:])
+	case "aggregate": runAggregate(os.Args[2:])
+	case "init": runInit(os.Args[2:])
+	case "schema": os.Stdout.Write(snapshotSchema)
+	case "daemon": runDaemon(os.Args[2:])
+	case "host": runHost(os.Args[2:])
+	case "cache": runCache(os.Args[2:])
+	case "pr-comment": runPRComment(os.Args[2:])
+	case "docs": runDocs(os.Args[2:])
+	case "check": runCheck(os.Args[2:])
+	case "rename-impact": runRenameImpact(os.Args[2:])
+	case "bench": runBench(os.Args[2:])
+	case "api-churn": runAPIChurn(os.Args[2:])
+	case "history": runHistory(os.Args[2:])
+	case "edges": runEdges(os.Args[2:])
+	case "what-if": runWhatIf(os.Args[2:])
+	case "who-uses", "impact", "explain", "imports": runQuery(os.Args[1], os.Args[2:])
+	case "-h", "-help", "--help", "help": flag.Usage()
+	default: runAnalyze(os.Args[1:]) // `dependant [flags] <directory>` predates the subcommands
+	}
+}
+
+// shortFileNames reduces paths to their base names for display, deduplicated and sorted.
+func shortFileNames(paths []string) []string {
+	var names []string; for _, p := range paths { names = append(names, filepath.Base(p)) }
+	return uniqueSorted(names)
+}
+
+func uniqueSorted(values []string) []string {
+	set := make(map[string]struct{}); for _, v := range values { set[v] = struct{}{} }
+	out := []string{}; for v := range set { out = append(out, v) }
+	sort.Strings(out)
+	return out
+}
+
+func relSlash(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil { return filepath.ToSlash(path) }
+	return filepath.ToSlash(rel)
+}
+
+func tagColor(tag string) string {
+	if c, ok := knownTagColors[tag]; ok { return c }
+	h := fnv.New32a(); h.Write([]byte(tag))
+	return tagPalette[h.Sum32()%uint32(len(tagPalette))]
+}
+
+// reportSections names the report's sections for --sections, in page order.
+var reportSections = []string{"summary", "layers", "notes", "languages", "top-items", "cycles", "modules", "outbound", "graph", "treemap", "inferred-layers", "metrics", "god-modules", "closure", "interfaces", "use-style", "conditional", "unsafe", "external-crates", "coupling", "edge-history", "boundaries", "mod-tree", "per-module"}
+
+// ReportOptions carry the command-line choices that shape the HTML report.
+type ReportOptions struct {
+	RootDir       string
+	MetricsScope  string                 // "prod" or "all"
+	SeparateTests bool                   // --tests separate
+	Minimal       bool                   // no external requests: system fonts, inline SVG icons and a Content-Security-Policy forbidding fetches
+	Strict        bool                   // strict boundary mode, also enabled by strict_boundaries in dependant.toml
+	Live          bool                   // served by --watch: listen on /events and reload when the tree is re-analyzed
+	NotesAPI      bool                   // served by serve: notes can be added from the page through /api/notes
+	SourceLinks   bool                   // served by serve: import lines link to the file's lines under /source/
+	Snapshot      bool                   // rebuilt from a snapshot: import lines are not read from the tree, which may have changed since
+	Sections      map[string]bool        // sections to render, keyed by reportSections name; nil renders all
+	Layout        map[string]LayoutPoint // optional saved graph layout
+	History       HistoryStore           // records dating each module edge for the edge-history section; nil omits it
+}
+
+func generateHTMLReport(analysis *analyzer.Report, opts ReportOptions) (string, error) {
+	graph, facts := analysis.Graph, analysis.Facts
+	dependencies, itemImports, tags := graph.Deps, graph.ItemImports, facts.Tags
+	rootDir, metricsScope := opts.RootDir, opts.MetricsScope
+	if analysis.Rev != "" { rootDir = fmt.Sprintf("%s @ %.10s", analysis.Origin, analysis.Rev) }
+	rel := func(file string) string { return relSlash(analysis.Root, file) } // whole paths: net/http.rs and server/http.rs are two importers
+	inbound := make(map[string][]string); for file, deps := range dependencies { for dep := range deps { inbound[dep] = append(inbound[dep], rel(file)) } }
+	testInbound := make(map[string][]string)
+	prodInbound := make(map[string][]string); for file, deps := range graph.ProdDeps { for dep := range deps { prodInbound[dep] = append(prodInbound[dep], rel(file)) } }
+	for file, deps := range dependencies { for dep := range deps { if _, prod := graph.ProdDeps[file][dep]; !prod { testInbound[dep] = append(testInbound[dep], rel(file)) } } }
+	var allModules []ModuleInfo
+	for module, files := range inbound {
+		if module == "" { continue }
+		fileSet := make(map[string]struct{}); for _, f := range files { fileSet[f] = struct{}{} }
+		uniqueFiles := []string{}; for f := range fileSet { uniqueFiles = append(uniqueFiles, f) }
+		sort.Strings(uniqueFiles)
+		testFiles := uniqueSorted(testInbound[module])
+		allModules = append(allModules, ModuleInfo{Name: module, ID: "module-" + module, CountStr: fmt.Sprintf("%d", len(uniqueFiles)), Tag: tags[module], Dependents: uniqueFiles, ProdDependents: uniqueSorted(prodInbound[module]), TestDependents: testFiles})
+	}
+	sort.Slice(allModules, func(i, j int) bool {
+		c1, _ := strconv.Atoi(allModules[i].CountStr); c2, _ := strconv.Atoi(allModules[j].CountStr)
+		if c1 != c2 { return c1 > c2 }; return allModules[i].Name < allModules[j].Name
+	})
+
+	var topImportedItems []ItemInfo
+	perModuleItemImports := make(map[string][]ItemInfo)
+	readSites := (opts.Sections == nil || opts.Sections["per-module"]) && !opts.Snapshot
+	statements := make(map[string][]importStatement) // file -> its import statements, read once for every item
+	var sortedModuleNames []string
+	for module := range itemImports { if len(itemImports[module]) > 0 { sortedModuleNames = append(sortedModuleNames, module) } }
+	sort.Strings(sortedModuleNames)
+	for _, module := range sortedModuleNames {
+		var items []ItemInfo
+		for name, fileSet := range itemImports[module] {
+			var files []string
+			for f := range fileSet { files = append(files, rel(f)) }
+			sort.Strings(files)
+			item := ItemInfo{ModuleName: module, Name: name, CountStr: fmt.Sprintf("%d", len(files)), Tag: tags[module], Files: files, Provenance: graph.ItemProvenance(module, name)}
+			if readSites { item.Sites = importSites(analysis.Root, fileSet, module, name, statements) }
+			items = append(items, item)
+			topImportedItems = append(topImportedItems, item)
+		}
+		sort.Slice(items, func(i, j int) bool {
+			c1, _ := strconv.Atoi(items[i].CountStr); c2, _ := strconv.Atoi(items[j].CountStr)
+			if c1 != c2 { return c1 > c2 }; return items[i].Name < items[j].Name
+		})
+		perModuleItemImports[module] = items
+	}
+	sort.Slice(topImportedItems, func(i, j int) bool {
+		c1, _ := strconv.Atoi(topImportedItems[i].CountStr); c2, _ := strconv.Atoi(topImportedItems[j].CountStr)
+		if c1 != c2 { return c1 > c2 }; return topImportedItems[i].ModuleName < topImportedItems[j].ModuleName
+	})
+
+	var tagInfos []TagInfo
+	seenTags := make(map[string]struct{})
+	for _, tag := range tags { if _, ok := seenTags[tag]; !ok && tag != "" { seenTags[tag] = struct{}{}; tagInfos = append(tagInfos, TagInfo{Name: tag, Color: tagColor(tag)}) } }
+	sort.Slice(tagInfos, func(i, j int) bool { return tagInfos[i].Name < tagInfos[j].Name })
+
+	notes, err := readNotes(analysis.Root)
+	if err != nil { return "", err }
+	show := func(section string) bool { return opts.Sections == nil || opts.Sections[section] }
+	data := TemplateData{ TargetDir: rootDir, Minimal: opts.Minimal, Live: opts.Live, NotesAPI: opts.NotesAPI, SourceLinks: opts.SourceLinks, Tags: tagInfos, MetricsScope: metricsScope, SeparateTests: opts.SeparateTests, ModTree: analysis.ModTree, AllModules: allModules, TopImportedItems: topImportedItems, PerModuleItemImports: perModuleItemImports }
+	if show("metrics") || show("graph") || show("unsafe") || show("treemap") {
+		metricsDeps := dependencies
+		if metricsScope == "prod" { metricsDeps = graph.ProdDeps }
+		data.Metrics = computeModuleMetrics(analysis, metricsDeps)
+	}
+	if show("summary") {
+		summaryDeps := dependencies
+		if metricsScope == "prod" { summaryDeps = graph.ProdDeps }
+		summary, err := computeReportSummary(analysis, summaryDeps, topImportedItems)
+		if err != nil { return "", err }
+		data.Summary = &summary
+	}
+	if show("graph") {
+		data.Graph = GraphData{Nodes: []GraphNode{}, Edges: buildWeightedEdges(analysis, itemImports), Layout: opts.Layout}
+		for _, m := range data.Metrics {
+			color := "#c0caf5"; if m.Tag != "" { color = tagColor(m.Tag) }
+			data.Graph.Nodes = append(data.Graph.Nodes, GraphNode{ID: m.Name, Tag: m.Tag, Color: color, FanIn: m.FanIn, Generated: facts.Generated[m.Name]})
+		}
+	}
+	if show("god-modules") { data.GodModules, data.GodModuleLimits = computeGodModules(analysis), analysis.Config.GodModules }
+	if show("notes") { data.Notes = noteInfos(notes, analysis) }
+	if show("languages") && len(analysis.Languages) > 1 { data.Languages, data.CrossLanguage = computeLanguages(analysis) }
+	if show("treemap") { data.Treemap = computeTreemap(allModules, data.Metrics) }
+	if show("closure") {
+		closureDeps := dependencies
+		if metricsScope == "prod" { closureDeps = graph.ProdDeps }
+		data.Closures = computeClosures(analysis.BuildModuleGraph(closureDeps), tags)
+	}
+	if show("unsafe") { data.UnsafeHotspots = computeUnsafeHotspots(facts, data.Metrics) }
+	if show("conditional") { data.Conditional = computeConditionalImports(graph.Conditions, tags) }
+	if show("external-crates") { data.ExternalCrates = computeCrateAudit(graph.External, analysis.Manifest, analysis.Lockfile) }
+	if show("cycles") { data.Cycles = computeCycles(analysis, dependencies) }
+	if show("outbound") { data.Outbound = computeFileOutbound(analysis, graph, tags) }
+	if (opts.Strict || analysis.Config.StrictBoundaries) && show("boundaries") { data.Strict, data.BoundaryLeaks = true, findBoundaryLeaks(analysis) }
+	if show("inferred-layers") { data.InferredLayers = inferLayers(analysis.BuildModuleGraph(graph.ProdDeps), sortedKeys(analysis.SymbolTable)) }
+	if show("layers") { data.Layers, data.LayerViolations = analysis.Config.Layers, findLayerViolations(analysis) }
+	if show("coupling") { data.Coupling, data.CouplingCommits = computeChangeCoupling(analysis) }
+	if opts.History != nil && show("edge-history") {
+		meta, err := readEdgeMetadata(opts.History)
+		if err != nil { return "", err }
+		data.EdgeHistory, data.EdgeAges = true, computeEdgeAges(analysis.BuildModuleGraph(dependencies), meta, tags)
+	}
+	if show("interfaces") { data.Interfaces = computeInterfaces(analysis, analysis.SymbolTable, itemImports, tags) }
+	if show("use-style") { data.UseStyleTotal, data.UseStyles = computeUseStyles(analysis) }
+	sections, icon := sectionProvenance(analysis), iconFunc(opts.Minimal)
+	funcs := template.FuncMap{
+		"show":         show,
+		"icon":         icon,
+		"badge":        provenanceBadge,
+		"sectionBadge": func(section string) template.HTML { if p, ok := sections[section]; ok { return provenanceBadge(&p) }; return "" },
+		"join":         func(s []string) string { return strings.Join(s, ", ") },
+		"copyAs":       func(module, item string) template.HTML { return copyAsHTML(copyForms(analysis, module, item)) },
+		"notes":        func(module, item string) template.HTML { return notesHTML(notes, module, item, opts.NotesAPI, icon) },
+		"tagOf":        func(module string) string { return tags[module] },
+		"generated":    func(module string) bool { return facts.Generated[module] },
+		"tagStyle":     func(tag string) template.CSS { if tag == "" { return "" }; return template.CSS("--tag-color: " + tagColor(tag)) },
+	}
+	tmpl, err := template.New("report").Funcs(funcs).Parse(htmlTemplate)
+	if err != nil { return "", err }
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil { return "", err }
+	return buf.String(), nil
+}
+
+const htmlTemplate = `
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8"><meta name="viewport" content="width=device-width, initial-scale=1.0"><title>Rust Dependency Analysis Report</title>
+    {{if .Minimal}}<meta http-equiv="Content-Security-Policy" content="default-src 'none'; style-src 'unsafe-inline'; script-src 'unsafe-inline'; img-src data:; connect-src 'self'"><link rel="icon" href="data:,">{{else}}
+    <link rel="preconnect" href="https://fonts.googleapis.com"><link rel="preconnect" href="https://fonts.gstatic.com" crossorigin>
+    <link href="https://fonts.googleapis.com/css2?family=Inter:wght@400;500;700&family=Fira+Code:wght@400;500&display=swap" rel="stylesheet">{{end}}
+    <style>
+        :root { --bg-color: #1a1b26; --card-bg: #24283b; --border-color: #3b4261; --text-color: #c0caf5; --heading-color: #ffffff; --green: #9ece6a; --yellow: #e0af68; --blue: #7aa2f7; --magenta: #bb9af7; --cyan: #7dcfff; --font-sans: 'Inter', sans-serif; --font-mono: 'Fira Code', monospace; }
+        {{if .Minimal}}:root { --font-sans: system-ui, -apple-system, 'Segoe UI', sans-serif; --font-mono: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; }{{end}}
+        .icon { width: 1em; height: 1em; vertical-align: -0.125em; fill: none; stroke: currentColor; stroke-width: 2; stroke-linecap: round; stroke-linejoin: round; }
+        html { scroll-behavior: smooth; }
+        body { background-color: var(--bg-color); color: var(--text-color); font-family: var(--font-sans); margin: 0; padding: 2rem; line-height: 1.6; }
+        .container { max-width: 1200px; margin: 0 auto; }
+        header { text-align: center; margin-bottom: 2rem; }
+        header h1 { font-size: 2.5rem; color: var(--heading-color); font-weight: 700; margin: 0; }
+        header .target-dir { font-family: var(--font-mono); color: var(--cyan); background-color: var(--card-bg); padding: 0.25rem 0.5rem; border-radius: 6px; display: inline-block; margin-top: 0.5rem; }
+		nav { background-color: var(--card-bg); border: 1px solid var(--border-color); padding: 1rem 1.5rem; margin-bottom: 2.5rem; border-radius: 8px; }
+		nav h3 { margin: 0 0 0.75rem 0; font-size: 1rem; color: var(--heading-color); text-align: center; }
+		.nav-links { display: flex; flex-wrap: wrap; justify-content: center; gap: 0.4rem 0.8rem; }
+		nav a { color: var(--blue); text-decoration: none; font-size: 0.9rem; font-family: var(--font-mono); transition: color 0.2s; background-color: var(--bg-color); padding: 0.2rem 0.5rem; border-radius: 4px; }
+		nav a:hover { color: var(--cyan); }
+        .analysis-section { background-color: var(--card-bg); border: 1px solid var(--border-color); border-radius: 8px; margin-bottom: 2.5rem; overflow: hidden; }
+        .analysis-section > h2 { font-size: 1.5rem; color: var(--heading-color); margin: 0; padding: 1rem 1.5rem; border-bottom: 1px solid var(--border-color); }
+        .table-container { overflow-x: auto; padding: 0.5rem 0 0.5rem 0; }
+		.table-container table { margin: 0 1.5rem; width: calc(100% - 3rem); }
+        table { border-collapse: collapse; font-size: 0.95rem; }
+        th, td { padding: 0.85rem 1rem; text-align: left; border-bottom: 1px solid var(--border-color); }
+        thead th { font-weight: 500; color: var(--heading-color); font-size: 1rem; white-space: nowrap; }
+        tbody tr:last-child td { border-bottom: none; }
+        .module-name, .item-name { color: var(--yellow); font-family: var(--font-mono); }
+        .dep-count { color: var(--green); font-weight: 500; font-family: var(--font-mono); text-align: center; white-space: nowrap; }
+        .used-by-files { color: var(--blue); font-family: var(--font-mono); white-space: normal; max-width: 60ch; }
+		details { cursor: pointer; }
+		summary { list-style: none; display: flex; align-items: center; justify-content: space-between; }
+		summary::-webkit-details-marker { display: none; }
+		summary .item-name { flex-grow: 1; }
+		summary .dep-count { padding-left: 1rem; }
+		summary::before { content: '▸'; color: var(--cyan); margin-right: 0.5rem; font-size: 0.8em; transition: transform 0.2s; }
+		details[open] > summary::before { transform: rotate(90deg); }
+		.details-content { padding: 0.75rem 1rem; margin-top: 0.5rem; background-color: var(--bg-color); border-radius: 4px; font-size: 0.9em; }
+		.details-content ul { margin: 0; padding-left: 1.2rem; }
+		.module-header { color: var(--magenta); margin: 0; padding: 1rem 1.5rem; border-bottom: 1px solid var(--border-color); border-top: 2px solid var(--border-color); }
+		.analysis-section > h2 { cursor: pointer; }
+		.analysis-section.collapsed > :not(h2) { display: none; }
+		.palette { position: fixed; inset: 0; background-color: rgba(0, 0, 0, 0.5); display: flex; justify-content: center; align-items: flex-start; padding-top: 12vh; z-index: 10; }
+		.palette[hidden] { display: none; }
+		.palette-box { width: min(640px, 90vw); background-color: var(--card-bg); border: 1px solid var(--border-color); border-radius: 8px; overflow: hidden; box-shadow: 0 12px 40px rgba(0, 0, 0, 0.5); }
+		.palette-box input { width: 100%; box-sizing: border-box; padding: 0.9rem 1rem; border: none; border-bottom: 1px solid var(--border-color); background-color: var(--bg-color); color: var(--heading-color); font-size: 1rem; outline: none; }
+		.palette-box ul { list-style: none; margin: 0; padding: 0; max-height: 50vh; overflow-y: auto; }
+		.palette-box li { padding: 0.45rem 1rem; cursor: pointer; font-family: var(--font-mono); font-size: 0.9rem; display: flex; justify-content: space-between; }
+		.palette-box li.selected { background-color: var(--bg-color); color: var(--cyan); }
+		.palette-box li span { color: var(--magenta); font-family: var(--font-sans); font-size: 0.8rem; }
+		.palette-help { padding: 0.5rem 1rem; font-size: 0.75rem; border-top: 1px solid var(--border-color); }
+		kbd { font-family: var(--font-mono); background-color: var(--bg-color); border: 1px solid var(--border-color); border-radius: 3px; padding: 0 0.3rem; }
+		.tag { display: inline-block; margin-left: 0.5rem; padding: 0 0.45rem; border-radius: 999px; font-size: 0.75rem; font-family: var(--font-sans); color: var(--bg-color); background-color: var(--tag-color, var(--border-color)); vertical-align: middle; }
+		tr[style*="--tag-color"] > td:first-child { box-shadow: inset 3px 0 0 var(--tag-color); }
+		nav a[style*="--tag-color"] { border-left: 3px solid var(--tag-color); }
+		tr.generated, .generated-node { opacity: 0.5; }
+		.confidence { display: inline-block; margin-left: 0.5rem; padding: 0 0.45rem; border-radius: 999px; font-size: 0.7rem; font-weight: 500; font-family: var(--font-sans); vertical-align: middle; cursor: help; border: 1px solid; }
+		.confidence-precise { color: var(--green); border-color: var(--green); }
+		.confidence-heuristic { color: var(--yellow); border-color: var(--yellow); border-style: dashed; }
+		.layer-stack { display: flex; flex-direction: column; gap: 0.5rem; }
+		.layer-row { display: flex; flex-wrap: wrap; align-items: center; gap: 0.5rem; padding: 0.75rem; background-color: var(--card-bg); border: 1px solid var(--border-color); border-radius: 8px; }
+		.layer-apart { border-style: dashed; }
+		.layer-cyclic { border-style: dashed; color: var(--yellow); }
+		.layer-label { min-width: 6rem; color: var(--yellow); font-size: 0.85rem; }
+		.layer-module { font-family: var(--font-mono); padding: 0.1rem 0.6rem; border-radius: 6px; border: 1px solid var(--tag-color, var(--border-color)); }
+		.use-style-total td { font-weight: 700; color: var(--heading-color); }
+		.import-line { display: block; margin: 0.1rem 0 0.4rem 1rem; color: var(--text-color); font-family: var(--font-mono); font-size: 0.85em; white-space: pre-wrap; overflow-wrap: anywhere; }
+		.source-link { color: var(--blue); text-decoration: none; }
+		.source-link:hover { color: var(--cyan); text-decoration: underline; }
+		.summary-card { display: grid; grid-template-columns: repeat(auto-fit, minmax(160px, 1fr)); gap: 1px; background-color: var(--border-color); border: 1px solid var(--border-color); border-radius: 8px; overflow: hidden; margin-bottom: 2.5rem; }
+		.summary-stat { background-color: var(--card-bg); padding: 1rem 1.25rem; display: flex; flex-direction: column; gap: 0.25rem; min-width: 0; }
+		.summary-stat.wide { grid-column: span 2; }
+		.summary-value { font-family: var(--font-mono); font-size: 1.6rem; font-weight: 500; color: var(--green); overflow-wrap: anywhere; }
+		.summary-value.name { font-size: 1.1rem; color: var(--yellow); }
+		.summary-value a { color: inherit; text-decoration: none; }
+		.summary-label { font-size: 0.8rem; color: var(--text-color); opacity: 0.8; }
+		.layer-alert { display: inline-block; margin-top: 1rem; padding: 0.5rem 1rem; border: 1px solid #f7768e; border-radius: 8px; color: #f7768e; font-weight: 700; text-decoration: none; }
+		tr.violation td:first-child { border-left: 3px solid #f7768e; }
+		.generated-badge { display: inline-block; margin-left: 0.5rem; padding: 0 0.45rem; border: 1px dashed var(--border-color); border-radius: 999px; font-size: 0.75rem; font-family: var(--font-sans); color: var(--border-color); vertical-align: middle; }
+		.tag-filter { display: flex; flex-wrap: wrap; justify-content: center; align-items: center; gap: 0.4rem; margin-top: 0.75rem; font-size: 0.85rem; }
+		.tag-filter button { cursor: pointer; border: 1px solid var(--border-color); border-radius: 999px; padding: 0.1rem 0.7rem; background-color: var(--bg-color); color: var(--tag-color, var(--text-color)); font-family: var(--font-sans); }
+		.copy-as { position: relative; display: inline-block; margin-left: 0.4rem; vertical-align: middle; }
+		.copy-as > button { cursor: pointer; border: none; background: none; color: var(--border-color); font-size: 0.85rem; padding: 0 0.2rem; }
+		.copy-as:hover > button, .copy-as:focus-within > button { color: var(--cyan); }
+		.copy-menu { display: none; position: absolute; left: 100%; top: 50%; transform: translateY(-50%); z-index: 5; gap: 0.25rem; padding: 0.2rem; white-space: nowrap; background-color: var(--bg-color); border: 1px solid var(--border-color); border-radius: 6px; }
+		.copy-as:hover .copy-menu, .copy-as:focus-within .copy-menu { display: flex; }
+		.copy-menu button { cursor: pointer; border: 1px solid var(--border-color); border-radius: 4px; background-color: var(--card-bg); color: var(--magenta); font-family: var(--font-sans); font-size: 0.75rem; padding: 0 0.45rem; }
+		.copy-menu button:hover { color: var(--cyan); }
+		.copy-menu button.copied::after { content: ' ✓'; color: var(--green); }
+		.treemap { padding: 1rem 1.5rem; }
+		.treemap svg { width: 100%; height: auto; display: block; }
+		.treemap rect { stroke: var(--bg-color); stroke-width: 2; fill-opacity: 0.85; }
+		.treemap a:hover rect { fill-opacity: 1; stroke: var(--heading-color); }
+		.treemap text { fill: var(--bg-color); font-family: var(--font-mono); font-size: 13px; pointer-events: none; }
+		.note { display: block; margin-top: 0.2rem; font-family: var(--font-sans); font-size: 0.8rem; font-weight: 400; color: var(--yellow); }
+		.note-author { color: var(--border-color); }
+		.note-add { cursor: pointer; border: none; background: none; color: var(--border-color); font-size: 0.75rem; padding: 0 0.2rem; margin-left: 0.3rem; vertical-align: middle; }
+		.note-add:hover { color: var(--cyan); }
+		.notes-add { padding: 0.5rem 1.5rem 1rem; }
+		.notes-add .note-add::after { content: ' Add a note'; font-family: var(--font-sans); }
+		tr.stale { opacity: 0.6; }
+		.report-search { display: flex; justify-content: center; align-items: center; gap: 0.75rem; margin-top: 0.75rem; font-size: 0.85rem; }
+		.report-search input { width: min(420px, 80vw); padding: 0.4rem 0.75rem; border: 1px solid var(--border-color); border-radius: 6px; background-color: var(--bg-color); color: var(--heading-color); font-family: var(--font-mono); font-size: 0.9rem; outline: none; }
+		.report-search input:focus { border-color: var(--cyan); }
+		.search-hidden { display: none !important; }
+		.edge-age-filter { padding: 0.5rem 1.5rem; font-size: 0.9rem; }
+		.edge-age-filter select { background-color: var(--bg-color); color: var(--heading-color); border: 1px solid var(--border-color); border-radius: 6px; padding: 0.2rem 0.4rem; }
+		.age-hidden { display: none !important; }
+		.tag-filter button.active { background-color: var(--tag-color, var(--text-color)); color: var(--bg-color); }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <header><h1>{{icon "report"}} Rust Dependency Analysis Report</h1><p>Target Directory: <span class="target-dir">{{ .TargetDir }}</span></p>{{if .LayerViolations}}<a class="layer-alert" href="#layers">{{icon "violation"}} {{len .LayerViolations}} import{{if ne (len .LayerViolations) 1}}s{{end}} against the declared layers</a>{{end}}</header>
+		{{with .Summary}}<section class="summary-card" id="summary" aria-label="Summary">
+			<div class="summary-stat"><span class="summary-value">{{.Files}}</span><span class="summary-label">files scanned</span></div>
+			<div class="summary-stat"><span class="summary-value">{{.Modules}}</span><span class="summary-label">modules</span></div>
+			<div class="summary-stat"><span class="summary-value">{{.Edges}}</span><span class="summary-label">module edges{{if eq $.MetricsScope "prod"}} (production){{end}}</span></div>
+			<div class="summary-stat"><span class="summary-value">{{if and .Cycles (show "cycles")}}<a href="#cycles">{{.Cycles}}</a>{{else}}{{.Cycles}}{{end}}</span><span class="summary-label">cycle{{if ne .Cycles 1}}s{{end}}</span></div>
+			<div class="summary-stat"><span class="summary-value">{{printf "%.1f" .AverageFanIn}}</span><span class="summary-label">average fan-in{{sectionBadge "summary"}}</span></div>
+			<div class="summary-stat wide"><span class="summary-value name">{{if .MaxFanInModule}}{{.MaxFanInModule}}{{else}}—{{end}}</span><span class="summary-label">most depended-on module{{if .MaxFanInModule}}, by {{.MaxFanIn}} module{{if ne .MaxFanIn 1}}s{{end}}{{end}}</span></div>
+			<div class="summary-stat wide"><span class="summary-value name">{{with .TopItem}}{{.ModuleName}}::{{.Name}}{{else}}—{{end}}</span><span class="summary-label">most imported item{{with .TopItem}}, by {{.CountStr}} file{{if ne .CountStr "1"}}s{{end}}{{end}}</span></div>
+		</section>{{end}}
+		<nav>
+			<h3>Quick Navigation</h3>
+			<div class="nav-links">
+				{{if .Layers}}<a href="#layers">{{icon "layers"}} Layers{{if .LayerViolations}} ({{len .LayerViolations}}){{end}}</a>{{end}}
+				{{if and (show "notes") (or .Notes .NotesAPI)}}<a href="#notes">{{icon "notes"}} Notes ({{len .Notes}})</a>{{end}}
+				{{if .Languages}}<a href="#languages">{{icon "languages"}} Languages ({{len .Languages}})</a>{{end}}
+				{{if show "top-items"}}<a href="#top-items">{{icon "top-items"}} Top Items</a>{{end}}
+				{{if show "cycles"}}<a href="#cycles">{{icon "cycles"}} Cycles{{if .Cycles}} ({{len .Cycles}}){{end}}</a>{{end}}
+				{{if show "modules"}}<a href="#inbound-deps">{{icon "modules"}} All Modules</a>{{end}}
+				{{if show "outbound"}}<a href="#outbound">{{icon "outbound"}} Per-File Imports</a>{{end}}
+				{{if show "graph"}}<a href="#graph">{{icon "graph"}} Graph</a>{{end}}
+				{{if show "treemap"}}<a href="#treemap">{{icon "treemap"}} Treemap</a>{{end}}
+				{{if show "inferred-layers"}}<a href="#inferred-layers">{{icon "inferred-layers"}} Inferred Layers</a>{{end}}
+				{{if show "metrics"}}<a href="#metrics">{{icon "metrics"}} Metrics</a>{{end}}
+				{{if show "god-modules"}}<a href="#god-modules">{{icon "god-modules"}} God Modules{{if .GodModules}} ({{len .GodModules}}){{end}}</a>{{end}}
+				{{if show "closure"}}<a href="#closure">{{icon "closure"}} Closure</a>{{end}}
+				{{if show "interfaces"}}<a href="#interfaces">{{icon "interfaces"}} Interfaces</a>{{end}}
+				{{if .UseStyles}}<a href="#use-style">{{icon "use-style"}} Use Style</a>{{end}}
+				{{if show "conditional"}}<a href="#conditional">{{icon "conditional"}} Conditional Imports</a>{{end}}
+				{{if show "unsafe"}}<a href="#unsafe">{{icon "unsafe"}} Unsafe Hotspots</a>{{end}}
+				{{if show "external-crates"}}<a href="#external-crates">{{icon "external-crates"}} External Crates</a>{{end}}
+				{{if .Strict}}<a href="#boundaries">{{icon "boundaries"}} Boundary Leaks{{if .BoundaryLeaks}} ({{len .BoundaryLeaks}}){{end}}</a>{{end}}
+				{{if show "coupling"}}<a href="#coupling">{{icon "coupling"}} Change Coupling{{if .Coupling}} ({{len .Coupling}}){{end}}</a>{{end}}
+				{{if .EdgeHistory}}<a href="#edge-history">{{icon "edge-history"}} Edge History</a>{{end}}
+				{{if and .ModTree (show "mod-tree")}}<a href="#mod-tree">{{icon "mod-tree"}} Module Tree</a>{{end}}
+				{{if show "per-module"}}{{range .AllModules}}<a href="#{{.ID}}" data-tag="{{.Tag}}" style="{{tagStyle .Tag}}">{{.Name}}</a>{{end}}{{end}}
+			</div>
+			{{if .Tags}}<div class="tag-filter"><span>Filter by tag:</span><button class="active" data-filter="">all</button>{{range .Tags}}<button data-filter="{{.Name}}" style="{{tagStyle .Name}}">{{.Name}}</button>{{end}}</div>{{end}}
+			<div class="report-search"><input id="report-search" type="search" placeholder="Filter by module, item or file name… (/)" autocomplete="off"><span id="report-search-count"></span></div>
+		</nav>
+        <main>
+			{{if .Layers}}<section class="analysis-section" id="layers">
+				<h2>{{icon "layers"}} Layer Violations <span class="scope">{{range $i, $chain := .Layers}}{{if $i}}; {{end}}{{range $j, $l := $chain}}{{if $j}} → {{end}}{{$l}}{{end}}{{end}}</span>{{sectionBadge "layers"}}</h2>
+				<div class="table-container"><table><thead><tr><th>Importing Module</th><th>Imported Module</th><th>Items</th><th>Files</th></tr></thead><tbody>
+				{{range .LayerViolations}}<tr class="violation" data-tag="{{tagOf .From}}" style="{{tagStyle (tagOf .From)}}"><td class="module-name">{{.From}}<span class="tag">{{.FromLayer}}</span></td><td class="module-name">{{.To}}<span class="tag">{{.ToLayer}}</span></td><td class="used-by-files">{{if .Items}}{{join .Items}}{{else}}—{{end}}</td><td class="used-by-files">{{join .Files}}</td></tr>{{else}}<tr><td colspan="4">Every import follows the declared layers. {{icon "done"}}</td></tr>{{end}}
+				</tbody></table></div>
+			</section>{{end}}
+			{{if and (show "notes") (or .Notes .NotesAPI)}}<section class="analysis-section" id="notes">
+				<h2>{{icon "notes"}} Notes <span class="scope">from dependant-notes.yaml{{if .NotesAPI}}; add one with +{{icon "notes"}} next to a module or item{{end}}</span></h2>
+				<div class="table-container"><table><thead><tr><th>Module</th><th>Item</th><th>Note</th><th>Author</th></tr></thead><tbody>
+				{{range .Notes}}<tr data-tag="{{.Tag}}" style="{{tagStyle .Tag}}"{{if .Stale}} class="stale"{{end}}><td class="module-name">{{.Module}}{{if .Tag}}<span class="tag">{{.Tag}}</span>{{end}}</td><td class="item-name">{{if .Item}}{{.Item}}{{else}}—{{end}}</td><td>{{.Text}}{{if .Stale}}<div class="scope">not found in this analysis; renamed or removed?</div>{{end}}</td><td>{{.Author}}</td></tr>{{else}}<tr><td colspan="4">No notes yet.</td></tr>{{end}}
+				</tbody></table></div>
+				{{if .NotesAPI}}<div class="notes-add">{{notes "" ""}}</div>{{end}}
+			</section>{{end}}
+			{{if .Languages}}<section class="analysis-section" id="languages">
+				<h2>{{icon "languages"}} Languages <span class="scope">each analyzed from the directory of its manifest; C headers only as far as other languages call into them</span>{{sectionBadge "languages"}}</h2>
+				<div class="table-container"><table><thead><tr><th>Language</th><th>Directories</th><th style="text-align: center;">Modules</th><th style="text-align: center;">Files</th><th style="text-align: center;">Lines of Code</th><th style="text-align: center;">Edges Within</th><th style="text-align: center;">Cross-Language Edges</th></tr></thead><tbody>
+				{{range .Languages}}<tr><td class="module-name">{{.Language}}</td><td class="used-by-files">{{if .Dirs}}{{join .Dirs}}{{else}}—{{end}}</td><td class="dep-count">{{.Modules}}</td><td class="dep-count">{{.Files}}</td><td class="dep-count">{{.LOC}}</td><td class="dep-count">{{.Edges}}</td><td class="dep-count">{{.CrossEdges}}</td></tr>{{end}}
+				</tbody></table></div>
+				<div class="table-container"><table><thead><tr><th>From</th><th>To</th><th>Via</th><th>Items</th><th>Files</th></tr></thead><tbody>
+				{{range .CrossLanguage}}<tr data-tag="{{tagOf .From}}" style="{{tagStyle (tagOf .From)}}"><td class="module-name">{{.From}}<span class="tag">{{.FromLanguage}}</span></td><td class="module-name">{{.To}}<span class="tag">{{.ToLanguage}}</span></td><td>{{.Via}}</td><td class="used-by-files">{{if .Items}}{{join .Items}}{{else}}—{{end}}</td><td class="used-by-files">{{join .Files}}</td></tr>{{else}}<tr><td colspan="5">No imports between languages found.</td></tr>{{end}}
+				</tbody></table></div>
+			</section>{{end}}
+			{{if show "top-items"}}<section class="analysis-section" id="top-items">
+				<h2>{{icon "top-items"}} Top Imported Items (All Modules){{sectionBadge "top-items"}}</h2>
+				<div class="table-container"><table><thead><tr><th>Item</th><th>From Module</th><th style="text-align: center;">Total Imports</th></tr></thead><tbody>
+				{{range .TopImportedItems}}<tr data-tag="{{.Tag}}" style="{{tagStyle .Tag}}"{{if generated .ModuleName}} class="generated"{{end}}><td class="item-name">{{.Name}}{{with .Provenance}}{{badge .}}{{end}}{{copyAs .ModuleName .Name}}{{notes .ModuleName .Name}}</td><td class="module-name">{{.ModuleName}}{{if .Tag}}<span class="tag">{{.Tag}}</span>{{end}}{{if generated .ModuleName}}<span class="generated-badge">generated</span>{{end}}</td><td class="dep-count">{{.CountStr}}</td></tr>{{else}}<tr><td colspan="3">No items found.</td></tr>{{end}}
+				</tbody></table></div>
+			</section>{{end}}
+			{{if show "cycles"}}<section class="analysis-section" id="cycles">
+				<h2>{{icon "cycles"}} Circular Dependencies <span class="scope">groups of modules that depend on each other, directly or transitively</span>{{sectionBadge "cycles"}}</h2>
+				<div class="table-container"><table><thead><tr><th>Cycle</th><th>Edges of the Cycle & Files Creating Them</th></tr></thead><tbody>
+				{{range .Cycles}}<tr><td class="module-name">{{range $i, $m := .Chain}}{{if $i}} → {{end}}{{$m}}{{end}}{{if .Others}}<div class="scope">also in this cycle group: {{join .Others}}</div>{{end}}</td>
+					<td class="used-by-files">{{range .Edges}}<div><span class="module-name">{{.From}} → {{.To}}</span>: {{join .Files}}</div>{{end}}</td></tr>{{else}}<tr><td colspan="2">No circular dependencies found. {{icon "done"}}</td></tr>{{end}}
+				</tbody></table></div>
+			</section>{{end}}
+            {{if show "modules"}}<section class="analysis-section" id="inbound-deps">
+                <h2>{{icon "modules"}} Inbound Module Dependencies{{sectionBadge "modules"}}</h2>
+				{{if .SeparateTests}}<div class="table-container"><table><thead><tr><th>Module</th><th style="text-align: center;">Production Importers</th><th style="text-align: center;">Test-Only Importers</th><th>Production Files</th><th>Test-Only Files</th></tr></thead><tbody>
+				{{range .AllModules}}<tr data-tag="{{.Tag}}" style="{{tagStyle .Tag}}"{{if generated .Name}} class="generated"{{end}}><td class="module-name">{{.Name}}{{if .Tag}}<span class="tag">{{.Tag}}</span>{{end}}{{if generated .Name}}<span class="generated-badge">generated</span>{{end}}{{copyAs .Name ""}}{{notes .Name ""}}</td><td class="dep-count">{{len .ProdDependents}}</td><td class="dep-count">{{len .TestDependents}}</td><td class="used-by-files">{{join .ProdDependents}}</td><td class="used-by-files test-files">{{join .TestDependents}}</td></tr>{{else}}<tr><td colspan="5">No module dependencies found.</td></tr>{{end}}
+				</tbody></table></div>{{else}}<div class="table-container"><table><thead><tr><th>Module</th><th style="text-align: center;">Used by # Files</th><th>Used By Files</th><th>Test-Only Importers</th></tr></thead><tbody>
+				{{range .AllModules}}<tr data-tag="{{.Tag}}" style="{{tagStyle .Tag}}"{{if generated .Name}} class="generated"{{end}}><td class="module-name">{{.Name}}{{if .Tag}}<span class="tag">{{.Tag}}</span>{{end}}{{if generated .Name}}<span class="generated-badge">generated</span>{{end}}{{copyAs .Name ""}}{{notes .Name ""}}</td><td class="dep-count">{{.CountStr}}</td><td class="used-by-files">{{join .Dependents}}</td><td class="used-by-files test-files">{{join .TestDependents}}</td></tr>{{else}}<tr><td colspan="4">No module dependencies found.</td></tr>{{end}}
+				</tbody></table></div>{{end}}
+            </section>{{end}}
+			{{if show "outbound"}}<section class="analysis-section" id="outbound">
+				<h2>{{icon "outbound"}} Outbound Imports per File <span class="scope">what each file pulls in from the crate</span>{{sectionBadge "outbound"}}</h2>
+				<div class="table-container"><table><thead><tr><th style="width: 100%;">File & (Click to expand)</th><th style="text-align: center;">Modules</th><th style="text-align: center;">Items</th></tr></thead><tbody>
+				{{range .Outbound}}<tr data-tag="{{.Tag}}" style="{{tagStyle .Tag}}">
+					<td><details><summary><span class="item-name">{{.File}}{{if .Tag}}<span class="tag">{{.Tag}}</span>{{end}}</span></summary>
+						<div class="details-content"><ul>{{range .Imports}}<li><span class="module-name">{{.Module}}</span>{{if .Tag}}<span class="tag" style="{{tagStyle .Tag}}">{{.Tag}}</span>{{end}}{{if .Items}}: {{join .Items}}{{else}} (module only){{end}}</li>{{end}}</ul></div>
+					</details></td>
+					<td class="dep-count">{{len .Imports}}</td><td class="dep-count">{{.Items}}</td>
+				</tr>{{else}}<tr><td colspan="3">No imports found.</td></tr>{{end}}
+				</tbody></table></div>
+			</section>{{end}}
+			{{if show "graph"}}<section class="analysis-section" id="graph">
+				<h2>{{icon "graph"}} Module Graph <span class="scope">edge thickness = distinct items imported</span>{{sectionBadge "graph"}}</h2>
+				<div class="graph-controls">
+					<label>Min edge weight <input type="range" id="min-weight" min="1" max="1" value="1"> <span id="min-weight-value">1</span></label>
+					<label>Min fan-in <input type="range" id="min-fanin" min="0" max="0" value="0"> <span id="min-fanin-value">0</span></label>
+					<label>Focus hops <input type="number" id="focus-hops" min="1" max="10" value="1"></label>
+					<button id="clear-focus" disabled>Clear focus</button> <em id="focus-label">Click a module to focus</em>
+					<span class="graph-export">Export visible: <button data-export="dot">DOT</button> <button data-export="svg">SVG</button> <button data-export="json">JSON</button></span>
+					<span class="graph-export">Layout: <button id="download-layout">Download</button> <button id="reset-layout">Reset</button></span>
+				</div>
+				<svg id="graph-svg" viewBox="0 0 1000 600" preserveAspectRatio="xMidYMid meet">
+					<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z" fill="#565f89"/></marker></defs>
+				</svg>
+				<div id="edge-items" class="scope">Click an edge to list the items flowing along it.</div>
+			</section>{{end}}
+			{{if show "treemap"}}<section class="analysis-section" id="treemap">
+				<h2>{{icon "treemap"}} Treemap <span class="scope">area: files depending on the module · color: instability, green stable to red unstable</span>{{sectionBadge "treemap"}}</h2>
+				<div class="treemap">{{if .Treemap}}<svg viewBox="0 0 1000 560" role="img" aria-label="Modules sized by inbound dependents">
+					{{range .Treemap}}<a href="#module-{{.Module}}" data-tag="{{.Tag}}"><g class="treemap-cell{{if generated .Module}} generated-node{{end}}"><title>{{.Module}}: {{.Dependents}} dependent file{{if ne .Dependents 1}}s{{end}}, instability {{printf "%.2f" .Instability}}</title><rect x="{{printf "%.1f" .X}}" y="{{printf "%.1f" .Y}}" width="{{printf "%.1f" .W}}" height="{{printf "%.1f" .H}}" fill="{{.Color}}"></rect>{{if .Label}}<text x="{{printf "%.1f" .X}}" y="{{printf "%.1f" .Y}}" dx="6" dy="17">{{.Module}} · {{.Dependents}}</text>{{end}}</g></a>{{end}}
+				</svg>{{else}}<p>No module has dependents.</p>{{end}}</div>
+			</section>{{end}}
+			{{if show "inferred-layers"}}<section class="analysis-section" id="inferred-layers">
+				<h2>{{icon "inferred-layers"}} Inferred Layers <span class="scope">production imports, each module one layer above the highest it imports (⟲ marks cycles); a starting point for declared layers</span>{{sectionBadge "inferred-layers"}}</h2>
+				{{with .InferredLayers}}<div class="layer-stack">
+				{{$cyclic := .Cyclic}}{{range .Top}}<div class="layer-row"><span class="layer-label">{{if .Level}}layer {{.Level}}{{else}}foundation{{end}}</span>{{range .Modules}}<span class="layer-module{{if index $cyclic .}} layer-cyclic{{end}}" data-tag="{{tagOf .}}" style="{{tagStyle (tagOf .)}}"{{if index $cyclic .}} title="in a cycle: placed as one unit with the rest of its cycle"{{end}}>{{.}}{{if index $cyclic .}} ⟲{{end}}</span>{{end}}</div>{{else}}<p>No module-to-module edges found.</p>{{end}}
+				{{if .Independent}}<div class="layer-row layer-apart"><span class="layer-label">no edges</span>{{range .Independent}}<span class="layer-module" data-tag="{{tagOf .}}" style="{{tagStyle (tagOf .)}}">{{.}}</span>{{end}}</div>{{end}}
+				</div>{{end}}
+			</section>{{end}}
+			{{if show "metrics"}}<section class="analysis-section" id="metrics">
+				<h2>{{icon "metrics"}} Coupling Metrics <span class="scope">{{if eq .MetricsScope "prod"}}production edges only{{else}}all edges, including tests{{end}}</span>{{sectionBadge "metrics"}}</h2>
+				<div class="table-container"><table><thead><tr><th>Module</th><th style="text-align: center;">Fan-in (Ca)</th><th style="text-align: center;">Fan-out (Ce)</th><th style="text-align: center;">Instability</th><th style="text-align: center;">LOC</th><th style="text-align: center;">Imports / 100 LOC</th><th style="text-align: center;">Dependents / 1k LOC</th></tr></thead><tbody>
+				{{range .Metrics}}<tr data-tag="{{.Tag}}" style="{{tagStyle .Tag}}"{{if generated .Name}} class="generated"{{end}}><td class="module-name">{{.Name}}{{if .Tag}}<span class="tag">{{.Tag}}</span>{{end}}{{if generated .Name}}<span class="generated-badge">generated</span>{{end}}{{copyAs .Name ""}}{{notes .Name ""}}</td><td class="dep-count">{{.FanIn}}</td><td class="dep-count">{{.FanOut}}</td><td class="dep-count">{{printf "%.2f" .Instability}}</td><td class="dep-count">{{.LOC}}</td><td class="dep-count">{{printf "%.1f" .ImportsPer100LOC}}</td><td class="dep-count">{{printf "%.1f" .DependentsPerKLOC}}</td></tr>{{else}}<tr><td colspan="7">No module-to-module edges found.</td></tr>{{end}}
+				</tbody></table></div>
+			</section>{{end}}
+			{{if show "god-modules"}}<section class="analysis-section" id="god-modules">
+				<h2>{{icon "god-modules"}} God Modules <span class="scope">imported by {{.GodModuleLimits.MinDependents}}+ files for {{.GodModuleLimits.MinItems}}+ distinct items; splits suggested by items always imported together</span>{{sectionBadge "god-modules"}}</h2>
+				<div class="table-container"><table><thead><tr><th>Module</th><th style="text-align: center;">Dependents</th><th style="text-align: center;">Items</th><th>Candidate Splits</th></tr></thead><tbody>
+				{{range .GodModules}}<tr data-tag="{{.Tag}}" style="{{tagStyle .Tag}}"><td class="module-name">{{.Name}}{{if .Tag}}<span class="tag">{{.Tag}}</span>{{end}}{{copyAs .Name ""}}</td><td class="dep-count">{{.Dependents}}</td><td class="dep-count">{{.Items}}</td><td class="used-by-files">{{range .Clusters}}<div><span class="scope">{{len .Items}} items, {{.Importers}} importer{{if ne .Importers 1}}s{{end}}:</span> {{join .Items}}</div>{{else}}—{{end}}{{if and .Clusters .Scattered}}<div><span class="scope">{{.Scattered}} more imported in no shared pattern</span></div>{{end}}</td></tr>{{else}}<tr><td colspan="4">No module is past the thresholds. {{icon "done"}}</td></tr>{{end}}
+				</tbody></table></div>
+			</section>{{end}}
+			{{if show "closure"}}<section class="analysis-section" id="closure">
+				<h2>{{icon "closure"}} Transitive Closure <span class="scope">{{if eq .MetricsScope "prod"}}production edges only{{else}}all edges, including tests{{end}}</span>{{sectionBadge "closure"}}</h2>
+				<div class="table-container"><table><thead><tr><th>Module</th><th style="text-align: center;">Reaches</th><th style="text-align: center;">Depth</th><th>Reached Modules, by Hops</th><th style="text-align: center;">Affected by a Change</th><th style="text-align: center;">Propagation Depth</th></tr></thead><tbody>
+				{{range .Closures}}<tr data-tag="{{.Tag}}" style="{{tagStyle .Tag}}"{{if generated .Name}} class="generated"{{end}}><td class="module-name">{{.Name}}{{if .Tag}}<span class="tag">{{.Tag}}</span>{{end}}{{if generated .Name}}<span class="generated-badge">generated</span>{{end}}{{copyAs .Name ""}}</td><td class="dep-count">{{.Reached}}</td><td class="dep-count">{{.Depth}}</td><td class="used-by-files">{{range .Reaches}}<div><span class="scope">{{.Hops}} hop{{if ne .Hops 1}}s{{end}}:</span> {{join .Modules}}</div>{{else}}—{{end}}</td><td class="dep-count">{{.Impacted}}</td><td class="dep-count">{{.ImpactDepth}}</td></tr>{{else}}<tr><td colspan="6">No module-to-module edges found.</td></tr>{{end}}
+				</tbody></table></div>
+			</section>{{end}}
+			{{if show "interfaces"}}<section class="analysis-section" id="interfaces">
+				<h2>{{icon "interfaces"}} Interface vs Implementation <span class="scope">public items used across the module boundary vs only by its own submodules</span>{{sectionBadge "interfaces"}}</h2>
+				<div class="table-container"><table><thead><tr><th>Module</th><th style="text-align: center;">Public</th><th>External Interface</th><th>Internal Only</th><th>Unused</th></tr></thead><tbody>
+				{{range .Interfaces}}<tr data-tag="{{.Tag}}" style="{{tagStyle .Tag}}"{{if generated .Name}} class="generated"{{end}}><td class="module-name">{{.Name}}{{if .Tag}}<span class="tag">{{.Tag}}</span>{{end}}{{if generated .Name}}<span class="generated-badge">generated</span>{{end}}</td><td class="dep-count">{{.Public}}</td><td class="used-by-files">{{if .External}}{{join .External}}{{else}}—{{end}}</td><td class="used-by-files">{{if .InternalOnly}}{{join .InternalOnly}}{{else}}—{{end}}</td><td class="used-by-files">{{if .Unused}}{{join .Unused}}{{else}}—{{end}}</td></tr>{{else}}<tr><td colspan="5">No public items found.</td></tr>{{end}}
+				</tbody></table></div>
+			</section>{{end}}
+			{{if .UseStyles}}<section class="analysis-section" id="use-style">
+				<h2>{{icon "use-style"}} Use Statement Style <span class="scope">how use statements are written: one path each, or grouped as use a::{b, c}</span>{{sectionBadge "use-style"}}</h2>
+				<div class="table-container"><table><thead><tr><th>Module</th><th style="text-align: center;">Statements</th><th style="text-align: center;">Single Path</th><th style="text-align: center;">Grouped</th><th style="text-align: center;">Avg. Group Size</th><th style="text-align: center;">Aliased (as)</th><th style="text-align: center;">Globs (*)</th></tr></thead><tbody>
+				{{with .UseStyleTotal}}<tr class="use-style-total"><td>Whole crate</td><td class="dep-count">{{.Statements}}</td><td class="dep-count">{{.Single}}</td><td class="dep-count">{{.Grouped}}</td><td class="dep-count">{{printf "%.1f" .AverageGroupSize}}</td><td class="dep-count">{{.Aliased}}</td><td class="dep-count">{{.Globs}}</td></tr>{{end}}
+				{{range .UseStyles}}<tr data-tag="{{.Tag}}" style="{{tagStyle .Tag}}"{{if generated .Module}} class="generated"{{end}}><td class="module-name">{{.Module}}{{if .Tag}}<span class="tag">{{.Tag}}</span>{{end}}{{if generated .Module}}<span class="generated-badge">generated</span>{{end}}</td><td class="dep-count">{{.Statements}}</td><td class="dep-count">{{.Single}}</td><td class="dep-count">{{.Grouped}}</td><td class="dep-count">{{if .Grouped}}{{printf "%.1f" .AverageGroupSize}}{{else}}—{{end}}</td><td class="dep-count">{{.Aliased}}</td><td class="dep-count">{{.Globs}}</td></tr>{{end}}
+				</tbody></table></div>
+			</section>{{end}}
+			{{if show "conditional"}}<section class="analysis-section" id="conditional">
+				<h2>{{icon "conditional"}} Conditional Imports <span class="scope">files importing each module unconditionally vs only behind #[cfg]</span>{{sectionBadge "conditional"}}</h2>
+				<div class="table-container"><table><thead><tr><th>Module</th><th style="text-align: center;">Unconditional</th><th style="text-align: center;">Gated</th><th>Per-Configuration Breakdown</th></tr></thead><tbody>
+				{{range .Conditional}}<tr data-tag="{{.Tag}}" style="{{tagStyle .Tag}}"{{if generated .Name}} class="generated"{{end}}><td class="module-name">{{.Name}}{{if .Tag}}<span class="tag">{{.Tag}}</span>{{end}}{{if generated .Name}}<span class="generated-badge">generated</span>{{end}}</td><td class="dep-count">{{.Unconditional}}</td><td class="dep-count">{{.Gated}}</td><td class="used-by-files">{{range .Breakdown}}<div><span class="cfg">cfg({{.Predicate}})</span>: {{len .Files}} ({{join .Files}})</div>{{else}}—{{end}}</td></tr>{{else}}<tr><td colspan="4">No module imports found.</td></tr>{{end}}
+				</tbody></table></div>
+			</section>{{end}}
+			{{if show "unsafe"}}<section class="analysis-section" id="unsafe">
+				<h2>{{icon "unsafe"}} Unsafe Hotspots <span class="scope">score = unsafe sites × fan-in</span>{{sectionBadge "unsafe"}}</h2>
+				<div class="table-container"><table><thead><tr><th>Module</th><th style="text-align: center;">Unsafe Blocks</th><th style="text-align: center;">Unsafe Fns</th><th style="text-align: center;">Fan-in</th><th style="text-align: center;">Score</th></tr></thead><tbody>
+				{{range .UnsafeHotspots}}<tr data-tag="{{.Tag}}" style="{{tagStyle .Tag}}"{{if generated .Name}} class="generated"{{end}}><td class="module-name">{{.Name}}{{if .Tag}}<span class="tag">{{.Tag}}</span>{{end}}{{if generated .Name}}<span class="generated-badge">generated</span>{{end}}</td><td class="dep-count">{{.Blocks}}</td><td class="dep-count">{{.Fns}}</td><td class="dep-count">{{.FanIn}}</td><td class="dep-count">{{.Score}}</td></tr>{{else}}<tr><td colspan="5">No unsafe code found.</td></tr>{{end}}
+				</tbody></table></div>
+			</section>{{end}}
+			{{if show "external-crates"}}<section class="analysis-section" id="external-crates">
+				<h2>{{icon "external-crates"}} External Crates <span class="scope">versions from Cargo.lock, usage from use statements</span>{{sectionBadge "external-crates"}}</h2>
+				<div class="table-container"><table><thead><tr><th style="width: 100%;">Crate & (Click to expand)</th><th>Version</th><th>Source</th><th style="text-align: center;">Importing Files</th></tr></thead><tbody>
+				{{range .ExternalCrates}}<tr>
+					<td><details><summary><span class="item-name">{{.Name}}{{if not .Declared}} <span class="scope">(not in Cargo.toml)</span>{{end}}</span><span class="dep-count">{{len .Items}} items</span></summary>
+						<div class="details-content">{{if .Items}}<ul>{{range .Items}}<li><span class="item-name">{{.Name}}</span> — {{.CountStr}} ({{join .Files}})</li>{{end}}</ul>{{else}}Declared but never imported with a use statement.{{end}}</div>
+					</details></td>
+					<td class="module-name">{{if .Versions}}{{join .Versions}}{{else}}—{{end}}</td><td>{{.Source}}</td><td class="dep-count">{{len .Files}}</td>
+				</tr>{{else}}<tr><td colspan="4">No external crates found.</td></tr>{{end}}
+				</tbody></table></div>
+			</section>{{end}}
+			{{if .Strict}}<section class="analysis-section" id="boundaries">
+				<h2>{{icon "boundaries"}} Boundary Leaks <span class="scope">pub(crate) items imported from outside the module that defines them</span>{{sectionBadge "boundaries"}}</h2>
+				<div class="table-container"><table><thead><tr><th>Item</th><th>Visibility</th><th>Imported By</th><th>Files</th></tr></thead><tbody>
+				{{range .BoundaryLeaks}}<tr data-tag="{{tagOf .Module}}" style="{{tagStyle (tagOf .Module)}}"><td class="module-name">{{.Module}}::<span class="item-name">{{.Item}}</span></td><td>{{.Visibility}}</td><td class="module-name">{{join .Importers}}</td><td class="used-by-files">{{join .Files}}</td></tr>{{else}}<tr><td colspan="4">No crate-visible items leak across module boundaries. {{icon "done"}}</td></tr>{{end}}
+				</tbody></table></div>
+			</section>{{end}}
+			{{if show "coupling"}}<section class="analysis-section" id="coupling">
+				<h2>{{icon "coupling"}} Hidden Change Coupling <span class="scope">modules that change in the same commits without importing each other{{if .CouplingCommits}}, last {{.CouplingCommits}} commits{{end}}</span>{{sectionBadge "coupling"}}</h2>
+				<div class="table-container"><table><thead><tr><th>Modules</th><th style="text-align: center;">Shared Commits</th><th style="text-align: center;">Commits Each</th><th style="text-align: center;">Confidence</th></tr></thead><tbody>
+				{{range .Coupling}}<tr><td class="module-name">{{.A}}{{with tagOf .A}}<span class="tag" style="{{tagStyle .}}">{{.}}</span>{{end}} ↔ {{.B}}{{with tagOf .B}}<span class="tag" style="{{tagStyle .}}">{{.}}</span>{{end}}</td><td class="dep-count">{{.Shared}}</td><td class="dep-count">{{.ACommits}} / {{.BCommits}}</td><td class="dep-count">{{.Confidence}}%</td></tr>{{else}}<tr><td colspan="4">{{if .CouplingCommits}}No hidden coupling found. {{icon "done"}}{{else}}No git history available for this directory.{{end}}</td></tr>{{end}}
+				</tbody></table></div>
+			</section>{{end}}
+			{{if .EdgeHistory}}<section class="analysis-section" id="edge-history">
+				<h2>{{icon "edge-history"}} Edge History <span class="scope">when each module edge first appeared and a history record last verified it</span>{{sectionBadge "edge-history"}}</h2>
+				<div class="edge-age-filter"><label>Added in the last <select id="edge-age"><option value="">any time</option><option value="7">7 days</option><option value="30">30 days</option><option value="90">90 days</option><option value="365">year</option></select></label></div>
+				<div class="table-container"><table><thead><tr><th>Edge</th><th style="text-align: center;">First Seen</th><th style="text-align: center;">Last Verified</th></tr></thead><tbody>
+				{{range .EdgeAges}}<tr data-tag="{{.Tag}}" style="{{tagStyle .Tag}}" data-first-seen="{{if .Recorded}}{{.FirstSeen.Unix}}{{end}}"><td class="module-name">{{.From}}{{if .Tag}}<span class="tag">{{.Tag}}</span>{{end}} → {{.To}}{{with tagOf .To}}<span class="tag" style="{{tagStyle .}}">{{.}}</span>{{end}}</td>{{if .Recorded}}<td class="dep-count">{{.FirstSeen.Format "2006-01-02"}}</td><td class="dep-count">{{.LastVerified.Format "2006-01-02"}}</td>{{else}}<td class="dep-count" colspan="2">new: not in the history yet</td>{{end}}</tr>{{else}}<tr><td colspan="3">No module-to-module edges found.</td></tr>{{end}}
+				</tbody></table></div>
+			</section>{{end}}
+			{{if show "mod-tree"}}{{with .ModTree}}<section class="analysis-section" id="mod-tree">
+				<h2>{{icon "mod-tree"}} Module Tree Consistency <span class="scope">{{len .Paths}} files reachable from mod declarations</span>{{sectionBadge "mod-tree"}}</h2>
+				<div class="table-container"><table><thead><tr><th>Problem</th><th>Where</th><th>Detail</th></tr></thead><tbody>
+				{{range .Dead}}<tr><td>Dead file</td><td class="module-name">{{.}}</td><td>not reachable from any mod declaration, so it is never compiled</td></tr>{{end}}
+				{{range .Missing}}<tr><td>Missing file</td><td class="module-name">{{.File}}:{{.Line}}</td><td><code>mod {{.Name}};</code> has no {{.Name}}.rs or {{.Name}}/mod.rs</td></tr>{{end}}
+				{{if and (not .Dead) (not .Missing)}}<tr><td colspan="3">Every .rs file is declared and every declaration has a file.</td></tr>{{end}}
+				</tbody></table></div>
+			</section>{{end}}{{end}}
+			{{if show "per-module"}}<section class="analysis-section" id="per-module-analysis">
+				<h2 style="border-bottom: none;">{{icon "per-module"}} Per-Module Item Frequency{{sectionBadge "per-module"}}</h2>
+				{{if not .PerModuleItemImports}}<div style="padding: 1.5rem;">No specific item imports found.</div>{{else}}
+                    {{range $module, $items := .PerModuleItemImports}}{{$tag := tagOf $module}}
+                    <div data-tag="{{$tag}}" style="{{tagStyle $tag}}">
+                    <h3 class="module-header" id="module-{{$module}}">Module: {{$module}}{{if $tag}}<span class="tag">{{$tag}}</span>{{end}}{{if generated $module}}<span class="generated-badge">generated</span>{{end}}{{notes $module ""}}</h3>
+					<div class="table-container"><table><thead><tr><th style="width: 100%;">Item & (Click to expand)</th><th style="text-align: center;">Import Count</th></tr></thead><tbody>
+					{{range $items}}
+					<tr><td colspan="2" style="padding: 0.5rem 1rem;">
+						<details id="item-{{$module}}-{{.Name}}">
+							<summary><span class="item-name">{{.Name}}{{with .Provenance}}{{badge .}}{{end}}{{copyAs $module .Name}}{{notes $module .Name}}</span><span class="dep-count">{{.CountStr}}</span></summary>
+							<div class="details-content"><strong>Imported in:</strong><ul>{{range .Sites}}<li>{{if $.SourceLinks}}<a class="source-link" href="/source/{{.File}}{{if .Line}}#L{{.Line}}{{end}}">{{.File}}{{if .Line}}:{{.Line}}{{end}}</a>{{else}}{{.File}}{{if .Line}}:{{.Line}}{{end}}{{end}}{{with .Statement}}<code class="import-line">{{.}}</code>{{end}}</li>{{else}}{{range .Files}}<li>{{.}}</li>{{end}}{{end}}</ul></div>
+						</details>
+					</td></tr>
+					{{end}}
+					</tbody></table></div>
+                    </div>
+                    {{end}}
+                {{end}}
+			</section>{{end}}
+        </main>
+    </div>
+	<div id="palette" class="palette" hidden>
+		<div class="palette-box">
+			<input id="palette-input" type="text" placeholder="Jump to a module, item or section…" autocomplete="off">
+			<ul id="palette-results"></ul>
+			<div class="palette-help"><kbd>Ctrl</kbd>+<kbd>K</kbd> palette · <kbd>g</kbd> graph · <kbd>t</kbd> tables · <kbd>[</kbd>/<kbd>]</kbd> prev/next section · <kbd>x</kbd> collapse section · <kbd>y</kbd> copy section link · <kbd>/</kbd> filter · <kbd>Esc</kbd> close</div>
+		</div>
+	</div>
+	<script>
+		var activeTag = '', focusId = '', graphNodes = [], graphEdges = [];
+		// Tell the local server the report rendered, so it can shut down; prefetchers never run this.
+		if (location.protocol === 'http:') fetch('/loaded', { method: 'POST', keepalive: true }).catch(function () {});
+		{{if .Live}}// Served with --watch: reload whenever the server has re-analyzed the tree; the browser restores the scroll position.
+		if (location.protocol === 'http:') new EventSource('/events').addEventListener('reload', function () { location.reload(); });{{end}}
+		// "Copy as" menus put a row's module or item on the clipboard; inside a summary they must not toggle it.
+		document.querySelectorAll('.copy-as > button').forEach(function (button) { button.addEventListener('click', function (evt) { evt.preventDefault(); button.focus(); }); });
+		document.querySelectorAll('.copy-menu button').forEach(function (button) {
+			button.addEventListener('click', function (evt) {
+				evt.preventDefault(); evt.stopPropagation();
+				navigator.clipboard.writeText(button.dataset.copy).then(function () {
+					button.classList.add('copied');
+					setTimeout(function () { button.classList.remove('copied'); }, 1200);
+				});
+			});
+		});
+		{{if .NotesAPI}}// Served by serve: a note added here is appended to dependant-notes.yaml, then the page reloads to show it (with
+		// --watch, the reload event does).
+		document.querySelectorAll('.note-add').forEach(function (button) {
+			button.addEventListener('click', function (evt) {
+				evt.preventDefault(); evt.stopPropagation();
+				var module = button.dataset.module || prompt('Module to annotate');
+				if (!module) return;
+				var item = button.dataset.module ? button.dataset.item : (prompt('Item in ' + module + ' (empty for the module itself)') || '');
+				var text = prompt('Note on ' + (item ? module + ' → ' + item : module));
+				if (!text) return;
+				fetch('/api/notes', { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify({ module: module, item: item, note: text }) })
+					.then(function (r) { if (!r.ok) return r.text().then(function (t) { throw new Error(t); }); {{if not .Live}}location.reload();{{end}} })
+					.catch(function (err) { alert('Could not save the note: ' + err.message); });
+			});
+		});{{end}}
+		{{if .EdgeHistory}}// Edges not yet in the history have no first-seen time and always count as recent.
+		document.getElementById('edge-age').addEventListener('change', function (evt) {
+			var days = +evt.target.value, cutoff = Date.now() / 1000 - days * 86400;
+			document.querySelectorAll('#edge-history tbody tr[data-first-seen]').forEach(function (row) { row.classList.toggle('age-hidden', days > 0 && row.dataset.firstSeen !== '' && +row.dataset.firstSeen < cutoff); });
+		});{{end}}
+		// The search box hides table rows, per-module blocks, module links and layer boxes whose text (module, item and
+		// file names) does not contain the query; a per-module block whose name matches keeps all its rows.
+		(function () {
+			var input = document.getElementById('report-search'), count = document.getElementById('report-search-count');
+			function text(el) {
+				if (el.searchText === undefined) {
+					var copy = el.cloneNode(true);
+					copy.querySelectorAll('.copy-as, .note-add').forEach(function (c) { c.remove(); });
+					el.searchText = copy.textContent.toLowerCase();
+				}
+				return el.searchText;
+			}
+			function apply() {
+				var query = input.value.trim().toLowerCase();
+				document.querySelectorAll('.search-hidden').forEach(function (el) { el.classList.remove('search-hidden'); });
+				if (!query) { count.textContent = ''; return; }
+				document.querySelectorAll('.analysis-section tbody tr, .nav-links a[href^="#module-"], .layer-module').forEach(function (el) { if (text(el).indexOf(query) < 0) el.classList.add('search-hidden'); });
+				document.querySelectorAll('#per-module-analysis > div').forEach(function (block) {
+					if (text(block.querySelector('h3')).indexOf(query) >= 0) block.querySelectorAll('tr.search-hidden').forEach(function (row) { row.classList.remove('search-hidden'); });
+					else if (!block.querySelector('tbody tr:not(.search-hidden)')) block.classList.add('search-hidden');
+				});
+				var shown = document.querySelectorAll('.analysis-section tbody tr:not(.search-hidden)').length;
+				count.textContent = shown + ' matching row' + (shown === 1 ? '' : 's');
+			}
+			input.addEventListener('input', apply);
+			input.addEventListener('keydown', function (evt) { if (evt.key === 'Escape') { input.value = ''; apply(); input.blur(); } });
+		})();
+		document.querySelectorAll('.tag-filter button').forEach(function (button) {
+			button.addEventListener('click', function () {
+				activeTag = button.dataset.filter;
+				document.querySelectorAll('.tag-filter button').forEach(function (b) { b.classList.toggle('active', b === button); });
+				document.querySelectorAll('[data-tag]').forEach(function (el) { el.style.display = (!activeTag || el.dataset.tag === activeTag) ? '' : 'none'; });
+				{{if show "graph"}}applyGraphFilters();{{end}}
+			});
+		});
+		{{if show "graph"}}
+		// A node is shown when it matches the tag filter and reaches the fan-in threshold; an edge when it
+		// reaches the weight threshold and both of its endpoints are shown.
+		function applyGraphFilters() {
+			var minWeight = +document.getElementById('min-weight').value, minFanIn = +document.getElementById('min-fanin').value, visible = {};
+			document.getElementById('min-weight-value').textContent = minWeight;
+			document.getElementById('min-fanin-value').textContent = minFanIn;
+			graphNodes.forEach(function (n) { visible[n.id] = (!activeTag || n.tag === activeTag) && n.fanIn >= minFanIn; n.el.style.display = visible[n.id] ? '' : 'none'; });
+			graphEdges.forEach(function (e) { e.shown = e.items >= minWeight && visible[e.from] && visible[e.to]; e.el.style.display = e.shown ? '' : 'none'; });
+			applyFocus();
+		}
+
+		// Focus mode fades everything further than k hops (in either direction) from the clicked module.
+		function applyFocus() {
+			var hops = {}, k = Math.max(1, +document.getElementById('focus-hops').value || 1);
+			if (focusId) {
+				hops[focusId] = 0;
+				for (var frontier = [focusId], d = 1; d <= k && frontier.length; d++) {
+					var next = [];
+					graphEdges.forEach(function (e) {
+						if (!e.shown) return;
+						[[e.from, e.to], [e.to, e.from]].forEach(function (p) { if (frontier.indexOf(p[0]) >= 0 && !(p[1] in hops)) { hops[p[1]] = d; next.push(p[1]); } });
+					});
+					frontier = next;
+				}
+			}
+			graphNodes.forEach(function (n) { n.el.classList.toggle('faded', !!focusId && !(n.id in hops)); n.el.classList.toggle('focused', n.id === focusId); });
+			graphEdges.forEach(function (e) { e.el.classList.toggle('faded', !!focusId && !(e.from in hops && e.to in hops)); });
+			document.getElementById('focus-label').textContent = focusId ? 'Focused on ' + focusId : 'Click a module to focus';
+			document.getElementById('clear-focus').disabled = !focusId;
+		}
+		document.getElementById('focus-hops').addEventListener('input', applyFocus);
+
+		// Clicking an edge lists the concrete items it carries, each linking to its entry in the per-module tables.
+		function showEdgeItems(edge) {
+			var box = document.getElementById('edge-items');
+			box.textContent = edge.from + ' → ' + edge.to + ': ';
+			edge.itemNames.forEach(function (name, i) {
+				if (i) box.appendChild(document.createTextNode(', '));
+				var link = document.createElement('a');
+				link.href = '#item-' + edge.to + '-' + name; link.textContent = name; link.className = 'item-name';
+				box.appendChild(link);
+			});
+		}
+
+		// Exports contain exactly what is on screen: nodes and edges that are neither filtered out nor faded by focus mode.
+		function visibleSubgraph() {
+			var shown = function (el) { return el.style.display !== 'none' && !el.classList.contains('faded'); };
+			return {
+				nodes: graphNodes.filter(function (n) { return shown(n.el); }).map(function (n) { return { id: n.id, tag: n.tag, fanIn: n.fanIn, x: n.x, y: n.y }; }),
+				edges: graphEdges.filter(function (e) { return shown(e.el); }).map(function (e) { return { from: e.from, to: e.to, items: e.items, occurrences: e.occurrences, itemNames: e.itemNames }; })
+			};
+		}
+		function download(name, type, content) {
+			var a = document.createElement('a');
+			a.href = URL.createObjectURL(new Blob([content], { type: type })); a.download = name;
+			document.body.appendChild(a); a.click(); a.remove();
+		}
+		var exporters = {
+			json: function () { download('modules.json', 'application/json', JSON.stringify(visibleSubgraph(), null, 2)); },
+			dot: function () {
+				var g = visibleSubgraph(), q = function (s) { return '"' + s.replace(/"/g, '\\"') + '"'; }, lines = ['digraph modules {', '  node [shape=box, style=rounded];'];
+				g.nodes.forEach(function (n) { lines.push('  ' + q(n.id) + (n.tag ? ' [tooltip=' + q(n.tag) + ']' : '') + ';'); });
+				g.edges.forEach(function (e) { lines.push('  ' + q(e.from) + ' -> ' + q(e.to) + ' [label=' + e.items + ', penwidth=' + (1 + Math.log2(e.items)).toFixed(2) + ', tooltip=' + q(e.itemNames.join(', ')) + '];'); });
+				download('modules.dot', 'text/vnd.graphviz', lines.concat('}').join('\n') + '\n');
+			},
+			svg: function () {
+				var clone = document.getElementById('graph-svg').cloneNode(true), style = document.createElementNS('http://www.w3.org/2000/svg', 'style');
+				clone.querySelectorAll('g, line').forEach(function (el) { if (el.style.display === 'none' || el.classList.contains('faded')) el.remove(); });
+				style.textContent = 'svg { background: #1a1b26; } line { stroke: #565f89; stroke-opacity: 0.7; } text { fill: #c0caf5; font-family: monospace; font-size: 12px; } circle { stroke: #1a1b26; stroke-width: 2; }';
+				clone.insertBefore(style, clone.firstChild);
+				clone.setAttribute('xmlns', 'http://www.w3.org/2000/svg');
+				download('modules.svg', 'image/svg+xml', new XMLSerializer().serializeToString(clone));
+			}
+		};
+		document.querySelectorAll('[data-export]').forEach(function (button) { button.addEventListener('click', function () { exporters[button.dataset.export](); }); });
+		document.getElementById('clear-focus').addEventListener('click', function () { focusId = ''; applyFocus(); });
+		document.querySelectorAll('.graph-controls input').forEach(function (input) { input.addEventListener('input', applyGraphFilters); });
+
+		// Force-directed layout: nodes repel each other, edges pull harder the more items flow along them.
+		// Positions saved in this browser or embedded with --layout pin their nodes; only the rest are simulated.
+		var layoutKey = 'dependant-layout:' + {{.TargetDir}};
+		(function () {
+			var graph = {{.Graph}}, saved = {};
+			try { saved = JSON.parse(localStorage.getItem(layoutKey) || 'null') || graph.layout || {}; } catch (err) { saved = graph.layout || {}; }
+			var svg = document.getElementById('graph-svg'), ns = 'http://www.w3.org/2000/svg', W = 1000, H = 600;
+			var nodes = graph.nodes.map(function (n, i) {
+				var a = 2 * Math.PI * i / graph.nodes.length, p = saved[n.id];
+				return { id: n.id, tag: n.tag, color: n.color, fanIn: n.fanIn, generated: n.generated, r: 6 + 3 * Math.sqrt(n.fanIn), x: p ? p.x : W / 2 + 220 * Math.cos(a), y: p ? p.y : H / 2 + 220 * Math.sin(a), pinned: !!p };
+			});
+			var byId = {}; nodes.forEach(function (n) { byId[n.id] = n; });
+			var edges = (graph.edges || []).filter(function (e) { return byId[e.from] && byId[e.to]; });
+			for (var it = 0; it < 300; it++) {
+				var step = 0.5 * (1 - it / 300), forces = nodes.map(function () { return { x: 0, y: 0 }; });
+				for (var i = 0; i < nodes.length; i++) for (var j = i + 1; j < nodes.length; j++) {
+					var dx = nodes[j].x - nodes[i].x, dy = nodes[j].y - nodes[i].y, d2 = Math.max(dx * dx + dy * dy, 100), d = Math.sqrt(d2), f = 6000 / d2;
+					forces[i].x -= f * dx / d; forces[i].y -= f * dy / d; forces[j].x += f * dx / d; forces[j].y += f * dy / d;
+				}
+				edges.forEach(function (e) {
+					var a = byId[e.from], b = byId[e.to], dx = b.x - a.x, dy = b.y - a.y, d = Math.max(Math.sqrt(dx * dx + dy * dy), 1);
+					var f = (d - 140) * 0.02 * Math.log2(e.items + 1), ia = nodes.indexOf(a), ib = nodes.indexOf(b);
+					forces[ia].x += f * dx / d; forces[ia].y += f * dy / d; forces[ib].x -= f * dx / d; forces[ib].y -= f * dy / d;
+				});
+				nodes.forEach(function (n, i) {
+					if (n.pinned) return;
+					forces[i].x += (W / 2 - n.x) * 0.01; forces[i].y += (H / 2 - n.y) * 0.01;
+					n.x = Math.min(W - 40, Math.max(40, n.x + step * forces[i].x)); n.y = Math.min(H - 30, Math.max(30, n.y + step * forces[i].y));
+				});
+			}
+			function el(name, attrs, parent) { var e = document.createElementNS(ns, name); for (var k in attrs) e.setAttribute(k, attrs[k]); parent.appendChild(e); return e; }
+			function placeEdge(e) {
+				var a = byId[e.from], b = byId[e.to], dx = b.x - a.x, dy = b.y - a.y, d = Math.max(Math.sqrt(dx * dx + dy * dy), 1);
+				e.el.setAttribute('x1', a.x + dx / d * a.r); e.el.setAttribute('y1', a.y + dy / d * a.r);
+				e.el.setAttribute('x2', b.x - dx / d * b.r); e.el.setAttribute('y2', b.y - dy / d * b.r);
+			}
+			function placeNode(n) {
+				n.circle.setAttribute('cx', n.x); n.circle.setAttribute('cy', n.y);
+				n.label.setAttribute('x', n.x + n.r + 3); n.label.setAttribute('y', n.y + 4);
+			}
+			edges.forEach(function (e) {
+				var line = el('line', { 'stroke-width': 1 + Math.log2(e.items), 'marker-end': 'url(#arrow)' }, svg);
+				var names = e.itemNames || [];
+				el('title', {}, line).textContent = e.from + ' → ' + e.to + ': ' + e.items + ' items, ' + e.occurrences + ' imports\n' + names.join(', ');
+				var edge = { from: e.from, to: e.to, items: e.items, occurrences: e.occurrences, itemNames: names, el: line };
+				line.addEventListener('click', function () { showEdgeItems(edge); });
+				graphEdges.push(edge); placeEdge(edge);
+			});
+			nodes.forEach(function (n) {
+				var g = el('g', { id: 'node-' + n.id, 'class': n.generated ? 'generated-node' : '' }, svg);
+				n.el = g; graphNodes.push(n);
+				n.circle = el('circle', { r: n.r, fill: n.color }, g);
+				n.label = el('text', {}, g); n.label.textContent = n.id;
+				el('title', {}, g).textContent = n.id + (n.tag ? ' [' + n.tag + ']' : '') + (n.generated ? ' (generated)' : '');
+				placeNode(n);
+			});
+
+			// Dragging moves a node and saves the whole layout; a press without movement is a click that toggles focus.
+			var dragging = null, moved = false;
+			function svgPoint(evt) { var p = svg.createSVGPoint(); p.x = evt.clientX; p.y = evt.clientY; return p.matrixTransform(svg.getScreenCTM().inverse()); }
+			graphNodes.forEach(function (n) { n.el.addEventListener('mousedown', function (evt) { dragging = n; moved = false; evt.preventDefault(); }); });
+			svg.addEventListener('mousemove', function (evt) {
+				if (!dragging) return;
+				var p = svgPoint(evt);
+				moved = moved || Math.abs(p.x - dragging.x) + Math.abs(p.y - dragging.y) > 3;
+				if (!moved) return;
+				dragging.x = p.x; dragging.y = p.y; placeNode(dragging);
+				graphEdges.forEach(function (e) { if (e.from === dragging.id || e.to === dragging.id) placeEdge(e); });
+			});
+			window.addEventListener('mouseup', function () {
+				if (!dragging) return;
+				if (moved) { localStorage.setItem(layoutKey, JSON.stringify(currentLayout())); }
+				else { focusId = focusId === dragging.id ? '' : dragging.id; applyFocus(); }
+				dragging = null;
+			});
+			document.getElementById('min-weight').max = Math.max.apply(null, [1].concat(edges.map(function (e) { return e.items; })));
+			document.getElementById('min-fanin').max = Math.max.apply(null, [0].concat(nodes.map(function (n) { return n.fanIn; })));
+			applyGraphFilters();
+		})();
+
+		function currentLayout() {
+			var positions = {};
+			graphNodes.forEach(function (n) { positions[n.id] = { x: Math.round(n.x * 10) / 10, y: Math.round(n.y * 10) / 10 }; });
+			return positions;
+		}
+		document.getElementById('download-layout').addEventListener('click', function () { download('dependant-layout.json', 'application/json', JSON.stringify({ positions: currentLayout() }, null, 2)); });
+		document.getElementById('reset-layout').addEventListener('click', function () { localStorage.removeItem(layoutKey); location.reload(); });
+		{{end}}
+		// Sections collapse when their heading is clicked.
+		document.querySelectorAll('.analysis-section > h2').forEach(function (h) { h.addEventListener('click', function () { h.parentNode.classList.toggle('collapsed'); }); });
+
+		// Command palette (Ctrl+K) and single-key shortcuts.
+		(function () {
+			var palette = document.getElementById('palette'), input = document.getElementById('palette-input'), list = document.getElementById('palette-results'), selected = 0, matches = [];
+			var entries = [];
+			document.querySelectorAll('section.analysis-section').forEach(function (s) {
+				var label = s.querySelector('h2').firstChild;
+				while (label && (label.nodeType !== Node.TEXT_NODE || !label.textContent.trim())) label = label.nextSibling; // past an SVG icon
+				entries.push({ label: label ? label.textContent.trim() : s.id, kind: 'section', id: s.id });
+			});
+			document.querySelectorAll('h3.module-header').forEach(function (h) { entries.push({ label: h.id.replace(/^module-/, ''), kind: 'module', id: h.id }); });
+			document.querySelectorAll('details[id^="item-"]').forEach(function (d) {
+				var module = d.closest('[data-tag]').querySelector('h3').id.replace(/^module-/, '');
+				entries.push({ label: module + '::' + d.querySelector('.item-name').textContent, kind: 'item', id: d.id });
+			});
+			function jump(id) {
+				var target = document.getElementById(id);
+				if (!target) return;
+				var section = target.closest('.analysis-section'); if (section) section.classList.remove('collapsed');
+				if (target.tagName === 'DETAILS') target.open = true;
+				target.scrollIntoView({ behavior: 'smooth', block: 'start' });
+				history.replaceState(null, '', '#' + id);
+			}
+			function render() {
+				var q = input.value.toLowerCase();
+				matches = entries.filter(function (e) { return e.label.toLowerCase().indexOf(q) >= 0; }).slice(0, 50);
+				selected = Math.min(selected, Math.max(matches.length - 1, 0));
+				list.innerHTML = '';
+				matches.forEach(function (e, i) {
+					var li = document.createElement('li'), kind = document.createElement('span');
+					li.textContent = e.label; kind.textContent = e.kind; li.appendChild(kind);
+					li.classList.toggle('selected', i === selected);
+					li.addEventListener('click', function () { close(); jump(e.id); });
+					list.appendChild(li);
+				});
+			}
+			function open() { palette.hidden = false; input.value = ''; selected = 0; render(); input.focus(); }
+			function close() { palette.hidden = true; }
+			input.addEventListener('input', function () { selected = 0; render(); });
+			input.addEventListener('keydown', function (evt) {
+				if (evt.key === 'ArrowDown') { selected = Math.min(selected + 1, matches.length - 1); render(); evt.preventDefault(); }
+				else if (evt.key === 'ArrowUp') { selected = Math.max(selected - 1, 0); render(); evt.preventDefault(); }
+				else if (evt.key === 'Enter' && matches[selected]) { close(); jump(matches[selected].id); }
+			});
+			palette.addEventListener('click', function (evt) { if (evt.target === palette) close(); });
+
+			function currentSection() {
+				var sections = Array.prototype.slice.call(document.querySelectorAll('section.analysis-section')), current = sections[0];
+				sections.forEach(function (s) { if (s.getBoundingClientRect().top <= 80) current = s; });
+				return current;
+			}
+			document.addEventListener('keydown', function (evt) {
+				if ((evt.ctrlKey || evt.metaKey) && evt.key.toLowerCase() === 'k') { evt.preventDefault(); palette.hidden ? open() : close(); return; }
+				if (evt.key === 'Escape') { close(); return; }
+				if (!palette.hidden || evt.ctrlKey || evt.metaKey || evt.altKey || /INPUT|TEXTAREA|SELECT/.test(evt.target.tagName)) return;
+				var sections = Array.prototype.slice.call(document.querySelectorAll('section.analysis-section')), current = currentSection(), index = sections.indexOf(current);
+				switch (evt.key) {
+				case 'g': jump('graph'); break;
+				case 't': if (sections[0]) jump(sections[0].id); break;
+				case ']': if (sections[index + 1]) jump(sections[index + 1].id); break;
+				case '[': if (sections[index - 1]) jump(sections[index - 1].id); break;
+				case 'x': current.classList.toggle('collapsed'); break;
+				case 'y': navigator.clipboard.writeText(location.href.split('#')[0] + '#' + current.id); break;
+				case '/': document.getElementById('report-search').focus(); break;
+				default: return;
+				}
+				evt.preventDefault();
+			});
+		})();
+	</script>
+</body>
+</html>
+`
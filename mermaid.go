@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// writeMermaid writes the module graph as a Mermaid `graph TD` block, which GitHub, GitLab and most wikis render
+// inline. A .md path gets the block fenced, ready to paste into a README or pull request. With top > 0 only the top
+// modules by inbound count (importing modules, then imported items) are kept, with the edges between them.
+func writeMermaid(path string, a *analyzer.Report, top int) error {
+	edges := buildWeightedEdges(a, a.Graph.ItemImports)
+	nodes := make(map[string]struct{})
+	dependents, items := make(map[string]int), make(map[string]int)
+	for _, e := range edges {
+		nodes[e.From], nodes[e.To] = struct{}{}, struct{}{}
+		dependents[e.To]++
+		items[e.To] += e.Items
+	}
+	names := sortedKeys(nodes)
+	if top > 0 && len(names) > top {
+		sort.SliceStable(names, func(i, j int) bool {
+			if dependents[names[i]] != dependents[names[j]] { return dependents[names[i]] > dependents[names[j]] }
+			return items[names[i]] > items[names[j]]
+		})
+		names = names[:top]
+		sort.Strings(names)
+	}
+	ids := make(map[string]string)
+	for i, n := range names { ids[n] = fmt.Sprintf("m%d", i) }
+
+	var sb strings.Builder
+	fence := strings.HasSuffix(strings.ToLower(path), ".md")
+	if fence { sb.WriteString("```mermaid\n") }
+	sb.WriteString("graph TD\n")
+	for _, n := range names { fmt.Fprintf(&sb, "  %s[\"%s\"]\n", ids[n], strings.ReplaceAll(n, `"`, "#quot;")) }
+	for _, e := range edges {
+		from, ok := ids[e.From]
+		if !ok { continue }
+		to, ok := ids[e.To]
+		if !ok { continue }
+		fmt.Fprintf(&sb, "  %s -->|%d| %s\n", from, e.Items, to)
+	}
+	if fence { sb.WriteString("```\n") }
+	return os.WriteFile(path, []byte(sb.String()), 0o644)
+}
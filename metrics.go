@@ -0,0 +1,118 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// ModuleMetrics are the classic coupling metrics for one module:
+// afferent coupling (fan-in), efferent coupling (fan-out) and instability Ce / (Ca + Ce),
+// plus rates normalized by module size so small and large modules compare fairly.
+type ModuleMetrics struct {
+	Name, Tag         string
+	FanIn, FanOut     int
+	Instability       float64
+	LOC               int
+	Imports           int // file -> module imports made by the module's files
+	Dependents        int // files importing the module
+	ImportsPer100LOC  float64
+	DependentsPerKLOC float64
+}
+
+func computeModuleMetrics(a *analyzer.Report, fileDeps map[string]map[string]struct{}) []ModuleMetrics {
+	facts, moduleGraph := a.Facts, a.BuildModuleGraph(fileDeps)
+	imports, dependents := make(map[string]int), make(map[string]int)
+	for file, deps := range fileDeps {
+		from := a.ModuleName(file)
+		for to := range deps { if to != from && to != "" { imports[from]++; dependents[to]++ } }
+	}
+	fanIn, fanOut := make(map[string]int), make(map[string]int)
+	for from, tos := range moduleGraph {
+		fanOut[from] += len(tos)
+		for to := range tos { fanIn[to]++ }
+	}
+	modules := make(map[string]struct{})
+	for m := range fanIn { modules[m] = struct{}{} }
+	for m := range fanOut { modules[m] = struct{}{} }
+
+	var metrics []ModuleMetrics
+	for m := range modules {
+		mm := ModuleMetrics{Name: m, Tag: facts.Tags[m], FanIn: fanIn[m], FanOut: fanOut[m], LOC: facts.LOC[m], Imports: imports[m], Dependents: dependents[m]}
+		if total := mm.FanIn + mm.FanOut; total > 0 { mm.Instability = float64(mm.FanOut) / float64(total) }
+		if mm.LOC > 0 {
+			mm.ImportsPer100LOC = float64(mm.Imports) * 100 / float64(mm.LOC)
+			mm.DependentsPerKLOC = float64(mm.Dependents) * 1000 / float64(mm.LOC)
+		}
+		metrics = append(metrics, mm)
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		if metrics[i].FanIn != metrics[j].FanIn { return metrics[i].FanIn > metrics[j].FanIn }
+		return metrics[i].Name < metrics[j].Name
+	})
+	return metrics
+}
+
+// UnsafeHotspot correlates a module's unsafe code with how many modules depend on it.
+type UnsafeHotspot struct {
+	Name, Tag                 string
+	Blocks, Fns, FanIn, Score int
+}
+
+func computeUnsafeHotspots(facts *analyzer.ModuleFacts, metrics []ModuleMetrics) []UnsafeHotspot {
+	fanIn := make(map[string]int)
+	for _, m := range metrics { fanIn[m.Name] = m.FanIn }
+	modules := make(map[string]struct{})
+	for m, n := range facts.UnsafeBlocks { if n > 0 { modules[m] = struct{}{} } }
+	for m, n := range facts.UnsafeFns { if n > 0 { modules[m] = struct{}{} } }
+
+	var hotspots []UnsafeHotspot
+	for m := range modules {
+		h := UnsafeHotspot{Name: m, Tag: facts.Tags[m], Blocks: facts.UnsafeBlocks[m], Fns: facts.UnsafeFns[m], FanIn: fanIn[m]}
+		h.Score = (h.Blocks + h.Fns) * h.FanIn
+		hotspots = append(hotspots, h)
+	}
+	sort.Slice(hotspots, func(i, j int) bool {
+		if hotspots[i].Score != hotspots[j].Score { return hotspots[i].Score > hotspots[j].Score }
+		if a, b := hotspots[i].Blocks+hotspots[i].Fns, hotspots[j].Blocks+hotspots[j].Fns; a != b { return a > b }
+		return hotspots[i].Name < hotspots[j].Name
+	})
+	return hotspots
+}
+
+// ModuleEdge is a module -> module dependency weighted by what flows along it.
+type ModuleEdge struct {
+	From        string   `json:"from"`
+	To          string   `json:"to"`
+	Items       int      `json:"items"`               // distinct items imported
+	Occurrences int      `json:"occurrences"`         // (importing file, item) pairs
+	ItemNames   []string `json:"itemNames,omitempty"` // the distinct items, sorted
+}
+
+// buildWeightedEdges derives weighted module edges from item imports, naming importing files as a does.
+func buildWeightedEdges(a *analyzer.Report, itemImports map[string]map[string]map[string]struct{}) []ModuleEdge {
+	type pair struct{ from, to string }
+	items := make(map[pair]map[string]struct{})
+	occurrences := make(map[pair]int)
+	for to, byItem := range itemImports {
+		for item, files := range byItem {
+			for file := range files {
+				from := a.ModuleName(file)
+				if from == to || to == "" { continue }
+				p := pair{from, to}
+				if items[p] == nil { items[p] = make(map[string]struct{}) }
+				items[p][item] = struct{}{}
+				occurrences[p]++
+			}
+		}
+	}
+	var edges []ModuleEdge
+	for p, set := range items {
+		edges = append(edges, ModuleEdge{From: p.from, To: p.to, Items: len(set), Occurrences: occurrences[p], ItemNames: sortedKeys(set)})
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From { return edges[i].From < edges[j].From }
+		return edges[i].To < edges[j].To
+	})
+	return edges
+}
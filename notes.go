@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/WillKirkmanM/dependant/internal/yaml"
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+const notesFileName = "dependant-notes.yaml"
+
+// Note is a free-form annotation on a module, or on one of its items, that the report shows wherever the module or
+// item appears: "scheduled for removal", "owned by team X", "do not add new dependents". Notes live in
+// dependant-notes.yaml at the root of the analyzed tree, committed next to the code:
+//
+//	- module: legacy
+//	  note: scheduled for removal
+//	- module: net
+//	  item: Client
+//	  note: "do not add new dependents: use net::pool"
+//	  author: alice
+//
+// `dependant serve` also appends to it through POST /api/notes.
+type Note struct {
+	Module string `json:"module"`
+	Item   string `json:"item,omitempty"`
+	Text   string `json:"note"`
+	Author string `json:"author,omitempty"`
+}
+
+func (n Note) validate() error {
+	if strings.TrimSpace(n.Module) == "" { return errors.New("a note needs a module") }
+	if strings.TrimSpace(n.Text) == "" { return errors.New("a note needs text") }
+	return nil
+}
+
+// readNotes loads the tree's notes; a tree without a notes file has none.
+func readNotes(root string) ([]Note, error) {
+	content, err := os.ReadFile(filepath.Join(root, notesFileName))
+	if errors.Is(err, os.ErrNotExist) { return nil, nil }
+	if err != nil { return nil, err }
+	notes, err := parseNotes(string(content))
+	if err != nil { return nil, fmt.Errorf("%s: %w", notesFileName, err) }
+	return notes, nil
+}
+
+// parseNotes reads the YAML subset the notes file uses: a sequence of flat mappings whose values are plain, single- or
+// double-quoted scalars on one line, with # comments.
+func parseNotes(content string) ([]Note, error) {
+	var notes []Note
+	var current *Note
+	finish := func(line int) error {
+		if current == nil { return nil }
+		if err := current.validate(); err != nil { return fmt.Errorf("entry ending before line %d: %w", line, err) }
+		notes = append(notes, *current)
+		current = nil
+		return nil
+	}
+	lines := strings.Split(content, "\n")
+	for i, raw := range lines {
+		n := i + 1
+		line := strings.TrimRight(yaml.StripComment(raw), " \t\r")
+		if strings.TrimSpace(line) == "" { continue }
+		if rest, ok := strings.CutPrefix(line, "-"); ok && (rest == "" || rest[0] == ' ') {
+			if err := finish(n); err != nil { return nil, err }
+			current = &Note{}
+			if line = strings.TrimSpace(rest); line == "" { continue }
+		} else if line[0] != ' ' || current == nil {
+			return nil, fmt.Errorf("line %d: expected a \"- module: ...\" entry", n)
+		}
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok { return nil, fmt.Errorf("line %d: expected key: value", n) }
+		text, err := yaml.Scalar(strings.TrimSpace(value))
+		if err != nil { return nil, fmt.Errorf("line %d: %w", n, err) }
+		switch strings.TrimSpace(key) {
+		case "module": current.Module = text
+		case "item": current.Item = text
+		case "note": current.Text = text
+		case "author": current.Author = text
+		default: return nil, fmt.Errorf("line %d: unknown key %q: expected module, item, note or author", n, key)
+		}
+	}
+	if err := finish(len(lines)); err != nil { return nil, err }
+	return notes, nil
+}
+
+// quoteYAML writes text as a plain scalar when parseNotes reads it back unchanged, else double-quoted.
+func quoteYAML(text string) string {
+	plain := text != "" && text == strings.TrimSpace(text) && !strings.ContainsAny(text, "\"'\n\r\t\\") && !strings.Contains(text, " #") && !strings.Contains(text, ": ") && !strings.ContainsAny(text[:1], "-#&*!|>%@`[]{},?:")
+	if plain { return text }
+	return strconv.Quote(text)
+}
+
+// appendNote adds a note to the end of the tree's notes file, creating it when needed, leaving the rest of the file
+// (comments, order) as the team wrote it.
+func appendNote(root string, note Note) error {
+	if err := note.validate(); err != nil { return err }
+	path := filepath.Join(root, notesFileName)
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) { return err }
+	var entry strings.Builder
+	if len(content) == 0 { entry.WriteString("# Notes on modules and items, shown in the dependant report.\n") } else if content[len(content)-1] != '\n' { entry.WriteString("\n") }
+	entry.WriteString("- module: " + quoteYAML(note.Module) + "\n")
+	if note.Item != "" { entry.WriteString("  item: " + quoteYAML(note.Item) + "\n") }
+	entry.WriteString("  note: " + quoteYAML(note.Text) + "\n")
+	if note.Author != "" { entry.WriteString("  author: " + quoteYAML(note.Author) + "\n") }
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil { return err }
+	if _, err := file.WriteString(entry.String()); err != nil { file.Close(); return err }
+	return file.Close()
+}
+
+// NoteInfo is a note as the report's notes section lists it; Stale marks a note whose module or item the analysis no
+// longer finds, typically after a rename.
+type NoteInfo struct {
+	Note
+	Tag   string
+	Stale bool
+}
+
+func noteInfos(notes []Note, a *analyzer.Report) []NoteInfo {
+	known := make(map[string]bool)
+	for m := range a.SymbolTable { known[m] = true }
+	for m := range a.Facts.LOC { known[m] = true }
+	for _, deps := range a.Graph.Deps { for m := range deps { known[m] = true } }
+	var out []NoteInfo
+	for _, n := range notes {
+		_, item := a.SymbolTable[n.Module][n.Item]
+		if _, imported := a.Graph.ItemImports[n.Module][n.Item]; imported { item = true }
+		out = append(out, NoteInfo{Note: n, Tag: a.Facts.Tags[n.Module], Stale: !known[n.Module] || (n.Item != "" && !item)})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Module != out[j].Module { return out[i].Module < out[j].Module }
+		return out[i].Item < out[j].Item
+	})
+	return out
+}
+
+// notesHTML renders the notes on a module (item "") or item inline, plus an add button when the server takes notes.
+// icon is the report's (see iconFunc).
+func notesHTML(notes []Note, module, item string, editable bool, icon func(string) template.HTML) template.HTML {
+	var b strings.Builder
+	for _, n := range notes {
+		if n.Module != module || n.Item != item { continue }
+		b.WriteString(`<span class="note">` + string(icon("notes")) + ` ` + html.EscapeString(n.Text))
+		if n.Author != "" { b.WriteString(` <span class="note-author">— ` + html.EscapeString(n.Author) + `</span>`) }
+		b.WriteString(`</span>`)
+	}
+	if editable { b.WriteString(`<button type="button" class="note-add" title="Add a note" data-module="` + html.EscapeString(module) + `" data-item="` + html.EscapeString(item) + `">+` + string(icon("notes")) + `</button>`) }
+	return template.HTML(b.String())
+}
+
+// notesStamp changes whenever the notes file does, so a watched or daemon-served report picks up edited notes.
+func notesStamp(root string) string {
+	info, err := os.Stat(filepath.Join(root, notesFileName))
+	if err != nil { return "" }
+	return fmt.Sprintf("%d-%d", info.Size(), info.ModTime().UnixNano())
+}
+
+// notesAPI serves /api/notes for `dependant serve`: GET lists the tree's notes, POST appends one to the notes file.
+// render re-renders the report so the next page load shows the note; it is nil under --watch, whose poll of the notes
+// file re-renders it and reloads every open page.
+type notesAPI struct {
+	root   string
+	render func() (string, error)
+	mu     sync.Mutex // serializes appends
+}
+
+func (n *notesAPI) handler(live *liveReport) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !allowMethods(w, r, http.MethodGet, http.MethodPost) { return }
+		w.Header().Set("Cache-Control", "no-store")
+		if r.Method == http.MethodGet {
+			notes, err := readNotes(n.root)
+			if err != nil { http.Error(w, err.Error(), http.StatusInternalServerError); return }
+			if notes == nil { notes = []Note{} }
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(notes)
+			return
+		}
+		// A JSON body cannot come from a cross-site form without a preflight, which the server never answers.
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" { http.Error(w, "expected application/json", http.StatusUnsupportedMediaType); return }
+		var note Note
+		if err := json.NewDecoder(r.Body).Decode(&note); err != nil { http.Error(w, "bad note: "+err.Error(), http.StatusBadRequest); return }
+		if err := note.validate(); err != nil { http.Error(w, err.Error(), http.StatusBadRequest); return }
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		if err := appendNote(n.root, note); err != nil { http.Error(w, err.Error(), http.StatusInternalServerError); return }
+		if n.render != nil {
+			html, err := n.render()
+			if err != nil { http.Error(w, "note saved, but the report could not be re-rendered: "+err.Error(), http.StatusInternalServerError); return }
+			live.update(html)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(note)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseNotes(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Note
+		err     string // a substring of the error; empty when the file parses
+	}{
+		{name: "empty", content: "# no notes yet\n", want: nil},
+		{
+			name:    "entries",
+			content: "# Notes\n- module: net\n  note: owns the sockets\n  author: sam\n\n- module: cpu\n  item: Engine\n  note: hot path # keep it fast\n",
+			want:    []Note{{Module: "net", Text: "owns the sockets", Author: "sam"}, {Module: "cpu", Item: "Engine", Text: "hot path"}},
+		},
+		{
+			name:    "quoted scalars",
+			content: "- module: 'a::b'\n  note: \"uses # and: colons\\n\"\n- module: x\n  note: 'it''s fine # really'\n",
+			want:    []Note{{Module: "a::b", Text: "uses # and: colons\n"}, {Module: "x", Text: "it's fine # really"}},
+		},
+		{name: "bare dash", content: "-\n  module: net\n  note: n\n", want: []Note{{Module: "net", Text: "n"}}},
+		{name: "CRLF", content: "- module: net\r\n  note: n\r\n", want: []Note{{Module: "net", Text: "n"}}},
+		{name: "hash inside a word", content: "- module: net\n  note: issue#12\n", want: []Note{{Module: "net", Text: "issue#12"}}},
+		{name: "no entry", content: "module: net\n", err: `line 1: expected a "- module: ..." entry`},
+		{name: "unindented key", content: "- module: net\nnote: n\n", err: "line 2: expected a"},
+		{name: "no colon", content: "- module net\n", err: "line 1: expected key: value"},
+		{name: "unknown key", content: "- module: net\n  owner: sam\n", err: `line 2: unknown key "owner"`},
+		{name: "missing note", content: "- module: net\n- module: cpu\n  note: n\n", err: "entry ending before line 2: a note needs text"},
+		{name: "missing module", content: "- note: n\n", err: "a note needs a module"},
+		{name: "bad double quotes", content: "- module: net\n  note: \"open\n", err: "line 2: bad double-quoted string"},
+		{name: "unterminated single quotes", content: "- module: net\n  note: 'open\n", err: "line 2: unterminated single-quoted string"},
+	}
+	for _, tt := range tests {
+		got, err := parseNotes(tt.content)
+		switch {
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: parseNotes error = %v, want one containing %q", tt.name, err, tt.err)
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: parseNotes error = %v", tt.name, err)
+		case tt.err == "" && !reflect.DeepEqual(got, tt.want):
+			t.Errorf("%s: parseNotes = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestQuoteYAML checks that what appendNote writes parses back unchanged.
+func TestQuoteYAML(t *testing.T) {
+	for _, text := range []string{"plain", "a::b", "- dash", "x # y", "key: value", "'quoted'", `"double"`, "line\nbreak", " padded ", "tab\there", `back\slash`, "[list]", "100%"} {
+		notes, err := parseNotes("- module: m\n  note: " + quoteYAML(text) + "\n")
+		if err != nil || len(notes) != 1 || notes[0].Text != text { t.Errorf("quoteYAML(%q) = %s, which parses back as %+v (%v)", text, quoteYAML(text), notes, err) }
+	}
+}
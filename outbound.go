@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// FileOutbound is everything one file imports from the crate: the complement of the inbound module view.
+type FileOutbound struct {
+	File    string           `json:"file"` // relative to the analyzed root
+	Module  string           `json:"module"`
+	Tag     string           `json:"tag,omitempty"`
+	Imports []OutboundModule `json:"imports"`
+	Items   int              `json:"items"`
+}
+
+// OutboundModule lists the items a file takes from one module; Items is empty for bare module imports.
+type OutboundModule struct {
+	Module string   `json:"module"`
+	Tag    string   `json:"tag,omitempty"`
+	Items  []string `json:"items"`
+}
+
+// computeFileOutbound inverts the item imports to a per-file view, most coupled files first.
+func computeFileOutbound(a *analyzer.Report, graph *analyzer.DependencyGraph, tags map[string]string) []FileOutbound {
+	items := make(map[string]map[string][]string) // file -> module -> items
+	for module, byItem := range graph.ItemImports {
+		for item, files := range byItem {
+			for f := range files {
+				if items[f] == nil { items[f] = make(map[string][]string) }
+				items[f][module] = append(items[f][module], item)
+			}
+		}
+	}
+	var out []FileOutbound
+	for file, modules := range graph.Deps {
+		rel, err := filepath.Rel(a.Root, file)
+		if err != nil { rel = file }
+		fo := FileOutbound{File: filepath.ToSlash(rel), Module: a.ModuleName(file), Tag: tags[a.ModuleName(file)]}
+		for _, module := range sortedKeys(modules) {
+			names := uniqueSorted(items[file][module])
+			fo.Imports = append(fo.Imports, OutboundModule{Module: module, Tag: tags[module], Items: names})
+			fo.Items += len(names)
+		}
+		out = append(out, fo)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if len(out[i].Imports) != len(out[j].Imports) { return len(out[i].Imports) > len(out[j].Imports) }
+		if out[i].Items != out[j].Items { return out[i].Items > out[j].Items }
+		return out[i].File < out[j].File
+	})
+	return out
+}
+
+// writeFileOutbound exports the per-file view as CSV (one row per file and module) or, for any other extension, JSON.
+func writeFileOutbound(path string, files []FileOutbound) error {
+	f, err := os.Create(path)
+	if err != nil { return err }
+	defer f.Close()
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		w := csv.NewWriter(f)
+		w.Write([]string{"file", "module", "items"})
+		for _, fo := range files { for _, m := range fo.Imports { w.Write([]string{fo.File, m.Module, strings.Join(m.Items, " ")}) } }
+		w.Flush()
+		return w.Error()
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if files == nil { files = []FileOutbound{} }
+	return enc.Encode(files)
+}
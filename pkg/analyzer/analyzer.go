@@ -0,0 +1,507 @@
+// Package analyzer is the analysis behind the dependant command: it reads a Rust, Go, TypeScript/JavaScript or
+// Python tree and reports its modules, their public items and the files importing each, so other Go tools can embed
+// the analysis without shelling out:
+//
+//	report, err := analyzer.Analyze("path/to/crate", analyzer.Options{})
+//	if err != nil { return err }
+//	for from, deps := range report.Modules() { ... }
+//
+// Module names depend on the tree analyzed (its crate name, [naming] table and mod declarations), so each Report names
+// its own files (see Report.ModuleName) and analyses of different trees may run at once.
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+var (
+	UseTreeRegex   = regexp.MustCompile(`\buse\s+([^;]*);`)
+	pubDefRegex    = regexp.MustCompile(`pub\s+(?:struct|enum|fn|trait)\s+((?:r#)?\w+)`)
+	tagMarkerRegex = regexp.MustCompile(`(?m)^\s*//!?\s*dependant:tag\s+([\w-]+)`)
+	unsafeFnRegex  = regexp.MustCompile(`\bunsafe\s+(?:extern\s+"[^"]*"\s+)?fn\b`)
+	unsafeBlkRegex = regexp.MustCompile(`\bunsafe\s*\{`)
+)
+
+// Options are the command-line choices that change what an analysis contains, and so are part of its cache key.
+type Options struct {
+	Aggregate string      `json:"aggregate,omitempty"` // unit of analysis: "module" (default) or "dir", each top-level directory under src/
+	Exclude   []string    `json:"exclude,omitempty"`   // globs from --exclude, on top of the config's
+	ReExports string      `json:"reexports,omitempty"` // "original" (default) attributes re-exported items to their defining module, "facade" to the re-exporter
+	Depth     int         `json:"depth,omitempty"`     // module path segments kept, e.g. 1 reports net::http as net; 0 keeps every level
+	Features  *FeatureSet `json:"features,omitempty"`  // the cfg configuration Rust imports are kept for; nil keeps every import
+	NoTests   bool        `json:"no_tests,omitempty"`  // leave imports from test code out, as if the tests did not exist
+	Mixed     bool        `json:"mixed,omitempty"`     // analyze every language under the root and merge them; see analyzeMixed
+}
+
+func (o Options) Equal(other Options) bool {
+	return o.Aggregate == other.Aggregate && slices.Equal(o.Exclude, other.Exclude) && o.ReExports == other.ReExports && o.Depth == other.Depth && o.Features.equal(other.Features) && o.NoTests == other.NoTests && o.Mixed == other.Mixed
+}
+
+// Report bundles the inputs and results of every pass over one source tree.
+type Report struct {
+	Root          string
+	Origin        string // with --rev or --expand: the working tree analyzed, Root being a temporary copy of it
+	Rev           string // with --rev: the commit analyzed
+	Language      string // the backend that analyzed the tree: "rust", "go", "js" or "python", or "mixed" for several
+	Options       Options
+	Config        *Config
+	Manifest      *CargoManifest
+	Lockfile      []LockedPackage
+	SymbolTable   map[string]map[string]struct{}
+	Facts         *ModuleFacts
+	Graph         *DependencyGraph
+	ModTree       *ModTree            // nil when the tree has no crate roots
+	FileModules   map[string]string   // file, relative to Root, -> module; set on a Report rebuilt from a snapshot, whose files cannot be read, and on a mixed one
+	Languages     []LanguageRoot      // with Options.Mixed: the languages found and where
+	CrossLanguage []CrossLanguageEdge // with Options.Mixed: imports between languages, which no one frontend sees
+
+	naming *moduleNaming // set by ConfigureModuleNaming
+}
+
+// ModuleGraph is module -> modules it imports, self-imports left out.
+type ModuleGraph map[string]map[string]struct{}
+
+// Modules is the module graph of every import, test code included.
+func (a *Report) Modules() ModuleGraph { return a.BuildModuleGraph(a.Graph.Deps) }
+
+// DisplayRoot is the directory the analysis describes to readers: Root, or with --rev the working tree it came from.
+func (a *Report) DisplayRoot() string { if a.Origin != "" { return a.Origin }; return a.Root }
+
+// Rebase moves a's file paths from a.Root to root, so an analysis of one checkout serves another with the same
+// contents; with root "" they are left relative. It copies what it changes, leaving other Reports sharing a's graph
+// alone. Files are named by path, so ConfigureModuleNaming must run again before a names any.
+func (a *Report) Rebase(root string) {
+	move := func(path string) string { return filepath.Join(root, filepath.FromSlash(relSlash(a.Root, path))) }
+	g := *a.Graph
+	g.Deps, g.ProdDeps, g.UseStyles = moveKeys(g.Deps, move), moveKeys(g.ProdDeps, move), moveKeys(g.UseStyles, move)
+	for _, imports := range []*map[string]map[string]map[string]struct{}{&g.ItemImports, &g.Conditions, &g.External} {
+		moved := make(map[string]map[string]map[string]struct{}, len(*imports))
+		for name, byKey := range *imports {
+			moved[name] = make(map[string]map[string]struct{}, len(byKey))
+			for key, files := range byKey { moved[name][key] = moveKeys(files, move) }
+		}
+		*imports = moved
+	}
+	inferred := make(map[string]map[string]map[string]string, len(g.Inferred))
+	for module, byItem := range g.Inferred {
+		inferred[module] = make(map[string]map[string]string, len(byItem))
+		for item, files := range byItem { inferred[module][item] = moveKeys(files, move) }
+	}
+	g.Inferred = inferred
+	g.Unparsed = slices.Clone(g.Unparsed)
+	for i := range g.Unparsed { g.Unparsed[i].File = move(g.Unparsed[i].File) }
+	a.Graph, a.Root, a.naming = &g, root, nil
+}
+
+// moveKeys copies m with every key passed through move.
+func moveKeys[V any](m map[string]V, move func(string) string) map[string]V {
+	if m == nil { return nil }
+	moved := make(map[string]V, len(m))
+	for k, v := range m { moved[move(k)] = v }
+	return moved
+}
+
+// Phases names the passes of an analysis, in order, as Analyzer.Progress reports them.
+var Phases = []string{"load", "symbols", "dependencies"}
+
+// Analyzer runs analyses with the same options.
+type Analyzer struct {
+	Options  Options
+	Progress func(phase string) // called as each of Phases finishes, when not nil; `dependant bench` times them
+}
+
+// Analyze analyzes the tree at root with the default options, or those given.
+func Analyze(root string, opts Options) (*Report, error) { return (&Analyzer{Options: opts}).Analyze(root) }
+
+// Analyze reads the tree at root, picking the language from its manifest (see detectLanguage), or with Mixed every
+// language under it, and returns what it found. Only the tree is read; nothing is cached.
+func (z *Analyzer) Analyze(root string) (*Report, error) {
+	if z.Options.Mixed { return z.analyzeMixed(root) }
+	return z.analyze(root, detectLanguage(root))
+}
+
+// analyze runs the passes of lang over root.
+func (z *Analyzer) analyze(root string, lang LanguageAnalyzer) (*Report, error) {
+	done, opts := z.Progress, z.Options
+	if done == nil { done = func(string) {} }
+	a := &Report{Root: root, Options: opts}
+	var err error
+	if a.Config, err = LoadConfig(root); err != nil { return nil, fmt.Errorf("loading config: %w", err) }
+	a.Config.Exclude = append(a.Config.Exclude, opts.Exclude...)
+	a.Language = lang.Name()
+	if err = lang.Load(a); err != nil { return nil, err }
+	ConfigureModuleNaming(a)
+	done("load")
+
+	if a.SymbolTable, a.Facts, err = lang.SymbolTable(a); err != nil { return nil, fmt.Errorf("building symbol table: %w", err) }
+	for module, tag := range a.Config.Tags { a.Facts.Tags[module] = tag } // config wins over in-source markers
+	done("symbols")
+
+	if a.Graph, err = lang.Dependencies(a); err != nil { return nil, fmt.Errorf("analyzing dependencies: %w", err) }
+	done("dependencies")
+	return a, nil
+}
+
+// ModuleFacts are per-module observations made while building the symbol table.
+type ModuleFacts struct {
+	Tags         map[string]string // from `//! dependant:tag <tag>` markers
+	UnsafeBlocks map[string]int
+	UnsafeFns    map[string]int
+	LOC          map[string]int  // non-blank lines outside comments
+	Generated    map[string]bool // modules whose every file matches a [generated] marker
+	ReExports     map[string]map[string]ReExport // facade module -> item it re-exports with `pub use` -> where it is defined
+	ReExportGlobs map[string][]string            // facade module -> modules it re-exports with `pub use module::*`
+	Restricted    map[string]map[string]string   // module -> pub(crate), pub(super) or pub(in ...) item -> its visibility
+	ModulePaths   map[string]string              // module path below the crate root, e.g. "net::http" -> the module it is reported as
+}
+
+// --- Pass 1: Symbol Table Builder ---
+func buildSymbolTable(a *Report) (map[string]map[string]struct{}, *ModuleFacts, error) {
+	root, cfg, libName, n := a.Root, a.Config, a.Manifest.LibName, a.names()
+	table := make(map[string]map[string]struct{})
+	facts := &ModuleFacts{Tags: make(map[string]string), UnsafeBlocks: make(map[string]int), UnsafeFns: make(map[string]int), LOC: make(map[string]int), Generated: make(map[string]bool), Restricted: make(map[string]map[string]string), ModulePaths: make(map[string]string)}
+	var pubUses []pubUse
+	filter := cfg.PathFilter(root)
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && filter.Skip(path, d.IsDir()) { if d.IsDir() { return filepath.SkipDir }; return nil }
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".rs") { return err }
+		content, err := os.ReadFile(path)
+		if err != nil { return err }
+		moduleName := n.name(path)
+		if _, ok := table[moduleName]; !ok { table[moduleName] = make(map[string]struct{}) }
+		if modPath, ok := n.modulePath(path); ok && len(modPath) > 0 { facts.ModulePaths[strings.Join(modPath, "::")] = moduleName }
+		generated := cfg.Generated.isGeneratedFile(root, path, string(content))
+		if seen, ok := facts.Generated[moduleName]; !ok || seen { facts.Generated[moduleName] = generated }
+		if m := tagMarkerRegex.FindStringSubmatch(string(content)); m != nil { facts.Tags[moduleName] = m[1] }
+		code := StripNonCode(string(content))
+		matches := pubDefRegex.FindAllStringSubmatch(code, -1)
+		for _, match := range matches { if len(match) > 1 { table[moduleName][unraw(match[1])] = struct{}{} } }
+		collectRestricted(facts.Restricted, moduleName, code)
+		facts.UnsafeBlocks[moduleName] += len(unsafeBlkRegex.FindAllStringIndex(code, -1))
+		facts.UnsafeFns[moduleName] += len(unsafeFnRegex.FindAllStringIndex(code, -1))
+		for _, line := range strings.Split(code, "\n") { if strings.TrimSpace(line) != "" { facts.LOC[moduleName]++ } }
+		pubUses = append(pubUses, collectPubUses(n, path, code)...)
+		return nil
+	})
+	facts.ReExports, facts.ReExportGlobs = resolveReExports(pubUses, table, facts.ModulePaths, libName, n.rootModule())
+	return table, facts, err
+}
+
+// DependencyGraph is everything Pass 2 learns about how files use modules.
+type DependencyGraph struct {
+	Deps        map[string]map[string]struct{}            // file -> modules it imports
+	ProdDeps    map[string]map[string]struct{}            // same, counting only imports outside test code
+	ItemImports map[string]map[string]map[string]struct{} // module -> item -> importing files
+	Conditions  map[string]map[string]map[string]struct{} // module -> cfg predicate ("" when unconditional) -> importing files
+	External    map[string]map[string]map[string]struct{} // external crate -> item -> importing files
+	Inferred    map[string]map[string]map[string]string   // module -> item -> file -> why the import was inferred rather than read
+	Unparsed    []UnparsedUse                             // use statements left out because they could not be parsed
+	UseStyles   map[string]UseStyle                       // file -> how its use statements are written; Rust only
+}
+
+// useSite describes where a use statement was found.
+type useSite struct {
+	File, Content string
+	IsTest        bool
+	Cfgs          []string // cfg predicates gating the statement, outermost first
+	Inferred      string   // why imports at this site are inferred, e.g. from a glob; "" when a statement names them
+}
+
+// --- Pass 2: Dependency Analyzer with NEW Parsing Engine ---
+// libName, when set, is the crate's own name: `use <libName>::...` in its binaries and tests is an internal import like `use crate::...`.
+// preludes are the configured items files use without importing them; see parsePreludes. Imports the options leave
+// out (see leavesOut) are not recorded, nor are those of dead files (see isDead).
+func analyzeDependencies(a *Report, resolver *importResolver) (*DependencyGraph, error) {
+	root, filter, libName, naming := a.Root, a.Config.PathFilter(a.Root), a.Manifest.LibName, a.names()
+	implicit, err := parsePreludes(a.Config.Preludes)
+	if err != nil { return nil, err }
+	graph := &DependencyGraph{
+		Deps:        make(map[string]map[string]struct{}),
+		ProdDeps:    make(map[string]map[string]struct{}),
+		ItemImports: make(map[string]map[string]map[string]struct{}),
+		Conditions:  make(map[string]map[string]map[string]struct{}),
+		External:    make(map[string]map[string]map[string]struct{}),
+		Inferred:    make(map[string]map[string]map[string]string),
+		UseStyles:   make(map[string]UseStyle),
+	}
+
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && filter.Skip(path, d.IsDir()) { if d.IsDir() { return filepath.SkipDir }; return nil }
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".rs") || a.isDead(path) { return err }
+		contentBytes, err := os.ReadFile(path)
+		if err != nil { return err }
+
+		fileContent := string(contentBytes)
+		contentWithoutComments := StripNonCode(fileContent)
+		testFile, fileCfgs, crateRoot := isTestFile(root, path), a.fileCfgs(path), isCrateRootFile(path)
+		blocks := CfgBlocks(contentWithoutComments)
+
+		// Each statement is parsed whole, so several on a line, attributes in front and comments inside are all fine,
+		// and a group may mix roots: `use {crate::cpu::Engine, serde::Serialize};`.
+		for _, loc := range UseTreeRegex.FindAllStringSubmatchIndex(contentWithoutComments, -1) {
+			tree := contentWithoutComments[loc[2]:loc[3]]
+			if strings.Contains(tree, "$") { continue } // a macro_rules! template such as `use $crate::x;`
+			leaves, err := parseUseTree(tree)
+			if err != nil { recordUnparsed(graph, path, contentWithoutComments, loc[0], fileContent[loc[0]:loc[1]], err); continue }
+
+			site := useSite{File: path, Content: fileContent, Cfgs: append(slices.Clone(fileCfgs), CfgPredicates(contentWithoutComments, loc[0], blocks)...)}
+			site.IsTest = testFile || AnyTestCfg(site.Cfgs)
+			if a.leavesOut(site) { continue }
+			recordUseStyle(graph, path, tree, leaves)
+			for _, leaf := range leaves {
+				var prefix []string
+				rest := leaf.Path[1:]
+				switch first := leaf.Path[0]; {
+				case first == "crate" || (first == libName && libName != ""):
+				case first == "super": prefix = naming.superPath(path)
+				case crateRoot && resolver.localModule(first): rest = leaf.Path // `pub use cpu::Engine;` in lib.rs names the crate's own module
+				default:
+					recordExternalLeaf(graph, path, leaf, libName)
+					continue
+				}
+				if len(rest) == 0 { continue }
+				recordUseLeaf(append(prefix, rest[:len(rest)-1]...), rest[len(rest)-1], site, graph, resolver)
+			}
+		}
+		file := useSite{File: path, Content: fileContent, IsTest: testFile || AnyTestCfg(fileCfgs), Cfgs: fileCfgs}
+		if !a.leavesOut(file) { recordPreludeUses(implicit, contentWithoutComments, file, naming.name(path), graph, resolver) }
+		return nil
+	})
+	return graph, err
+}
+
+// recordUseLeaf records one leaf of a use tree: itemName imported through the module path prefixParts.
+func recordUseLeaf(prefixParts []string, itemName string, site useSite, graph *DependencyGraph, resolver *importResolver) {
+	// The leaf is a final item (e.g., `Engine`, `*`, or `self`)
+	moduleName := resolver.rootModule // `use crate::Item`: only meaningful when the crate root re-exports Item
+	if len(prefixParts) > 0 { moduleName = prefixParts[0] }
+	if module, n := resolveModulePath(resolver.modulePaths, prefixParts); n > 0 { moduleName = module } // the most specific module that exists
+	if itemName == "self" {
+		if _, known := resolver.symbolTable[moduleName]; known && len(prefixParts) > 0 { recordImport(graph, site, moduleName, "") } // `use crate::net::{self}`
+		return
+	}
+
+	// Handle glob or specific item
+	if itemName == "*" {
+		site.Inferred = InferredGlob
+		matched := false
+		for _, symbol := range resolver.exported(moduleName) {
+			if r, err := regexp.Compile(`\b` + symbol + `\b`); err == nil && r.MatchString(site.Content) {
+				module, item := resolver.resolve(moduleName, symbol)
+				recordImport(graph, site, module, item)
+				matched = matched || module == moduleName
+			}
+		}
+		if !matched && len(prefixParts) > 0 { recordImport(graph, site, moduleName, "") }
+		return
+	}
+	if module, n := resolveModulePath(resolver.modulePaths, append(prefixParts[:len(prefixParts):len(prefixParts)], itemName)); n == len(prefixParts)+1 {
+		recordImport(graph, site, module, "") // `use crate::net::http;` imports the module itself
+		return
+	}
+	module, item := resolver.resolve(moduleName, itemName)
+	if len(prefixParts) == 0 && module == moduleName { return } // an item of the crate root itself, which is not a module
+	recordImport(graph, site, module, item)
+}
+
+// recordImport registers that site imports item (or, when item is empty, only the module) from module.
+func recordImport(graph *DependencyGraph, site useSite, module, item string) {
+	filePath := site.File
+	if graph.Deps[filePath] == nil { graph.Deps[filePath] = make(map[string]struct{}) }
+	graph.Deps[filePath][module] = struct{}{}
+	if !site.IsTest {
+		if graph.ProdDeps[filePath] == nil { graph.ProdDeps[filePath] = make(map[string]struct{}) }
+		graph.ProdDeps[filePath][module] = struct{}{}
+	}
+	if graph.Conditions[module] == nil { graph.Conditions[module] = make(map[string]map[string]struct{}) }
+	condition := strings.Join(site.Cfgs, " && ")
+	if graph.Conditions[module][condition] == nil { graph.Conditions[module][condition] = make(map[string]struct{}) }
+	graph.Conditions[module][condition][filePath] = struct{}{}
+
+	if _, ok := graph.ItemImports[module]; !ok { graph.ItemImports[module] = make(map[string]map[string]struct{}) }
+	if item == "" { return }
+	if _, ok := graph.ItemImports[module][item]; !ok { graph.ItemImports[module][item] = make(map[string]struct{}) }
+	_, seen := graph.ItemImports[module][item][filePath]
+	graph.ItemImports[module][item][filePath] = struct{}{}
+	recordInferred(graph, site, module, item, seen)
+}
+
+// leavesOut reports whether the options leave the code at site out of the graph: test code with NoTests, and in a
+// Rust tree code the Features configuration does not compile.
+func (a *Report) leavesOut(site useSite) bool {
+	if site.IsTest && a.Options.NoTests { return true }
+	return a.Language == "rust" && a.Options.Features.compiledOut(site)
+}
+
+// fileCfgs returns the cfg predicates on the mod declarations through which a crate root reaches path, e.g. test for
+// the file of `#[cfg(test)] mod tests;`.
+func (a *Report) fileCfgs(path string) []string {
+	if a.ModTree == nil { return nil }
+	return a.ModTree.Cfgs[relSlash(a.Root, path)]
+}
+
+// isDead reports whether path is a file no crate root reaches (see ModTree.Dead). It never compiles, so its imports
+// are not dependencies: a chain of dead files importing each other would otherwise keep every one of them in use.
+func (a *Report) isDead(path string) bool {
+	if a.ModTree == nil { return false }
+	rel := relSlash(a.Root, path)
+	i := sort.SearchStrings(a.ModTree.Dead, rel)
+	return i < len(a.ModTree.Dead) && a.ModTree.Dead[i] == rel
+}
+
+// isTestFile reports whether path lives under a `tests/` directory of the analyzed tree (integration tests).
+func isTestFile(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil { return false }
+	for _, part := range strings.Split(filepath.ToSlash(filepath.Dir(rel)), "/") { if part == "tests" { return true } }
+	return false
+}
+
+// RootModules lists the modules a named after the tree itself: the crate roots of a Rust tree, the root package of a
+// Go module, and the root and workspace packages of a JS one.
+func (a *Report) RootModules() []string {
+	n := a.names()
+	var roots []string
+	for _, name := range []string{n.goRoot, n.jsRoot} { if name != "" { roots = append(roots, name) } }
+	for _, name := range n.rootNames { roots = append(roots, name) }
+	for _, name := range n.jsPackages { roots = append(roots, name) }
+	return roots
+}
+
+// moduleNaming is how a Report names the modules of its files. It depends on the tree analyzed and the options, so each
+// Report carries its own; ConfigureModuleNaming builds it and nothing changes it after, so a Report can be read from
+// several goroutines while another analysis runs.
+type moduleNaming struct {
+	root       string
+	rootNames  map[string]string   // crate-root file, src/lib.rs or src/main.rs by base name, -> its module name; legacy names when empty
+	aggregate  bool                // --aggregate dir: every file below src/<dir>/ is named <dir>
+	depth      int                 // --depth: module paths are capped at that many segments below the crate root
+	declared   map[string][]string // file, relative to root, -> the module path the crate's `mod` declarations give it, so a file loaded through #[path] is named after its module
+	files      map[string]string   // Report.FileModules, which names files outright
+	goRoot     string              // module name of the package at the root of a Go module
+	jsRoot     string              // module name of the root index file of a JS package
+	jsPackages map[string]string   // JS workspace packages by directory relative to root
+}
+
+// names is a's module naming; a Report ConfigureModuleNaming never ran on names files the legacy way.
+func (a *Report) names() *moduleNaming {
+	if a.naming != nil { return a.naming }
+	return &moduleNaming{root: a.Root}
+}
+
+// ModuleName names the module a file of a belongs to. A Rust file the crate's `mod` declarations reach is named by its
+// whole module path below the crate root, such as net::http, so net::http and server::http stay apart.
+func (a *Report) ModuleName(path string) string { return a.names().name(path) }
+
+func (n *moduleNaming) name(path string) string {
+	if n.files != nil {
+		if name, ok := n.files[relSlash(n.root, path)]; ok { return name }
+	}
+	base, dir := filepath.Base(path), filepath.Dir(path)
+	if strings.HasSuffix(base, ".go") { return n.goPackage(path) }
+	if IsJSFile(base) { return n.jsModule(path) }
+	if IsPythonFile(base) { return n.pyModule(path) }
+	if n.aggregate {
+		if rel, err := filepath.Rel(n.root, path); err == nil {
+			if parts := strings.Split(filepath.ToSlash(rel), "/"); len(parts) > 2 && parts[0] == "src" { return parts[1] }
+		}
+	}
+	if parts, ok := n.modulePath(path); ok && len(parts) > 0 {
+		if n.depth > 0 && len(parts) > n.depth { return strings.Join(parts[:n.depth], "::") }
+		if _, declared := n.declared[relSlash(n.root, path)]; declared { return strings.Join(parts, "::") }
+	}
+	if name, ok := n.rootNames[base]; ok && filepath.Base(dir) == "src" { return name }
+	if base == "mod.rs" || base == "lib.rs" { return filepath.Base(dir) }
+	return strings.TrimSuffix(base, ".rs")
+}
+
+// modulePath is the module path of a file under root/src: the one its `mod` declaration gives it when the crate's
+// module tree reaches it, else the one the file layout implies: src/net/http/mod.rs and src/net/http.rs are net::http,
+// src/lib.rs is the crate root (no segments). Files outside src/, such as tests, have none.
+func (n *moduleNaming) modulePath(path string) ([]string, bool) {
+	rel := relSlash(n.root, path)
+	if parts, ok := n.declared[rel]; ok { return parts, true }
+	parts := strings.Split(rel, "/")
+	if len(parts) < 2 || parts[0] != "src" { return nil, false }
+	dirs, stem := parts[1:len(parts)-1], strings.TrimSuffix(parts[len(parts)-1], ".rs")
+	if stem == "mod" || (len(dirs) == 0 && (stem == "lib" || stem == "main")) { return dirs, true }
+	return append(dirs, stem), true
+}
+
+func relSlash(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil { return filepath.ToSlash(path) }
+	return filepath.ToSlash(rel)
+}
+
+// superPath is the module path `super` names in path: its parent module's. Outside src/ it falls back to the
+// file's directory.
+func (n *moduleNaming) superPath(path string) []string {
+	parts, ok := n.modulePath(path)
+	if !ok || len(parts) == 0 { return []string{filepath.Base(filepath.Dir(path))} }
+	return parts[:len(parts)-1]
+}
+
+// resolveModulePath finds the longest prefix of segments that names a module in paths (see ModuleFacts.ModulePaths),
+// returning that module and the number of segments it spans, 0 when even the first segment is not a known path.
+func resolveModulePath(paths map[string]string, segments []string) (string, int) {
+	for n := len(segments); n > 0; n-- {
+		if module, ok := paths[strings.Join(segments[:n], "::")]; ok { return module, n }
+	}
+	return "", 0
+}
+
+// ConfigureModuleNaming names crate-root files (or a Go module's root package, or a JS package's root index) after the [naming] table of
+// dependant.toml, defaulting to the crate name, names files after their declared modules, switches to directory units for --aggregate dir and caps module paths for
+// --depth. Analyze runs it; a caller restoring a Report it saved earlier runs it again before naming that tree's files.
+func ConfigureModuleNaming(a *Report) {
+	cfg, manifest := a.Config, a.Manifest
+	n := &moduleNaming{root: a.Root, rootNames: map[string]string{}, aggregate: a.Options.Aggregate == "dir", depth: a.Options.Depth, declared: map[string][]string{}, files: a.FileModules}
+	if a.ModTree != nil {
+		for rel, modPath := range a.ModTree.Paths {
+			if parts := strings.Split(modPath, "::"); parts[0] == "crate" && strings.HasPrefix(rel, "src/") { n.declared[rel] = parts[1:] }
+		}
+	}
+	for _, file := range []string{"lib", "main"} {
+		name := cfg.Naming[file]
+		if name == "" && manifest != nil && manifest.Name != "" { name = CrateImportName(manifest.Name) }
+		if name != "" { n.rootNames[file+".rs"] = name }
+	}
+	if a.Language == "go" {
+		if n.goRoot = cfg.Naming["lib"]; n.goRoot == "" && manifest != nil && manifest.Name != "" { n.goRoot = goPackageBase(manifest.Name) }
+	}
+	if a.Language == "js" {
+		if n.jsRoot = cfg.Naming["lib"]; n.jsRoot == "" && manifest != nil { n.jsRoot = manifest.Name }
+		n.jsPackages = make(map[string]string)
+		for name, dir := range newJSResolver(a.Root).packages { if dir != a.Root { n.jsPackages[relSlash(a.Root, dir)] = name } }
+	}
+	a.naming = n
+}
+
+// BuildModuleGraph lifts file -> module edges to module -> module edges, naming files as a does and dropping
+// self-references.
+func (a *Report) BuildModuleGraph(fileDeps map[string]map[string]struct{}) ModuleGraph {
+	n, graph := a.names(), make(ModuleGraph)
+	for file, deps := range fileDeps {
+		from := n.name(file)
+		for to := range deps {
+			if to == from || to == "" { continue }
+			if graph[from] == nil { graph[from] = make(map[string]struct{}) }
+			graph[from][to] = struct{}{}
+		}
+	}
+	return graph
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m { keys = append(keys, k) }
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,34 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRebase(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"Cargo.toml":     "[package]\nname = \"shop\"\n",
+		"src/lib.rs":     "pub mod net;\npub mod cpu;\n",
+		"src/net/mod.rs": "use crate::cpu::Engine;\nuse crate::cpu::{;\n",
+		"src/cpu.rs":     "pub struct Engine;\n",
+	})
+	a, err := Analyze(root, Options{})
+	if err != nil { t.Fatal(err) }
+	before := a.Modules()
+	file := filepath.Join(root, "src", "net", "mod.rs")
+
+	stored := *a
+	stored.Rebase("")
+	if _, ok := stored.Graph.Deps[filepath.Join("src", "net", "mod.rs")]; !ok { t.Errorf("relative deps = %v", sortedKeys(stored.Graph.Deps)) }
+	if _, ok := a.Graph.Deps[file]; !ok { t.Errorf("Rebase changed the original's deps to %v", sortedKeys(a.Graph.Deps)) }
+
+	other := filepath.Join(t.TempDir(), "checkout")
+	stored.Rebase(other)
+	ConfigureModuleNaming(&stored)
+	moved := filepath.Join(other, "src", "net", "mod.rs")
+	if _, ok := stored.Graph.ItemImports["cpu"]["Engine"][moved]; !ok { t.Errorf("Engine is imported by %v, want %s", sortedKeys(stored.Graph.ItemImports["cpu"]["Engine"]), moved) }
+	if len(stored.Graph.Unparsed) != 1 || stored.Graph.Unparsed[0].File != moved { t.Errorf("unparsed = %+v, want one in %s", stored.Graph.Unparsed, moved) }
+	if got := stored.Modules(); !reflect.DeepEqual(got, before) { t.Errorf("modules after Rebase = %v, want %v", got, before) }
+}
@@ -0,0 +1,18 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// restrictedDefRegex finds items whose visibility is limited to the crate or part of it: pub(crate), pub(super) and
+// pub(in path). Crates that use visibility as their architecture expect these to stay inside one top-level module.
+var restrictedDefRegex = regexp.MustCompile(`\bpub\s*\(\s*(crate|super|in\s+[\w:]+)\s*\)\s*(?:unsafe\s+)?(?:struct|enum|fn|trait|type|const|static|union)\s+((?:r#)?\w+)`)
+
+// collectRestricted records module -> item -> visibility for every restricted item defined in code.
+func collectRestricted(restricted map[string]map[string]string, module, code string) {
+	for _, m := range restrictedDefRegex.FindAllStringSubmatch(code, -1) {
+		if restricted[module] == nil { restricted[module] = make(map[string]string) }
+		restricted[module][unraw(m[2])] = "pub(" + strings.Join(strings.Fields(m[1]), " ") + ")"
+	}
+}
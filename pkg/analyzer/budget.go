@@ -0,0 +1,42 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/WillKirkmanM/dependant/internal/toml"
+)
+
+// Budget caps how coupled one module may become, and how large its interface may grow. A negative limit means
+// unlimited.
+//
+//	[budgets]
+//	cpu = { max_outbound = 3, max_inbound = 10 }
+//	ui  = { max_outbound = 5, max_public = 40, max_public_growth = 2 }
+type Budget struct {
+	MaxOutbound     int // modules this module may depend on
+	MaxInbound      int // modules that may depend on this module
+	MaxPublic       int // public items this module may declare
+	MaxPublicGrowth int // public items it may add since the previous run: the --baseline snapshot or the last --history record
+}
+
+func parseBudgets(table map[string]any) (map[string]Budget, error) {
+	budgets := make(map[string]Budget)
+	for module, v := range table {
+		entry := toml.Table(v)
+		if entry == nil { return nil, fmt.Errorf("%s: [budgets] %s must be a table", ConfigFileName, module) }
+		budget := Budget{MaxOutbound: -1, MaxInbound: -1, MaxPublic: -1, MaxPublicGrowth: -1}
+		for key, limit := range entry {
+			n, ok := toml.Int(limit)
+			if !ok || n < 0 { return nil, fmt.Errorf("%s: [budgets] %s.%s must be a non-negative integer", ConfigFileName, module, key) }
+			switch key {
+			case "max_outbound": budget.MaxOutbound = n
+			case "max_inbound": budget.MaxInbound = n
+			case "max_public": budget.MaxPublic = n
+			case "max_public_growth": budget.MaxPublicGrowth = n
+			default: return nil, fmt.Errorf("%s: [budgets] %s supports max_outbound, max_inbound, max_public and max_public_growth, not %q", ConfigFileName, module, key)
+			}
+		}
+		budgets[module] = budget
+	}
+	return budgets, nil
+}
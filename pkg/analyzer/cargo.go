@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/internal/toml"
+)
+
+// CargoManifest is the part of Cargo.toml the analyzer cares about.
+type CargoManifest struct {
+	Name, Version string
+	Library       bool              // the crate has a library target
+	LibName       string            // name binaries, tests and examples import the library by: [lib] name, else the package name
+	Dependencies  map[string]string // import name -> package name, across normal, dev and build dependencies
+}
+
+// loadCargoManifest reads root/Cargo.toml; a missing manifest yields a zero manifest and no error.
+func loadCargoManifest(root string) (*CargoManifest, error) {
+	m := &CargoManifest{Dependencies: make(map[string]string)}
+	content, err := os.ReadFile(filepath.Join(root, "Cargo.toml"))
+	if errors.Is(err, os.ErrNotExist) { return m, nil }
+	if err != nil { return nil, err }
+	doc, err := toml.Parse(string(content))
+	if err != nil { return nil, err }
+	pkg := toml.Table(doc["package"])
+	m.Name, m.Version = toml.String(pkg["name"]), toml.String(pkg["version"])
+	_, hasLibSection := doc["lib"]
+	_, err = os.Stat(filepath.Join(root, "src", "lib.rs"))
+	m.Library = hasLibSection || err == nil
+	if m.LibName = CrateImportName(toml.String(toml.Table(doc["lib"])["name"])); m.LibName == "" { m.LibName = CrateImportName(m.Name) }
+	for _, section := range []string{"dependencies", "dev-dependencies", "build-dependencies"} {
+		for name, spec := range toml.Table(doc[section]) {
+			pkg := name
+			if renamed := toml.String(toml.Table(spec)["package"]); renamed != "" { pkg = renamed } // `alias = { package = "real-name" }`
+			m.Dependencies[CrateImportName(name)] = pkg
+		}
+	}
+	return m, nil
+}
+
+// LockedPackage is one [[package]] entry of Cargo.lock.
+type LockedPackage struct {
+	Name, Version, Source string
+}
+
+// loadCargoLock reads the Cargo.lock of root or, for workspace members, of the nearest ancestor that has one.
+func loadCargoLock(root string) ([]LockedPackage, error) {
+	dir, err := filepath.Abs(root)
+	if err != nil { return nil, err }
+	for {
+		content, err := os.ReadFile(filepath.Join(dir, "Cargo.lock"))
+		if err == nil {
+			doc, err := toml.Parse(string(content))
+			if err != nil { return nil, fmt.Errorf("Cargo.lock: %w", err) }
+			var pkgs []LockedPackage
+			list, _ := doc["package"].([]any)
+			for _, p := range list {
+				t := toml.Table(p)
+				pkgs = append(pkgs, LockedPackage{Name: toml.String(t["name"]), Version: toml.String(t["version"]), Source: toml.String(t["source"])})
+			}
+			return pkgs, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) { return nil, err }
+		parent := filepath.Dir(dir)
+		if parent == dir { return nil, nil }
+		dir = parent
+	}
+}
+
+// CrateImportName is how code refers to a package: Cargo turns hyphens into underscores.
+func CrateImportName(pkg string) string { return strings.ReplaceAll(pkg, "-", "_") }
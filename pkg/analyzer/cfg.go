@@ -0,0 +1,139 @@
+package analyzer
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	cfgModRegex  = regexp.MustCompile(`#\[cfg\((.*?)\)\]\s*(?:pub(?:\([^)]*\))?\s+)?mod\s+\w+\s*\{`)
+	cfgAttrRegex = regexp.MustCompile(`^#\[cfg\((.*)\)\]$`)
+	testCfgRegex = regexp.MustCompile(`\btest\b`)
+	notTestRegex = regexp.MustCompile(`not\(\s*test\s*\)`)
+)
+
+// cfgBlock is an inline module gated by a `#[cfg(...)]` attribute, e.g. `#[cfg(test)] mod tests { ... }`.
+type cfgBlock struct {
+	Start, End int
+	Predicate  string
+}
+
+// CfgBlocks finds cfg-gated inline modules by brace matching.
+func CfgBlocks(content string) []cfgBlock {
+	var blocks []cfgBlock
+	for _, loc := range cfgModRegex.FindAllStringSubmatchIndex(content, -1) {
+		depth := 0
+		for i := loc[1] - 1; i < len(content); i++ {
+			if content[i] == '{' { depth++ } else if content[i] == '}' { depth-- }
+			if depth == 0 { blocks = append(blocks, cfgBlock{Start: loc[0], End: i + 1, Predicate: normalizeCfg(content[loc[2]:loc[3]])}); break }
+		}
+	}
+	return blocks
+}
+
+// CfgPredicates returns the cfg predicates gating the statement at offset: those of enclosing
+// cfg'd modules followed by any `#[cfg(...)]` attributes written directly on the statement.
+func CfgPredicates(content string, offset int, blocks []cfgBlock) []string {
+	var preds []string
+	for _, b := range blocks { if offset >= b.Start && offset < b.End { preds = append(preds, b.Predicate) } }
+	var own []string
+	rest := strings.TrimSpace(content[:offset])
+	for strings.HasSuffix(rest, "]") {
+		start := strings.LastIndex(rest, "#[")
+		if start < 0 { break }
+		if m := cfgAttrRegex.FindStringSubmatch(rest[start:]); m != nil { own = append([]string{normalizeCfg(m[1])}, own...) }
+		rest = strings.TrimSpace(rest[:start])
+	}
+	return append(preds, own...)
+}
+
+func normalizeCfg(predicate string) string { return strings.Join(strings.Fields(predicate), " ") }
+
+func AnyTestCfg(preds []string) bool {
+	for _, p := range preds { if testCfgRegex.MatchString(p) && !notTestRegex.MatchString(p) { return true } }
+	return false
+}
+
+// FeatureSet is one cfg configuration to compute the graph for, as cargo build --features would compile it, instead
+// of the union of every cfg.
+type FeatureSet struct {
+	Enabled []string `json:"enabled"` // Cargo features turned on
+	Tests   bool     `json:"tests"`   // whether #[cfg(test)] code and tests/ are compiled
+}
+
+func (s *FeatureSet) equal(other *FeatureSet) bool {
+	if s == nil || other == nil { return s == other }
+	return slices.Equal(s.Enabled, other.Enabled) && s.Tests == other.Tests
+}
+
+func (s *FeatureSet) String() string {
+	features := "no features"
+	if len(s.Enabled) > 0 { features = "features " + strings.Join(s.Enabled, ", ") }
+	if s.Tests { return features + ", with tests" }
+	return features + ", without tests"
+}
+
+// compiledOut reports whether the configuration leaves the code at site out: test code without Tests, or code behind
+// a predicate that does not hold. A nil FeatureSet keeps everything.
+func (s *FeatureSet) compiledOut(site useSite) bool {
+	if s == nil { return false }
+	if site.IsTest && !s.Tests { return true }
+	for _, p := range site.Cfgs { if v, rest := s.eval(p); v == cfgFalse && strings.TrimSpace(rest) == "" { return true } }
+	return false
+}
+
+// cfgValue is a predicate's value under a FeatureSet. Predicates on anything but features and test, such as
+// target_os or debug_assertions, are unknown, and unknown code is kept: the graph is not computed for one target.
+type cfgValue int
+
+const (
+	cfgUnknown cfgValue = iota
+	cfgTrue
+	cfgFalse
+)
+
+var cfgTermRegex = regexp.MustCompile(`^\s*(\w+)\s*(?:=\s*"([^"]*)"\s*)?`)
+
+// eval evaluates the predicate at the start of p, e.g. `all(feature = "fast", not(test))`, returning what follows it.
+func (s *FeatureSet) eval(p string) (cfgValue, string) {
+	m := cfgTermRegex.FindStringSubmatchIndex(p)
+	if m == nil { return cfgUnknown, p }
+	name, rest := p[m[2]:m[3]], p[m[1]:]
+	if m[4] >= 0 {
+		if name == "feature" { return cfgOf(slices.Contains(s.Enabled, p[m[4]:m[5]])), rest }
+		return cfgUnknown, rest
+	}
+	if !strings.HasPrefix(rest, "(") {
+		if name == "test" { return cfgOf(s.Tests), rest }
+		return cfgUnknown, rest
+	}
+	var args []cfgValue
+	for rest = rest[1:]; ; {
+		if rest = strings.TrimSpace(rest); strings.HasPrefix(rest, ")") { rest = rest[1:]; break }
+		var v cfgValue
+		if v, rest = s.eval(rest); rest == "" { return cfgUnknown, rest } // unbalanced
+		args = append(args, v)
+		if rest = strings.TrimSpace(rest); strings.HasPrefix(rest, ",") { rest = rest[1:] } else if !strings.HasPrefix(rest, ")") { return cfgUnknown, "" }
+	}
+	switch name {
+	case "not":
+		if len(args) == 1 && args[0] != cfgUnknown { return cfgOf(args[0] == cfgFalse), rest }
+	case "all": return cfgCombine(args, cfgFalse, cfgTrue), rest
+	case "any": return cfgCombine(args, cfgTrue, cfgFalse), rest
+	}
+	return cfgUnknown, rest
+}
+
+func cfgOf(b bool) cfgValue { if b { return cfgTrue }; return cfgFalse }
+
+// cfgCombine folds the arguments of all() (decisive false) or any() (decisive true); with none decisive, an unknown
+// argument makes the result unknown, and no arguments at all give empty.
+func cfgCombine(args []cfgValue, decisive, empty cfgValue) cfgValue {
+	result := empty
+	for _, v := range args {
+		if v == decisive { return decisive }
+		if v == cfgUnknown { result = cfgUnknown }
+	}
+	return result
+}
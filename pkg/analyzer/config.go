@@ -0,0 +1,139 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/internal/toml"
+)
+
+const ConfigFileName = "dependant.toml"
+
+// Config mirrors dependant.toml, which is optional and read from the root of the analyzed tree.
+//
+//	exclude = ["target", "src/generated"]
+//	stable  = ["cpu"] # public API contracts, checked against `check --baseline`
+//	gitignore = false # also analyze files .gitignore excludes (cargo's target/ is always skipped)
+//	strict_boundaries = true # report pub(crate) items imported across top-level modules (see BoundaryLeak)
+//	preludes = ["crate::prelude::*"] # items used without a use statement (see parsePreludes)
+//	test_modules = ["test_utils", "fixtures*"] # modules production code must not import (default: defaultTestModules)
+//	layers = ["ui -> domain -> storage"] # each layer may import only those below it (see parseLayers, and parseLayersFile for dependant.yaml)
+//
+//	[tags]
+//	cpu = "domain"
+//	ui  = "ui"
+//
+//	[naming]
+//	lib  = "core" # module name for src/lib.rs (default: the crate name from Cargo.toml)
+//	main = "app"  # module name for src/main.rs (default: the crate name)
+//
+//	[importers]
+//	auth_internal = ["api", "api::*"] # the only modules, besides its own submodules, that may import it
+//
+// See GeneratedConfig for the [generated] table, Budget for [budgets] and GodModuleLimits for [god_modules].
+type Config struct {
+	Exclude          []string            // globs; without a slash they match any path component, with one the path from the root
+	Tags             map[string]string   // module name -> tag
+	Naming           map[string]string   // "lib" / "main" -> module name for that crate-root file
+	Generated        GeneratedConfig     // markers for codegen output
+	Budgets          map[string]Budget   // module -> coupling budget enforced by check
+	Stable           []string            // modules whose public items and dependents check compares with a baseline
+	Gitignore        bool                // honor .gitignore files while walking the tree (default true)
+	StrictBoundaries bool                // strict boundary mode for crates that use pub(crate) as their architecture
+	Preludes         []string            // crate paths every file can use without importing them
+	TestModules      []string            // module-name patterns production code must not import; empty turns the rule off
+	Layers           [][]string          // chains of layers, top down, that imports may only descend, from dependant.toml and dependant.yaml
+	GodModules       GodModuleLimits     // thresholds of the report's god-module heuristic
+	Importers        map[string][]string // module pattern -> patterns of the only other modules that may import it
+}
+
+// GodModuleLimits are the [god_modules] thresholds: the report flags a module imported by at least MinDependents
+// files of other modules that import at least MinItems distinct items from it.
+type GodModuleLimits struct {
+	MinDependents int
+	MinItems      int
+}
+
+// DefaultConfig is the configuration of a tree without a dependant.toml.
+func DefaultConfig() *Config {
+	return &Config{Tags: make(map[string]string), Naming: make(map[string]string), Gitignore: true, Generated: GeneratedConfig{Headers: defaultGeneratedHeaders}, TestModules: defaultTestModules, GodModules: GodModuleLimits{MinDependents: 10, MinItems: 15}}
+}
+
+func LoadConfig(root string) (*Config, error) {
+	cfg := DefaultConfig()
+	content, err := os.ReadFile(filepath.Join(root, ConfigFileName))
+	if errors.Is(err, os.ErrNotExist) { return withLayersFile(root, cfg) }
+	if err != nil { return nil, err }
+	doc, err := toml.Parse(string(content))
+	if err != nil { return nil, fmt.Errorf("%s: %w", ConfigFileName, err) }
+	cfg.Exclude, cfg.Stable = toml.Strings(doc["exclude"]), toml.Strings(doc["stable"])
+	if gitignore, ok := doc["gitignore"].(bool); ok { cfg.Gitignore = gitignore }
+	cfg.StrictBoundaries, _ = doc["strict_boundaries"].(bool)
+	cfg.Preludes = toml.Strings(doc["preludes"])
+	if patterns, ok := doc["test_modules"]; ok { cfg.TestModules = toml.Strings(patterns) }
+	for _, pattern := range cfg.TestModules {
+		if _, err := pathpkg.Match(pattern, ""); err != nil { return nil, fmt.Errorf("%s: test_modules: bad pattern %q", ConfigFileName, pattern) }
+	}
+	if _, err := parsePreludes(cfg.Preludes); err != nil { return nil, fmt.Errorf("%s: %w", ConfigFileName, err) }
+	for module, tag := range toml.Table(doc["tags"]) { cfg.Tags[module] = toml.String(tag) }
+	for file, name := range toml.Table(doc["naming"]) {
+		if file != "lib" && file != "main" { return nil, fmt.Errorf("%s: [naming] supports lib and main, not %q", ConfigFileName, file) }
+		cfg.Naming[file] = toml.String(name)
+	}
+	if cfg.Layers, err = parseLayers(ConfigFileName, toml.Strings(doc["layers"])); err != nil { return nil, err }
+	if cfg.Importers, err = parseImporters(toml.Table(doc["importers"])); err != nil { return nil, err }
+	if cfg.Budgets, err = parseBudgets(toml.Table(doc["budgets"])); err != nil { return nil, err }
+	for key, v := range toml.Table(doc["god_modules"]) {
+		n, ok := toml.Int(v)
+		if !ok || n < 1 { return nil, fmt.Errorf("%s: [god_modules] %s must be a positive integer", ConfigFileName, key) }
+		switch key {
+		case "min_dependents": cfg.GodModules.MinDependents = n
+		case "min_items": cfg.GodModules.MinItems = n
+		default: return nil, fmt.Errorf("%s: [god_modules] supports min_dependents and min_items, not %q", ConfigFileName, key)
+		}
+	}
+	if gen := toml.Table(doc["generated"]); gen != nil {
+		if headers, ok := gen["headers"]; ok { cfg.Generated.Headers = toml.Strings(headers) }
+		cfg.Generated.Attributes, cfg.Generated.Paths = toml.Strings(gen["attributes"]), toml.Strings(gen["paths"])
+		cfg.Generated.Enforce, _ = gen["enforce"].(bool)
+	}
+	return withLayersFile(root, cfg)
+}
+
+// withLayersFile adds the chains of root's dependant.yaml, if it has one, to those dependant.toml declares.
+func withLayersFile(root string, cfg *Config) (*Config, error) {
+	content, err := os.ReadFile(filepath.Join(root, LayersFileName))
+	if errors.Is(err, os.ErrNotExist) { return cfg, nil }
+	if err != nil { return nil, err }
+	chains, err := parseLayersFile(string(content))
+	if err != nil { return nil, fmt.Errorf("%s: %w", LayersFileName, err) }
+	layers, err := parseLayers(LayersFileName, chains)
+	if err != nil { return nil, err }
+	cfg.Layers = append(cfg.Layers, layers...)
+	return cfg, nil
+}
+
+// matchesGlobs reports whether path (inside root) matches one of the globs, with the semantics of Config.Exclude.
+func matchesGlobs(root, path string, patterns []string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." { return false }
+	rel = filepath.ToSlash(rel)
+	parts := strings.Split(rel, "/")
+	for _, pattern := range patterns {
+		pattern = strings.Trim(pattern, "/")
+		if !strings.Contains(pattern, "/") {
+			for _, part := range parts { if ok, _ := pathpkg.Match(pattern, part); ok { return true } }
+			continue
+		}
+		for i := range parts { if ok, _ := pathpkg.Match(pattern, strings.Join(parts[:i+1], "/")); ok { return true } }
+	}
+	return false
+}
+
+// defaultTestModules are the module-name patterns production code must not import unless dependant.toml sets
+// test_modules: test suites and the helpers written for them.
+var defaultTestModules = []string{"tests", "test", "test_utils", "test_util", "testutil", "testutils", "test_helpers", "__tests__", "conftest"}
@@ -0,0 +1,173 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// CrossLanguageEdge is a file of one language using a module of another, which neither frontend sees on its own:
+// Rust calling C through extern "C", Go through cgo, and Python importing a Rust extension module.
+type CrossLanguageEdge struct {
+	File  string   `json:"file"` // relative to the root
+	From  string   `json:"from"` // the file's module
+	To    string   `json:"to"`
+	Via   string   `json:"via"` // "extern \"C\"", "cgo" or "pyo3"
+	Items []string `json:"items,omitempty"`
+}
+
+// Reasons a cross-language import is inferred; see DependencyGraph.Inferred.
+const (
+	inferredFFI  = "extern \"C\": functions are matched by name to the C headers declaring them"
+	inferredCgo  = "cgo: C.name selectors are matched to the headers the preamble includes"
+	inferredPyO3 = "#[pymodule]: Python imports are matched by module name to the Rust function defining it"
+)
+
+var (
+	externBlockRegex = regexp.MustCompile(`\bextern\s+(?:"[^"]*"\s*)?\{`)
+	externFnRegex    = regexp.MustCompile(`\bfn\s+(\w+)`)
+	cPrototypeRegex  = regexp.MustCompile(`\b([A-Za-z_]\w*)\s*\([^;{}()]*(?:\([^;{}()]*\)[^;{}()]*)*\)\s*;`)
+	cgoImportRegex   = regexp.MustCompile(`(?m)^import\s+(?:"C"|\(\s*"C")`)
+	cgoIncludeRegex  = regexp.MustCompile(`#include\s*"([^"]+)"`)
+	cgoSelectorRegex = regexp.MustCompile(`\bC\.(\w+)`)
+	pymoduleRegex    = regexp.MustCompile(`#\[pymodule\]((?:\s*#\[[^\]]*\])*)\s*(?:pub(?:\([^)]*\))?\s+)?fn\s+(\w+)`)
+	pyo3NameRegex    = regexp.MustCompile(`#\[pyo3\(\s*name\s*=\s*"(\w+)"`)
+)
+
+// IsCHeader reports whether name is a C or C++ header.
+func IsCHeader(name string) bool {
+	return strings.HasSuffix(name, ".h") || strings.HasSuffix(name, ".hpp") || strings.HasSuffix(name, ".hh")
+}
+
+// cHeader is a header file under the tree, with the functions it declares.
+type cHeader struct {
+	path, rel string
+	functions map[string]struct{}
+}
+
+// linkLanguages records the edges between the languages of a mixed analysis in its graph and in CrossLanguage. The
+// C headers reached become modules named by their path, e.g. include/codec.h, declaring their functions.
+func linkLanguages(a *Report) error {
+	headers, err := findCHeaders(a)
+	if err != nil { return err }
+	declaring := make(map[string][]*cHeader) // function -> headers declaring it
+	for _, h := range headers { for fn := range h.functions { declaring[fn] = append(declaring[fn], h) } }
+	byBase := make(map[string][]*cHeader)
+	for _, h := range headers { byBase[filepath.Base(h.path)] = append(byBase[filepath.Base(h.path)], h) }
+
+	pymodules := make(map[string]string) // Python import name -> Rust module defining it
+	for _, rel := range sortedKeys(a.FileModules) {
+		path := filepath.Join(a.Root, filepath.FromSlash(rel))
+		switch FileLanguage(rel) {
+		case "rust":
+			content, err := os.ReadFile(path)
+			if err != nil { return err }
+			code := StripNonCode(string(content))
+			calls := make(map[*cHeader][]string)
+			for _, loc := range externBlockRegex.FindAllStringIndex(code, -1) {
+				end := strings.IndexByte(code[loc[1]:], '}')
+				if end < 0 { continue }
+				for _, m := range externFnRegex.FindAllStringSubmatch(code[loc[1]:loc[1]+end], -1) {
+					for _, h := range declaring[m[1]] { calls[h] = append(calls[h], m[1]) }
+				}
+			}
+			linkHeaders(a, path, rel, calls, `extern "C"`, inferredFFI)
+			for _, m := range pymoduleRegex.FindAllStringSubmatch(code, -1) {
+				name := m[2]
+				if n := pyo3NameRegex.FindStringSubmatch(m[1]); n != nil { name = n[1] }
+				pymodules[name] = a.FileModules[rel]
+			}
+		case "go":
+			content, err := os.ReadFile(path)
+			if err != nil { return err }
+			src := string(content)
+			loc := cgoImportRegex.FindStringIndex(src)
+			if loc == nil { continue }
+			used := make(map[string]struct{})
+			for _, m := range cgoSelectorRegex.FindAllStringSubmatch(src[loc[1]:], -1) { used[m[1]] = struct{}{} }
+			calls := make(map[*cHeader][]string)
+			for _, m := range cgoIncludeRegex.FindAllStringSubmatch(src[:loc[0]], -1) {
+				for _, h := range includedHeaders(filepath.Join(filepath.Dir(path), filepath.FromSlash(m[1])), byBase) {
+					calls[h] = []string{}
+					for fn := range used { if _, ok := h.functions[fn]; ok { calls[h] = append(calls[h], fn) } }
+				}
+			}
+			linkHeaders(a, path, rel, calls, "cgo", inferredCgo)
+		}
+	}
+
+	for _, name := range sortedKeys(pymodules) {
+		items, ok := a.Graph.External[name]
+		if !ok { continue }
+		module := pymodules[name]
+		byFile := make(map[string][]string)
+		for item, files := range items {
+			for file := range files {
+				if FileLanguage(file) != "python" { continue }
+				recordImport(a.Graph, useSite{File: file, IsTest: isTestFile(a.Root, file), Inferred: inferredPyO3}, module, item)
+				byFile[file] = append(byFile[file], item)
+			}
+		}
+		if len(byFile) == 0 { continue }
+		delete(a.Graph.External, name) // the tree's own code, not a dependency
+		for _, file := range sortedKeys(byFile) {
+			sort.Strings(byFile[file])
+			rel := relSlash(a.Root, file)
+			a.CrossLanguage = append(a.CrossLanguage, CrossLanguageEdge{File: rel, From: a.FileModules[rel], To: module, Via: "pyo3", Items: byFile[file]})
+		}
+	}
+	sort.SliceStable(a.CrossLanguage, func(i, j int) bool { return a.CrossLanguage[i].File < a.CrossLanguage[j].File })
+	return nil
+}
+
+// linkHeaders records the file at path using the functions calls lists from each header, or just the header.
+func linkHeaders(a *Report, path, rel string, calls map[*cHeader][]string, via, inferred string) {
+	hs := make([]*cHeader, 0, len(calls))
+	for h := range calls { hs = append(hs, h) }
+	sort.Slice(hs, func(i, j int) bool { return hs[i].rel < hs[j].rel })
+	for _, h := range hs {
+		fns := uniqueStrings(calls[h])
+		if _, known := a.SymbolTable[h.rel]; !known {
+			a.SymbolTable[h.rel] = h.functions
+			a.FileModules[h.rel] = h.rel
+			if content, err := os.ReadFile(h.path); err == nil {
+				for _, line := range strings.Split(StripNonCode(string(content)), "\n") { if strings.TrimSpace(line) != "" { a.Facts.LOC[h.rel]++ } }
+			}
+		}
+		site := useSite{File: path, IsTest: isTestFile(a.Root, path), Inferred: inferred}
+		if len(fns) == 0 { recordImport(a.Graph, site, h.rel, "") }
+		for _, fn := range fns { recordImport(a.Graph, site, h.rel, fn) }
+		a.CrossLanguage = append(a.CrossLanguage, CrossLanguageEdge{File: rel, From: a.FileModules[rel], To: h.rel, Via: via, Items: fns})
+	}
+}
+
+// includedHeaders resolves a quoted #include: the file itself when it exists, else the tree's headers of that name.
+func includedHeaders(path string, byBase map[string][]*cHeader) []*cHeader {
+	for _, h := range byBase[filepath.Base(path)] { if h.path == path { return []*cHeader{h} } }
+	return byBase[filepath.Base(path)]
+}
+
+// findCHeaders reads the function prototypes of every C header the config does not exclude.
+func findCHeaders(a *Report) ([]*cHeader, error) {
+	var headers []*cHeader
+	filter := a.Config.PathFilter(a.Root, a.Options.Exclude...)
+	err := filepath.WalkDir(a.Root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && filter.Skip(path, d.IsDir()) { if d.IsDir() { return filepath.SkipDir }; return nil }
+		if err != nil || d.IsDir() || !IsCHeader(d.Name()) { return err }
+		content, err := os.ReadFile(path)
+		if err != nil { return err }
+		h := &cHeader{path: path, rel: relSlash(a.Root, path), functions: make(map[string]struct{})}
+		for _, m := range cPrototypeRegex.FindAllStringSubmatch(StripNonCode(string(content)), -1) { h.functions[m[1]] = struct{}{} }
+		headers = append(headers, h)
+		return nil
+	})
+	return headers, err
+}
+
+func uniqueStrings(values []string) []string {
+	set := make(map[string]struct{})
+	for _, v := range values { set[v] = struct{}{} }
+	return sortedKeys(set)
+}
@@ -0,0 +1,15 @@
+package analyzer
+
+
+// Path roots that are not third-party crates.
+var nonExternalRoots = map[string]struct{}{"crate": {}, "super": {}, "self": {}, "Self": {}, "std": {}, "core": {}, "alloc": {}}
+
+// recordExternalLeaf records a leaf of `use some_crate::...` as crate -> item -> importing file. Imports of the analyzed
+// crate itself (libName) are internal and skipped, as is a bare `use some_crate;`.
+func recordExternalLeaf(graph *DependencyGraph, file string, leaf useLeaf, libName string) {
+	crate, item := leaf.Path[0], leaf.Path[len(leaf.Path)-1]
+	if _, skip := nonExternalRoots[crate]; skip || crate == libName || len(leaf.Path) < 2 || item == "self" { return }
+	if graph.External[crate] == nil { graph.External[crate] = make(map[string]map[string]struct{}) }
+	if graph.External[crate][item] == nil { graph.External[crate][item] = make(map[string]struct{}) }
+	graph.External[crate][item][file] = struct{}{}
+}
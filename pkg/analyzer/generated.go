@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"strings"
+)
+
+// GeneratedConfig is the [generated] table of dependant.toml. Generated modules are still analyzed, but the report
+// de-emphasizes them and rules skip them unless Enforce is set.
+//
+//	[generated]
+//	headers    = ["@generated", "DO NOT EDIT"]   # text in the leading comment block of a file
+//	attributes = ["#![allow(clippy::all)]"]      # attribute text anywhere in a file
+//	paths      = ["src/proto", "*_generated.rs"] # globs, as for exclude
+//	enforce    = false
+type GeneratedConfig struct {
+	Headers, Attributes, Paths []string
+	Enforce                    bool
+}
+
+// defaultGeneratedHeaders are the conventional markers code generators put in a file's header.
+var defaultGeneratedHeaders = []string{"@generated", "DO NOT EDIT", "Automatically generated"}
+
+// leadingComments returns the comment lines (and blank lines) at the top of a file, where generators leave their mark.
+func leadingComments(content string) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "//") && !strings.HasPrefix(trimmed, "/*") && !strings.HasPrefix(trimmed, "*") { break }
+		sb.WriteString(trimmed + "\n")
+	}
+	return sb.String()
+}
+
+// isGeneratedFile reports whether a file matches any generated-code marker.
+func (g GeneratedConfig) isGeneratedFile(root, path, content string) bool {
+	if matchesGlobs(root, path, g.Paths) { return true }
+	header := leadingComments(content)
+	for _, marker := range g.Headers { if strings.Contains(header, marker) { return true } }
+	for _, attr := range g.Attributes { if strings.Contains(content, attr) { return true } }
+	return false
+}
+
+// Enforced reports whether rules and regression checks apply to module: everything except generated modules,
+// unless [generated] enforce is set.
+func (a *Report) Enforced(module string) bool {
+	return !a.Facts.Generated[module] || a.Config.Generated.Enforce
+}
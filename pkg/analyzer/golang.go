@@ -0,0 +1,271 @@
+package analyzer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// The Go backend maps packages onto modules: every directory of .go files is a module named after the directory, the
+// module root's package after the module path, and its exported identifiers are the module's public items. Imports of
+// the module's own packages are internal edges with the selectors used through them (`store.Open`) as items; imports
+// of required modules are external crates, and the standard library is skipped as std is for Rust.
+type goAnalyzer struct{}
+
+func (goAnalyzer) Name() string { return "go" }
+
+var goMajorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// goPackageBase is the name code refers to an import path by when it does not rename it: its last element, skipping a
+// /vN major version suffix.
+func goPackageBase(importPath string) string {
+	base := path.Base(importPath)
+	if goMajorVersion.MatchString(base) && path.Dir(importPath) != "." { base = path.Base(path.Dir(importPath)) }
+	return base
+}
+
+// goPackage names the package a .go file belongs to, honoring --aggregate dir and --depth as for Rust modules.
+func (n *moduleNaming) goPackage(file string) string {
+	rel := relSlash(n.root, filepath.Dir(file))
+	if rel == "." || rel == "" {
+		if n.goRoot != "" { return n.goRoot }
+		return filepath.Base(filepath.Dir(file))
+	}
+	parts := strings.Split(rel, "/")
+	switch {
+	case n.aggregate: return parts[0]
+	case n.depth > 0 && len(parts) > n.depth: return parts[n.depth-1]
+	}
+	return parts[len(parts)-1]
+}
+
+// loadGoModule reads root/go.mod into the manifest model: the module path is the crate name and every required module
+// a dependency, with its version as a locked package. A replace pointing at a local directory makes it a path source.
+func loadGoModule(root string) (*CargoManifest, []LockedPackage, error) {
+	m := &CargoManifest{Library: true, Dependencies: make(map[string]string)}
+	file, err := os.Open(filepath.Join(root, "go.mod"))
+	if errors.Is(err, os.ErrNotExist) { return m, nil, nil }
+	if err != nil { return nil, nil, err }
+	defer file.Close()
+	var lock []LockedPackage
+	local := make(map[string]bool)
+	block := ""
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+		if i := strings.Index(line, "//"); i >= 0 { line = strings.TrimSpace(line[:i]) }
+		fields := strings.Fields(line)
+		if len(fields) == 0 { continue }
+		if block != "" {
+			if fields[0] == ")" { block = ""; continue }
+			fields = append([]string{block}, fields...)
+		} else if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+		switch {
+		case fields[0] == "module" && len(fields) >= 2: m.Name = strings.Trim(fields[1], `"`)
+		case fields[0] == "require" && len(fields) >= 3:
+			m.Dependencies[fields[1]] = fields[1]
+			lock = append(lock, LockedPackage{Name: fields[1], Version: fields[2], Source: "proxy.golang.org"})
+		case fields[0] == "replace":
+			if arrow := slices.Index(fields, "=>"); arrow > 1 && arrow+1 < len(fields) && (strings.HasPrefix(fields[arrow+1], ".") || filepath.IsAbs(fields[arrow+1])) { local[fields[1]] = true }
+		}
+	}
+	for i := range lock { if local[lock[i].Name] { lock[i].Source = "" } }
+	m.LibName = m.Name
+	return m, lock, lines.Err()
+}
+
+func (goAnalyzer) Load(a *Report) error {
+	var err error
+	if a.Manifest, a.Lockfile, err = loadGoModule(a.Root); err != nil { return fmt.Errorf("reading go.mod: %w", err) }
+	return nil
+}
+
+// walkGoFiles parses every .go file the filter keeps. A file with syntax errors is still visited with the partial
+// tree the parser recovered, along with the error.
+func walkGoFiles(a *Report, visit func(path string, content []byte, file *ast.File, parseErr error)) error {
+	filter := a.Config.PathFilter(a.Root)
+	return filepath.WalkDir(a.Root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && filter.Skip(path, d.IsDir()) { if d.IsDir() { return filepath.SkipDir }; return nil }
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".go") { return err }
+		content, err := os.ReadFile(path)
+		if err != nil { return err }
+		fset := token.NewFileSet()
+		file, parseErr := parser.ParseFile(fset, path, content, parser.ParseComments|parser.SkipObjectResolution)
+		if file == nil { return nil }
+		visit(path, content, file, parseErr)
+		return nil
+	})
+}
+
+func (goAnalyzer) SymbolTable(a *Report) (map[string]map[string]struct{}, *ModuleFacts, error) {
+	table := make(map[string]map[string]struct{})
+	facts := &ModuleFacts{Tags: make(map[string]string), UnsafeBlocks: make(map[string]int), UnsafeFns: make(map[string]int), LOC: make(map[string]int), Generated: make(map[string]bool), ReExports: make(map[string]map[string]ReExport), ReExportGlobs: make(map[string][]string), Restricted: make(map[string]map[string]string), ModulePaths: make(map[string]string)}
+	err := walkGoFiles(a, func(path string, content []byte, file *ast.File, _ error) {
+		moduleName := a.ModuleName(path)
+		if _, ok := table[moduleName]; !ok { table[moduleName] = make(map[string]struct{}) }
+		if rel := relSlash(a.Root, filepath.Dir(path)); rel != "." { facts.ModulePaths[strings.ReplaceAll(rel, "/", "::")] = moduleName }
+		generated := a.Config.Generated.isGeneratedFile(a.Root, path, string(content))
+		if seen, ok := facts.Generated[moduleName]; !ok || seen { facts.Generated[moduleName] = generated }
+		if m := tagMarkerRegex.FindStringSubmatch(string(content)); m != nil { facts.Tags[moduleName] = m[1] }
+		facts.LOC[moduleName] += goCodeLines(content)
+		// unsafe.Pointer and friends are Go's escape hatch: each one a file uses counts as an unsafe block would in Rust.
+		if local := goImportName(file, "unsafe"); local != "" { facts.UnsafeBlocks[moduleName] += len(goSelectors(file)[local]) }
+		if strings.HasSuffix(path, "_test.go") { return } // test helpers are not part of the package's API
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil && decl.Name.IsExported() { table[moduleName][decl.Name.Name] = struct{}{} }
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						if spec.Name.IsExported() { table[moduleName][spec.Name.Name] = struct{}{} }
+					case *ast.ValueSpec:
+						for _, name := range spec.Names { if name.IsExported() { table[moduleName][name.Name] = struct{}{} } }
+					}
+				}
+			}
+		}
+	})
+	return table, facts, err
+}
+
+// goCodeLines counts the lines holding at least one token other than a comment.
+func goCodeLines(content []byte) int {
+	fset := token.NewFileSet()
+	var s scanner.Scanner
+	s.Init(fset.AddFile("", fset.Base(), len(content)), content, nil, 0)
+	lines := make(map[int]struct{})
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF { break }
+		if tok == token.SEMICOLON && lit == "\n" { continue } // inserted at line ends
+		lines[fset.Position(pos).Line] = struct{}{}
+		if tok == token.STRING { // a raw string spans every line it covers
+			for i := 1; i <= strings.Count(lit, "\n"); i++ { lines[fset.Position(pos).Line+i] = struct{}{} }
+		}
+	}
+	return len(lines)
+}
+
+// goImportName is the name file refers to importPath by, "" when it does not import it or imports it for effect only.
+func goImportName(file *ast.File, importPath string) string {
+	for _, spec := range file.Imports {
+		if p, err := strconv.Unquote(spec.Path.Value); err != nil || p != importPath { continue }
+		if spec.Name == nil { return goPackageBase(importPath) }
+		if spec.Name.Name == "_" { return "" }
+		return spec.Name.Name
+	}
+	return ""
+}
+
+// goSelectors collects the exported selectors file uses on each bare identifier: `store.Open` gives store -> {Open}.
+func goSelectors(file *ast.File) map[string]map[string]struct{} {
+	selectors := make(map[string]map[string]struct{})
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok || !sel.Sel.IsExported() { return true }
+		if x, ok := sel.X.(*ast.Ident); ok {
+			if selectors[x.Name] == nil { selectors[x.Name] = make(map[string]struct{}) }
+			selectors[x.Name][sel.Sel.Name] = struct{}{}
+		}
+		return true
+	})
+	return selectors
+}
+
+// goBuildConstraint is the file's //go:build expression, which gates its imports as a cfg predicate gates a Rust use.
+func goBuildConstraint(file *ast.File) string {
+	for _, group := range file.Comments {
+		if group.Pos() > file.Package { break }
+		for _, c := range group.List {
+			if expr, err := constraint.Parse(c.Text); err == nil && constraint.IsGoBuild(c.Text) { return expr.String() }
+		}
+	}
+	return ""
+}
+
+func (goAnalyzer) Dependencies(a *Report) (*DependencyGraph, error) {
+	graph := &DependencyGraph{
+		Deps:        make(map[string]map[string]struct{}),
+		ProdDeps:    make(map[string]map[string]struct{}),
+		ItemImports: make(map[string]map[string]map[string]struct{}),
+		Conditions:  make(map[string]map[string]map[string]struct{}),
+		External:    make(map[string]map[string]map[string]struct{}),
+		Inferred:    make(map[string]map[string]map[string]string),
+	}
+	modPath := a.Manifest.Name
+	err := walkGoFiles(a, func(path string, content []byte, file *ast.File, parseErr error) {
+		if parseErr != nil {
+			var list scanner.ErrorList
+			if errors.As(parseErr, &list) && len(list) > 0 {
+				graph.Unparsed = append(graph.Unparsed, UnparsedUse{File: path, Line: list[0].Pos.Line, Statement: "(file)", Err: list[0].Msg})
+			}
+		}
+		site := useSite{File: path, Content: string(content), IsTest: strings.HasSuffix(path, "_test.go")}
+		if cond := goBuildConstraint(file); cond != "" { site.Cfgs = []string{cond} }
+		if a.leavesOut(site) { return }
+		selectors := goSelectors(file)
+		for _, spec := range file.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil { continue }
+			local := goPackageBase(importPath)
+			if spec.Name != nil { local = spec.Name.Name }
+			internal := modPath != "" && (importPath == modPath || strings.HasPrefix(importPath, modPath+"/"))
+			if !internal {
+				if strings.Contains(strings.Split(importPath, "/")[0], ".") { recordGoExternal(graph, a.Manifest, importPath, selectors[local], path) }
+				continue
+			}
+			dir := filepath.Join(a.Root, filepath.FromSlash(strings.TrimPrefix(strings.TrimPrefix(importPath, modPath), "/")))
+			module := a.ModuleName(filepath.Join(dir, "x.go"))
+			items, itemSite := selectors[local], site
+			if local == "." { items, itemSite.Inferred = goDotImported(file, a.SymbolTable[module]), inferredDotImport }
+			if local == "_" || len(items) == 0 { recordImport(graph, site, module, ""); continue }
+			for item := range items { recordImport(graph, itemSite, module, item) }
+		}
+	})
+	return graph, err
+}
+
+// goDotImported finds the identifiers of a dot-imported package's API that file uses unqualified.
+func goDotImported(file *ast.File, exported map[string]struct{}) map[string]struct{} {
+	used := make(map[string]struct{})
+	ast.Inspect(file, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok { if _, ok := exported[id.Name]; ok { used[id.Name] = struct{}{} } }
+		return true
+	})
+	return used
+}
+
+// recordGoExternal records the selectors used through an imported third-party package under the required module that
+// provides it. Items of a package below the module's root are qualified by it: `github.com/x/y/z` under
+// github.com/x/y gives z.Item.
+func recordGoExternal(graph *DependencyGraph, manifest *CargoManifest, importPath string, items map[string]struct{}, file string) {
+	crate := ""
+	for dep := range manifest.Dependencies {
+		if (importPath == dep || strings.HasPrefix(importPath, dep+"/")) && len(dep) > len(crate) { crate = dep }
+	}
+	if crate == "" { crate = importPath }
+	for item := range items {
+		name := item
+		if crate != importPath { name = goPackageBase(importPath) + "." + item }
+		if graph.External[crate] == nil { graph.External[crate] = make(map[string]map[string]struct{}) }
+		if graph.External[crate][name] == nil { graph.External[crate][name] = make(map[string]struct{}) }
+		graph.External[crate][name][file] = struct{}{}
+	}
+}
@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"bufio"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRule is one pattern line of a .gitignore, relative to the directory holding that file.
+type ignoreRule struct {
+	base     string // directory of the .gitignore, slash-separated and relative to the root ("" for the root)
+	pattern  string
+	negate   bool // `!pattern` re-includes what earlier rules excluded
+	dirOnly  bool // `pattern/` matches directories only
+	anchored bool // a slash before the end anchors the pattern to base
+}
+
+// PathFilter decides which paths the analysis walks skip: Config.Exclude and --exclude globs, every .gitignore in the
+// tree (unless gitignore = false in dependant.toml), Cargo build directories and VCS metadata.
+type PathFilter struct {
+	root      string
+	exclude   []string
+	gitignore bool
+	rules     []ignoreRule
+	loaded    map[string]bool // directories whose .gitignore has been read
+}
+
+func NewPathFilter(root string, exclude []string, gitignore bool) *PathFilter {
+	return &PathFilter{root: root, exclude: exclude, gitignore: gitignore, loaded: make(map[string]bool)}
+}
+
+// PathFilter builds the filter for walking root under this config, plus any extra globs.
+func (c *Config) PathFilter(root string, extra ...string) *PathFilter {
+	return NewPathFilter(root, append(append([]string{}, c.Exclude...), extra...), c.Gitignore)
+}
+
+// Skip reports whether path should be left out. Walks visit a directory before its contents, which is when its
+// .gitignore is read, so rules are in place by the time they apply.
+func (f *PathFilter) Skip(path string, isDir bool) bool {
+	rel, err := filepath.Rel(f.root, path)
+	if err != nil || rel == "." {
+		if isDir { f.load(path, "") }
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	name := pathpkg.Base(rel)
+	if isDir && (name == ".git" || name == ".hg" || name == ".svn" || name == "node_modules" || name == "__pycache__") { return true }
+	if isDir && name == "target" {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(path), "Cargo.toml")); err == nil { return true } // cargo build output
+	}
+	if isDir && (strings.HasPrefix(name, ".") || name == "venv" || name == "env") {
+		if _, err := os.Stat(filepath.Join(path, "pyvenv.cfg")); err == nil { return true } // Python virtual environment
+	}
+	if matchesGlobs(f.root, path, f.exclude) { return true }
+	if f.ignored(rel, isDir) { return true }
+	if isDir { f.load(path, rel) }
+	return false
+}
+
+func (f *PathFilter) load(dir, rel string) {
+	if !f.gitignore || f.loaded[rel] { return }
+	f.loaded[rel] = true
+	file, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil { return }
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") { continue }
+		rule := ignoreRule{base: rel}
+		if strings.HasPrefix(line, "!") { rule.negate, line = true, line[1:] }
+		line = strings.TrimPrefix(line, `\`) // escaped leading ! or #
+		if strings.HasSuffix(line, "/") { rule.dirOnly, line = true, strings.TrimSuffix(line, "/") }
+		rule.anchored = strings.Contains(line, "/")
+		rule.pattern = strings.TrimPrefix(line, "/")
+		if rule.pattern != "" { f.rules = append(f.rules, rule) }
+	}
+}
+
+// ignored applies the gitignore rules to rel in order; as in git, the last matching rule wins.
+func (f *PathFilter) ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, r := range f.rules {
+		if r.dirOnly && !isDir { continue }
+		sub := rel
+		if r.base != "" {
+			if !strings.HasPrefix(rel, r.base+"/") { continue }
+			sub = strings.TrimPrefix(rel, r.base+"/")
+		}
+		var matched bool
+		if r.anchored { matched = globMatch(r.pattern, sub) } else { matched = globMatch(r.pattern, pathpkg.Base(sub)) }
+		if matched { ignored = !r.negate }
+	}
+	return ignored
+}
+
+// globMatch is path.Match extended with gitignore's `**`, which matches any number of directories.
+func globMatch(pattern, name string) bool {
+	if !strings.Contains(pattern, "**") { ok, _ := pathpkg.Match(pattern, name); return ok }
+	head, tail, _ := strings.Cut(pattern, "**")
+	tail = strings.TrimPrefix(tail, "/")
+	parts := strings.Split(name, "/")
+	for i := 0; i <= len(parts); i++ {
+		prefix := strings.Join(parts[:i], "/")
+		if head != "" {
+			if ok, _ := pathpkg.Match(strings.TrimSuffix(head, "/"), prefix); !ok { continue }
+		}
+		for j := i; j <= len(parts); j++ {
+			if globMatch(tail, strings.Join(parts[j:], "/")) { return true }
+		}
+		if head == "" { break }
+	}
+	return false
+}
@@ -0,0 +1,451 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// The TypeScript/JavaScript backend treats every source file as a module, named by its path below the root (or below
+// src/) without the extension, an index file by its directory: src/components/Button.tsx is components/Button and
+// src/components/index.ts is components. Exported declarations are the module's public items, and `import` / `export
+// ... from` statements and require() calls are its imports, with the names they pull in as items. Relative paths,
+// index files, tsconfig.json paths and workspace packages resolve to files of the tree; other packages are external
+// crates, and Node's built-in modules are skipped as std is for Rust.
+type jsAnalyzer struct{}
+
+func (jsAnalyzer) Name() string { return "js" }
+
+// jsExtensions are the file suffixes the backend reads, in the order a bare import path tries them.
+var jsExtensions = []string{".ts", ".tsx", ".d.ts", ".mts", ".cts", ".js", ".jsx", ".mjs", ".cjs"}
+
+func IsJSFile(name string) bool {
+	for _, ext := range jsExtensions { if strings.HasSuffix(name, ext) { return true } }
+	return false
+}
+
+// jsModule names the module a TS/JS file is, honoring --aggregate dir (the first directory) and --depth (the
+// first that many path segments). Files of a workspace package are named under the package: packages/ui/src/index.ts
+// is @acme/ui and packages/ui/src/card.ts @acme/ui/card.
+func (n *moduleNaming) jsModule(file string) string {
+	rel := relSlash(n.root, file)
+	for dir, name := range n.jsPackages {
+		if !strings.HasPrefix(rel, dir+"/") { continue }
+		sub := strings.TrimPrefix(strings.TrimPrefix(rel, dir+"/"), "src/")
+		for _, ext := range jsExtensions { if strings.HasSuffix(sub, ext) { sub = strings.TrimSuffix(sub, ext); break } }
+		if sub == "index" { return name }
+		return name + "/" + strings.TrimSuffix(sub, "/index")
+	}
+	rel = strings.TrimPrefix(rel, "src/")
+	for _, ext := range jsExtensions { if strings.HasSuffix(rel, ext) { rel = strings.TrimSuffix(rel, ext); break } }
+	if rel == "index" {
+		if n.jsRoot != "" { return n.jsRoot }
+		return rel
+	}
+	rel = strings.TrimSuffix(rel, "/index")
+	parts := strings.Split(rel, "/")
+	switch {
+	case n.aggregate && len(parts) > 1: return parts[0]
+	case n.depth > 0 && len(parts) > n.depth: return strings.Join(parts[:n.depth], "/")
+	}
+	return rel
+}
+
+// packageJSON is the part of a package.json the backend reads.
+type packageJSON struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Main                 string            `json:"main"`
+	Module               string            `json:"module"`
+	Source               string            `json:"source"`
+	Types                string            `json:"types"`
+	Exports              json.RawMessage   `json:"exports"`
+	Workspaces           json.RawMessage   `json:"workspaces"` // ["packages/*"] or {"packages": ["packages/*"]}
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+func readPackageJSON(path string) (*packageJSON, error) {
+	content, err := os.ReadFile(path)
+	if err != nil { return nil, err }
+	var pkg packageJSON
+	if err := json.Unmarshal(content, &pkg); err != nil { return nil, fmt.Errorf("%s: %w", path, err) }
+	return &pkg, nil
+}
+
+// workspaceGlobs are the directories, as globs relative to the package, holding the packages of a workspace.
+func (p *packageJSON) workspaceGlobs() []string {
+	var globs []string
+	if json.Unmarshal(p.Workspaces, &globs) == nil { return globs }
+	var nested struct{ Packages []string `json:"packages"` }
+	json.Unmarshal(p.Workspaces, &nested)
+	return nested.Packages
+}
+
+// loadPackageManifest reads root/package.json into the manifest model, and package-lock.json into locked packages. A
+// missing package.json yields a zero manifest and no error.
+func loadPackageManifest(root string) (*CargoManifest, []LockedPackage, error) {
+	m := &CargoManifest{Dependencies: make(map[string]string)}
+	pkg, err := readPackageJSON(filepath.Join(root, "package.json"))
+	if errors.Is(err, os.ErrNotExist) { return m, nil, nil }
+	if err != nil { return nil, nil, err }
+	m.Name, m.Version, m.LibName = pkg.Name, pkg.Version, pkg.Name
+	m.Library = pkg.Main != "" || pkg.Module != "" || pkg.Types != "" || len(pkg.Exports) > 0
+	for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies, pkg.PeerDependencies, pkg.OptionalDependencies} {
+		for name := range deps { m.Dependencies[name] = name }
+	}
+	var lock struct {
+		Packages map[string]struct {
+			Version  string `json:"version"`
+			Resolved string `json:"resolved"`
+		} `json:"packages"`
+	}
+	content, err := os.ReadFile(filepath.Join(root, "package-lock.json"))
+	if errors.Is(err, os.ErrNotExist) { return m, nil, nil }
+	if err != nil { return nil, nil, err }
+	if err := json.Unmarshal(content, &lock); err != nil { return nil, nil, fmt.Errorf("package-lock.json: %w", err) }
+	var locked []LockedPackage
+	for _, key := range sortedKeys(lock.Packages) {
+		i := strings.LastIndex(key, "node_modules/")
+		if i < 0 { continue }
+		entry := lock.Packages[key]
+		locked = append(locked, LockedPackage{Name: key[i+len("node_modules/"):], Version: entry.Version, Source: entry.Resolved})
+	}
+	return m, locked, nil
+}
+
+func (jsAnalyzer) Load(a *Report) error {
+	var err error
+	if a.Manifest, a.Lockfile, err = loadPackageManifest(a.Root); err != nil { return fmt.Errorf("reading package.json: %w", err) }
+	return nil
+}
+
+// jsStripComments blanks out line and block comments, keeping string and template literals, since import specifiers
+// are strings. Like StripNonCode it keeps newlines and the length of src.
+func jsStripComments(src string) string {
+	out := []byte(src)
+	for i := 0; i < len(src); {
+		switch c := src[i]; {
+		case strings.HasPrefix(src[i:], "//"):
+			for ; i < len(src) && src[i] != '\n'; i++ { out[i] = ' ' }
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			end = i + 2 + end + 2
+			if end < i+4 { end = len(src) }
+			for j := i; j < end; j++ { if out[j] != '\n' { out[j] = ' ' } }
+			i = end
+		case c == '"' || c == '\'' || c == '`':
+			j := i + 1
+			for ; j < len(src) && src[j] != c; j++ {
+				if src[j] == '\\' { j++ } else if src[j] == '\n' && c != '`' { break }
+			}
+			i = j + 1
+		default:
+			i++
+		}
+	}
+	return string(out)
+}
+
+// jsSpecifierContext matches the code in front of a string literal that is an import specifier: `from`, a bare
+// `import`, `require(` or `import(`.
+var jsSpecifierContext = regexp.MustCompile(`(?:\bfrom|\bimport|\b(?:require|import)\s*\()\s*$`)
+
+// jsMaskLiterals blanks the contents of string and template literals in code whose comments are already stripped,
+// keeping import specifiers, so `"import x from 'y'"` inside a string is not read as an import. Like jsStripComments
+// it keeps newlines and the length of code.
+func jsMaskLiterals(code string) string {
+	out := []byte(code)
+	for i := 0; i < len(code); {
+		c := code[i]
+		if c != '"' && c != '\'' && c != '`' { i++; continue }
+		j := i + 1
+		for ; j < len(code) && code[j] != c; j++ {
+			if code[j] == '\\' { j++ } else if code[j] == '\n' && c != '`' { break }
+		}
+		if c == '`' || !jsSpecifierContext.MatchString(code[max(0, i-32):i]) {
+			for k := i + 1; k < min(j, len(code)); k++ { if out[k] != '\n' { out[k] = ' ' } }
+		}
+		i = j + 1
+	}
+	return string(out)
+}
+
+var (
+	jsImportFromRegex  = regexp.MustCompile(`\bimport\s+(?:type\s+)?([\w$*{},\s]+?)\s*from\s*['"]([^'"\n]+)['"]`)
+	jsImportBareRegex  = regexp.MustCompile(`\bimport\s*['"]([^'"\n]+)['"]`)
+	jsExportFromRegex  = regexp.MustCompile(`\bexport\s+(?:type\s+)?(\*(?:\s+as\s+[\w$]+)?|\{[^}]*\})\s*from\s*['"]([^'"\n]+)['"]`)
+	jsRequireRegex     = regexp.MustCompile(`(?:\b(?:const|let|var)\s+(\{[^}]*\}|[\w$]+)\s*=\s*)?\brequire\s*\(\s*['"]([^'"\n]+)['"]\s*\)`)
+	jsDynamicRegex     = regexp.MustCompile(`\bimport\s*\(\s*['"]([^'"\n]+)['"]\s*\)`)
+	jsExportDeclRegex  = regexp.MustCompile(`\bexport\s+(?:declare\s+)?(default\s+)?(?:async\s+)?(?:abstract\s+)?(?:function\s*\*?|class|const|let|var|interface|type|enum|namespace)\s+([\w$]+)`)
+	jsExportDefault    = regexp.MustCompile(`\bexport\s+default\b`)
+	jsExportListRegex  = regexp.MustCompile(`\bexport\s+(?:type\s+)?\{([^}]*)\}`)
+	jsCommonJSExport   = regexp.MustCompile(`\b(?:module\.)?exports\.([\w$]+)\s*=[^=]`)
+	jsIdentifierRegex  = regexp.MustCompile(`^[\w$]+$`)
+)
+
+// jsImportClause is what an import binds: `Button, { useState as useS, type Props }` imports default and the names
+// useState and Props; `* as api` binds the namespace api, whose members are read from `api.member` in the file.
+type jsImportClause struct {
+	named     []string // imported names, default included
+	namespace string   // local name of a namespace import or a whole-module require
+}
+
+func parseJSImportClause(clause string) jsImportClause {
+	var c jsImportClause
+	if open := strings.IndexByte(clause, '{'); open >= 0 {
+		end := strings.IndexByte(clause[open:], '}')
+		if end < 0 { end = len(clause) - open }
+		for _, spec := range strings.Split(clause[open+1:open+end], ",") {
+			fields := strings.Fields(strings.ReplaceAll(spec, ":", " : "))
+			if len(fields) > 0 && fields[0] == "type" && len(fields) > 1 { fields = fields[1:] }
+			if len(fields) > 0 && jsIdentifierRegex.MatchString(fields[0]) { c.named = append(c.named, fields[0]) }
+		}
+		clause = clause[:open] + clause[min(open+end+1, len(clause)):]
+	}
+	for _, part := range strings.Split(clause, ",") {
+		fields := strings.Fields(part)
+		switch {
+		case len(fields) == 3 && fields[0] == "*" && fields[1] == "as": c.namespace = fields[2]
+		case len(fields) == 1 && jsIdentifierRegex.MatchString(fields[0]): c.named = append(c.named, "default")
+		}
+	}
+	return c
+}
+
+// jsExportedNames lists the exported names of an `export { a, b as c }` list: a and c.
+func jsExportedNames(list string) []string {
+	var names []string
+	for _, spec := range strings.Split(list, ",") {
+		fields := strings.Fields(spec)
+		if len(fields) > 0 && fields[0] == "type" && len(fields) > 1 { fields = fields[1:] }
+		if len(fields) == 0 { continue }
+		name := fields[len(fields)-1]
+		if jsIdentifierRegex.MatchString(name) { names = append(names, name) }
+	}
+	return names
+}
+
+// walkJSFiles visits every TS/JS file the filter keeps with its content, comments and literals other than import
+// specifiers blanked (see jsMaskLiterals).
+func walkJSFiles(a *Report, visit func(path, content, code string)) error {
+	filter := a.Config.PathFilter(a.Root)
+	return filepath.WalkDir(a.Root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && filter.Skip(path, d.IsDir()) { if d.IsDir() { return filepath.SkipDir }; return nil }
+		if err != nil || d.IsDir() || !IsJSFile(d.Name()) { return err }
+		content, err := os.ReadFile(path)
+		if err != nil { return err }
+		visit(path, string(content), jsMaskLiterals(jsStripComments(string(content))))
+		return nil
+	})
+}
+
+func (jsAnalyzer) SymbolTable(a *Report) (map[string]map[string]struct{}, *ModuleFacts, error) {
+	table := make(map[string]map[string]struct{})
+	facts := &ModuleFacts{Tags: make(map[string]string), UnsafeBlocks: make(map[string]int), UnsafeFns: make(map[string]int), LOC: make(map[string]int), Generated: make(map[string]bool), ReExports: make(map[string]map[string]ReExport), ReExportGlobs: make(map[string][]string), Restricted: make(map[string]map[string]string), ModulePaths: make(map[string]string)}
+	err := walkJSFiles(a, func(path, content, code string) {
+		moduleName := a.ModuleName(path)
+		if _, ok := table[moduleName]; !ok { table[moduleName] = make(map[string]struct{}) }
+		generated := a.Config.Generated.isGeneratedFile(a.Root, path, content)
+		if seen, ok := facts.Generated[moduleName]; !ok || seen { facts.Generated[moduleName] = generated }
+		if m := tagMarkerRegex.FindStringSubmatch(content); m != nil { facts.Tags[moduleName] = m[1] }
+		for _, line := range strings.Split(code, "\n") { if strings.TrimSpace(line) != "" { facts.LOC[moduleName]++ } }
+		if jsIsTestFile(a.Root, path) { return }
+		if jsExportDefault.MatchString(code) { table[moduleName]["default"] = struct{}{} }
+		for _, m := range jsExportDeclRegex.FindAllStringSubmatch(code, -1) { if m[1] == "" { table[moduleName][m[2]] = struct{}{} } }
+		for _, m := range jsExportListRegex.FindAllStringSubmatch(code, -1) { for _, name := range jsExportedNames(m[1]) { table[moduleName][name] = struct{}{} } }
+		for _, m := range jsCommonJSExport.FindAllStringSubmatch(code, -1) { table[moduleName][m[1]] = struct{}{} }
+	})
+	return table, facts, err
+}
+
+// jsIsTestFile reports whether path is a test: *.test.* and *.spec.* files, and anything under __tests__ or tests/.
+func jsIsTestFile(root, path string) bool {
+	base := filepath.Base(path)
+	if strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") || isTestFile(root, path) { return true }
+	return slices.Contains(strings.Split(relSlash(root, path), "/"), "__tests__")
+}
+
+// nodeBuiltins are Node's own modules, importable without the node: prefix.
+var nodeBuiltins = map[string]struct{}{
+	"assert": {}, "buffer": {}, "child_process": {}, "cluster": {}, "crypto": {}, "dgram": {}, "dns": {}, "events": {}, "fs": {},
+	"http": {}, "http2": {}, "https": {}, "module": {}, "net": {}, "os": {}, "path": {}, "perf_hooks": {}, "process": {},
+	"querystring": {}, "readline": {}, "stream": {}, "string_decoder": {}, "timers": {}, "tls": {}, "tty": {}, "url": {},
+	"util": {}, "v8": {}, "vm": {}, "worker_threads": {}, "zlib": {},
+}
+
+// jsResolver turns import specifiers into files of the tree, following tsconfig.json paths and workspace packages.
+type jsResolver struct {
+	root     string
+	baseURL  string              // compilerOptions.baseUrl, absolute; "" when unset
+	paths    map[string][]string // compilerOptions.paths, e.g. "@/*" -> ["src/*"], relative to baseURL (or root)
+	packages map[string]string   // workspace (and root) package name -> its directory
+	entries  map[string]string   // package directory -> its entry file from package.json, when it names one
+}
+
+func newJSResolver(root string) *jsResolver {
+	r := &jsResolver{root: root, paths: make(map[string][]string), packages: make(map[string]string), entries: make(map[string]string)}
+	if content, err := os.ReadFile(filepath.Join(root, "tsconfig.json")); err == nil {
+		var tsconfig struct {
+			CompilerOptions struct {
+				BaseURL string              `json:"baseUrl"`
+				Paths   map[string][]string `json:"paths"`
+			} `json:"compilerOptions"`
+		}
+		if json.Unmarshal([]byte(jsStripComments(string(content))), &tsconfig) == nil {
+			if tsconfig.CompilerOptions.BaseURL != "" { r.baseURL = filepath.Join(root, tsconfig.CompilerOptions.BaseURL) }
+			r.paths = tsconfig.CompilerOptions.Paths
+		}
+	}
+	addPackage := func(dir string) *packageJSON {
+		pkg, err := readPackageJSON(filepath.Join(dir, "package.json"))
+		if err != nil || pkg.Name == "" { return pkg }
+		r.packages[pkg.Name] = dir
+		for _, entry := range []string{pkg.Source, pkg.Module, pkg.Main, pkg.Types} { if entry != "" { r.entries[dir] = filepath.Join(dir, entry); break } }
+		return pkg
+	}
+	if rootPkg := addPackage(root); rootPkg != nil {
+		for _, glob := range rootPkg.workspaceGlobs() {
+			dirs, _ := filepath.Glob(filepath.Join(root, strings.ReplaceAll(glob, "**", "*")))
+			for _, dir := range dirs { addPackage(dir) }
+		}
+	}
+	return r
+}
+
+// resolveFile finds the file an extensionless path, a path to a directory or a .js path written for a .ts file names.
+func resolveJSFile(base string) string {
+	if info, err := os.Stat(base); err == nil && !info.IsDir() && IsJSFile(base) { return base }
+	stem := base
+	for _, ext := range []string{".js", ".jsx", ".mjs", ".cjs"} { if strings.HasSuffix(base, ext) { stem = strings.TrimSuffix(base, ext); break } }
+	for _, candidate := range []string{stem, filepath.Join(base, "index")} {
+		for _, ext := range jsExtensions {
+			if info, err := os.Stat(candidate + ext); err == nil && !info.IsDir() { return candidate + ext }
+		}
+	}
+	return ""
+}
+
+// resolve returns the file spec names when it is part of the tree, else the external package it belongs to ("" for
+// Node built-ins). A relative specifier that names no file returns neither.
+func (r *jsResolver) resolve(from, spec string) (file, pkg string) {
+	if strings.HasPrefix(spec, ".") || strings.HasPrefix(spec, "/") {
+		if strings.HasPrefix(spec, "/") { return resolveJSFile(spec), "" }
+		return resolveJSFile(filepath.Join(filepath.Dir(from), filepath.FromSlash(spec))), ""
+	}
+	base := r.baseURL
+	if base == "" { base = r.root }
+	for pattern, targets := range r.paths {
+		prefix, suffix, wildcard := strings.Cut(pattern, "*")
+		if !wildcard && spec != pattern { continue }
+		if wildcard && (!strings.HasPrefix(spec, prefix) || !strings.HasSuffix(spec, suffix) || len(spec) < len(prefix)+len(suffix)) { continue }
+		match := strings.TrimSuffix(strings.TrimPrefix(spec, prefix), suffix)
+		for _, target := range targets {
+			if f := resolveJSFile(filepath.Join(base, filepath.FromSlash(strings.Replace(target, "*", match, 1)))); f != "" { return f, "" }
+		}
+	}
+	if r.baseURL != "" {
+		if f := resolveJSFile(filepath.Join(r.baseURL, filepath.FromSlash(spec))); f != "" { return f, "" }
+	}
+	name, sub := spec, ""
+	if parts := strings.SplitN(spec, "/", 3); strings.HasPrefix(spec, "@") && len(parts) >= 2 {
+		name = parts[0] + "/" + parts[1]
+		if len(parts) == 3 { sub = parts[2] }
+	} else if before, after, ok := strings.Cut(spec, "/"); ok {
+		name, sub = before, after
+	}
+	if dir, ok := r.packages[name]; ok {
+		if sub != "" {
+			for _, candidate := range []string{filepath.Join(dir, sub), filepath.Join(dir, "src", sub)} { if f := resolveJSFile(candidate); f != "" { return f, "" } }
+		} else {
+			for _, candidate := range []string{r.entries[dir], filepath.Join(dir, "src", "index"), filepath.Join(dir, "index")} {
+				if candidate == "" { continue }
+				if f := resolveJSFile(candidate); f != "" { return f, "" }
+			}
+		}
+	}
+	if _, builtin := nodeBuiltins[name]; builtin || strings.HasPrefix(spec, "node:") { return "", "" }
+	return "", name
+}
+
+// jsSelectors lists the members code reads through the local name ns: `api.get` gives get.
+func jsSelectors(code, ns string) []string {
+	seen := make(map[string]struct{})
+	for _, m := range regexp.MustCompile(`(?:^|[^\w$.])`+regexp.QuoteMeta(ns)+`\.([\w$]+)`).FindAllStringSubmatch(code, -1) { seen[m[1]] = struct{}{} }
+	return sortedKeys(seen)
+}
+
+func (jsAnalyzer) Dependencies(a *Report) (*DependencyGraph, error) {
+	graph := &DependencyGraph{
+		Deps:        make(map[string]map[string]struct{}),
+		ProdDeps:    make(map[string]map[string]struct{}),
+		ItemImports: make(map[string]map[string]map[string]struct{}),
+		Conditions:  make(map[string]map[string]map[string]struct{}),
+		External:    make(map[string]map[string]map[string]struct{}),
+		Inferred:    make(map[string]map[string]map[string]string),
+	}
+	resolver := newJSResolver(a.Root)
+	err := walkJSFiles(a, func(path, content, code string) {
+		site := useSite{File: path, Content: content, IsTest: jsIsTestFile(a.Root, path)}
+		if a.leavesOut(site) { return }
+		record := func(offset int, statement, spec string, clause jsImportClause) {
+			file, pkg := resolver.resolve(path, spec)
+			items := clause.named
+			if clause.namespace != "" { items = append(items, jsSelectors(code, clause.namespace)...) }
+			switch {
+			case file != "":
+				module := a.ModuleName(file)
+				if len(items) == 0 { recordImport(graph, site, module, ""); return }
+				for _, item := range items { recordImport(graph, site, module, item) }
+			case pkg != "":
+				if len(items) == 0 { items = []string{"*"} } // used whole: `import * as React from 'react'` with no React.x, or a side-effect import
+				for _, item := range items {
+					if graph.External[pkg] == nil { graph.External[pkg] = make(map[string]map[string]struct{}) }
+					if graph.External[pkg][item] == nil { graph.External[pkg][item] = make(map[string]struct{}) }
+					graph.External[pkg][item][path] = struct{}{}
+				}
+			case strings.HasPrefix(spec, "."):
+				if _, err := os.Stat(filepath.Join(filepath.Dir(path), spec)); err != nil { // an asset such as ./styles.css is fine
+					recordUnparsed(graph, path, code, offset, statement, fmt.Errorf("cannot resolve %q", spec))
+				}
+			}
+		}
+		for _, loc := range jsImportFromRegex.FindAllStringSubmatchIndex(code, -1) {
+			record(loc[0], code[loc[0]:loc[1]], code[loc[4]:loc[5]], parseJSImportClause(code[loc[2]:loc[3]]))
+		}
+		for _, loc := range jsImportBareRegex.FindAllStringSubmatchIndex(code, -1) {
+			record(loc[0], code[loc[0]:loc[1]], code[loc[2]:loc[3]], jsImportClause{})
+		}
+		for _, loc := range jsDynamicRegex.FindAllStringSubmatchIndex(code, -1) {
+			record(loc[0], code[loc[0]:loc[1]], code[loc[2]:loc[3]], jsImportClause{})
+		}
+		for _, loc := range jsExportFromRegex.FindAllStringSubmatchIndex(code, -1) {
+			var clause jsImportClause
+			if list := code[loc[2]:loc[3]]; strings.HasPrefix(list, "{") {
+				clause = parseJSImportClause(list)
+			} else {
+				site := site
+				site.Inferred = InferredGlob
+				file, _ := resolver.resolve(path, code[loc[4]:loc[5]])
+				if file != "" { recordImport(graph, site, a.ModuleName(file), "") }
+				continue
+			}
+			record(loc[0], code[loc[0]:loc[1]], code[loc[4]:loc[5]], clause)
+		}
+		for _, loc := range jsRequireRegex.FindAllStringSubmatchIndex(code, -1) {
+			var clause jsImportClause
+			if loc[2] >= 0 {
+				if binding := code[loc[2]:loc[3]]; strings.HasPrefix(binding, "{") { clause = parseJSImportClause(binding) } else { clause.namespace = binding }
+			}
+			record(loc[0], code[loc[0]:loc[1]], code[loc[4]:loc[5]], clause)
+		}
+	})
+	return graph, err
+}
@@ -0,0 +1,108 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// writeTree writes files, by slash-separated path relative to root, under root.
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { t.Fatal(err) }
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil { t.Fatal(err) }
+	}
+}
+
+// importedItems lists what graph records as imported, as module::item or, for an external package, package!item.
+func importedItems(graph *DependencyGraph) []string {
+	var got []string
+	for module, items := range graph.ItemImports { for item := range items { got = append(got, module+"::"+item) } }
+	for pkg, items := range graph.External { for item := range items { got = append(got, pkg+"!"+item) } }
+	sort.Strings(got)
+	return got
+}
+
+func TestParseJSImportClause(t *testing.T) {
+	tests := []struct {
+		clause    string
+		named     []string
+		namespace string
+	}{
+		{clause: "Button", named: []string{"default"}},
+		{clause: "{ useState, useEffect as useE }", named: []string{"useState", "useEffect"}},
+		{clause: "{ type Props, Card }", named: []string{"Props", "Card"}},
+		{clause: "Button, { useState }", named: []string{"useState", "default"}},
+		{clause: "* as api", namespace: "api"},
+		{clause: "Button, * as api", named: []string{"default"}, namespace: "api"},
+		{clause: "{ a: renamed, b }", named: []string{"a", "b"}}, // a destructuring require
+		{clause: "{ a,\n  b,\n}", named: []string{"a", "b"}},
+		{clause: "{}", named: nil},
+	}
+	for _, tt := range tests {
+		got := parseJSImportClause(tt.clause)
+		if !reflect.DeepEqual(got.named, tt.named) || got.namespace != tt.namespace {
+			t.Errorf("parseJSImportClause(%q) = %q, %q; want %q, %q", tt.clause, got.named, got.namespace, tt.named, tt.namespace)
+		}
+	}
+}
+
+func TestJSMaskLiterals(t *testing.T) {
+	tests := []struct{ src, want string }{
+		{`import a from './a';`, `import a from './a';`},
+		{`import './side';`, `import './side';`},
+		{`const b = require("b");`, `const b = require("b");`},
+		{`await import('./lazy')`, `await import('./lazy')`},
+		{`const s = "import x from 'y'";`, `const s = "                 ";`},
+		{"const t = `import x from 'y'`;", "const t = `                 `;"},
+		{`log('from', "x")`, `log('    ', " ")`},
+		{"'a\\'b' + 'c'", "'    ' + ' '"},
+		{"`a\nb`", "` \n `"},
+	}
+	for _, tt := range tests {
+		if got := jsMaskLiterals(tt.src); got != tt.want { t.Errorf("jsMaskLiterals(%q) = %q, want %q", tt.src, got, tt.want) }
+	}
+}
+
+func TestJSStripComments(t *testing.T) {
+	tests := []struct{ src, want string }{
+		{"import a from './a'; // import b from './b'", "import a from './a';                       "},
+		{"/* import b\nfrom './b' */x", "           \n             x"},
+		{`const u = "http://example.com";`, `const u = "http://example.com";`},
+		{"/* unterminated", "               "},
+	}
+	for _, tt := range tests {
+		if got := jsStripComments(tt.src); got != tt.want { t.Errorf("jsStripComments(%q) = %q, want %q", tt.src, got, tt.want) }
+	}
+}
+
+func TestJSDependencies(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"package.json":       `{"name": "shop", "dependencies": {"react": "^18.0.0"}}`,
+		"src/index.ts":       "export { Cart } from './cart';\nexport * from './money';\n",
+		"src/cart.ts":        "import { price, type Money } from './money';\nimport * as React from 'react';\nimport './styles.css';\nexport class Cart { total(): Money { React.useMemo(); return price(); } }\n",
+		"src/money.ts":       "export type Money = number;\nexport function price(): Money { return 1 }\n",
+		"src/checkout.js":    "const { Cart } = require('./cart');\nconst money = require('./money');\nmoney.price();\n// import { gone } from './gone';\nconst s = \"import { fake } from './fake'\";\nimport('./lazy');\n",
+		"src/lazy.ts":        "export default 1;\n",
+		"src/missing.ts":     "import { x } from './nowhere';\n",
+		"src/styles.css":     "",
+		"src/cart.test.ts":   "import { Cart } from './cart';\n",
+	})
+	a, err := Analyze(root, Options{})
+	if err != nil { t.Fatal(err) }
+	got := importedItems(a.Graph)
+	for _, w := range []string{"cart::Cart", "money::Money", "money::price", "react!useMemo"} {
+		if i := sort.SearchStrings(got, w); i == len(got) || got[i] != w { t.Errorf("imports = %q, missing %q", got, w) }
+	}
+	for _, unwanted := range []string{"gone::gone", "fake::fake"} {
+		for _, g := range got { if g == unwanted { t.Errorf("imports = %q, want no %q from a comment or string", got, unwanted) } }
+	}
+	if len(a.Graph.Unparsed) != 1 || filepath.Base(a.Graph.Unparsed[0].File) != "missing.ts" { t.Errorf("unparsed = %+v, want the import of ./nowhere only", a.Graph.Unparsed) }
+	if _, ok := a.Graph.ProdDeps[filepath.Join(root, "src", "cart.test.ts")]; ok { t.Errorf("the test file's imports count as production imports") }
+	if _, ok := a.Graph.Deps[filepath.Join(root, "src", "checkout.js")]["lazy"]; !ok { t.Errorf("checkout.js imports %v, want lazy through import()", sortedKeys(a.Graph.Deps[filepath.Join(root, "src", "checkout.js")])) }
+}
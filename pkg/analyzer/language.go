@@ -0,0 +1,58 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LanguageAnalyzer runs the passes of an analysis for one source language. Every backend fills in the same model:
+// modules with their public items (the symbol table) and the files importing them (the dependency graph), so the
+// report, snapshots and queries work unchanged whatever the tree is written in.
+type LanguageAnalyzer interface {
+	Name() string
+	// Load reads the project manifest and anything else module naming depends on, before ConfigureModuleNaming runs.
+	Load(a *Report) error
+	SymbolTable(a *Report) (map[string]map[string]struct{}, *ModuleFacts, error)
+	Dependencies(a *Report) (*DependencyGraph, error)
+}
+
+// languages are the backends by the name recorded in Report.Language.
+var languages = map[string]LanguageAnalyzer{"rust": rustAnalyzer{}, "go": goAnalyzer{}, "js": jsAnalyzer{}, "python": pythonAnalyzer{}}
+
+// detectLanguage picks the backend for root from its manifest: Cargo.toml means Rust, go.mod Go, package.json or
+// tsconfig.json TypeScript/JavaScript, and pyproject.toml, setup.py or requirements.txt Python. A tree with none is
+// taken for loose Rust sources, as it always was.
+func detectLanguage(root string) LanguageAnalyzer {
+	if _, err := os.Stat(filepath.Join(root, "Cargo.toml")); err == nil { return languages["rust"] }
+	if _, err := os.Stat(filepath.Join(root, "go.mod")); err == nil { return languages["go"] }
+	for _, manifest := range []string{"package.json", "tsconfig.json"} {
+		if _, err := os.Stat(filepath.Join(root, manifest)); err == nil { return languages["js"] }
+	}
+	for _, manifest := range []string{"pyproject.toml", "setup.py", "requirements.txt"} {
+		if _, err := os.Stat(filepath.Join(root, manifest)); err == nil { return languages["python"] }
+	}
+	return languages["rust"]
+}
+
+type rustAnalyzer struct{}
+
+func (rustAnalyzer) Name() string { return "rust" }
+
+func (rustAnalyzer) Load(a *Report) error {
+	var err error
+	if a.Manifest, err = loadCargoManifest(a.Root); err != nil { return fmt.Errorf("reading Cargo.toml: %w", err) }
+	if a.Lockfile, err = loadCargoLock(a.Root); err != nil { return fmt.Errorf("reading Cargo.lock: %w", err) }
+	if a.ModTree, err = buildModTree(a.Root, a.Config.PathFilter(a.Root)); err != nil { return fmt.Errorf("building module tree: %w", err) }
+	return nil
+}
+
+func (rustAnalyzer) SymbolTable(a *Report) (map[string]map[string]struct{}, *ModuleFacts, error) {
+	return buildSymbolTable(a)
+}
+
+func (rustAnalyzer) Dependencies(a *Report) (*DependencyGraph, error) {
+	graph, err := analyzeDependencies(a, newImportResolver(a))
+	if err != nil { return nil, err }
+	return graph, recordMacroUses(a, graph)
+}
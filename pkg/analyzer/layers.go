@@ -0,0 +1,98 @@
+package analyzer
+
+import (
+	"fmt"
+	pathpkg "path"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/internal/toml"
+	"github.com/WillKirkmanM/dependant/internal/yaml"
+)
+
+// LayersFileName is the optional home of the layers besides dependant.toml; see parseLayersFile.
+const LayersFileName = "dependant.yaml"
+
+// parseLayers reads chains of layers, each listing layers from the top down, as declared in file.
+//
+//	layers = ["ui -> domain -> storage", "cli -> domain"]
+//
+// A layer names a tag, a module (with its submodules) or a glob of module names. Each layer may import the layers
+// below it in a chain, never those above.
+func parseLayers(file string, chains []string) ([][]string, error) {
+	var layers [][]string
+	for _, chain := range chains {
+		var names []string
+		for _, name := range strings.Split(chain, "->") {
+			name = strings.TrimSpace(name)
+			if name == "" { return nil, fmt.Errorf("%s: layers: empty layer in %q", file, chain) }
+			if _, err := pathpkg.Match(name, ""); err != nil { return nil, fmt.Errorf("%s: layers: bad pattern %q", file, name) }
+			names = append(names, name)
+		}
+		if len(names) < 2 { return nil, fmt.Errorf("%s: layers: %q needs at least two layers, as in \"ui -> domain\"", file, chain) }
+		layers = append(layers, names)
+	}
+	return layers, nil
+}
+
+// parseLayersFile reads the chains of dependant.yaml:
+//
+//	layers:
+//	  - ui -> domain -> storage
+//	  - cli -> domain
+//
+// A single chain may follow the key, and a flow sequence ([ui -> domain, cli -> domain]) lists several on one line.
+// Every other setting lives in dependant.toml, so any other key is an error rather than silently ignored.
+func parseLayersFile(content string) ([]string, error) {
+	var chains []string
+	add := func(n int, value string) error {
+		chain, err := yaml.Scalar(value)
+		if err != nil { return fmt.Errorf("line %d: %w", n, err) }
+		chains = append(chains, chain)
+		return nil
+	}
+	seen, open := false, false // open: inside the block sequence under layers:
+	for i, raw := range strings.Split(content, "\n") {
+		n := i + 1
+		line := strings.TrimRight(yaml.StripComment(raw), " \t\r")
+		item := strings.TrimSpace(line)
+		if item == "" || line == "---" { continue }
+		if rest, ok := strings.CutPrefix(item, "-"); ok && open && (rest == "" || rest[0] == ' ') {
+			if err := add(n, strings.TrimSpace(rest)); err != nil { return nil, err }
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || key != "layers" { return nil, fmt.Errorf("line %d: expected layers: and a list of chains; %s holds only the layers, the rest of the configuration lives in %s", n, LayersFileName, ConfigFileName) }
+		if seen { return nil, fmt.Errorf("line %d: layers given twice", n) }
+		seen, value = true, strings.TrimSpace(value)
+		switch open = value == ""; {
+		case open:
+		case strings.HasPrefix(value, "["):
+			if !strings.HasSuffix(value, "]") { return nil, fmt.Errorf("line %d: unterminated flow sequence %s", n, value) }
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				if item = strings.TrimSpace(item); item == "" { continue }
+				if err := add(n, item); err != nil { return nil, err }
+			}
+		default:
+			if err := add(n, value); err != nil { return nil, err }
+		}
+	}
+	return chains, nil
+}
+
+// parseImporters reads the [importers] table: each key, a module pattern as in a layer, lists the patterns of the
+// modules allowed to import the modules it matches. An empty list lets no other module import them.
+func parseImporters(table map[string]any) (map[string][]string, error) {
+	importers := make(map[string][]string)
+	for module, v := range table {
+		switch v.(type) {
+		case string, []any:
+		default: return nil, fmt.Errorf("%s: [importers] %s must list the modules allowed to import it", ConfigFileName, module)
+		}
+		patterns := toml.Strings(v)
+		for _, pattern := range append([]string{module}, patterns...) {
+			if _, err := pathpkg.Match(pattern, ""); err != nil { return nil, fmt.Errorf("%s: [importers] %s: bad pattern %q", ConfigFileName, module, pattern) }
+		}
+		importers[module] = patterns
+	}
+	return importers, nil
+}
@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLayersFile(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []string
+		err  string // a substring of the error; empty when the file parses
+	}{
+		{src: "layers:\n  - ui -> domain -> storage\n  - cli -> domain\n", want: []string{"ui -> domain -> storage", "cli -> domain"}},
+		{src: "# architecture\n---\nlayers:\n- 'ui -> domain' # top down\n", want: []string{"ui -> domain"}},
+		{src: "layers: ui -> domain\n", want: []string{"ui -> domain"}},
+		{src: "layers: [ui -> domain, \"cli -> domain\"]\n", want: []string{"ui -> domain", "cli -> domain"}},
+		{src: "", want: nil},
+		{src: "layers:\n  - ui -> domain\nexclude: [target]\n", err: "line 3: expected layers:"},
+		{src: "  - ui -> domain\n", err: "line 1: expected layers:"},
+		{src: "layers: ui -> domain\n  - cli -> domain\n", err: "line 2: expected layers:"},
+		{src: "layers:\nlayers:\n", err: "given twice"},
+		{src: "layers: [ui -> domain\n", err: "unterminated flow sequence"},
+		{src: "layers:\n  - \"ui -> domain\n", err: "bad double-quoted string"},
+	}
+	for _, tt := range tests {
+		got, err := parseLayersFile(tt.src)
+		switch {
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("parseLayersFile(%q) error = %v, want one containing %q", tt.src, err, tt.err)
+		case tt.err == "" && err != nil:
+			t.Errorf("parseLayersFile(%q) error = %v", tt.src, err)
+		case tt.err == "" && !reflect.DeepEqual(got, tt.want):
+			t.Errorf("parseLayersFile(%q) = %q, want %q", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigLayers(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{LayersFileName: "layers:\n  - ui -> domain\n"})
+	cfg, err := LoadConfig(root)
+	if err != nil { t.Fatal(err) }
+	if want := [][]string{{"ui", "domain"}}; !reflect.DeepEqual(cfg.Layers, want) { t.Errorf("layers without %s = %q, want %q", ConfigFileName, cfg.Layers, want) }
+
+	writeTree(t, root, map[string]string{ConfigFileName: "layers = [\"cli -> domain -> storage\"]\n"})
+	cfg, err = LoadConfig(root)
+	if err != nil { t.Fatal(err) }
+	if want := [][]string{{"cli", "domain", "storage"}, {"ui", "domain"}}; !reflect.DeepEqual(cfg.Layers, want) { t.Errorf("layers of both files = %q, want %q", cfg.Layers, want) }
+
+	writeTree(t, root, map[string]string{LayersFileName: "layers: ui\n"})
+	if _, err := LoadConfig(root); err == nil || !strings.HasPrefix(err.Error(), LayersFileName+": layers:") { t.Errorf("a one-layer chain in %s gave error %v", LayersFileName, err) }
+}
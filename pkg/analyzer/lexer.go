@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// StripNonCode blanks out what regex-based extraction must not see: line, block (nested) and doc comments become
+// spaces, and the contents of string, raw string, byte string and char literals become underscores, so a `use`
+// mentioned in either is not taken for an import. Newlines are kept and the result has the same length as src, so
+// offsets and line numbers computed on it hold for src too.
+//
+// String literals inside attributes other than #[doc] are kept, since cfg predicates such as
+// #[cfg(feature = "fast")] are read from them.
+func StripNonCode(src string) string {
+	out := []byte(src)
+	blank := func(from, to int, fill byte) {
+		for i := from; i < to && i < len(out); i++ { if out[i] != '\n' { out[i] = fill } }
+	}
+	attrDepth := 0 // bracket depth inside a non-doc attribute, where strings are kept
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 { end = len(src) - i }
+			blank(i, i+end, ' ')
+			i += end
+		case strings.HasPrefix(src[i:], "/*"):
+			depth, j := 1, i+2
+			for j < len(src) && depth > 0 {
+				switch {
+				case strings.HasPrefix(src[j:], "/*"): depth++; j += 2
+				case strings.HasPrefix(src[j:], "*/"): depth--; j += 2
+				default: j++
+				}
+			}
+			blank(i, j, ' ')
+			i = j
+		case c == '#' && attrDepth == 0 && (strings.HasPrefix(src[i:], "#[") || strings.HasPrefix(src[i:], "#![")):
+			open := i + strings.IndexByte(src[i:], '[')
+			if !strings.HasPrefix(strings.TrimSpace(src[open+1:]), "doc") { attrDepth = 1 }
+			i = open + 1
+		case attrDepth > 0 && c == '[': attrDepth++; i++
+		case attrDepth > 0 && c == ']': attrDepth--; i++
+		case c == '"' || ((c == 'b' || c == 'c' || c == 'r') && isStringStart(src, i)):
+			end := stringEnd(src, i)
+			if attrDepth == 0 {
+				open := i + strings.IndexByte(src[i:], '"')
+				blank(open+1, end-1-closingHashes(src, i), '_')
+			}
+			i = end
+		case c == '\'' && isCharLiteral(src, i):
+			start := i + 2
+			if src[i+1] == '\\' { start = i + 3 } // the escaped character may itself be a quote
+			end := strings.IndexByte(src[start:], '\'')
+			if end < 0 { i++; continue }
+			blank(i+1, start+end, '_')
+			i = start + end + 1
+		default:
+			if isIdentByte(c) { for i < len(src) && isIdentByte(src[i]) { i++ } } else { i++ } // skip whole identifiers so `br` in `abr"` is not a prefix
+		}
+	}
+	return string(out)
+}
+
+// rawIdentRegex matches the r# prefix of raw identifiers such as r#type, which name the same thing as plain type.
+var rawIdentRegex = regexp.MustCompile(`\br#`)
+
+// unraw drops raw identifier prefixes, so `crate::r#type::r#Match` compares equal to the module and item names.
+func unraw(s string) string { return rawIdentRegex.ReplaceAllString(s, "") }
+
+func isIdentByte(c byte) bool { return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+
+// isStringStart reports whether a string literal with a prefix (b"", c"", r"", r#""#, br"", cr#""#) starts at i.
+func isStringStart(src string, i int) bool {
+	j := i
+	if src[j] == 'b' || src[j] == 'c' { j++ }
+	if j < len(src) && src[j] == 'r' {
+		j++
+		for j < len(src) && src[j] == '#' { j++ }
+	}
+	return j > i && j < len(src) && src[j] == '"'
+}
+
+// closingHashes is the number of #s that close the (raw) string starting at i.
+func closingHashes(src string, i int) int {
+	j := i
+	for j < len(src) && src[j] != '"' && src[j] != '#' { j++ }
+	n := 0
+	for j < len(src) && src[j] == '#' { n++; j++ }
+	return n
+}
+
+// stringEnd returns the offset just past the string literal starting at i.
+func stringEnd(src string, i int) int {
+	open := i + strings.IndexByte(src[i:], '"')
+	raw := strings.Contains(src[i:open], "r")
+	if raw {
+		closing := `"` + strings.Repeat("#", closingHashes(src, i))
+		if end := strings.Index(src[open+1:], closing); end >= 0 { return open + 1 + end + len(closing) }
+		return len(src)
+	}
+	for j := open + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\': j++
+		case '"': return j + 1
+		}
+	}
+	return len(src)
+}
+
+// isCharLiteral tells 'x' and '\n' apart from lifetimes such as 'a.
+func isCharLiteral(src string, i int) bool {
+	if i+2 >= len(src) { return false }
+	if src[i+1] == '\\' { return true }
+	return src[i+2] == '\'' || (src[i+1] >= 0x80 && strings.IndexByte(src[i+1:min(i+6, len(src))], '\'') > 0) // a multi-byte character
+}
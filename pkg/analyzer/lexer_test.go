@@ -0,0 +1,26 @@
+package analyzer
+
+import "testing"
+
+func TestStripNonCode(t *testing.T) {
+	tests := []struct{ name, src, want string }{
+		{"line comment", "use a; // use b;\nuse c;", "use a;          \nuse c;"},
+		{"nested block comment", "/* a /* b */ c */x", "                 x"},
+		{"doc comment", "/// use a::b;\nfn f() {}", "             \nfn f() {}"},
+		{"string", `let s = "use a::b;";`, `let s = "_________";`},
+		{"escaped quote", `"a\"b" use`, `"____" use`},
+		{"raw string", `r#"use "a";"#;`, `r#"________"#;`},
+		{"byte string", `b"use" x`, `b"___" x`},
+		{"char and lifetime", `fn f<'a>(c: char) { '"'; }`, `fn f<'a>(c: char) { '_'; }`},
+		{"escaped char", `'\''`, `'__'`},
+		{"cfg attribute keeps its string", `#[cfg(feature = "fast")] "x"`, `#[cfg(feature = "fast")] "_"`},
+		{"doc attribute blanks its string", `#[doc = "use a;"]`, `#[doc = "______"]`},
+		{"newlines kept", "\"a\nb\"", "\"_\n_\""},
+		{"identifier ending in a prefix", `abr"x"`, `abr"_"`},
+	}
+	for _, tt := range tests {
+		got := StripNonCode(tt.src)
+		if got != tt.want { t.Errorf("%s: StripNonCode(%q) = %q, want %q", tt.name, tt.src, got, tt.want) }
+		if len(got) != len(tt.src) { t.Errorf("%s: StripNonCode changed the length from %d to %d", tt.name, len(tt.src), len(got)) }
+	}
+}
@@ -0,0 +1,148 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// macroUseRegex finds legacy `#[macro_use] extern crate log;` imports, with an optional list of the macros taken
+	// (`#[macro_use(info, warn)]`), and `#[macro_use] mod macros;` declarations exporting a module's macros.
+	macroUseRegex        = regexp.MustCompile(`#\[\s*macro_use\s*(?:\(([^)]*)\))?\s*\]\s*(?:#\[[^\]]*\]\s*)*(?:pub(?:\s*\([^)]*\))?\s+)?(extern\s+crate|mod)\s+((?:r#)?\w+)(?:\s+as\s+\w+)?\s*[;{]`)
+	macroInvocationRegex = regexp.MustCompile(`\b((?:r#)?[A-Za-z_]\w*)!\s*[(\[{]`)
+	macroRulesRegex      = regexp.MustCompile(`\bmacro_rules!\s*((?:r#)?\w+)`)
+)
+
+// builtinMacros are the macros of std and core every crate can invoke.
+var builtinMacros = map[string]struct{}{
+	"assert": {}, "assert_eq": {}, "assert_ne": {}, "cfg": {}, "column": {}, "compile_error": {}, "concat": {}, "dbg": {},
+	"debug_assert": {}, "debug_assert_eq": {}, "debug_assert_ne": {}, "env": {}, "eprint": {}, "eprintln": {}, "file": {},
+	"format": {}, "format_args": {}, "include": {}, "include_bytes": {}, "include_str": {}, "line": {}, "macro_rules": {},
+	"matches": {}, "module_path": {}, "option_env": {}, "panic": {}, "print": {}, "println": {}, "stringify": {},
+	"thread_local": {}, "todo": {}, "try": {}, "r#try": {}, "unimplemented": {}, "unreachable": {}, "vec": {}, "write": {},
+	"writeln": {}, "asm": {}, "global_asm": {},
+}
+
+// knownCrateMacros are the macros of crates that commonly came in through #[macro_use], so invocations can be told
+// apart when a crate imports several.
+var knownCrateMacros = map[string][]string{
+	"log":         {"debug", "error", "info", "log", "log_enabled", "trace", "warn"},
+	"lazy_static": {"lazy_static"},
+	"serde_json":  {"json"},
+	"bitflags":    {"bitflags"},
+	"error_chain": {"bail", "ensure", "error_chain"},
+	"failure":     {"bail", "ensure", "format_err"},
+	"quick_error": {"quick_error"},
+	"maplit":      {"btreemap", "btreeset", "convert_args", "hashmap", "hashset"},
+	"itertools":   {"iproduct", "izip"},
+	"cfg_if":      {"cfg_if"},
+	"quote":       {"quote", "quote_spanned"},
+	"nom":         {"alt", "call", "do_parse", "many0", "many1", "map", "named", "opt", "tag", "take", "take_until", "terminated", "preceded", "delimited"},
+	"slog":        {"crit", "debug", "error", "info", "o", "slog_crit", "slog_debug", "slog_error", "slog_info", "slog_trace", "slog_warn", "trace", "warn"},
+}
+
+// macroImport is one #[macro_use] declaration: the crate or module whose macros it brings into scope.
+type macroImport struct {
+	file     string
+	offset   int
+	external bool
+	name     string   // crate name, or the module name for `#[macro_use] mod`
+	macros   []string // the #[macro_use(...)] list; nil for all of them
+	crateKey string   // the crate whose files see the macros; "" for the declaring file alone
+}
+
+// recordMacroUses attributes macro invocations to what #[macro_use] brought them in from, so pre-2018 code shows the
+// coupling a `use` would: a `#[macro_use] extern crate` in a crate root covers every file of the crate, and
+// elsewhere the rest of its file; a `#[macro_use] mod` covers the macro_rules! macros defined in the module. An
+// invocation counts as an item of the crate (or module) providing it, matched by knownCrateMacros and the macros
+// defined in the tree, or by elimination when only one unknown crate is in scope.
+func recordMacroUses(a *Report, graph *DependencyGraph) error {
+	type rustFile struct{ content, code string }
+	files := make(map[string]rustFile)
+	filter := a.Config.PathFilter(a.Root)
+	err := filepath.WalkDir(a.Root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && filter.Skip(path, d.IsDir()) { if d.IsDir() { return filepath.SkipDir }; return nil }
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".rs") || a.isDead(path) { return err }
+		content, err := os.ReadFile(path)
+		if err != nil { return err }
+		files[path] = rustFile{string(content), StripNonCode(string(content))}
+		return nil
+	})
+	if err != nil { return err }
+
+	modPathOf := func(path string) string {
+		if a.ModTree == nil { return "" }
+		return a.ModTree.Paths[relSlash(a.Root, path)]
+	}
+	crateOf := func(path string) string { return strings.Split(modPathOf(path), "::")[0] }
+	var imports []macroImport
+	definedIn := make(map[string]map[string]struct{}) // macro name -> modules defining it
+	for _, path := range sortedKeys(files) {
+		code := files[path].code
+		for _, m := range macroRulesRegex.FindAllStringSubmatch(code, -1) {
+			module := a.ModuleName(path)
+			if definedIn[m[1]] == nil { definedIn[m[1]] = make(map[string]struct{}) }
+			definedIn[m[1]][module] = struct{}{}
+		}
+		for _, loc := range macroUseRegex.FindAllStringSubmatchIndex(code, -1) {
+			imp := macroImport{file: path, offset: loc[1], external: code[loc[4]:loc[5]] != "mod", name: unraw(code[loc[6]:loc[7]])}
+			if loc[2] >= 0 { for _, name := range strings.Split(code[loc[2]:loc[3]], ",") { if name = strings.TrimSpace(name); name != "" { imp.macros = append(imp.macros, name) } } }
+			if modPath := modPathOf(path); modPath != "" && !strings.Contains(modPath, "::") { imp.crateKey = modPath } // a crate root
+			imports = append(imports, imp)
+		}
+	}
+	if len(imports) == 0 { return nil }
+
+	for _, path := range sortedKeys(files) {
+		f, own, crate := files[path], a.ModuleName(path), crateOf(path)
+		blocks := CfgBlocks(f.code)
+		testFile, fileCfgs := isTestFile(a.Root, path), a.fileCfgs(path)
+		for _, loc := range macroInvocationRegex.FindAllStringSubmatchIndex(f.code, -1) {
+			name := f.code[loc[2]:loc[3]]
+			if _, builtin := builtinMacros[name]; builtin || strings.HasSuffix(strings.TrimRight(f.code[:loc[0]], " \t"), "::") { continue } // path-qualified macros are imported by use
+			var inScope []macroImport
+			for _, imp := range imports {
+				if (imp.crateKey != "" && imp.crateKey == crate) || (imp.file == path && imp.offset <= loc[0]) { inScope = append(inScope, imp) }
+			}
+			provider, ok := macroProvider(name, inScope, definedIn)
+			if !ok { continue }
+			site := useSite{File: path, Content: f.content, Cfgs: append(slices.Clone(fileCfgs), CfgPredicates(f.code, loc[0], blocks)...), Inferred: inferredMacroUse}
+			site.IsTest = testFile || AnyTestCfg(site.Cfgs)
+			if a.leavesOut(site) { continue }
+			if provider.external {
+				if graph.External[provider.name] == nil { graph.External[provider.name] = make(map[string]map[string]struct{}) }
+				if graph.External[provider.name][name] == nil { graph.External[provider.name][name] = make(map[string]struct{}) }
+				graph.External[provider.name][name][path] = struct{}{}
+				continue
+			}
+			module := ""
+			for m := range definedIn[name] { if m == provider.name || strings.HasPrefix(m, provider.name+"::") || strings.HasPrefix(m, provider.name+"/") { module = m } }
+			if module == "" || module == own { continue }
+			recordImport(graph, site, module, name)
+		}
+	}
+	return nil
+}
+
+// macroProvider picks the #[macro_use] declaration an invocation of name comes from: one listing it explicitly, a
+// module defining it, a crate known to export it, else the only crate in scope whose macros are unknown.
+func macroProvider(name string, inScope []macroImport, definedIn map[string]map[string]struct{}) (macroImport, bool) {
+	var unknown []macroImport
+	for _, imp := range inScope {
+		switch {
+		case imp.macros != nil:
+			for _, m := range imp.macros { if m == name { return imp, true } }
+		case !imp.external:
+			for module := range definedIn[name] { if module == imp.name || strings.HasPrefix(module, imp.name+"::") || strings.HasPrefix(module, imp.name+"/") { return imp, true } }
+		case knownCrateMacros[imp.name] != nil:
+			if slices.Contains(knownCrateMacros[imp.name], name) { return imp, true }
+		default:
+			unknown = append(unknown, imp)
+		}
+	}
+	if _, local := definedIn[name]; local || len(unknown) != 1 { return macroImport{}, false }
+	return unknown[0], true
+}
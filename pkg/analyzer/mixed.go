@@ -0,0 +1,228 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LanguageRoot is one language found in a mixed tree: the directory holding its manifest, which the language's
+// frontend analyzes as if it were the whole tree.
+type LanguageRoot struct {
+	Language string `json:"language"`
+	Dir      string `json:"dir"` // relative to the tree's root, "." for the root itself
+}
+
+// languageManifests are the files detectLanguage picks each language by, in its order.
+var languageManifests = []struct {
+	language  string
+	manifests []string
+}{
+	{"rust", []string{"Cargo.toml"}},
+	{"go", []string{"go.mod"}},
+	{"js", []string{"package.json", "tsconfig.json"}},
+	{"python", []string{"pyproject.toml", "setup.py", "requirements.txt"}},
+}
+
+// DetectLanguages finds every language under root by its manifests, with the outermost directory of each: a
+// manifest below another of its own language, such as a workspace member's, belongs to the same analysis. A tree
+// with no manifest at all is loose Rust sources, as detectLanguage takes it.
+func DetectLanguages(root string, filter *PathFilter) ([]LanguageRoot, error) {
+	var roots []LanguageRoot
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() { return err }
+		if filter.Skip(path, true) { return filepath.SkipDir }
+		dir := relSlash(root, path)
+		for _, lm := range languageManifests {
+			if nestedRoot(roots, lm.language, dir) { continue }
+			for _, manifest := range lm.manifests {
+				if _, err := os.Stat(filepath.Join(path, manifest)); err == nil { roots = append(roots, LanguageRoot{Language: lm.language, Dir: dir}); break }
+			}
+		}
+		return nil
+	})
+	if len(roots) == 0 { roots = []LanguageRoot{{Language: "rust", Dir: "."}} }
+	return roots, err
+}
+
+// nestedRoot reports whether dir is, or lies below, a root of language already found.
+func nestedRoot(roots []LanguageRoot, language, dir string) bool {
+	for _, r := range roots {
+		if r.Language == language && (r.Dir == "." || dir == r.Dir || strings.HasPrefix(dir, r.Dir+"/")) { return true }
+	}
+	return false
+}
+
+// ownsFile reports whether the frontend of language reads the file called name.
+func ownsFile(language, name string) bool {
+	switch language {
+	case "go": return strings.HasSuffix(name, ".go")
+	case "js": return IsJSFile(name)
+	case "python": return IsPythonFile(name)
+	}
+	return strings.HasSuffix(name, ".rs")
+}
+
+// FileLanguage names the language of a source file, "c" for the C headers cross-language edges lead to, or "".
+func FileLanguage(name string) string {
+	if IsCHeader(name) { return "c" }
+	for _, lm := range languageManifests { if ownsFile(lm.language, name) { return lm.language } }
+	return ""
+}
+
+// ModuleLanguages maps each module of a mixed analysis to its language, from the files FileModules names.
+func (a *Report) ModuleLanguages() map[string]string {
+	languages := make(map[string]string)
+	for file, module := range a.FileModules { if lang := FileLanguage(file); lang != "" { languages[module] = lang } }
+	return languages
+}
+
+// analyzeMixed analyzes each language DetectLanguages finds with its own frontend, from the directory of its
+// manifest, and merges the results into one Report rooted at root. Module names stay those each frontend gives,
+// except where several languages use the same one: each is then qualified by its language, e.g. python:utils. Every
+// file's module is recorded in FileModules, since no one naming scheme covers them all. The languages are then
+// linked where that can be seen from the source; see linkLanguages.
+func (z *Analyzer) analyzeMixed(root string) (*Report, error) {
+	cfg, err := LoadConfig(root)
+	if err != nil { return nil, fmt.Errorf("loading config: %w", err) }
+	roots, err := DetectLanguages(root, cfg.PathFilter(root, z.Options.Exclude...))
+	if err != nil { return nil, err }
+	opts := z.Options
+	opts.Mixed = false
+	sub := &Analyzer{Options: opts, Progress: z.Progress}
+	if len(roots) == 1 && roots[0].Dir == "." {
+		a, err := sub.analyze(root, languages[roots[0].Language])
+		if err != nil { return nil, err }
+		a.Options.Mixed, a.Languages = true, roots
+		return a, nil
+	}
+
+	parts := make([]*Report, len(roots))
+	files := make([]map[string]string, len(roots)) // per part: file, relative to root -> module
+	for i, r := range roots {
+		if parts[i], err = sub.analyze(filepath.Join(root, filepath.FromSlash(r.Dir)), languages[r.Language]); err != nil { return nil, fmt.Errorf("analyzing the %s code in %s: %w", r.Language, r.Dir, err) }
+		if files[i], err = nameFiles(root, parts[i], r.Language); err != nil { return nil, err }
+	}
+	renameCollisions(roots, parts, files)
+
+	a := &Report{
+		Root: root, Options: z.Options, Config: cfg, Language: "mixed", Languages: roots,
+		Manifest:    &CargoManifest{Name: filepath.Base(root), Dependencies: make(map[string]string)},
+		SymbolTable: make(map[string]map[string]struct{}),
+		Facts:       &ModuleFacts{Tags: make(map[string]string), UnsafeBlocks: make(map[string]int), UnsafeFns: make(map[string]int), LOC: make(map[string]int), Generated: make(map[string]bool), ReExports: make(map[string]map[string]ReExport), ReExportGlobs: make(map[string][]string), Restricted: make(map[string]map[string]string), ModulePaths: make(map[string]string)},
+		Graph:       &DependencyGraph{Deps: make(map[string]map[string]struct{}), ProdDeps: make(map[string]map[string]struct{}), ItemImports: make(map[string]map[string]map[string]struct{}), Conditions: make(map[string]map[string]map[string]struct{}), External: make(map[string]map[string]map[string]struct{}), Inferred: make(map[string]map[string]map[string]string), UseStyles: make(map[string]UseStyle)},
+		FileModules: make(map[string]string),
+	}
+	for i, part := range parts {
+		if roots[i].Dir == "." { name := a.Manifest.Name; *a.Manifest = *part.Manifest; a.Manifest.Dependencies = make(map[string]string); if a.Manifest.Name == "" { a.Manifest.Name = name } }
+		mergeMaps(a.Manifest.Dependencies, part.Manifest.Dependencies)
+		a.Lockfile = append(a.Lockfile, part.Lockfile...)
+		mergeMaps(a.SymbolTable, part.SymbolTable)
+		f, pf := a.Facts, part.Facts
+		mergeMaps(f.Tags, pf.Tags); mergeMaps(f.UnsafeBlocks, pf.UnsafeBlocks); mergeMaps(f.UnsafeFns, pf.UnsafeFns); mergeMaps(f.LOC, pf.LOC)
+		mergeMaps(f.Generated, pf.Generated); mergeMaps(f.ReExports, pf.ReExports); mergeMaps(f.ReExportGlobs, pf.ReExportGlobs); mergeMaps(f.Restricted, pf.Restricted)
+		mergeMaps(f.ModulePaths, pf.ModulePaths)
+		g, pg := a.Graph, part.Graph
+		mergeMaps(g.Deps, pg.Deps); mergeMaps(g.ProdDeps, pg.ProdDeps); mergeMaps(g.ItemImports, pg.ItemImports); mergeMaps(g.Conditions, pg.Conditions)
+		mergeMaps(g.Inferred, pg.Inferred); mergeMaps(g.UseStyles, pg.UseStyles)
+		for crate, items := range pg.External {
+			if g.External[crate] == nil { g.External[crate] = make(map[string]map[string]struct{}) }
+			for item, importers := range items {
+				if g.External[crate][item] == nil { g.External[crate][item] = make(map[string]struct{}) }
+				mergeMaps(g.External[crate][item], importers)
+			}
+		}
+		g.Unparsed = append(g.Unparsed, pg.Unparsed...)
+		mergeMaps(a.FileModules, files[i])
+		if part.ModTree != nil { a.ModTree = mergeModTree(a.ModTree, part.ModTree, roots[i].Dir) }
+	}
+	for module, tag := range cfg.Tags { a.Facts.Tags[module] = tag } // the root's config wins, as it does over markers
+	if err := linkLanguages(a); err != nil { return nil, fmt.Errorf("linking languages: %w", err) }
+	ConfigureModuleNaming(a)
+	return a, nil
+}
+
+// nameFiles names every file of language under part's root the way part names them.
+func nameFiles(root string, part *Report, language string) (map[string]string, error) {
+	named := make(map[string]string)
+	filter := part.Config.PathFilter(part.Root)
+	err := filepath.WalkDir(part.Root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && filter.Skip(path, d.IsDir()) { if d.IsDir() { return filepath.SkipDir }; return nil }
+		if err != nil || d.IsDir() || !ownsFile(language, d.Name()) { return err }
+		named[relSlash(root, path)] = part.ModuleName(path)
+		return nil
+	})
+	return named, err
+}
+
+// renameCollisions qualifies the module names several parts use with each one's language, or its language and
+// directory when the language was found in several places.
+func renameCollisions(roots []LanguageRoot, parts []*Report, files []map[string]string) {
+	owners := make(map[string]map[int]struct{})
+	perLanguage := make(map[string]int)
+	for i, part := range parts {
+		perLanguage[roots[i].Language]++
+		for _, module := range partModules(part, files[i]) {
+			if owners[module] == nil { owners[module] = make(map[int]struct{}) }
+			owners[module][i] = struct{}{}
+		}
+	}
+	for i, part := range parts {
+		qualifier := roots[i].Language
+		if perLanguage[qualifier] > 1 { qualifier += ":" + roots[i].Dir }
+		rename := make(map[string]string)
+		for module, in := range owners { if _, ok := in[i]; ok && len(in) > 1 { rename[module] = qualifier + ":" + module } }
+		if len(rename) > 0 { renameModules(part, files[i], rename) }
+	}
+}
+
+// partModules lists the modules a part defines or imports.
+func partModules(part *Report, files map[string]string) []string {
+	modules := make(map[string]struct{})
+	for module := range part.SymbolTable { modules[module] = struct{}{} }
+	for _, module := range files { modules[module] = struct{}{} }
+	for _, deps := range part.Graph.Deps { for module := range deps { modules[module] = struct{}{} } }
+	delete(modules, "")
+	return sortedKeys(modules)
+}
+
+// renameModules renames modules throughout part and its named files.
+func renameModules(part *Report, files map[string]string, rename map[string]string) {
+	name := func(module string) string { if to, ok := rename[module]; ok { return to }; return module }
+	part.SymbolTable = renamedKeys(part.SymbolTable, name)
+	f := part.Facts
+	f.Tags, f.UnsafeBlocks, f.UnsafeFns, f.LOC, f.Generated = renamedKeys(f.Tags, name), renamedKeys(f.UnsafeBlocks, name), renamedKeys(f.UnsafeFns, name), renamedKeys(f.LOC, name), renamedKeys(f.Generated, name)
+	f.ReExports, f.ReExportGlobs, f.Restricted = renamedKeys(f.ReExports, name), renamedKeys(f.ReExportGlobs, name), renamedKeys(f.Restricted, name)
+	for _, items := range f.ReExports { for item, re := range items { re.Module = name(re.Module); items[item] = re } }
+	for facade, globs := range f.ReExportGlobs { for i := range globs { f.ReExportGlobs[facade][i] = name(globs[i]) } }
+	for path, module := range f.ModulePaths { f.ModulePaths[path] = name(module) }
+	g := part.Graph
+	for _, deps := range []map[string]map[string]struct{}{g.Deps, g.ProdDeps} {
+		for file, modules := range deps { deps[file] = renamedKeys(modules, name) }
+	}
+	g.ItemImports, g.Conditions, g.Inferred = renamedKeys(g.ItemImports, name), renamedKeys(g.Conditions, name), renamedKeys(g.Inferred, name)
+	for file, module := range files { files[file] = name(module) }
+}
+
+func renamedKeys[V any](m map[string]V, name func(string) string) map[string]V {
+	if m == nil { return nil }
+	out := make(map[string]V, len(m))
+	for k, v := range m { out[name(k)] = v }
+	return out
+}
+
+func mergeMaps[V any](dst, src map[string]V) { for k, v := range src { dst[k] = v } }
+
+// mergeModTree adds tree, whose paths are relative to dir, to merged, relative to the mixed tree's root.
+func mergeModTree(merged, tree *ModTree, dir string) *ModTree {
+	if merged == nil { merged = &ModTree{Paths: make(map[string]string), Dead: []string{}, Missing: []MissingMod{}, Cfgs: make(map[string][]string)} }
+	rebase := func(rel string) string { if dir == "." { return rel }; return dir + "/" + rel }
+	for rel, modPath := range tree.Paths { merged.Paths[rebase(rel)] = modPath }
+	for _, rel := range tree.Dead { merged.Dead = append(merged.Dead, rebase(rel)) }
+	for _, m := range tree.Missing { m.File = rebase(m.File); merged.Missing = append(merged.Missing, m) }
+	for rel, cfgs := range tree.Cfgs { merged.Cfgs[rebase(rel)] = cfgs }
+	sort.Strings(merged.Dead)
+	return merged
+}
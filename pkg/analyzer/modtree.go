@@ -0,0 +1,136 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// modDeclRegex matches module declarations with their attributes: out-of-line ones (`mod foo;`, `pub(crate) mod foo;`)
+// end in `;`, inline `mod foo { ... }` blocks in `{`.
+var (
+	modDeclRegex  = regexp.MustCompile(`(?m)^\s*((?:#\[[^\]]*\]\s*)*)(?:pub(?:\s*\([^)]*\))?\s+)?mod\s+((?:r#)?\w+)\s*([;{])`)
+	pathAttrRegex = regexp.MustCompile(`#\[\s*path\s*=\s*"([^"]*)"\s*\]`)
+)
+
+// inlineMod is the body of an inline `mod name { ... }` block, as byte offsets into the file.
+type inlineMod struct {
+	name       string
+	start, end int
+}
+
+// ModTree is the module tree rustc would build from `mod` declarations, compared with the .rs files on disk.
+type ModTree struct {
+	Paths   map[string]string   `json:"paths"`   // reachable file (relative to root) -> module path, e.g. crate::cpu::engine
+	Dead    []string            `json:"dead"`    // .rs files no crate root reaches
+	Missing []MissingMod        `json:"missing"` // declarations with no file behind them
+	Cfgs    map[string][]string `json:"cfgs,omitempty"` // reachable file -> cfg predicates on the declarations reaching it, e.g. #[cfg(test)] mod tests;
+}
+
+// MissingMod is a `mod name;` declaration whose name.rs / name/mod.rs (or #[path] file) does not exist.
+type MissingMod struct {
+	File string `json:"file"`
+	Name string `json:"name"`
+	Line int    `json:"line"`
+}
+
+// crateRoots lists the files Cargo compiles as crate roots, each with the path its modules hang off.
+func crateRoots(root string) map[string]string {
+	roots := make(map[string]string)
+	for _, f := range []string{"src/lib.rs", "src/main.rs", "build.rs"} {
+		if _, err := os.Stat(filepath.Join(root, f)); err == nil { roots[f] = "crate" }
+	}
+	for _, dir := range []string{"src/bin", "tests", "benches", "examples"} {
+		entries, _ := os.ReadDir(filepath.Join(root, dir))
+		for _, e := range entries {
+			switch {
+			case !e.IsDir() && strings.HasSuffix(e.Name(), ".rs"): roots[dir+"/"+e.Name()] = strings.TrimSuffix(e.Name(), ".rs")
+			case e.IsDir():
+				if _, err := os.Stat(filepath.Join(root, dir, e.Name(), "main.rs")); err == nil { roots[dir+"/"+e.Name()+"/main.rs"] = e.Name() }
+			}
+		}
+	}
+	return roots
+}
+
+// inlineMods finds the inline module blocks of code, outermost first.
+func inlineMods(code string) []inlineMod {
+	var mods []inlineMod
+	for _, loc := range modDeclRegex.FindAllStringSubmatchIndex(code, -1) {
+		if code[loc[6]:loc[7]] != "{" { continue }
+		depth, end := 0, len(code)
+		for i := loc[6]; i < len(code); i++ {
+			if code[i] == '{' { depth++ } else if code[i] == '}' { if depth--; depth == 0 { end = i; break } }
+		}
+		mods = append(mods, inlineMod{name: unraw(code[loc[4]:loc[5]]), start: loc[7], end: end})
+	}
+	return mods
+}
+
+// buildModTree follows `mod` declarations from every crate root, through #[path] attributes and inline module blocks
+// as rustc does. It returns nil when the tree has no crate roots, since a loose directory of .rs files has no module
+// tree to check.
+func buildModTree(root string, filter *PathFilter) (*ModTree, error) {
+	roots := crateRoots(root)
+	if len(roots) == 0 { return nil, nil }
+	tree := &ModTree{Paths: make(map[string]string), Dead: []string{}, Missing: []MissingMod{}, Cfgs: make(map[string][]string)}
+	var visit func(rel, modPath string, modRS bool, cfgs []string) error
+	visit = func(rel, modPath string, modRS bool, cfgs []string) error {
+		if _, seen := tree.Paths[rel]; seen { return nil }
+		tree.Paths[rel] = modPath
+		if len(cfgs) > 0 { tree.Cfgs[rel] = cfgs }
+		content, err := os.ReadFile(filepath.Join(root, rel))
+		if err != nil { return err }
+		code := StripNonCode(string(content))
+		// Children of crate roots, mod.rs and #[path] files live beside them; children of foo.rs live in foo/.
+		dir := pathDir(rel)
+		if !modRS && filepath.Base(rel) != "mod.rs" { dir = strings.TrimSuffix(rel, ".rs") }
+		inline, blocks := inlineMods(code), CfgBlocks(code)
+		for _, loc := range modDeclRegex.FindAllStringSubmatchIndex(code, -1) {
+			if code[loc[6]:loc[7]] != ";" { continue }
+			name := unraw(code[loc[4]:loc[5]])
+			// A declaration inside `mod a { mod b { ... } }` belongs to a::b and looks in a/b/.
+			parentPath, parentDir := modPath, dir
+			for _, m := range inline {
+				if m.start <= loc[0] && loc[0] < m.end { parentPath, parentDir = parentPath+"::"+m.name, joinRel(parentDir, m.name) }
+			}
+			candidates := []string{joinRel(parentDir, name+".rs"), joinRel(parentDir, name+"/mod.rs")}
+			attr := pathAttrRegex.FindStringSubmatch(code[loc[2]:loc[3]])
+			if attr != nil {
+				base := parentDir // relative to the file's own directory, unless inside an inline block
+				if parentPath == modPath { base = pathDir(rel) }
+				candidates = []string{filepath.ToSlash(filepath.Clean(joinRel(base, attr[1])))}
+			}
+			found := ""
+			for _, candidate := range candidates {
+				if _, err := os.Stat(filepath.Join(root, candidate)); err == nil { found = candidate; break }
+			}
+			if found == "" {
+				tree.Missing = append(tree.Missing, MissingMod{File: rel, Name: name, Line: strings.Count(code[:loc[4]], "\n") + 1})
+				continue
+			}
+			gated := append(slices.Clone(cfgs), CfgPredicates(code, loc[3], blocks)...)
+			if err := visit(found, parentPath+"::"+name, attr != nil, gated); err != nil { return err }
+		}
+		return nil
+	}
+	for _, rel := range sortedKeys(roots) { if err := visit(rel, roots[rel], true, nil); err != nil { return nil, err } }
+
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && filter.Skip(path, d.IsDir()) { if d.IsDir() { return filepath.SkipDir }; return nil }
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".rs") { return err }
+		rel, err := filepath.Rel(root, path)
+		if err != nil { return err }
+		if _, ok := tree.Paths[filepath.ToSlash(rel)]; !ok { tree.Dead = append(tree.Dead, filepath.ToSlash(rel)) }
+		return nil
+	})
+	sort.Strings(tree.Dead)
+	return tree, err
+}
+
+func pathDir(rel string) string { if i := strings.LastIndex(rel, "/"); i >= 0 { return rel[:i] }; return "" }
+
+func joinRel(dir, name string) string { if dir == "" { return name }; return dir + "/" + name }
@@ -0,0 +1,41 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// parsePreludes parses the `preludes` paths of dependant.toml: items every file can use without a use statement, such
+// as macros made crate-wide with #[macro_use] or a prelude a build tool injects. Each is a crate path, globs included:
+//
+//	preludes = ["crate::prelude::*", "crate::macros::trace"]
+func parsePreludes(paths []string) ([]useLeaf, error) {
+	var leaves []useLeaf
+	for _, p := range paths {
+		parsed, err := parseUseTree(p)
+		if err != nil { return nil, fmt.Errorf("preludes entry %q: %w", p, err) }
+		for _, leaf := range parsed {
+			if leaf.Path[0] != "crate" || len(leaf.Path) < 2 { return nil, fmt.Errorf("preludes entry %q: expected a path starting with crate::", p) }
+			leaves = append(leaves, leaf)
+		}
+	}
+	return leaves, nil
+}
+
+// recordPreludeUses counts site as importing each implicit prelude item its code mentions, the way a glob import is
+// counted. A file does not import the items of own, its own module, and a prelude the file never mentions adds no edge.
+func recordPreludeUses(preludes []useLeaf, code string, site useSite, own string, graph *DependencyGraph, resolver *importResolver) {
+	site.Inferred = inferredPrelude
+	for _, leaf := range preludes {
+		moduleName := resolver.rootModule
+		if len(leaf.Path) > 2 { moduleName = leaf.Path[1] }
+		names := []string{leaf.Path[len(leaf.Path)-1]}
+		if names[0] == "*" { names = resolver.exported(moduleName) }
+		for _, name := range names {
+			if r, err := regexp.Compile(`\b` + name + `\b`); err != nil || !r.MatchString(code) { continue }
+			module, item := resolver.resolve(moduleName, name)
+			if module == own || (len(leaf.Path) == 2 && module == moduleName) { continue }
+			recordImport(graph, site, module, item)
+		}
+	}
+}
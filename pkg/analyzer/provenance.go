@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+	"fmt"
+)
+
+// Provenance says how a metric or finding was produced, so a reader can judge how far to trust each data point.
+type Provenance struct {
+	Source     string   `json:"source"`            // the pass or input that produced it
+	Confidence string   `json:"confidence"`        // "precise" when read from declarations, "heuristic" when inferred
+	Caveats    []string `json:"caveats,omitempty"` // known ways it can be wrong
+}
+
+const (
+	ConfidencePrecise   = "precise"
+	ConfidenceHeuristic = "heuristic"
+)
+
+// Reasons an import is inferred rather than read from a statement naming the item (see DependencyGraph.Inferred).
+const (
+	InferredGlob      = "glob import: items are matched by name anywhere in the file"
+	inferredPrelude   = "implicit prelude: items are matched by name anywhere in the file"
+	inferredDotImport = "dot import: identifiers are matched by name against the package's API"
+	inferredMacroUse  = "#[macro_use]: macro invocations are matched by name to the module defining them"
+)
+
+// recordInferred keeps DependencyGraph.Inferred in step with one recorded item import: a statement naming the item
+// makes the file's import precise for good, while an inferred import only marks a file nothing has named it in yet.
+func recordInferred(graph *DependencyGraph, site useSite, module, item string, seen bool) {
+	if graph.Inferred == nil { graph.Inferred = make(map[string]map[string]map[string]string) }
+	if site.Inferred == "" { delete(graph.Inferred[module][item], site.File); return }
+	if _, inferred := graph.Inferred[module][item][site.File]; seen && !inferred { return }
+	if graph.Inferred[module] == nil { graph.Inferred[module] = make(map[string]map[string]string) }
+	if graph.Inferred[module][item] == nil { graph.Inferred[module][item] = make(map[string]string) }
+	graph.Inferred[module][item][site.File] = site.Inferred
+}
+
+// ItemProvenance is the provenance of one imported item when some of its importing files were inferred, nil when every
+// importer names it in a statement. It is heuristic only when no importer names it.
+func (g *DependencyGraph) ItemProvenance(module, item string) *Provenance {
+	inferred := g.Inferred[module][item]
+	if len(inferred) == 0 { return nil }
+	reasons := make(map[string]struct{})
+	for _, why := range inferred { reasons[why] = struct{}{} }
+	p := &Provenance{Source: "inferred from names used in the importing files", Confidence: ConfidenceHeuristic, Caveats: sortedKeys(reasons)}
+	if len(inferred) < len(g.ItemImports[module][item]) {
+		p.Source, p.Confidence = "named by import statements, and inferred in some importing files", ConfidencePrecise
+		p.Caveats = append(p.Caveats, fmt.Sprintf("%d of %d importing files inferred", len(inferred), len(g.ItemImports[module][item])))
+	}
+	return p
+}
@@ -0,0 +1,329 @@
+package analyzer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/internal/toml"
+)
+
+// The Python backend treats every .py file as a module named by its dotted path below the root (or below src/), a
+// package by its __init__.py: src/shop/cart/models.py is shop.cart.models and shop/cart/__init__.py is shop.cart.
+// Top-level definitions not starting with an underscore are the public items, or the names in __all__ when a module
+// declares it. `from package.module import Name` imports Name from the module, or the submodule when Name is one;
+// `import package.module` imports the attributes read through it. Packages outside the tree are external crates and
+// the standard library is skipped.
+type pythonAnalyzer struct{}
+
+func (pythonAnalyzer) Name() string { return "python" }
+
+func IsPythonFile(name string) bool { return strings.HasSuffix(name, ".py") || strings.HasSuffix(name, ".pyi") }
+
+// pyDottedPath is the full dotted path of a Python file: its module name before --aggregate and --depth apply.
+func (n *moduleNaming) pyDottedPath(file string) string {
+	rel := strings.TrimPrefix(relSlash(n.root, file), "src/")
+	rel = strings.TrimSuffix(strings.TrimSuffix(rel, ".pyi"), ".py")
+	if rel = strings.TrimSuffix(rel, "/__init__"); rel == "__init__" { return rel }
+	return strings.ReplaceAll(rel, "/", ".")
+}
+
+// pyModule names the module a Python file is, honoring --aggregate dir (the top-level package) and --depth (the
+// first that many dotted segments).
+func (n *moduleNaming) pyModule(file string) string {
+	dotted := n.pyDottedPath(file)
+	parts := strings.Split(dotted, ".")
+	switch {
+	case n.aggregate && len(parts) > 1: return parts[0]
+	case n.depth > 0 && len(parts) > n.depth: return strings.Join(parts[:n.depth], ".")
+	}
+	return dotted
+}
+
+// pyRequirementName is the distribution a requirement names: `PyYAML[cli]>=6` gives PyYAML.
+func pyRequirementName(requirement string) string {
+	requirement = strings.TrimSpace(requirement)
+	if end := strings.IndexAny(requirement, " [<>=!~;@("); end >= 0 { requirement = requirement[:end] }
+	return requirement
+}
+
+// pyImportName is how code imports a distribution, by the usual normalization: Flask-Login gives flask_login.
+// Distributions whose import name differs from their name, such as PyYAML's yaml, are not mapped.
+func pyImportName(dist string) string { return strings.ToLower(strings.NewReplacer("-", "_", ".", "_").Replace(dist)) }
+
+// loadPythonProject reads the project name, version and dependencies from pyproject.toml ([project] or
+// [tool.poetry]) and requirements.txt, and locked versions from poetry.lock or uv.lock. Missing files are no error.
+func loadPythonProject(root string) (*CargoManifest, []LockedPackage, error) {
+	m := &CargoManifest{Dependencies: make(map[string]string)}
+	content, err := os.ReadFile(filepath.Join(root, "pyproject.toml"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) { return nil, nil, err }
+	if err == nil {
+		doc, err := toml.Parse(string(content))
+		if err != nil { return nil, nil, fmt.Errorf("pyproject.toml: %w", err) }
+		project, poetry := toml.Table(doc["project"]), toml.Table(toml.Table(doc["tool"])["poetry"])
+		for _, t := range []map[string]any{poetry, project} {
+			if name := toml.String(t["name"]); name != "" { m.Name, m.Version, m.Library = name, toml.String(t["version"]), true }
+		}
+		requirements := toml.Strings(project["dependencies"])
+		for _, group := range toml.Table(project["optional-dependencies"]) { requirements = append(requirements, toml.Strings(group)...) }
+		for _, req := range requirements { dist := pyRequirementName(req); m.Dependencies[pyImportName(dist)] = dist }
+		for name := range toml.Table(poetry["dependencies"]) { if name != "python" { m.Dependencies[pyImportName(name)] = name } }
+	}
+	if f, err := os.Open(filepath.Join(root, "requirements.txt")); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(strings.SplitN(scanner.Text(), "#", 2)[0])
+			if line == "" || strings.HasPrefix(line, "-") { continue }
+			dist := pyRequirementName(line)
+			m.Dependencies[pyImportName(dist)] = dist
+		}
+		f.Close()
+	}
+	m.LibName = pyImportName(m.Name)
+	var locked []LockedPackage
+	for _, lockfile := range []string{"poetry.lock", "uv.lock"} {
+		content, err := os.ReadFile(filepath.Join(root, lockfile))
+		if errors.Is(err, os.ErrNotExist) { continue }
+		if err != nil { return nil, nil, err }
+		doc, err := toml.Parse(string(content))
+		if err != nil { return nil, nil, fmt.Errorf("%s: %w", lockfile, err) }
+		list, _ := doc["package"].([]any)
+		for _, p := range list {
+			t := toml.Table(p)
+			source := toml.Table(t["source"])
+			pkg := LockedPackage{Name: toml.String(t["name"]), Version: toml.String(t["version"]), Source: "registry+https://pypi.org/simple"}
+			switch {
+			case toml.String(source["git"]) != "": pkg.Source = "git+" + toml.String(source["git"])
+			case toml.String(source["registry"]) != "": pkg.Source = "registry+" + toml.String(source["registry"])
+			case toml.String(source["editable"]) != "", toml.String(source["directory"]) != "", toml.String(source["virtual"]) != "": pkg.Source = ""
+			}
+			locked = append(locked, pkg)
+		}
+		break
+	}
+	return m, locked, nil
+}
+
+func (pythonAnalyzer) Load(a *Report) error {
+	var err error
+	if a.Manifest, a.Lockfile, err = loadPythonProject(a.Root); err != nil { return fmt.Errorf("reading pyproject.toml: %w", err) }
+	return nil
+}
+
+// pyStripCode blanks out comments and string literals, triple-quoted ones included, and joins backslash-continued
+// lines, keeping newlines and the length of src so offsets still give line numbers.
+func pyStripCode(src string) string {
+	out := []byte(src)
+	for i := 0; i < len(src); {
+		switch c := src[i]; {
+		case c == '#':
+			for ; i < len(src) && src[i] != '\n'; i++ { out[i] = ' ' }
+		case c == '\\' && i+1 < len(src) && src[i+1] == '\n':
+			out[i], out[i+1] = ' ', ' '
+			i += 2
+		case c == '"' || c == '\'':
+			quote := string(c)
+			if strings.HasPrefix(src[i:], strings.Repeat(quote, 3)) { quote = strings.Repeat(quote, 3) }
+			j := i + len(quote)
+			for ; j < len(src) && !strings.HasPrefix(src[j:], quote); j++ {
+				if src[j] == '\\' { j++ } else if src[j] == '\n' && len(quote) == 1 { break }
+			}
+			end := min(j+len(quote), len(src))
+			for k := i; k < end; k++ { if out[k] != '\n' { out[k] = ' ' } }
+			i = end
+		default:
+			i++
+		}
+	}
+	return string(out)
+}
+
+var (
+	pyFromImportRegex = regexp.MustCompile(`(?m)^[ \t]*from[ \t]+(\.*[\w.]*)[ \t]+import[ \t]+(\([^)]*\)|[^\n;]+)`)
+	pyImportRegex     = regexp.MustCompile(`(?m)^[ \t]*import[ \t]+([^\n;]+)`)
+	pyDefinitionRegex = regexp.MustCompile(`(?m)^(?:async[ \t]+def|def|class)[ \t]+([A-Za-z]\w*)|^([A-Za-z]\w*)[ \t]*(?::[^=\n]+)?=[^=]`)
+	pyAllRegex        = regexp.MustCompile(`(?ms)^__all__[ \t]*(?::[^=\n]*)?\+?=[ \t]*[\[(](.*?)[\])]`)
+	pyQuotedRegex     = regexp.MustCompile(`['"](\w+)['"]`)
+	pyWordRegex       = regexp.MustCompile(`\w+`)
+)
+
+// pyImportedNames parses the name list of an import statement: `a as b, c` gives [a b] and [b c]; a bare `*` is kept.
+func pyImportedNames(list string) (names, locals []string) {
+	for _, spec := range strings.Split(strings.Trim(strings.TrimSpace(list), "()"), ",") {
+		fields := strings.Fields(spec)
+		if len(fields) == 0 { continue }
+		names, locals = append(names, fields[0]), append(locals, fields[len(fields)-1])
+	}
+	return names, locals
+}
+
+// walkPythonFiles visits every Python file the filter keeps with its content, comments and strings blanked.
+func walkPythonFiles(a *Report, visit func(path, content, code string)) error {
+	filter := a.Config.PathFilter(a.Root)
+	return filepath.WalkDir(a.Root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && filter.Skip(path, d.IsDir()) { if d.IsDir() { return filepath.SkipDir }; return nil }
+		if err != nil || d.IsDir() || !IsPythonFile(d.Name()) { return err }
+		content, err := os.ReadFile(path)
+		if err != nil { return err }
+		visit(path, string(content), pyStripCode(string(content)))
+		return nil
+	})
+}
+
+// pyIsTestFile reports whether path is a test: test_*.py, *_test.py and conftest.py files, and anything under tests/.
+func pyIsTestFile(root, path string) bool {
+	base := strings.TrimSuffix(filepath.Base(path), ".py")
+	return strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test") || base == "conftest" || isTestFile(root, path) ||
+		slices.Contains(strings.Split(relSlash(root, path), "/"), "test")
+}
+
+func (pythonAnalyzer) SymbolTable(a *Report) (map[string]map[string]struct{}, *ModuleFacts, error) {
+	table := make(map[string]map[string]struct{})
+	facts := &ModuleFacts{Tags: make(map[string]string), UnsafeBlocks: make(map[string]int), UnsafeFns: make(map[string]int), LOC: make(map[string]int), Generated: make(map[string]bool), ReExports: make(map[string]map[string]ReExport), ReExportGlobs: make(map[string][]string), Restricted: make(map[string]map[string]string), ModulePaths: make(map[string]string)}
+	err := walkPythonFiles(a, func(path, content, code string) {
+		moduleName := a.ModuleName(path)
+		if _, ok := table[moduleName]; !ok { table[moduleName] = make(map[string]struct{}) }
+		generated := a.Config.Generated.isGeneratedFile(a.Root, path, content)
+		if seen, ok := facts.Generated[moduleName]; !ok || seen { facts.Generated[moduleName] = generated }
+		if m := tagMarkerRegex.FindStringSubmatch(content); m != nil { facts.Tags[moduleName] = m[1] }
+		for _, line := range strings.Split(code, "\n") { if strings.TrimSpace(line) != "" { facts.LOC[moduleName]++ } }
+		if pyIsTestFile(a.Root, path) { return }
+		if all := pyAllRegex.FindAllStringSubmatch(content, -1); all != nil {
+			for _, m := range all { for _, q := range pyQuotedRegex.FindAllStringSubmatch(m[1], -1) { table[moduleName][q[1]] = struct{}{} } }
+			return
+		}
+		for _, m := range pyDefinitionRegex.FindAllStringSubmatch(code, -1) {
+			if name := m[1] + m[2]; name != "" { table[moduleName][name] = struct{}{} }
+		}
+	})
+	return table, facts, err
+}
+
+// pythonStdlib are the top-level modules of the standard library an import may name.
+var pythonStdlib = map[string]struct{}{
+	"__future__": {}, "abc": {}, "argparse": {}, "array": {}, "ast": {}, "asyncio": {}, "base64": {}, "bisect": {}, "builtins": {},
+	"calendar": {}, "collections": {}, "concurrent": {}, "configparser": {}, "contextlib": {}, "contextvars": {}, "copy": {},
+	"csv": {}, "ctypes": {}, "dataclasses": {}, "datetime": {}, "decimal": {}, "difflib": {}, "email": {}, "enum": {}, "errno": {},
+	"fnmatch": {}, "fractions": {}, "functools": {}, "gc": {}, "getpass": {}, "glob": {}, "gzip": {}, "hashlib": {}, "heapq": {},
+	"hmac": {}, "html": {}, "http": {}, "importlib": {}, "inspect": {}, "io": {}, "ipaddress": {}, "itertools": {}, "json": {},
+	"logging": {}, "math": {}, "mimetypes": {}, "multiprocessing": {}, "operator": {}, "os": {}, "pathlib": {}, "pickle": {},
+	"platform": {}, "pprint": {}, "queue": {}, "random": {}, "re": {}, "secrets": {}, "select": {}, "shlex": {}, "shutil": {},
+	"signal": {}, "socket": {}, "sqlite3": {}, "ssl": {}, "statistics": {}, "string": {}, "struct": {}, "subprocess": {}, "sys": {},
+	"tempfile": {}, "textwrap": {}, "threading": {}, "time": {}, "timeit": {}, "tomllib": {}, "traceback": {}, "types": {},
+	"typing": {}, "unittest": {}, "urllib": {}, "uuid": {}, "warnings": {}, "weakref": {}, "xml": {}, "zipfile": {}, "zlib": {},
+	"zoneinfo": {},
+}
+
+// pyResolveModule finds the file of a dotted module path under root or root/src: a module file or a package's
+// __init__.py.
+func pyResolveModule(root, dotted string) string {
+	if dotted == "" { return "" }
+	rel := filepath.FromSlash(strings.ReplaceAll(dotted, ".", "/"))
+	for _, base := range []string{filepath.Join(root, "src", rel), filepath.Join(root, rel)} {
+		for _, candidate := range []string{base + ".py", base + ".pyi", filepath.Join(base, "__init__.py")} {
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() { return candidate }
+		}
+	}
+	return ""
+}
+
+// pyAbsolute turns the module of a `from` statement in file into a dotted path: `..util` in shop/cart/models.py is
+// shop.util. It reports false when the dots climb above the root.
+func (n *moduleNaming) pyAbsolute(file, from string) (string, bool) {
+	dots := len(from) - len(strings.TrimLeft(from, "."))
+	if dots == 0 { return from, true }
+	pkg := strings.Split(n.pyDottedPath(file), ".")
+	if filepath.Base(file) != "__init__.py" { pkg = pkg[:len(pkg)-1] }
+	if dots-1 > len(pkg) { return "", false }
+	parts := append(slices.Clone(pkg[:len(pkg)-(dots-1)]), strings.Split(from[dots:], ".")...)
+	return strings.Trim(strings.Join(parts, "."), "."), true
+}
+
+// pySelectors lists the attributes code reads through the dotted local name ns: `np.array` gives array.
+func pySelectors(code, ns string) []string {
+	seen := make(map[string]struct{})
+	for _, m := range regexp.MustCompile(`(?:^|[^\w.])`+regexp.QuoteMeta(ns)+`\.(\w+)`).FindAllStringSubmatch(code, -1) { seen[m[1]] = struct{}{} }
+	return sortedKeys(seen)
+}
+
+func (pythonAnalyzer) Dependencies(a *Report) (*DependencyGraph, error) {
+	graph := &DependencyGraph{
+		Deps:        make(map[string]map[string]struct{}),
+		ProdDeps:    make(map[string]map[string]struct{}),
+		ItemImports: make(map[string]map[string]map[string]struct{}),
+		Conditions:  make(map[string]map[string]map[string]struct{}),
+		External:    make(map[string]map[string]map[string]struct{}),
+		Inferred:    make(map[string]map[string]map[string]string),
+	}
+	external := func(dotted, path string, items []string) {
+		top, sub, _ := strings.Cut(dotted, ".")
+		if _, std := pythonStdlib[top]; std || top == "" { return }
+		for _, item := range items {
+			if sub != "" { item = sub + "." + item }
+			if graph.External[top] == nil { graph.External[top] = make(map[string]map[string]struct{}) }
+			if graph.External[top][item] == nil { graph.External[top][item] = make(map[string]struct{}) }
+			graph.External[top][item][path] = struct{}{}
+		}
+	}
+	err := walkPythonFiles(a, func(path, content, code string) {
+		site := useSite{File: path, Content: content, IsTest: pyIsTestFile(a.Root, path)}
+		if a.leavesOut(site) { return }
+		record := func(file string, items []string) {
+			module := a.ModuleName(file)
+			if len(items) == 0 { recordImport(graph, site, module, ""); return }
+			for _, item := range items { recordImport(graph, site, module, item) }
+		}
+		for _, loc := range pyImportRegex.FindAllStringSubmatchIndex(code, -1) {
+			names, locals := pyImportedNames(code[loc[2]:loc[3]])
+			for i, dotted := range names {
+				binding := dotted
+				if locals[i] != dotted { binding = locals[i] }
+				file, prefix := "", dotted
+				for ; prefix != ""; prefix = prefix[:max(strings.LastIndex(prefix, "."), 0)] {
+					if file = pyResolveModule(a.Root, prefix); file != "" { break }
+				}
+				if file == "" { external(dotted, path, pySelectors(code, binding)); continue }
+				record(file, pySelectors(code, binding))
+			}
+		}
+		for _, loc := range pyFromImportRegex.FindAllStringSubmatchIndex(code, -1) {
+			from, statement := code[loc[2]:loc[3]], code[loc[0]:loc[1]]
+			dotted, ok := a.names().pyAbsolute(path, from)
+			if !ok { recordUnparsed(graph, path, code, loc[0], statement, fmt.Errorf("%q climbs above the root", from)); continue }
+			file := pyResolveModule(a.Root, dotted)
+			names, locals := pyImportedNames(code[loc[4]:loc[5]])
+			var items []string
+			submodules := 0
+			for i, name := range names {
+				if name == "*" && file != "" {
+					globSite := site
+					globSite.Inferred = InferredGlob
+					module := a.ModuleName(file)
+					words := make(map[string]struct{})
+					for _, w := range pyWordRegex.FindAllString(code, -1) { words[w] = struct{}{} }
+					used := 0
+					for item := range a.SymbolTable[module] { if _, ok := words[item]; ok { recordImport(graph, globSite, module, item); used++ } }
+					if used == 0 { recordImport(graph, site, module, "") }
+					continue
+				}
+				if sub := pyResolveModule(a.Root, strings.TrimPrefix(dotted+"."+name, ".")); sub != "" {
+					record(sub, pySelectors(code, locals[i]))
+					submodules++
+					continue
+				}
+				items = append(items, name)
+			}
+			switch {
+			case file != "" && len(items) > 0: record(file, items)
+			case file == "" && !strings.HasPrefix(from, "."): external(dotted, path, items)
+			case file == "" && submodules == 0: recordUnparsed(graph, path, code, loc[0], statement, fmt.Errorf("cannot resolve %q", from))
+			}
+		}
+	})
+	return graph, err
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestPyStripCode(t *testing.T) {
+	tests := []struct{ src, want string }{
+		{"import a  # import b", "import a            "},
+		{`s = "from a import b"`, `s =                  `},
+		{"d = '''\nimport x\n'''\nimport y", "d =    \n        \n   \nimport y"},
+		{"from a import (b,\\\n    c)", "from a import (b,      c)"}, // the continuation is joined
+		{`s = 'it\'s' + x`, `s =         + x`},
+	}
+	for _, tt := range tests {
+		if got := pyStripCode(tt.src); got != tt.want { t.Errorf("pyStripCode(%q) = %q, want %q", tt.src, got, tt.want) }
+	}
+}
+
+func TestPyImportedNames(t *testing.T) {
+	tests := []struct {
+		list          string
+		names, locals []string
+	}{
+		{list: "os", names: []string{"os"}, locals: []string{"os"}},
+		{list: "numpy as np, os.path", names: []string{"numpy", "os.path"}, locals: []string{"np", "os.path"}},
+		{list: "(a,\n    b as c,\n)", names: []string{"a", "b"}, locals: []string{"a", "c"}},
+		{list: "*", names: []string{"*"}, locals: []string{"*"}},
+	}
+	for _, tt := range tests {
+		names, locals := pyImportedNames(tt.list)
+		if !reflect.DeepEqual(names, tt.names) || !reflect.DeepEqual(locals, tt.locals) {
+			t.Errorf("pyImportedNames(%q) = %q, %q; want %q, %q", tt.list, names, locals, tt.names, tt.locals)
+		}
+	}
+}
+
+func TestPyAbsolute(t *testing.T) {
+	n := &moduleNaming{root: "/r"}
+	tests := []struct {
+		file, from, want string
+		ok               bool
+	}{
+		{"/r/shop/cart/models.py", "shop.util", "shop.util", true},
+		{"/r/shop/cart/models.py", ".", "shop.cart", true},
+		{"/r/shop/cart/models.py", ".views", "shop.cart.views", true},
+		{"/r/shop/cart/models.py", "..util", "shop.util", true},
+		{"/r/shop/cart/__init__.py", ".models", "shop.cart.models", true},
+		{"/r/src/shop/a.py", ".b", "shop.b", true},
+		{"/r/shop/cart/models.py", "....util", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := n.pyAbsolute(tt.file, tt.from)
+		if got != tt.want || ok != tt.ok { t.Errorf("pyAbsolute(%q, %q) = %q, %v; want %q, %v", tt.file, tt.from, got, ok, tt.want, tt.ok) }
+	}
+}
+
+func TestPythonDependencies(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"pyproject.toml":         "[project]\nname = \"shop\"\ndependencies = [\"requests>=2\"]\n",
+		"shop/__init__.py":       "",
+		"shop/util.py":           "def fmt(): pass\nclass Money: pass\n",
+		"shop/cart/__init__.py":  "",
+		"shop/cart/models.py":    "from ..util import fmt, Money as M\nimport requests\nimport os\nrequests.get(os.sep)\n# from shop.gone import x\ns = 'from shop.fake import y'\nfrom ....far import z\nclass Cart: pass\n",
+		"shop/cart/views.py":     "from shop.cart import models\nmodels.Cart()\nfrom .missing import w\n",
+		"tests/test_models.py":   "from shop.util import fmt\n",
+	})
+	a, err := Analyze(root, Options{})
+	if err != nil { t.Fatal(err) }
+	got := importedItems(a.Graph)
+	want := []string{"requests!get", "shop.cart.models::Cart", "shop.util::Money", "shop.util::fmt"}
+	if !reflect.DeepEqual(got, want) { t.Errorf("imports = %q, want %q", got, want) }
+	var unparsed []string
+	for _, u := range a.Graph.Unparsed { unparsed = append(unparsed, filepath.Base(u.File)+": "+u.Err) }
+	sort.Strings(unparsed)
+	if want := []string{`models.py: "....far" climbs above the root`, `views.py: cannot resolve ".missing"`}; !reflect.DeepEqual(unparsed, want) { t.Errorf("unparsed = %q, want %q", unparsed, want) }
+	if _, ok := a.Graph.ProdDeps[filepath.Join(root, "tests", "test_models.py")]; ok { t.Errorf("the test file's imports count as production imports") }
+}
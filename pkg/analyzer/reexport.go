@@ -0,0 +1,131 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"regexp"
+)
+
+var pubUseRegex = regexp.MustCompile(`\bpub(?:\s*\([^)]*\))?\s+use\s+([\s\S]*?);`)
+
+// ReExport is where an item re-exported with `pub use` is actually defined.
+type ReExport struct {
+	Module string `json:"module"`
+	Item   string `json:"item"`
+}
+
+// pubUse is one leaf of a `pub use` statement, waiting for every module name to be known before it is resolved.
+type pubUse struct {
+	facade       string   // module of the re-exporting file
+	self, parent []string // module paths `self` and `super` name in the file
+	crateRoot    bool     // the file is src/lib.rs or src/main.rs, where bare paths name the crate's modules
+	leaf         useLeaf  // e.g. `crate::cpu::Engine as CpuEngine`
+}
+
+// isCrateRootFile reports whether path is src/lib.rs or src/main.rs, where a bare path such as cpu::Engine names the
+// crate's own module cpu rather than a crate.
+func isCrateRootFile(path string) bool {
+	return filepath.Base(filepath.Dir(path)) == "src" && (filepath.Base(path) == "lib.rs" || filepath.Base(path) == "main.rs")
+}
+
+// localModule reports whether first, the first segment of a bare path, names one of the crate's modules.
+func (r *importResolver) localModule(first string) bool {
+	if _, n := resolveModulePath(r.modulePaths, []string{first}); n > 0 { return true }
+	_, known := r.symbolTable[first]
+	return known
+}
+
+func collectPubUses(n *moduleNaming, path, code string) []pubUse {
+	crateRoot := isCrateRootFile(path)
+	facade := n.name(path)
+	self, ok := n.modulePath(path)
+	if !ok { self = []string{facade} }
+	var uses []pubUse
+	for _, match := range pubUseRegex.FindAllStringSubmatch(code, -1) {
+		leaves, _ := parseUseTree(match[1]) // Pass 2 reports the statements it cannot parse
+		for _, leaf := range leaves {
+			uses = append(uses, pubUse{facade: facade, self: self, parent: n.superPath(path), crateRoot: crateRoot, leaf: leaf})
+		}
+	}
+	return uses
+}
+
+// resolveReExports maps facade module -> exported name -> origin, and facade module -> modules it glob re-exports.
+// Paths are resolved the way Pass 2 resolves use statements: to the most specific module in paths, else the first
+// segment after `crate`.
+func resolveReExports(uses []pubUse, table map[string]map[string]struct{}, paths map[string]string, libName, rootModule string) (map[string]map[string]ReExport, map[string][]string) {
+	reExports, globs := make(map[string]map[string]ReExport), make(map[string][]string)
+	for _, u := range uses {
+		segments := u.leaf.Path
+		var abs []string
+		switch first := segments[0]; {
+		case first == "crate" || (first == libName && libName != ""): abs = segments[1:]
+		case first == "super": abs = append(append([]string(nil), u.parent...), segments[1:]...)
+		case first == "self": abs = append(append([]string(nil), u.self...), segments[1:]...)
+		case u.crateRoot:
+			if _, known := table[first]; !known { continue } // another crate's item
+			abs = segments
+		default: abs = append(append([]string(nil), u.self...), segments...) // relative to the facade's own submodules
+		}
+		if len(abs) == 0 { continue }
+		item, module := abs[len(abs)-1], rootModule
+		if len(abs) >= 2 { module = abs[0] }
+		if m, n := resolveModulePath(paths, abs[:len(abs)-1]); n > 0 { module = m }
+		if item == "*" {
+			if len(abs) >= 2 && module != u.facade { globs[u.facade] = append(globs[u.facade], module) }
+			continue
+		}
+		origin := ReExport{Module: module, Item: item}
+		exported := u.leaf.Alias
+		if exported == "" || exported == "_" { exported = item }
+		if item == "self" || origin.Module == "" || (origin.Module == u.facade && origin.Item == exported) { continue }
+		if reExports[u.facade] == nil { reExports[u.facade] = make(map[string]ReExport) }
+		reExports[u.facade][exported] = origin
+	}
+	return reExports, globs
+}
+
+// importResolver attributes imported items to the module that defines them, following re-export chains through facades
+// such as a prelude. With follow unset it leaves imports on the module they were written against.
+type importResolver struct {
+	symbolTable map[string]map[string]struct{}
+	reExports   map[string]map[string]ReExport
+	globs       map[string][]string
+	modulePaths map[string]string // see ModuleFacts.ModulePaths
+	rootModule  string
+	follow      bool
+}
+
+func newImportResolver(a *Report) *importResolver {
+	return &importResolver{symbolTable: a.SymbolTable, reExports: a.Facts.ReExports, globs: a.Facts.ReExportGlobs, modulePaths: a.Facts.ModulePaths, rootModule: a.names().rootModule(), follow: a.Options.ReExports != "facade"}
+}
+
+// rootModule is the module name `use crate::Item` resolves to: the library's, else the binary's.
+func (n *moduleNaming) rootModule() string {
+	if name, ok := n.rootNames["lib.rs"]; ok { return name }
+	if name, ok := n.rootNames["main.rs"]; ok { return name }
+	return n.name(filepath.Join(n.root, "src", "lib.rs"))
+}
+
+func (r *importResolver) resolve(module, item string) (string, string) {
+	if !r.follow { return module, item }
+	for hops := 0; hops < 16; hops++ { // re-export chains are short; the bound only guards against cycles
+		if origin, ok := r.reExports[module][item]; ok { module, item = origin.Module, origin.Item; continue }
+		next := ""
+		for _, g := range r.globs[module] {
+			if _, ok := r.symbolTable[g][item]; ok { next = g; break }
+			if _, ok := r.reExports[g][item]; ok { next = g; break }
+		}
+		if next == "" { break }
+		module = next
+	}
+	return module, item
+}
+
+// exported lists the names a glob import of module brings into scope: its own public items and everything it re-exports.
+func (r *importResolver) exported(module string) []string {
+	names := make(map[string]struct{})
+	for item := range r.symbolTable[module] { names[item] = struct{}{} }
+	for item := range r.reExports[module] { names[item] = struct{}{} }
+	for _, g := range r.globs[module] { for item := range r.symbolTable[g] { names[item] = struct{}{} } }
+	return sortedKeys(names)
+}
@@ -0,0 +1,164 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxUseTreeDepth bounds group nesting; real code stays in single digits, so anything deeper is malformed input.
+const maxUseTreeDepth = 64
+
+// useLeaf is one path a use tree imports: `a::{b, c::d as e}` has the leaves a::b and a::c::d (alias e).
+type useLeaf struct {
+	Path  []string // segments, the last being the item, `self` or `*`
+	Alias string   // the `as` name, "_" included; empty when not renamed
+}
+
+// UseStyle counts how a file writes its use statements: grouped or one path each, renamed, globbed. Summed per module
+// and crate, it lets a team settle its import conventions on numbers.
+type UseStyle struct {
+	Statements    int `json:"statements"`
+	Grouped       int `json:"grouped"`       // statements with a {...} group, e.g. use a::{b, c};
+	GroupedLeaves int `json:"groupedLeaves"` // paths the grouped statements import
+	Aliased       int `json:"aliased"`       // paths renamed with as
+	Globs         int `json:"globs"`         // paths ending in *
+}
+
+// Add sums the counts of o into s.
+func (s *UseStyle) Add(o UseStyle) {
+	s.Statements += o.Statements; s.Grouped += o.Grouped; s.GroupedLeaves += o.GroupedLeaves; s.Aliased += o.Aliased; s.Globs += o.Globs
+}
+
+// Single is the number of statements importing one path without a group, e.g. use a::b;
+func (s UseStyle) Single() int { return s.Statements - s.Grouped }
+
+// AverageGroupSize is the mean number of paths a grouped statement imports, or 0 without any.
+func (s UseStyle) AverageGroupSize() float64 {
+	if s.Grouped == 0 { return 0 }
+	return float64(s.GroupedLeaves) / float64(s.Grouped)
+}
+
+// recordUseStyle counts the use statement tree, with leaves, in file's style.
+func recordUseStyle(graph *DependencyGraph, file, tree string, leaves []useLeaf) {
+	s := graph.UseStyles[file]
+	s.Statements++
+	if strings.Contains(tree, "{") { s.Grouped++; s.GroupedLeaves += len(leaves) }
+	for _, leaf := range leaves {
+		if leaf.Alias != "" { s.Aliased++ }
+		if leaf.Path[len(leaf.Path)-1] == "*" { s.Globs++ }
+	}
+	graph.UseStyles[file] = s
+}
+
+// UnparsedUse is a use statement the use-tree parser rejected. Its imports are missing from the graph, so they are
+// reported rather than dropped silently.
+type UnparsedUse struct {
+	File      string
+	Line      int
+	Statement string // whitespace collapsed
+	Err       string
+}
+
+// tokenizeUse splits a use tree into identifiers, `::`, `{`, `}`, `,` and `*`. Raw identifiers lose their r# prefix.
+// Comments and literals must already be blanked (see StripNonCode).
+func tokenizeUse(src string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(src); {
+		switch c := src[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "::"):
+			tokens = append(tokens, "::")
+			i += 2
+		case c == '{' || c == '}' || c == ',' || c == '*':
+			tokens = append(tokens, string(c))
+			i++
+		case isIdentByte(c):
+			start := i
+			if strings.HasPrefix(src[i:], "r#") { i += 2; start = i }
+			for i < len(src) && isIdentByte(src[i]) { i++ }
+			if i == start { return nil, fmt.Errorf("empty raw identifier") }
+			tokens = append(tokens, src[start:i])
+		default:
+			return nil, fmt.Errorf("unexpected %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+// parseUseTree parses what follows `use` (a leading `::` allowed, the semicolon not) into its leaves, or reports why
+// it cannot: unbalanced braces, a missing `::`, a stray token and the like.
+func parseUseTree(src string) ([]useLeaf, error) {
+	tokens, err := tokenizeUse(src)
+	if err != nil { return nil, err }
+	p := &useTreeParser{tokens: tokens}
+	if p.peek() == "::" { p.pos++ }
+	leaves, err := p.tree(nil, 0)
+	if err != nil { return nil, err }
+	if p.pos < len(p.tokens) { return nil, fmt.Errorf("unexpected %q after the use tree", p.tokens[p.pos]) }
+	return leaves, nil
+}
+
+type useTreeParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *useTreeParser) peek() string {
+	if p.pos < len(p.tokens) { return p.tokens[p.pos] }
+	return ""
+}
+
+func (p *useTreeParser) ident(tok string) bool {
+	return tok != "" && tok != "::" && tok != "{" && tok != "}" && tok != "," && tok != "*" && tok != "as"
+}
+
+// tree parses `path::{...}`, `path::*` or `path [as name]` below prefix.
+func (p *useTreeParser) tree(prefix []string, depth int) ([]useLeaf, error) {
+	if depth > maxUseTreeDepth { return nil, fmt.Errorf("use groups nested deeper than %d", maxUseTreeDepth) }
+	path := append([]string(nil), prefix...)
+	for {
+		switch tok := p.peek(); {
+		case tok == "{":
+			p.pos++
+			var leaves []useLeaf
+			for p.peek() != "}" {
+				sub, err := p.tree(path, depth+1)
+				if err != nil { return nil, err }
+				leaves = append(leaves, sub...)
+				if p.peek() == "," { p.pos++; continue }
+				if p.peek() != "}" { return nil, p.unexpected("`,` or `}`") }
+			}
+			p.pos++
+			return leaves, nil
+		case tok == "*":
+			p.pos++
+			return []useLeaf{{Path: append(path, "*")}}, nil
+		case p.ident(tok):
+			p.pos++
+			path = append(path, tok)
+			if p.peek() == "::" { p.pos++; continue }
+			leaf := useLeaf{Path: path}
+			if p.peek() == "as" {
+				p.pos++
+				if !p.ident(p.peek()) { return nil, p.unexpected("a name after `as`") }
+				leaf.Alias = p.peek()
+				p.pos++
+			}
+			return []useLeaf{leaf}, nil
+		default:
+			return nil, p.unexpected("a path, `{` or `*`")
+		}
+	}
+}
+
+func (p *useTreeParser) unexpected(want string) error {
+	if p.pos >= len(p.tokens) { return fmt.Errorf("expected %s, found the end of the statement", want) }
+	return fmt.Errorf("expected %s, found %q", want, p.tokens[p.pos])
+}
+
+// recordUnparsed notes a use statement starting at offset of content that parseUseTree rejected.
+func recordUnparsed(graph *DependencyGraph, file, content string, offset int, statement string, err error) {
+	line := strings.Count(content[:offset], "\n") + 1
+	graph.Unparsed = append(graph.Unparsed, UnparsedUse{File: file, Line: line, Statement: strings.Join(strings.Fields(statement), " "), Err: err.Error()})
+}
@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenizeUse(t *testing.T) {
+	tests := []struct {
+		src     string
+		want    []string
+		wantErr bool
+	}{
+		{src: "crate::cpu::Engine", want: []string{"crate", "::", "cpu", "::", "Engine"}},
+		{src: "a::{b, c::*}", want: []string{"a", "::", "{", "b", ",", "c", "::", "*", "}"}},
+		{src: "a::b as _", want: []string{"a", "::", "b", "as", "_"}},
+		{src: "r#type::r#match", want: []string{"type", "::", "match"}},
+		{src: "::std::io", want: []string{"::", "std", "::", "io"}},
+		{src: "a\n\t::\r\n{ b }", want: []string{"a", "::", "{", "b", "}"}},
+		{src: "", want: nil},
+		{src: "a::b;", wantErr: true},
+		{src: "a:b", wantErr: true},
+		{src: "r#", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := tokenizeUse(tt.src)
+		if (err != nil) != tt.wantErr { t.Errorf("tokenizeUse(%q) error = %v, want error %v", tt.src, err, tt.wantErr); continue }
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) { t.Errorf("tokenizeUse(%q) = %q, want %q", tt.src, got, tt.want) }
+	}
+}
+
+// leafStrings renders leaves as path[ as alias], which keeps the tables short.
+func leafStrings(leaves []useLeaf) []string {
+	var out []string
+	for _, leaf := range leaves {
+		s := strings.Join(leaf.Path, "::")
+		if leaf.Alias != "" { s += " as " + leaf.Alias }
+		out = append(out, s)
+	}
+	return out
+}
+
+func TestParseUseTree(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []string
+		err  string // a substring of the error; empty when the tree parses
+	}{
+		{src: "crate::cpu::Engine", want: []string{"crate::cpu::Engine"}},
+		{src: "::serde::Serialize", want: []string{"serde::Serialize"}},
+		{src: "crate::net::*", want: []string{"crate::net::*"}},
+		{src: "crate::cpu::Engine as CpuEngine", want: []string{"crate::cpu::Engine as CpuEngine"}},
+		{src: "std::io::Write as _", want: []string{"std::io::Write as _"}},
+		{src: "crate::{a, b::c}", want: []string{"crate::a", "crate::b::c"}},
+		{src: "crate::{a, b,}", want: []string{"crate::a", "crate::b"}},
+		{src: "crate::net::{self, http::{Client, Server as S}, *}", want: []string{"crate::net::self", "crate::net::http::Client", "crate::net::http::Server as S", "crate::net::*"}},
+		{src: "{crate::cpu::Engine, serde::Serialize}", want: []string{"crate::cpu::Engine", "serde::Serialize"}},
+		{src: "crate::{}", want: nil},
+		{src: "crate::{a::{b::{c::{d}}}}", want: []string{"crate::a::b::c::d"}},
+		{src: "crate::r#type::r#match", want: []string{"crate::type::match"}},
+		{src: "crate::{\n    a,\n    b,\n}", want: []string{"crate::a", "crate::b"}},
+		{src: "crate::{a, b", err: "found the end of the statement"},
+		{src: "crate::{a b}", err: "expected `,` or `}`"},
+		{src: "crate::a}", err: "after the use tree"},
+		{src: "crate::", err: "expected a path"},
+		{src: "crate::a as", err: "a name after `as`"},
+		{src: "crate::a as ::", err: "a name after `as`"},
+		{src: "crate::a::b c", err: "after the use tree"},
+		{src: "crate::a;", err: "unexpected"},
+		{src: "crate::" + strings.Repeat("{", maxUseTreeDepth+2) + "a" + strings.Repeat("}", maxUseTreeDepth+2), err: "nested deeper"},
+	}
+	for _, tt := range tests {
+		leaves, err := parseUseTree(tt.src)
+		switch {
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("parseUseTree(%q) error = %v, want one containing %q", tt.src, err, tt.err)
+		case tt.err == "" && err != nil:
+			t.Errorf("parseUseTree(%q) error = %v", tt.src, err)
+		case tt.err == "" && !reflect.DeepEqual(leafStrings(leaves), tt.want):
+			t.Errorf("parseUseTree(%q) = %q, want %q", tt.src, leafStrings(leaves), tt.want)
+		}
+	}
+}
+
+// FuzzParseUseTree checks that no input panics the parser, and that every leaf it returns is a path that parses back
+// to itself. The seeds are use statements as they appear in real crates; testdata/fuzz/FuzzParseUseTree holds more.
+func FuzzParseUseTree(f *testing.F) {
+	for _, seed := range []string{
+		"crate::cpu::Engine",
+		"std::collections::{HashMap, HashSet, hash_map::Entry}",
+		"crate::net::{self, http::{Client, Server as S}, *}",
+		"::serde::{Deserialize, Serialize as _}",
+		"super::super::util::r#type",
+		"{crate::a, super::b::*,}",
+		"crate::{a, b",
+		"crate::a as",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, src string) {
+		leaves, err := parseUseTree(src)
+		if err != nil { return }
+		for _, leaf := range leaves {
+			if len(leaf.Path) == 0 { t.Fatalf("parseUseTree(%q) returned an empty path", src) }
+			for i, segment := range leaf.Path {
+				if segment == "" || (segment == "*" && i != len(leaf.Path)-1) { t.Fatalf("parseUseTree(%q) returned the path %q", src, leaf.Path) }
+			}
+			again := strings.Join(leaf.Path, "::")
+			if leaf.Alias != "" { again += " as " + leaf.Alias }
+			reparsed, err := parseUseTree(again)
+			if err != nil || len(reparsed) != 1 || !reflect.DeepEqual(reparsed[0], leaf) { t.Fatalf("leaf %q of %q parses back as %v (%v)", again, src, reparsed, err) }
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// prCommentMarker identifies dependant's comment so later runs update it instead of adding another.
+const prCommentMarker = "<!-- dependant:pr-comment -->"
+
+// archView is the module-level shape of one analysis that architecture diffs compare.
+type archView struct {
+	Graph   map[string]map[string]struct{}
+	FanIn   map[string]int
+	Inbound map[string]int    // files importing each module
+	Exempt  map[string]bool   // generated modules that regression checks ignore
+	API     map[string]string // fingerprint of each module's public items; see apiFingerprint
+	Public  map[string]int    // public items of each module
+}
+
+// viewOf is what the architecture diff and the edge history compare of a.
+func viewOf(a *analyzer.Report) archView {
+	v := archView{Graph: a.BuildModuleGraph(a.Graph.Deps), FanIn: make(map[string]int), Inbound: make(map[string]int), Exempt: make(map[string]bool), API: make(map[string]string), Public: make(map[string]int)}
+	for _, m := range computeModuleMetrics(a, a.Graph.Deps) { v.FanIn[m.Name] = m.FanIn }
+	for _, deps := range a.Graph.Deps { for m := range deps { v.Inbound[m]++ } }
+	for m := range a.Facts.Generated { if !a.Enforced(m) { v.Exempt[m] = true } }
+	for m, items := range a.SymbolTable { v.API[m], v.Public[m] = apiFingerprint(sortedKeys(items)), len(items) }
+	return v
+}
+
+// FanInDelta is a module whose number of dependent modules changed.
+type FanInDelta struct {
+	Module        string
+	Before, After int
+}
+
+// ArchDiff is what a branch changed about the module graph.
+type ArchDiff struct {
+	NewEdges, RemovedEdges [][2]string
+	NewCycles              [][]string
+	FanIn                  []FanInDelta
+}
+
+// diffArchitecture leaves out modules either view exempts, so codegen churn does not read as a regression.
+func diffArchitecture(before, after archView) ArchDiff {
+	var diff ArchDiff
+	exempt := func(m string) bool { return before.Exempt[m] || after.Exempt[m] }
+	edges := func(from, to map[string]map[string]struct{}) [][2]string {
+		var out [][2]string
+		for _, a := range sortedKeys(to) {
+			for _, b := range sortedKeys(to[a]) { if _, ok := from[a][b]; !ok && !exempt(a) && !exempt(b) { out = append(out, [2]string{a, b}) } }
+		}
+		return out
+	}
+	diff.NewEdges, diff.RemovedEdges = edges(before.Graph, after.Graph), edges(after.Graph, before.Graph)
+	oldCycles := make(map[string]bool)
+	for _, c := range findCycles(before.Graph) { oldCycles[strings.Join(c, ",")] = true }
+	for _, c := range findCycles(after.Graph) {
+		involvesExempt := false
+		for _, m := range c { involvesExempt = involvesExempt || exempt(m) }
+		if !oldCycles[strings.Join(c, ",")] && !involvesExempt { diff.NewCycles = append(diff.NewCycles, c) }
+	}
+	modules := make(map[string]struct{})
+	for m := range before.FanIn { modules[m] = struct{}{} }
+	for m := range after.FanIn { modules[m] = struct{}{} }
+	for _, m := range sortedKeys(modules) {
+		if before.FanIn[m] != after.FanIn[m] && !exempt(m) { diff.FanIn = append(diff.FanIn, FanInDelta{Module: m, Before: before.FanIn[m], After: after.FanIn[m]}) }
+	}
+	sort.SliceStable(diff.FanIn, func(i, j int) bool { return abs(diff.FanIn[i].After-diff.FanIn[i].Before) > abs(diff.FanIn[j].After-diff.FanIn[j].Before) })
+	return diff
+}
+
+func abs(n int) int { if n < 0 { return -n }; return n }
+
+// writePRCommentMarkdown renders a compact summary meant for a pull request comment.
+func writePRCommentMarkdown(w io.Writer, diff ArchDiff, baseDesc string) {
+	fmt.Fprintf(w, "%s\n### 🧭 Dependency changes in this branch\n\nCompared with %s.\n\n", prCommentMarker, baseDesc)
+	if len(diff.NewEdges) == 0 && len(diff.NewCycles) == 0 && len(diff.FanIn) == 0 && len(diff.RemovedEdges) == 0 {
+		fmt.Fprintln(w, "No architectural changes: no new module edges, cycles or fan-in changes.")
+		return
+	}
+	if len(diff.NewCycles) > 0 {
+		fmt.Fprintf(w, "**⚠️ New cycles (%d)**\n\n", len(diff.NewCycles))
+		for _, c := range diff.NewCycles { fmt.Fprintf(w, "- `%s`\n", strings.Join(c, "` ⇄ `")) }
+		fmt.Fprintln(w)
+	}
+	if len(diff.NewEdges) > 0 {
+		fmt.Fprintf(w, "**New module edges (%d)**\n\n| From | To |\n|---|---|\n", len(diff.NewEdges))
+		for _, e := range diff.NewEdges { fmt.Fprintf(w, "| `%s` | `%s` |\n", e[0], e[1]) }
+		fmt.Fprintln(w)
+	}
+	if len(diff.RemovedEdges) > 0 {
+		var removed []string
+		for _, e := range diff.RemovedEdges { removed = append(removed, fmt.Sprintf("`%s → %s`", e[0], e[1])) }
+		fmt.Fprintf(w, "Removed edges: %s\n\n", strings.Join(removed, ", "))
+	}
+	if len(diff.FanIn) > 0 {
+		fmt.Fprintf(w, "**Fan-in changes**\n\n| Module | Before | After | Δ |\n|---|---:|---:|---:|\n")
+		for _, d := range diff.FanIn { fmt.Fprintf(w, "| `%s` | %d | %d | %+d |\n", d.Module, d.Before, d.After, d.After-d.Before) }
+	}
+}
+
+// runPRComment posts the summary to GitHub, GitLab or Bitbucket; see reviewProviders.
+func runPRComment(args []string) {
+	fs := flag.NewFlagSet("pr-comment", flag.ExitOnError)
+	base := fs.String("base", "origin/main", "branch the pull request merges into; the diff starts at its merge base with HEAD")
+	pr := fs.Int("pr", 0, "pull or merge request number (default: from the CI environment)")
+	provider := fs.String("provider", "", "code host to post to: github, gitlab or bitbucket (default: detected from the CI environment)")
+	dryRun := fs.Bool("dry-run", false, "print the comment instead of posting it")
+	fs.Usage = func() { fmt.Println("Usage: dependant pr-comment [flags] [directory]"); fs.PrintDefaults() }
+	fs.Parse(args)
+	root := "."
+	if fs.NArg() > 0 { root = fs.Arg(0) }
+	if *provider == "" { *provider = detectProvider() }
+	configure, ok := reviewProviders[*provider]
+	if !ok && !*dryRun { log.Fatalf("Unknown --provider %q: expected github, gitlab or bitbucket", *provider) }
+
+	mergeBase, err := gitOutput(root, "merge-base", "HEAD", *base)
+	if err != nil { log.Fatalf("Error finding merge base with %s: %v", *base, err) }
+	baseDir, cleanup, err := checkoutRev(root, mergeBase)
+	if err != nil { log.Fatalf("Error checking out %s: %v", mergeBase, err) }
+	defer cleanup()
+	fatalf := func(format string, args ...any) { cleanup(); log.Fatalf(format, args...) } // os.Exit skips the deferred cleanup
+	before, err := analyzer.Analyze(baseDir, analyzer.Options{})
+	if err != nil { fatalf("Error analyzing %s: %v", mergeBase, err) }
+	beforeView := viewOf(before)
+	after, err := analyzer.Analyze(root, analyzer.Options{})
+	if err != nil { fatalf("Error analyzing %s: %v", root, err) }
+
+	var body bytes.Buffer
+	writePRCommentMarkdown(&body, diffArchitecture(beforeView, viewOf(after)), fmt.Sprintf("`%.10s` (merge base with `%s`)", mergeBase, *base))
+	if *dryRun {
+		os.Stdout.Write(body.Bytes())
+		return
+	}
+	host, err := configure(*pr)
+	if err != nil { fatalf("Error configuring %s: %v", *provider, err) }
+	url, err := host.upsertComment(body.String())
+	if err != nil { fatalf("Error posting comment: %v", err) }
+	fmt.Printf("✅ Posted dependency summary: %s\n", url)
+}
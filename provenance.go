@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// methodology describes how each kind of finding in a was produced: imports, publicItems, externalCrates and the
+// kinds behind the other report sections (see sectionBasis). Kinds built on imports turn heuristic when the analysis
+// inferred any.
+func methodology(a *analyzer.Report) map[string]analyzer.Provenance {
+	inferred := 0
+	for _, items := range a.Graph.Inferred { for _, files := range items { inferred += len(files) } }
+	importConfidence := analyzer.ConfidencePrecise
+	if inferred > 0 || a.Language == "js" { importConfidence = analyzer.ConfidenceHeuristic }
+	m := make(map[string]analyzer.Provenance)
+	if a.Language == "go" {
+		imports := analyzer.Provenance{Source: "go/parser: import declarations and the exported selectors used through them", Confidence: importConfidence, Caveats: []string{"identifiers reached through a renamed local variable or an embedded field are missed"}}
+		if inferred > 0 { imports.Caveats = append(imports.Caveats, fmt.Sprintf("%d item import(s) inferred from dot imports", inferred)) }
+		m["imports"] = imports
+		m["publicItems"] = analyzer.Provenance{Source: "go/parser: exported top-level declarations outside _test.go files", Confidence: analyzer.ConfidencePrecise, Caveats: []string{"methods are not counted as items"}}
+		m["externalCrates"] = analyzer.Provenance{Source: "import paths of required modules; versions from go.mod", Confidence: analyzer.ConfidencePrecise, Caveats: []string{"the standard library is not listed"}}
+		m["conditional"] = analyzer.Provenance{Source: "//go:build constraints of the importing files", Confidence: analyzer.ConfidencePrecise, Caveats: []string{"file name suffixes such as _linux.go are not read as constraints"}}
+		m["unsafe"] = analyzer.Provenance{Source: "selectors used on package unsafe", Confidence: analyzer.ConfidencePrecise, Caveats: []string{"cgo is not counted"}}
+	} else if a.Language == "js" {
+		imports := analyzer.Provenance{Source: "import, export ... from and require() statements matched by pattern, resolved to files", Confidence: importConfidence, Caveats: []string{"members of a namespace import are matched as ns.member anywhere in the file", "specifiers built at run time are not seen"}}
+		if n := len(a.Graph.Unparsed); n > 0 { imports.Caveats = append(imports.Caveats, fmt.Sprintf("%d unresolvable relative import(s) skipped", n)) }
+		m["imports"] = imports
+		m["publicItems"] = analyzer.Provenance{Source: "export declarations, export lists and CommonJS exports.X assignments matched by pattern", Confidence: analyzer.ConfidenceHeuristic, Caveats: []string{"names re-exported with export * are not counted"}}
+		m["externalCrates"] = analyzer.Provenance{Source: "bare import specifiers naming packages; versions from package-lock.json", Confidence: analyzer.ConfidencePrecise, Caveats: []string{"Node built-in modules are not listed"}}
+	} else if a.Language == "python" {
+		imports := analyzer.Provenance{Source: "import and from ... import statements matched by pattern, resolved to modules and __init__.py packages", Confidence: importConfidence, Caveats: []string{"attributes of an imported module are matched as module.attribute anywhere in the file", "imports made with importlib or __import__ are not seen"}}
+		if inferred > 0 { imports.Caveats = append(imports.Caveats, fmt.Sprintf("%d item import(s) inferred from star imports by name", inferred)) }
+		if n := len(a.Graph.Unparsed); n > 0 { imports.Caveats = append(imports.Caveats, fmt.Sprintf("%d unresolvable relative import(s) skipped", n)) }
+		m["imports"] = imports
+		m["publicItems"] = analyzer.Provenance{Source: "__all__, else top-level def, class and assignments not starting with an underscore", Confidence: analyzer.ConfidenceHeuristic, Caveats: []string{"names a package's __init__.py imports are not counted unless listed in __all__"}}
+		m["externalCrates"] = analyzer.Provenance{Source: "top-level packages imported from outside the tree; versions from poetry.lock or uv.lock", Confidence: analyzer.ConfidenceHeuristic, Caveats: []string{"the standard library is not listed", "distributions whose import name differs from their name are not matched to the lockfile"}}
+	} else if a.Language == "mixed" {
+		var found []string
+		for _, r := range a.Languages { found = append(found, r.Language+" in "+r.Dir) }
+		imports := analyzer.Provenance{Source: "each language's own frontend, run from the directory of its manifest, merged: " + strings.Join(found, ", "), Confidence: analyzer.ConfidenceHeuristic, Caveats: []string{"module names several languages use are qualified with the language, e.g. python:utils", "dependant.toml is read from each language's directory; only the root's [tags] apply to every language"}}
+		if inferred > 0 { imports.Caveats = append(imports.Caveats, fmt.Sprintf("%d item import(s) inferred by name", inferred)) }
+		m["imports"] = imports
+		m["publicItems"] = analyzer.Provenance{Source: "each language's frontend; the functions a C header declares", Confidence: analyzer.ConfidenceHeuristic}
+		m["externalCrates"] = analyzer.Provenance{Source: "the imports of other packages in every language; versions from each lockfile", Confidence: analyzer.ConfidencePrecise, Caveats: []string{"a package name several ecosystems use is listed once"}}
+		m["crossLanguage"] = analyzer.Provenance{Source: "extern \"C\" blocks and cgo preambles matched to the C headers in the tree, and #[pymodule] functions to Python imports", Confidence: analyzer.ConfidenceHeuristic, Caveats: []string{"C functions are matched to headers by name", "other bindings, such as wasm-bindgen, JNI or ctypes, are not seen"}}
+	} else {
+		imports := analyzer.Provenance{Source: "use statements resolved against the symbol table", Confidence: importConfidence, Caveats: []string{"use statements expanded from macros are not seen"}}
+		if inferred > 0 { imports.Caveats = append(imports.Caveats, fmt.Sprintf("%d item import(s) inferred from glob imports or preludes by name", inferred)) }
+		if n := len(a.Graph.Unparsed); n > 0 { imports.Caveats = append(imports.Caveats, fmt.Sprintf("%d unparsable use statement(s) skipped", n)) }
+		if a.Options.ReExports != "facade" { imports.Caveats = append(imports.Caveats, "items imported through pub use re-exports count against their defining module") }
+		if f := a.Options.Features; f != nil { imports.Caveats = append(imports.Caveats, "only code compiled with "+f.String()+" counts; cfg predicates on anything else are taken to hold") }
+		m["imports"] = imports
+		m["publicItems"] = analyzer.Provenance{Source: "pub struct, enum, fn and trait definitions matched by pattern", Confidence: analyzer.ConfidenceHeuristic, Caveats: []string{"pub const, static, type and macro items are not counted", "definitions generated by macros are not seen"}}
+		m["externalCrates"] = analyzer.Provenance{Source: "use statements naming other crates; versions from Cargo.lock", Confidence: analyzer.ConfidencePrecise, Caveats: []string{"crates used only through fully qualified paths are missed", "macros from #[macro_use] crates are matched by name"}}
+		conditional := analyzer.Provenance{Source: "#[cfg] attributes enclosing each use statement and on the mod declarations reaching its file", Confidence: analyzer.ConfidencePrecise, Caveats: []string{"predicates are reported as written, not evaluated"}}
+		if f := a.Options.Features; f != nil { conditional.Caveats = []string{"only imports compiled with " + f.String() + " are listed"} }
+		m["conditional"] = conditional
+		m["unsafe"] = analyzer.Provenance{Source: "unsafe blocks and fns matched by pattern outside comments and strings", Confidence: analyzer.ConfidenceHeuristic, Caveats: []string{"unsafe code generated by macros is not counted"}}
+		m["boundaries"] = analyzer.Provenance{Source: "pub(crate), pub(super) and pub(in ...) definitions matched by pattern", Confidence: analyzer.ConfidenceHeuristic}
+		m["modTree"] = analyzer.Provenance{Source: "mod declarations, #[path] attributes and inline mod blocks followed from each crate root", Confidence: analyzer.ConfidencePrecise, Caveats: []string{"cfg-gated declarations are followed as if always compiled", "declarations generated by macros are not seen"}}
+	}
+	if a.Options.NoTests {
+		imports := m["imports"]
+		imports.Caveats = append(imports.Caveats, "imports from test code are left out (--tests exclude)")
+		m["imports"] = imports
+	}
+	metrics := analyzer.Provenance{Source: "module graph built from the imports, with lines of code outside comments", Confidence: importConfidence}
+	metrics.Caveats = append(metrics.Caveats, m["imports"].Caveats...)
+	m["metrics"] = metrics
+	m["coupling"] = analyzer.Provenance{Source: "files changed together in recent git commits", Confidence: analyzer.ConfidenceHeuristic, Caveats: []string{"commits touching many modules are ignored", "co-change suggests, but does not prove, a hidden dependency"}}
+	return m
+}
+
+// sectionBasis maps each report section to the kind of finding its provenance badge describes.
+var sectionBasis = map[string]string{
+	"summary": "metrics", "top-items": "imports", "cycles": "imports", "modules": "imports", "outbound": "imports", "graph": "imports", "per-module": "imports",
+	"metrics": "metrics", "interfaces": "imports", "use-style": "imports", "conditional": "conditional", "unsafe": "unsafe", "external-crates": "externalCrates",
+	"coupling": "coupling", "boundaries": "boundaries", "mod-tree": "modTree", "layers": "imports", "inferred-layers": "imports", "closure": "imports", "treemap": "metrics",
+	"edge-history": "imports", "god-modules": "imports", "languages": "crossLanguage",
+}
+
+// sectionProvenance is the provenance of each report section, keyed by reportSections name.
+func sectionProvenance(a *analyzer.Report) map[string]analyzer.Provenance {
+	kinds := methodology(a)
+	sections := make(map[string]analyzer.Provenance)
+	for section, kind := range sectionBasis { if p, ok := kinds[kind]; ok { sections[section] = p } }
+	return sections
+}
+
+// provenanceBadge renders p as a confidence badge whose tooltip gives the source and caveats; nil renders nothing.
+func provenanceBadge(p *analyzer.Provenance) template.HTML {
+	if p == nil { return "" }
+	title := p.Source
+	for _, c := range p.Caveats { title += "\n• " + c }
+	return template.HTML(fmt.Sprintf(`<span class="confidence confidence-%s" title="%s">%s</span>`, p.Confidence, template.HTMLEscapeString(title), p.Confidence))
+}
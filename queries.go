@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// queryNames lists the questions the daemon (or a cold analysis) can answer.
+var queryNames = map[string]string{
+	"who-uses": "who-uses <module>|<module>::<Item>  files importing a module or item",
+	"impact":   "impact <module>                      modules that depend on a module, directly or transitively",
+	"explain":  "explain <from> <to>                  why one module depends on another",
+	"imports":  "imports <file>                       what one file pulls in from the crate, by module",
+}
+
+// answerQuery evaluates a query against an analysis and returns printable lines.
+func answerQuery(a *analyzer.Report, query string, args []string) ([]string, error) {
+	rel := func(path string) string { if r, err := filepath.Rel(a.Root, path); err == nil { return filepath.ToSlash(r) }; return path }
+	switch query {
+	case "who-uses":
+		if len(args) != 1 { return nil, fmt.Errorf("usage: %s", queryNames[query]) }
+		module, item, hasItem := args[0], "", false
+		if _, known := a.SymbolTable[module]; !known { // net::http is a module, net::http::Request an item of it
+			if i := strings.LastIndex(module, "::"); i > 0 { module, item, hasItem = args[0][:i], args[0][i+2:], true }
+		}
+		var files []string
+		if hasItem {
+			for f := range a.Graph.ItemImports[module][item] { files = append(files, rel(f)) }
+		} else {
+			for f, deps := range a.Graph.Deps { if _, ok := deps[module]; ok { files = append(files, rel(f)) } }
+		}
+		if len(files) == 0 { return []string{fmt.Sprintf("Nothing imports %s.", args[0])}, nil }
+		sort.Strings(files)
+		return append([]string{fmt.Sprintf("%s is imported by %d file(s):", args[0], len(files))}, files...), nil
+
+	case "impact":
+		if len(args) != 1 { return nil, fmt.Errorf("usage: %s", queryNames[query]) }
+		depth := reverseReach(a.BuildModuleGraph(a.Graph.Deps), args[0])
+		if len(depth) == 0 { return []string{fmt.Sprintf("No module depends on %s.", args[0])}, nil }
+		modules := sortedKeys(depth)
+		sort.SliceStable(modules, func(i, j int) bool { return depth[modules[i]] < depth[modules[j]] })
+		lines := []string{fmt.Sprintf("Changing %s can affect %d module(s):", args[0], len(modules))}
+		for _, m := range modules { lines = append(lines, fmt.Sprintf("  %s (%d hop%s)", m, depth[m], plural(depth[m]))) }
+		return lines, nil
+
+	case "explain":
+		if len(args) != 2 { return nil, fmt.Errorf("usage: %s", queryNames[query]) }
+		from, to := args[0], args[1]
+		var lines []string
+		for item, files := range a.Graph.ItemImports[to] {
+			for f := range files { if a.ModuleName(f) == from { lines = append(lines, fmt.Sprintf("  %s imports %s::%s", rel(f), to, item)) } }
+		}
+		if len(lines) > 0 {
+			sort.Strings(lines)
+			return append([]string{fmt.Sprintf("%s depends on %s directly:", from, to)}, lines...), nil
+		}
+		if path := shortestPath(a.BuildModuleGraph(a.Graph.Deps), from, to); path != nil {
+			return []string{fmt.Sprintf("%s depends on %s indirectly: %s", from, to, strings.Join(path, " → "))}, nil
+		}
+		return []string{fmt.Sprintf("%s does not depend on %s.", from, to)}, nil
+
+	case "imports":
+		if len(args) != 1 { return nil, fmt.Errorf("usage: %s", queryNames[query]) }
+		want := filepath.ToSlash(filepath.Clean(args[0]))
+		var match *FileOutbound
+		for _, fo := range computeFileOutbound(a, a.Graph, a.Facts.Tags) {
+			if fo.File != want && !strings.HasSuffix(fo.File, "/"+want) { continue } // a path relative to the root, or a unique tail of one
+			if match != nil { return nil, fmt.Errorf("%s matches both %s and %s; give more of the path", args[0], match.File, fo.File) }
+			match = &fo
+		}
+		if match == nil { return []string{fmt.Sprintf("%s imports nothing from the crate.", args[0])}, nil }
+		lines := []string{fmt.Sprintf("%s imports %d item%s from %d module%s:", match.File, match.Items, plural(match.Items), len(match.Imports), plural(len(match.Imports)))}
+		for _, m := range match.Imports {
+			if len(m.Items) == 0 { lines = append(lines, fmt.Sprintf("  %s (module only)", m.Module)); continue }
+			lines = append(lines, fmt.Sprintf("  %s (%d): %s", m.Module, len(m.Items), strings.Join(m.Items, ", ")))
+		}
+		return lines, nil
+	}
+	return nil, fmt.Errorf("unknown query %q", query)
+}
+
+// reverseReach returns every module that reaches target, with its distance in hops.
+func reverseReach(moduleGraph map[string]map[string]struct{}, target string) map[string]int {
+	reverse := make(map[string][]string)
+	for from, tos := range moduleGraph { for to := range tos { reverse[to] = append(reverse[to], from) } }
+	depth := make(map[string]int)
+	for frontier, d := []string{target}, 1; len(frontier) > 0; d++ {
+		var next []string
+		for _, m := range frontier {
+			for _, dependent := range reverse[m] {
+				if _, seen := depth[dependent]; seen || dependent == target { continue }
+				depth[dependent] = d
+				next = append(next, dependent)
+			}
+		}
+		frontier = next
+	}
+	return depth
+}
+
+// shortestPath finds a shortest module path from -> to by breadth-first search, or nil.
+func shortestPath(moduleGraph map[string]map[string]struct{}, from, to string) []string {
+	prev := map[string]string{from: ""}
+	for frontier := []string{from}; len(frontier) > 0; {
+		var next []string
+		for _, m := range frontier {
+			for _, n := range sortedKeys(moduleGraph[m]) {
+				if _, seen := prev[n]; seen { continue }
+				prev[n] = m
+				if n == to {
+					path := []string{to}
+					for p := m; p != ""; p = prev[p] { path = append([]string{p}, path...) }
+					return path
+				}
+				next = append(next, n)
+			}
+		}
+		frontier = next
+	}
+	return nil
+}
+
+func plural(n int) string { if n == 1 { return "" }; return "s" }
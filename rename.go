@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// useLineRegex finds use statements that begin a line, so a "use" inside a string literal is not mistaken for one.
+var useLineRegex = regexp.MustCompile(`(?m)^[ \t]*(?:pub(?:\([^)]*\))?\s+)?use\s+[^;]*;`)
+
+// RenameEdit is one line a rename touches. Kind is "definition", "use" (an import or re-export), "reference" (a body
+// mention, found with --precise) or "manual" for lines that cannot be rewritten mechanically, such as a moved
+// item inside a grouped import.
+type RenameEdit struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Kind   string `json:"kind"`
+	Before string `json:"before"`
+	After  string `json:"after,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+// splitItemPath splits "module::Item" at its last separator.
+func splitItemPath(path string) (module, item string, err error) {
+	i := strings.LastIndex(path, "::")
+	if i <= 0 || i+2 >= len(path) { return "", "", fmt.Errorf("%q: expected module::Item", path) }
+	return path[:i], path[i+2:], nil
+}
+
+// renameImpact lists the edits renaming oldModule::oldItem to newModule::newItem needs: its definition, every use
+// statement naming it and, when precise, every other mention in the files that import it.
+func renameImpact(a *analyzer.Report, oldModule, oldItem, newModule, newItem string, precise bool) ([]RenameEdit, error) {
+	if _, ok := a.SymbolTable[oldModule][oldItem]; !ok { return nil, fmt.Errorf("%s::%s is not a public item of the analyzed tree", oldModule, oldItem) }
+	moved := newModule != oldModule
+	files := make(map[string]bool) // file -> defines the item
+	for file := range a.Graph.ItemImports[oldModule][oldItem] { files[file] = false }
+	filter := a.Config.PathFilter(a.Root)
+	err := filepath.WalkDir(a.Root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && filter.Skip(path, d.IsDir()) { if d.IsDir() { return filepath.SkipDir }; return nil }
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".rs") { return err }
+		if a.ModuleName(path) == oldModule { files[path] = true }
+		return nil
+	})
+	if err != nil { return nil, err }
+
+	word := regexp.MustCompile(`\b` + regexp.QuoteMeta(oldItem) + `\b`)
+	definition := regexp.MustCompile(`pub\s+(?:struct|enum|fn|trait)\s+` + regexp.QuoteMeta(oldItem) + `\b`)
+	qualified := regexp.MustCompile(`\b` + regexp.QuoteMeta(oldModule) + `::` + regexp.QuoteMeta(oldItem) + `\b`)
+	aliased := regexp.MustCompile(`\b` + regexp.QuoteMeta(oldItem) + `\s+as\s+\w+`)
+	var edits []RenameEdit
+	for _, file := range sortedKeys(files) {
+		content, err := os.ReadFile(file)
+		if err != nil { return nil, err }
+		text := string(content)
+		rel, _ := filepath.Rel(a.Root, file)
+		rel = filepath.ToSlash(rel)
+		// Mark the lines covered by use statements; a body reference is any other mention.
+		inUse := make(map[int]string) // line -> the use statement covering it
+		stripped := analyzer.StripNonCode(text) // keeps every newline, so line numbers still match
+		for _, loc := range useLineRegex.FindAllStringIndex(stripped, -1) {
+			first, last := strings.Count(stripped[:loc[0]], "\n"), strings.Count(stripped[:loc[1]], "\n")
+			for l := first; l <= last; l++ { inUse[l] = stripped[loc[0]:loc[1]] }
+		}
+		keepsName := aliased.MatchString(text) // `use ...::Old as X`: the body uses X, which the rename does not touch
+		for i, line := range strings.Split(text, "\n") {
+			code, _, _ := strings.Cut(line, "//")
+			if !word.MatchString(code) { continue }
+			edit := RenameEdit{File: rel, Line: i + 1, Before: line}
+			switch {
+			case files[file] && definition.MatchString(code):
+				edit.Kind, edit.After = "definition", word.ReplaceAllString(line, newItem)
+				if moved { edit.Kind, edit.After, edit.Note = "manual", "", "move the definition to "+newModule }
+			case inUse[i] != "":
+				edit.Kind, edit.After = "use", word.ReplaceAllString(line, newItem)
+				if moved {
+					if qualified.MatchString(code) {
+						edit.After = qualified.ReplaceAllString(line, newModule+"::"+newItem)
+					} else if strings.Contains(inUse[i], oldModule+"::") { // a group import from the old module
+						edit.Kind, edit.After, edit.Note = "manual", "", "import "+newItem+" from "+newModule+" instead"
+					}
+				}
+			case precise && !keepsName:
+				edit.Kind = "reference"
+				edit.After = qualified.ReplaceAllString(line, newModule+"::"+newItem)
+				edit.After = word.ReplaceAllString(edit.After, newItem)
+			default:
+				continue
+			}
+			edits = append(edits, edit)
+		}
+	}
+	return edits, nil
+}
+
+func writeRenameText(w io.Writer, edits []RenameEdit) {
+	for _, e := range edits {
+		fmt.Fprintf(w, "%s:%d: [%s] %s\n", e.File, e.Line, e.Kind, strings.TrimSpace(e.Before))
+		if e.After != "" { fmt.Fprintf(w, "    -> %s\n", strings.TrimSpace(e.After)) }
+		if e.Note != "" { fmt.Fprintf(w, "    (%s)\n", e.Note) }
+	}
+	files := make(map[string]struct{})
+	for _, e := range edits { files[e.File] = struct{}{} }
+	fmt.Fprintf(w, "%d line%s in %d file%s\n", len(edits), plural(len(edits)), len(files), plural(len(files)))
+}
+
+// writeRenamePatch renders the mechanical edits as a unified diff that `git apply` accepts; manual edits are listed
+// in the patch header, which git ignores.
+func writeRenamePatch(w io.Writer, root string, edits []RenameEdit) error {
+	const context = 3
+	byFile := make(map[string]map[int]RenameEdit)
+	for _, e := range edits {
+		if e.Kind == "manual" { fmt.Fprintf(w, "# manual: %s:%d: %s\n", e.File, e.Line, e.Note); continue }
+		if byFile[e.File] == nil { byFile[e.File] = make(map[int]RenameEdit) }
+		byFile[e.File][e.Line] = e
+	}
+	for _, file := range sortedKeys(byFile) {
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(file)))
+		if err != nil { return err }
+		lines := strings.Split(string(content), "\n")
+		var changed []int
+		for line := range byFile[file] { changed = append(changed, line) }
+		sort.Ints(changed)
+		fmt.Fprintf(w, "--- a/%s\n+++ b/%s\n", file, file)
+		for i := 0; i < len(changed); {
+			j := i
+			for j+1 < len(changed) && changed[j+1]-changed[j] <= 2*context { j++ }
+			start, end := max(changed[i]-context, 1), min(changed[j]+context, len(lines))
+			if end == len(lines) && lines[end-1] == "" { end-- } // the empty string after a trailing newline is not a line
+			fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", start, end-start+1, start, end-start+1)
+			for l := start; l <= end; l++ {
+				if e, ok := byFile[file][l]; ok { fmt.Fprintf(w, "-%s\n+%s\n", e.Before, e.After) } else { fmt.Fprintf(w, " %s\n", lines[l-1]) }
+			}
+			i = j + 1
+		}
+	}
+	return nil
+}
+
+func runRenameImpact(args []string) {
+	fs := flag.NewFlagSet("rename-impact", flag.ExitOnError)
+	format := fs.String("format", "text", "output format: text, json or patch")
+	precise := fs.Bool("precise", false, "also list mentions in function bodies and other code, not just use statements")
+	noCache := fs.Bool("no-cache", false, "ignore and do not update the analysis cache")
+	fs.Usage = func() { fmt.Println("Usage: dependant rename-impact [flags] <directory> <module::Old> <module::New>"); fs.PrintDefaults() }
+	fs.Parse(args)
+	if fs.NArg() != 3 { fs.Usage(); os.Exit(1) }
+	oldModule, oldItem, err := splitItemPath(fs.Arg(1))
+	if err != nil { log.Fatal(err) }
+	newModule, newItem, err := splitItemPath(fs.Arg(2))
+	if err != nil { log.Fatal(err) }
+
+	analysis, err := analyzeCached(fs.Arg(0), analyzer.Options{}, !*noCache)
+	if err != nil { log.Fatalf("Error analyzing %s: %v", fs.Arg(0), err) }
+	edits, err := renameImpact(analysis, oldModule, oldItem, newModule, newItem, *precise)
+	if err != nil { log.Fatal(err) }
+	switch *format {
+	case "text": writeRenameText(os.Stdout, edits)
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(edits); err != nil { log.Fatalf("Error writing JSON: %v", err) }
+	case "patch": if err := writeRenamePatch(os.Stdout, analysis.Root, edits); err != nil { log.Fatalf("Error writing patch: %v", err) }
+	default: log.Fatalf("Unknown --format %q: expected text, json or patch", *format)
+	}
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// replayCommit is a first-parent commit the history command may analyze.
+type replayCommit struct {
+	Hash  string
+	Time  time.Time
+	Tag   string // a tag pointing at it, "" when none does
+}
+
+func (c replayCommit) label() string { if c.Tag != "" { return c.Tag }; return c.Hash[:min(10, len(c.Hash))] }
+
+// replayCommits lists the first-parent commits from since (inclusive; the root commit when "") to until, oldest first.
+func replayCommits(root, since, until string) ([]replayCommit, error) {
+	rangeSpec := until
+	if since != "" { rangeSpec = since + ".." + until }
+	out, err := gitOutput(root, "log", "--first-parent", "--reverse", "--format=%H %ct", "--end-of-options", rangeSpec)
+	if err != nil { return nil, err }
+	if since != "" {
+		first, err := gitOutput(root, "log", "-1", "--format=%H %ct", "--end-of-options", since)
+		if err != nil { return nil, err }
+		out = first + "\n" + out
+	}
+	tagged, err := gitOutput(root, "for-each-ref", "--sort=creatordate", "--format=%(objectname) %(*objectname) %(refname:short)", "refs/tags")
+	if err != nil { return nil, err }
+	tags := make(map[string]string)
+	for _, line := range strings.Split(tagged, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 { tags[fields[0]] = fields[1] } else if len(fields) == 3 { tags[fields[1]] = fields[2] } // annotated: the peeled commit
+	}
+	var commits []replayCommit
+	for _, line := range strings.Split(out, "\n") {
+		hash, stamp, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok { continue }
+		seconds, err := strconv.ParseInt(stamp, 10, 64)
+		if err != nil { return nil, fmt.Errorf("git log: bad commit time %q", stamp) }
+		commits = append(commits, replayCommit{Hash: hash, Time: time.Unix(seconds, 0).UTC(), Tag: tags[hash]})
+	}
+	return commits, nil
+}
+
+// sampleCommits picks the commits to analyze: every tagged one ("tag"), every Nth ("25"), or the first after each
+// interval ("30d", "2w", "720h"). The first and last commits are always kept, and at most max are, evenly thinned.
+func sampleCommits(commits []replayCommit, every string, max int) ([]replayCommit, error) {
+	if len(commits) == 0 { return nil, nil }
+	keep := make([]bool, len(commits))
+	keep[0], keep[len(commits)-1] = true, true
+	switch n, err := strconv.Atoi(every); {
+	case every == "tag":
+		for i, c := range commits { if c.Tag != "" { keep[i] = true } }
+	case err == nil:
+		if n < 1 { return nil, fmt.Errorf("--every %s: expected at least 1 commit", every) }
+		for i := range commits { if i%n == 0 { keep[i] = true } }
+	default:
+		interval, err := parseInterval(every)
+		if err != nil { return nil, fmt.Errorf("--every %s: expected tag, a number of commits, or an interval such as 30d, 2w or 720h", every) }
+		next := commits[0].Time.Add(interval)
+		for i, c := range commits {
+			if c.Time.Before(next) { continue }
+			keep[i] = true
+			for !c.Time.Before(next) { next = next.Add(interval) }
+		}
+	}
+	var sampled []replayCommit
+	for i, c := range commits { if keep[i] { sampled = append(sampled, c) } }
+	if max > 1 && len(sampled) > max {
+		thinned := make([]replayCommit, max)
+		for i := range thinned { thinned[i] = sampled[i*(len(sampled)-1)/(max-1)] }
+		sampled = thinned
+	}
+	return sampled, nil
+}
+
+// parseInterval reads a Go duration, or a number of days ("30d") or weeks ("2w").
+func parseInterval(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, err := strconv.Atoi(strings.TrimSuffix(s, suffix)); strings.HasSuffix(s, suffix) && err == nil && n > 0 { return time.Duration(n) * unit, nil }
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 { return 0, fmt.Errorf("%q: expected an interval such as 30d, 2w or 720h", s) }
+	return d, nil
+}
+
+// TrendSeries is one module's inbound count (files importing it) at each sampled commit, with its line on the chart.
+type TrendSeries struct {
+	Module string
+	Color  string
+	Counts []int
+	Points string // SVG polyline points
+}
+
+// TrendChart is the coupling trend the history command renders: the most imported modules at the last sample.
+type TrendChart struct {
+	Root      string
+	Labels    []string
+	Dates     []string
+	Series    []TrendSeries
+	X         []float64 // horizontal position of each sample
+	MaxY      int
+	GridLines []TrendGridLine
+}
+
+type TrendGridLine struct {
+	Y     float64
+	Value int
+}
+
+const trendWidth, trendHeight, trendLeft, trendBottom = 960.0, 420.0, 48.0, 24.0
+
+// buildTrendChart lays out the top modules' inbound counts over the records, which are in sample order.
+func buildTrendChart(root string, samples []replayCommit, records []HistoryRecord, top int) TrendChart {
+	chart := TrendChart{Root: root, MaxY: 1}
+	last := records[len(records)-1].Inbound
+	modules := sortedKeys(last)
+	sort.SliceStable(modules, func(i, j int) bool { return last[modules[i]] > last[modules[j]] })
+	if top > 0 && len(modules) > top { modules = modules[:top] }
+	for i, s := range samples {
+		chart.Labels, chart.Dates = append(chart.Labels, s.label()), append(chart.Dates, s.Time.Format("2006-01-02"))
+		x := trendLeft + (trendWidth-trendLeft)/2
+		if len(samples) > 1 { x = trendLeft + float64(i)*(trendWidth-trendLeft-16)/float64(len(samples)-1) }
+		chart.X = append(chart.X, x)
+	}
+	for i, m := range modules {
+		series := TrendSeries{Module: m, Color: tagPalette[i%len(tagPalette)]}
+		if c, ok := knownTagColors[m]; ok { series.Color = c }
+		for _, rec := range records { series.Counts = append(series.Counts, rec.Inbound[m]); chart.MaxY = max(chart.MaxY, rec.Inbound[m]) }
+		chart.Series = append(chart.Series, series)
+	}
+	y := func(v int) float64 { return trendHeight - trendBottom - float64(v)/float64(chart.MaxY)*(trendHeight-trendBottom-12) }
+	for i := range chart.Series {
+		var points []string
+		for j, v := range chart.Series[i].Counts { points = append(points, fmt.Sprintf("%.1f,%.1f", chart.X[j], y(v))) }
+		chart.Series[i].Points = strings.Join(points, " ")
+	}
+	for _, v := range []int{0, chart.MaxY / 2, chart.MaxY} { chart.GridLines = append(chart.GridLines, TrendGridLine{y(v), v}) }
+	return chart
+}
+
+// sparkline draws counts as block characters, scaled to their own maximum.
+func sparkline(counts []int) string {
+	const blocks = "▁▂▃▄▅▆▇█"
+	peak := 1
+	for _, c := range counts { peak = max(peak, c) }
+	var b strings.Builder
+	for _, c := range counts { b.WriteRune([]rune(blocks)[c*7/peak]) }
+	return b.String()
+}
+
+var trendTemplate = template.Must(template.New("trend").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8"><meta name="viewport" content="width=device-width, initial-scale=1.0"><title>Dependency History</title>
+<meta http-equiv="Content-Security-Policy" content="default-src 'none'; style-src 'unsafe-inline'">
+<style>
+	body { background-color: #1a1b26; color: #c0caf5; font-family: system-ui, -apple-system, 'Segoe UI', sans-serif; margin: 0; padding: 2rem; line-height: 1.6; }
+	main { max-width: 1000px; margin: 0 auto; }
+	h1, h2 { color: #ffffff; }
+	code, .mono { font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; }
+	svg { width: 100%; height: auto; display: block; background-color: #24283b; border: 1px solid #3b4261; border-radius: 8px; }
+	svg text { fill: #c0caf5; font-size: 11px; font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; }
+	.grid { stroke: #3b4261; stroke-dasharray: 3 3; }
+	polyline { fill: none; stroke-width: 2.5; } circle { stroke: #24283b; }
+	table { width: 100%; border-collapse: collapse; margin-top: 1.5rem; } th, td { text-align: left; padding: 0.4rem 0.6rem; border-bottom: 1px solid #3b4261; }
+	.num { text-align: right; }
+	.swatch { display: inline-block; width: 0.8rem; height: 0.8rem; border-radius: 2px; margin-right: 0.5rem; vertical-align: middle; }
+</style>
+</head>
+<body><main>
+<h1>📈 Dependency History</h1>
+<p>Files importing each of the {{len .Series}} most imported modules of <code>{{.Root}}</code>, at {{len .Labels}} commit{{if ne (len .Labels) 1}}s{{end}}.</p>
+<svg viewBox="0 0 960 420" role="img" aria-label="Inbound counts per module over time">
+	{{range .GridLines}}<line class="grid" x1="48" x2="944" y1="{{printf "%.1f" .Y}}" y2="{{printf "%.1f" .Y}}"></line><text x="40" y="{{printf "%.1f" .Y}}" dy="4" text-anchor="end">{{.Value}}</text>{{end}}
+	{{range $i, $x := .X}}<text x="{{printf "%.1f" $x}}" y="414" text-anchor="middle">{{index $.Labels $i}}</text>{{end}}
+	{{range .Series}}{{$s := .}}<g><title>{{.Module}}</title><polyline points="{{.Points}}" stroke="{{.Color}}"></polyline></g>{{end}}
+</svg>
+<table><tr><th>Module</th>{{range $i, $l := .Labels}}<th class="num" title="{{index $.Dates $i}}">{{$l}}</th>{{end}}</tr>
+{{range .Series}}<tr><td class="mono"><span class="swatch" style="background-color: {{.Color}}"></span>{{.Module}}</td>{{range .Counts}}<td class="num">{{.}}</td>{{end}}</tr>{{end}}
+</table>
+</main></body>
+</html>
+`))
+
+// runHistory replays the analysis over sampled commits of the tree's git history, each read without touching the
+// working tree, and charts how many files import each module, showing how coupling grew. The records can also be
+// appended to a --history store, beside those the daemon writes, for api-churn.
+func runHistory(args []string) {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	since := fs.String("since", "", "oldest commit to analyze, e.g. v1.0 (default: the first commit)")
+	until := fs.String("until", "HEAD", "newest commit to analyze")
+	every := fs.String("every", "tag", "commits to sample between them: tag (every tagged commit), a number N (every Nth commit) or an interval such as 30d, 2w or 720h")
+	maxSamples := fs.Int("max", 30, "analyze at most this many commits, thinned evenly")
+	top := fs.Int("top", 8, "chart the N modules with the most importing files at the newest commit")
+	output := fs.String("output", "", "write the trend chart to this HTML file")
+	historyPath := fs.String("history", "", "also append a record per commit to this history: a SQLite file (the default), a .jsonl file or a postgres:// URL")
+	fs.Usage = func() { fmt.Println("Usage: dependant history [--since <rev>] [flags] <directory>"); fs.PrintDefaults() }
+	fs.Parse(args)
+	if fs.NArg() != 1 || *maxSamples < 2 { fs.Usage(); os.Exit(1) }
+	root := fs.Arg(0)
+
+	commits, err := replayCommits(root, *since, *until)
+	if err != nil { log.Fatalf("Error reading git history: %v", err) }
+	samples, err := sampleCommits(commits, *every, *maxSamples)
+	if err != nil { log.Fatal(err) }
+	if len(samples) == 0 { log.Fatalf("No commits between %s and %s", *since, *until) }
+	var store HistoryStore
+	if *historyPath != "" {
+		if store, err = openHistoryStore(*historyPath); err != nil { log.Fatalf("Error opening history %s: %v", *historyPath, err) }
+		defer store.Close()
+	}
+
+	var analyzed []replayCommit
+	var records []HistoryRecord
+	for i, c := range samples {
+		log.Printf("Analyzing %s (%s), %d/%d", c.label(), c.Time.Format("2006-01-02"), i+1, len(samples))
+		dir, cleanup, err := checkoutRev(root, c.Hash)
+		if err != nil { log.Fatalf("Error reading %s: %v", c.label(), err) }
+		a, err := analyzer.Analyze(dir, analyzer.Options{})
+		cleanup()
+		if err != nil { log.Printf("Skipping %s: %v", c.label(), err); continue } // e.g. before the manifest existed
+		rec := historyRecordOf(root, viewOf(a), c.Time)
+		rec.Rev = c.Hash
+		if store != nil {
+			if err := store.Append(rec); err != nil { log.Fatalf("Error appending history: %v", err) }
+		}
+		analyzed, records = append(analyzed, c), append(records, rec)
+	}
+	if len(records) == 0 { log.Fatalf("No sampled commit could be analyzed") }
+
+	chart := buildTrendChart(root, analyzed, records, *top)
+	width := len("Module")
+	for _, s := range chart.Series { width = max(width, len(s.Module)) }
+	fmt.Printf("Files importing each module, %s (%s) → %s (%s)\n\n", chart.Labels[0], chart.Dates[0], chart.Labels[len(chart.Labels)-1], chart.Dates[len(chart.Dates)-1])
+	for _, s := range chart.Series {
+		first, last := s.Counts[0], s.Counts[len(s.Counts)-1]
+		fmt.Printf("  %-*s  %s  %4d → %-4d (%+d)\n", width, s.Module, sparkline(s.Counts), first, last, last-first)
+	}
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err == nil { err = trendTemplate.Execute(file, chart) }
+		if err == nil { err = file.Close() }
+		if err != nil { log.Fatalf("Error writing %s: %v", *output, err) }
+		fmt.Printf("\n✅ Wrote %s\n", *output)
+	}
+}
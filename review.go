@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// reviewProvider posts dependant's summary on a pull or merge request of one code host,
+// replacing its earlier comment (recognized by prCommentMarker) when there is one.
+type reviewProvider interface {
+	upsertComment(body string) (url string, err error)
+}
+
+// reviewProviders configure each supported host from the environment of its CI system.
+var reviewProviders = map[string]func(number int) (reviewProvider, error){
+	"github":    func(n int) (reviewProvider, error) { return githubFromEnv(n) },
+	"gitlab":    func(n int) (reviewProvider, error) { return gitlabFromEnv(n) },
+	"bitbucket": func(n int) (reviewProvider, error) { return bitbucketFromEnv(n) },
+}
+
+// detectProvider picks the host whose CI we are running in, defaulting to GitHub.
+func detectProvider() string {
+	switch {
+	case os.Getenv("GITLAB_CI") != "": return "gitlab"
+	case os.Getenv("BITBUCKET_BUILD_NUMBER") != "": return "bitbucket"
+	}
+	return "github"
+}
+
+// apiClient is a small JSON-over-HTTP client shared by the providers.
+type apiClient struct {
+	base      string
+	authorize func(*http.Request)
+	client    *http.Client
+}
+
+func newAPIClient(base string, authorize func(*http.Request)) *apiClient {
+	return &apiClient{base: strings.TrimSuffix(base, "/"), authorize: authorize, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// do sends body as JSON and decodes the response into out. path is relative to the API base unless it is already absolute,
+// as in the next-page links some APIs return.
+func (c *apiClient) do(method, path string, body, out any) error {
+	var payload io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil { return err }
+		payload = bytes.NewReader(content)
+	}
+	url := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") { url = c.base + path }
+	req, err := http.NewRequest(method, url, payload)
+	if err != nil { return err }
+	req.Header.Set("Content-Type", "application/json")
+	c.authorize(req)
+	resp, err := c.client.Do(req)
+	if err != nil { return err }
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil { return nil }
+	return json.NewDecoder(resp.Body).Decode(out)
+}
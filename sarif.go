@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// checkFinding is one violation `check` reports, kept for --format sarif.
+type checkFinding struct {
+	Rule    string // one of checkRules
+	Level   string // "error" when empty, or "warning" or "note" for findings that do not fail the check
+	Message string
+	File    string // relative to the root; "" for a module-level finding
+	Line    int
+}
+
+// checkRules describes the rules of `check` and of the findings `export --format sarif` reports, as SARIF rule metadata.
+var checkRules = []struct{ ID, Description string }{
+	{"layers", "Imports must follow the layers declared in dependant.toml or dependant.yaml"},
+	{"test-imports", "Production code must not import test modules"},
+	{"importers", "Modules restricted in [importers] must only be imported by the modules allowed there"},
+	{"budgets", "Modules must stay within their coupling budgets"},
+	{"stability", "Stable modules must keep their public items and dependents"},
+	{"max-dependents", "Modules must not have more dependent modules than --max-dependents"},
+	{"cycles", "Modules must not depend on each other in a cycle"},
+	{"boundaries", "Crate-visible items should not be imported across top-level modules"},
+	{"god-modules", "Modules should not both depend on and be depended on by a large share of the tree"},
+	{"unused-pub", "Public items should be imported somewhere, or made private"},
+	{"new-edges", "Modules should not gain imports beyond the --baseline snapshot"},
+	{"glob-imports", "Imports should name their items rather than use a glob"},
+}
+
+// writeSARIF renders findings as a SARIF 2.1.0 log, the format code-scanning services read.
+func writeSARIF(w io.Writer, findings []checkFinding) error {
+	type message struct{ Text string `json:"text"` }
+	type region struct{ StartLine int `json:"startLine"` }
+	type physicalLocation struct {
+		ArtifactLocation struct{ URI string `json:"uri"` } `json:"artifactLocation"`
+		Region           *region `json:"region,omitempty"`
+	}
+	type location struct{ PhysicalLocation physicalLocation `json:"physicalLocation"` }
+	type result struct {
+		RuleID    string     `json:"ruleId"`
+		Level     string     `json:"level"`
+		Message   message    `json:"message"`
+		Locations []location `json:"locations,omitempty"`
+	}
+	type rule struct {
+		ID               string  `json:"id"`
+		ShortDescription message `json:"shortDescription"`
+	}
+	results := []result{}
+	for _, f := range findings {
+		r := result{RuleID: f.Rule, Level: f.Level, Message: message{f.Message}}
+		if r.Level == "" { r.Level = "error" }
+		if f.File != "" {
+			var loc location
+			loc.PhysicalLocation.ArtifactLocation.URI = f.File
+			if f.Line > 0 { loc.PhysicalLocation.Region = &region{f.Line} }
+			r.Locations = []location{loc}
+		}
+		results = append(results, r)
+	}
+	var rules []rule
+	for _, r := range checkRules { rules = append(rules, rule{r.ID, message{r.Description}}) }
+	log := map[string]any{
+		"version": "2.1.0",
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"runs": []any{map[string]any{
+			"tool":    map[string]any{"driver": map[string]any{"name": "dependant", "informationUri": "https://github.com/WillKirkmanM/dependant", "rules": rules}},
+			"results": results,
+		}},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+// definitionRegex finds where an item is defined, in any of the supported languages.
+func definitionRegex(item string) *regexp.Regexp {
+	return regexp.MustCompile(`\b(?:fn|struct|enum|union|trait|type|const|static|mod|macro_rules!|func|var|class|def|function|let|interface)\s+` + regexp.QuoteMeta(item) + `\b`)
+}
+
+// analysisFindings lists what `export --format sarif` reports without any configuration: cycles and god modules as
+// warnings, imports against declared layers or [importers] as errors, and public items nothing imports as notes. A
+// god module depends on, and is depended on by, at least a quarter of the other modules (and three or more). The
+// public items of root modules are the package's API and are not reported.
+func analysisFindings(a *analyzer.Report) ([]checkFinding, error) {
+	var findings []checkFinding
+	for _, v := range findLayerViolations(a) {
+		for _, file := range v.Files { findings = append(findings, checkFinding{Rule: "layers", File: file, Message: fmt.Sprintf("%s (layer %s) imports %s from layer %s above it", v.From, v.FromLayer, v.To, v.ToLayer)}) }
+	}
+	restricted, err := findImporterViolations(a)
+	if err != nil { return nil, err }
+	for _, v := range restricted { findings = append(findings, importerFinding(v)) }
+	edgeFiles := moduleEdgeFiles(a, a.Graph.Deps)
+	for _, c := range computeCycles(a, a.Graph.Deps) {
+		for _, e := range c.Edges {
+			for _, file := range edgeFiles[[2]string{e.From, e.To}] { findings = append(findings, checkFinding{Rule: "cycles", Level: "warning", File: file, Message: fmt.Sprintf("%s imports %s, part of the cycle %s", e.From, e.To, strings.Join(c.Chain, " → "))}) }
+		}
+	}
+
+	files := make(map[string][]string) // module -> its files
+	filter := a.Config.PathFilter(a.Root)
+	err = filepath.WalkDir(a.Root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && filter.Skip(path, d.IsDir()) { if d.IsDir() { return filepath.SkipDir }; return nil }
+		if err != nil || d.IsDir() || !isCodeFile(d.Name()) { return err }
+		module := a.ModuleName(path)
+		files[module] = append(files[module], path)
+		return nil
+	})
+	if err != nil { return nil, err }
+	moduleFile := func(module string) string { if len(files[module]) == 0 { return "" }; return relSlash(a.Root, files[module][0]) }
+
+	metrics := computeModuleMetrics(a, a.Graph.ProdDeps)
+	limit := max(3, (len(metrics)-1)/4)
+	for _, m := range metrics {
+		if m.FanIn < limit || m.FanOut < limit || a.Facts.Generated[m.Name] { continue }
+		findings = append(findings, checkFinding{Rule: "god-modules", Level: "warning", File: moduleFile(m.Name), Message: fmt.Sprintf("%s depends on %d modules and %d depend on it; consider splitting it", m.Name, m.FanOut, m.FanIn)})
+	}
+
+	roots := make(map[string]bool)
+	for _, name := range a.RootModules() { roots[name] = true }
+	for _, info := range computeInterfaces(a, a.SymbolTable, a.Graph.ItemImports, a.Facts.Tags) {
+		if roots[info.Name] || a.Facts.Generated[info.Name] { continue }
+		for _, item := range info.Unused {
+			f := checkFinding{Rule: "unused-pub", Level: "note", File: moduleFile(info.Name), Message: fmt.Sprintf("%s::%s is public but nothing imports it", info.Name, item)}
+			def := definitionRegex(item)
+			for _, path := range files[info.Name] {
+				content, err := os.ReadFile(path)
+				if err != nil { return nil, err }
+				if loc := def.FindIndex(content); loc != nil { f.File, f.Line = relSlash(a.Root, path), 1+strings.Count(string(content[:loc[0]]), "\n"); break }
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings, nil
+}
+
+// writeFindingsSARIF writes analysisFindings as a SARIF log, for code-scanning annotations.
+func writeFindingsSARIF(path string, a *analyzer.Report) error {
+	findings, err := analysisFindings(a)
+	if err != nil { return err }
+	file, err := os.Create(path)
+	if err != nil { return err }
+	if err := writeSARIF(file, findings); err != nil { file.Close(); return err }
+	return file.Close()
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/internal/toml"
+)
+
+//go:embed schema/snapshot.schema.json
+var snapshotSchema []byte
+
+// validateJSON checks a decoded document against a JSON Schema. It implements the keywords our
+// published schemas use: type, const, enum, minimum, required, properties, additionalProperties, items and local $refs.
+func validateJSON(schema, doc any) []string {
+	root := toml.Table(schema)
+	var errs []string
+	var check func(s map[string]any, v any, path string)
+	check = func(s map[string]any, v any, path string) {
+		if ref := toml.String(s["$ref"]); ref != "" {
+			target := any(root)
+			for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") { target = toml.Table(target)[part] }
+			check(toml.Table(target), v, path)
+			return
+		}
+		if t, ok := s["type"]; ok && !matchesType(toml.Strings(t), v) {
+			errs = append(errs, fmt.Sprintf("%s: expected %s, got %s", path, strings.Join(toml.Strings(t), " or "), jsonType(v)))
+			return
+		}
+		if c, ok := s["const"]; ok && !reflect.DeepEqual(c, v) { errs = append(errs, fmt.Sprintf("%s: must be %v, got %v", path, c, v)) }
+		if e, ok := s["enum"].([]any); ok {
+			found := false
+			for _, option := range e { found = found || reflect.DeepEqual(option, v) }
+			if !found { errs = append(errs, fmt.Sprintf("%s: %v is not one of %v", path, v, e)) }
+		}
+		if min, ok := s["minimum"].(float64); ok {
+			if n, isNum := v.(float64); isNum && n < min { errs = append(errs, fmt.Sprintf("%s: %v is below the minimum %v", path, n, min)) }
+		}
+		switch val := v.(type) {
+		case map[string]any:
+			for _, r := range toml.Strings(s["required"]) { if _, ok := val[r]; !ok { errs = append(errs, fmt.Sprintf("%s: missing required property %q", path, r)) } }
+			props := toml.Table(s["properties"])
+			keys := make([]string, 0, len(val)); for k := range val { keys = append(keys, k) }
+			sort.Strings(keys)
+			for _, k := range keys {
+				if ps, ok := props[k]; ok { check(toml.Table(ps), val[k], path+"."+k) } else if s["additionalProperties"] == false { errs = append(errs, fmt.Sprintf("%s: unexpected property %q", path, k)) }
+			}
+		case []any:
+			if items, ok := s["items"]; ok { for i, e := range val { check(toml.Table(items), e, fmt.Sprintf("%s[%d]", path, i)) } }
+		}
+	}
+	check(root, doc, "$")
+	return errs
+}
+
+func matchesType(types []string, v any) bool {
+	for _, t := range types {
+		actual := jsonType(v)
+		if t == actual || (t == "number" && actual == "integer") { return true }
+	}
+	return false
+}
+
+func jsonType(v any) string {
+	switch n := v.(type) {
+	case nil: return "null"
+	case bool: return "boolean"
+	case string: return "string"
+	case []any: return "array"
+	case map[string]any: return "object"
+	case float64: if n == math.Trunc(n) { return "integer" }; return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// runValidate checks snapshot files against the embedded schema and exits non-zero if any fail.
+func runValidate(args []string) {
+	if len(args) == 0 { fmt.Println("Usage: dependant validate <snapshot.json>..."); os.Exit(1) }
+	var schema any
+	if err := json.Unmarshal(snapshotSchema, &schema); err != nil { log.Fatalf("Embedded schema is invalid: %v", err) }
+	failed := false
+	for _, path := range args {
+		data, err := os.ReadFile(path)
+		if err != nil { log.Fatalf("Error reading %s: %v", path, err) }
+		var doc any
+		if err := json.Unmarshal(data, &doc); err != nil { fmt.Printf("❌ %s: not valid JSON: %v\n", path, err); failed = true; continue }
+		errs := validateJSON(schema, doc)
+		if len(errs) == 0 { fmt.Printf("✅ %s matches snapshot schema version %d\n", path, snapshotSchemaVersion); continue }
+		failed = true
+		fmt.Printf("❌ %s: %d problem(s)\n", path, len(errs))
+		for _, e := range errs { fmt.Printf("   %s\n", e) }
+	}
+	if failed { os.Exit(1) }
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// SCIP (https://github.com/sourcegraph/scip) is protobuf; the few messages dependant emits are encoded by hand
+// with protoWriter rather than pulling in a protobuf runtime. Field numbers follow scip.proto.
+
+type protoWriter struct{ buf []byte }
+
+func (p *protoWriter) varint(v uint64) {
+	for v >= 0x80 { p.buf = append(p.buf, byte(v)|0x80); v >>= 7 }
+	p.buf = append(p.buf, byte(v))
+}
+
+func (p *protoWriter) uint(field int, v uint64) {
+	if v == 0 { return }
+	p.varint(uint64(field)<<3 | 0)
+	p.varint(v)
+}
+
+func (p *protoWriter) bytes(field int, b []byte) {
+	p.varint(uint64(field)<<3 | 2)
+	p.varint(uint64(len(b)))
+	p.buf = append(p.buf, b...)
+}
+
+func (p *protoWriter) string(field int, s string) { if s != "" { p.bytes(field, []byte(s)) } }
+
+func (p *protoWriter) message(field int, m *protoWriter) { p.bytes(field, m.buf) }
+
+func (p *protoWriter) packed(field int, values []int32) {
+	var inner protoWriter
+	for _, v := range values { inner.varint(uint64(uint32(v))) }
+	p.bytes(field, inner.buf)
+}
+
+const (
+	scipRoleDefinition = 1
+	scipRoleImport     = 2
+)
+
+var scipDefRegex = regexp.MustCompile(`pub\s+(struct|enum|fn|trait)\s+(?:r#)?(\w+)`)
+
+type scipOccurrence struct {
+	line, start, end int
+	symbol           string
+	roles            uint64
+}
+
+// position converts a byte offset to a 0-based line and UTF-8 column.
+func position(content string, offset int) (line, col int) {
+	line = strings.Count(content[:offset], "\n")
+	return line, offset - (strings.LastIndex(content[:offset], "\n") + 1)
+}
+
+// writeSCIP exports public item definitions and the files importing them as a SCIP index, so code-intelligence
+// platforms like Sourcegraph can offer go-to-definition and find-references from dependant's cross-references.
+func writeSCIP(path string, a *analyzer.Report) error {
+	pkg := ". . ."
+	if a.Manifest != nil && a.Manifest.Name != "" {
+		version := a.Manifest.Version
+		if version == "" { version = "." }
+		pkg = "cargo " + a.Manifest.Name + " " + version
+	}
+	rel := func(file string) string { r, _ := filepath.Rel(a.Root, file); return filepath.ToSlash(r) }
+	// Namespace descriptors come from the module tree when there is one (crate::cpu::engine -> cpu/engine/).
+	namespace := func(file string) string {
+		if a.ModTree != nil {
+			if modPath, ok := a.ModTree.Paths[rel(file)]; ok {
+				parts := strings.Split(modPath, "::")[1:]
+				if len(parts) == 0 { return "" }
+				return strings.Join(parts, "/") + "/"
+			}
+		}
+		return a.ModuleName(file) + "/"
+	}
+
+	occurrences := make(map[string][]scipOccurrence)
+	docs := make(map[string]map[string]string) // file -> symbol -> documentation
+	defs := make(map[string]map[string]string)  // module -> item -> symbol
+	contents := make(map[string]string)
+	filter := a.Config.PathFilter(a.Root)
+	err := filepath.WalkDir(a.Root, func(file string, d os.DirEntry, err error) error {
+		if err == nil && filter.Skip(file, d.IsDir()) { if d.IsDir() { return filepath.SkipDir }; return nil }
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".rs") { return err }
+		content, err := os.ReadFile(file)
+		if err != nil { return err }
+		code := analyzer.StripNonCode(string(content)) // same offsets as content, minus comments and literals
+		contents[file] = code
+		module := a.ModuleName(file)
+		for _, m := range scipDefRegex.FindAllStringSubmatchIndex(code, -1) {
+			kind, name := code[m[2]:m[3]], code[m[4]:m[5]]
+			suffix := "#"
+			if kind == "fn" { suffix = "()." }
+			symbol := "dependant " + pkg + " " + namespace(file) + name + suffix
+			line, col := position(code, m[4])
+			occurrences[file] = append(occurrences[file], scipOccurrence{line, col, col + len(name), symbol, scipRoleDefinition})
+			if docs[file] == nil { docs[file] = make(map[string]string) }
+			docs[file][symbol] = fmt.Sprintf("```rust\npub %s %s\n```", kind, name)
+			if defs[module] == nil { defs[module] = make(map[string]string) }
+			if _, seen := defs[module][name]; !seen { defs[module][name] = symbol }
+		}
+		return nil
+	})
+	if err != nil { return err }
+
+	// Items imported through a re-exporting module link to their definition when only one module defines them.
+	unique := make(map[string]string)
+	for _, items := range defs {
+		for item, symbol := range items {
+			if _, dup := unique[item]; dup { unique[item] = "" } else { unique[item] = symbol }
+		}
+	}
+
+	// References: every mention of an imported item in an importing file; mentions inside use statements are imports.
+	for module, items := range a.Graph.ItemImports {
+		for item, files := range items {
+			symbol, ok := defs[module][item]
+			if !ok { symbol = unique[item] }
+			if symbol == "" { continue } // unresolved; nothing to link to
+			word := regexp.MustCompile(`\b` + regexp.QuoteMeta(item) + `\b`)
+			for file := range files {
+				content := contents[file]
+				uses := analyzer.UseTreeRegex.FindAllStringIndex(content, -1)
+				for _, m := range word.FindAllStringIndex(content, -1) {
+					var roles uint64
+					for _, u := range uses { if m[0] >= u[0] && m[1] <= u[1] { roles = scipRoleImport; break } }
+					line, col := position(content, m[0])
+					occurrences[file] = append(occurrences[file], scipOccurrence{line, col, col + len(item), symbol, roles})
+				}
+			}
+		}
+	}
+
+	var index protoWriter
+	var metadata, tool protoWriter
+	tool.string(1, "dependant")
+	version := "(devel)"
+	if info, ok := debug.ReadBuildInfo(); ok { version = info.Main.Version }
+	tool.string(2, version)
+	metadata.message(2, &tool)
+	abs, err := filepath.Abs(a.Root)
+	if err != nil { return err }
+	metadata.string(3, "file://"+filepath.ToSlash(abs))
+	metadata.uint(4, 1) // TextEncoding.UTF8
+	index.message(1, &metadata)
+	for _, file := range sortedKeys(occurrences) {
+		occs := occurrences[file]
+		sort.SliceStable(occs, func(i, j int) bool { if occs[i].line != occs[j].line { return occs[i].line < occs[j].line }; return occs[i].start < occs[j].start })
+		var doc protoWriter
+		doc.string(1, rel(file))
+		for _, o := range occs {
+			var occ protoWriter
+			occ.packed(1, []int32{int32(o.line), int32(o.start), int32(o.end)})
+			occ.string(2, o.symbol)
+			occ.uint(3, o.roles)
+			doc.message(2, &occ)
+		}
+		for _, symbol := range sortedKeys(docs[file]) {
+			var info protoWriter
+			info.string(1, symbol)
+			info.string(3, docs[file][symbol])
+			doc.message(3, &info)
+		}
+		doc.string(4, "rust")
+		doc.uint(6, 1) // PositionEncoding.UTF8CodeUnitOffsetFromLineStart
+		index.message(2, &doc)
+	}
+	return os.WriteFile(path, index.buf, 0o644)
+}
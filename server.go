@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// serveOptions control how long and where serveAndOpen serves.
+type serveOptions struct {
+	Port         int                  // 0 picks any free port
+	NoBrowser    bool                 // print the URL instead of opening a browser
+	Persist      bool                 // keep serving until interrupted instead of stopping once the page has loaded
+	Live         *liveReport          // with --watch: serve its current report and push a reload to /events on every change
+	Notes        *notesAPI            // serve /api/notes, adding notes to the tree's notes file from the page
+	Source       string               // serve the code files under this root at /source/<path>; "" serves none
+	SourceFilter *analyzer.PathFilter // the files under Source that /source/ hides, as the analysis skipped them
+}
+
+// liveReport is the report of a --watch session, replaced whenever the tree is re-analyzed, and the browsers
+// listening for that on /events.
+type liveReport struct {
+	mu      sync.Mutex
+	html    string
+	clients map[chan struct{}]struct{}
+	done    chan struct{} // closed at shutdown, ending every event stream
+}
+
+func newLiveReport(html string) *liveReport {
+	return &liveReport{html: html, clients: make(map[chan struct{}]struct{}), done: make(chan struct{})}
+}
+
+func (l *liveReport) current() string { l.mu.Lock(); defer l.mu.Unlock(); return l.html }
+
+// update swaps in a new report and tells every listening browser to reload.
+func (l *liveReport) update(html string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.html = html
+	for c := range l.clients { select { case c <- struct{}{}: default: } } // a reload is already pending
+}
+
+// serveEvents streams server-sent events: one "reload" per update, until the browser leaves or the server stops.
+func (l *liveReport) serveEvents(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet) { return }
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{}) // the stream outlives the server's write timeout
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	changed := make(chan struct{}, 1)
+	l.mu.Lock()
+	l.clients[changed] = struct{}{}
+	l.mu.Unlock()
+	defer func() { l.mu.Lock(); delete(l.clients, changed); l.mu.Unlock() }()
+	io.WriteString(w, ": watching\n\n")
+	rc.Flush()
+	for {
+		select {
+		case <-changed:
+			io.WriteString(w, "event: reload\ndata: {}\n\n")
+			if err := rc.Flush(); err != nil { return }
+		case <-r.Context().Done(): return
+		case <-l.done: return
+		}
+	}
+}
+
+// serveAndOpen serves the report on a loopback port until the page reports it has rendered.
+// The page POSTs to /loaded from its script, so prefetchers, link previews and favicon requests,
+// which never run it, cannot end the session before the user sees the report.
+func serveAndOpen(htmlContent string, opts serveOptions) {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", opts.Port))
+	if err != nil { log.Fatalf("Could not listen on port %d: %v", opts.Port, err) }
+	port := listener.Addr().(*net.TCPAddr).Port
+	url := fmt.Sprintf("http://127.0.0.1:%d", port)
+
+	current := opts.Live
+	if current == nil { current = newLiveReport(htmlContent) } // holds the report a note re-renders
+	loaded := make(chan struct{})
+	var once sync.Once
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" { http.NotFound(w, r); return }
+		if !allowMethods(w, r, http.MethodGet, http.MethodHead) { return }
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		io.WriteString(w, current.current())
+	})
+	if opts.Live != nil { mux.HandleFunc("/events", opts.Live.serveEvents) }
+	if opts.Notes != nil { mux.HandleFunc("/api/notes", opts.Notes.handler(current)) }
+	if opts.Source != "" { mux.HandleFunc("/source/", sourceHandler(opts.Source, opts.SourceFilter)) }
+	mux.HandleFunc("/loaded", func(w http.ResponseWriter, r *http.Request) {
+		if !allowMethods(w, r, http.MethodPost) { return }
+		once.Do(func() { close(loaded) })
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/favicon.ico", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+
+	server := &http.Server{
+		Handler:           hardened(mux, fmt.Sprintf("127.0.0.1:%d", port), fmt.Sprintf("localhost:%d", port)),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       30 * time.Second,
+		MaxHeaderBytes:    16 << 10,
+	}
+	if opts.Live != nil { server.RegisterOnShutdown(func() { close(opts.Live.done) }) }
+	go func() { if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) { log.Fatalf("Server error: %v", err) } }()
+
+	switch {
+	case opts.Live != nil: fmt.Printf("✅ Analysis complete. Serving the live report at %s; it reloads when the tree changes\n", url)
+	case opts.Persist: fmt.Printf("✅ Analysis complete. Serving the report at %s until interrupted\n", url)
+	case opts.NoBrowser: fmt.Printf("✅ Analysis complete. Open the report at %s\n", url)
+	default: fmt.Printf("✅ Analysis complete. Opening report in your browser at %s\n", url)
+	}
+	if !opts.NoBrowser {
+		if err := openBrowser(url); err != nil { log.Printf("Could not open browser automatically: %v. Please open this URL manually: %s", err, url) }
+	}
+	// Without a browser we cannot know when the user will get to the page, so only an interrupt ends the wait.
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	var timeout <-chan time.Time
+	if opts.Live != nil { opts.Persist = true }
+	if !opts.Persist && !opts.NoBrowser { timeout = time.After(30 * time.Second) }
+	done := (<-chan struct{})(loaded)
+	if opts.Persist { done = nil }
+	select {
+	case <-done:
+	case <-stop:
+	case <-timeout: log.Println("Timed out waiting for page to be loaded.")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil { log.Printf("Server shutdown: %v", err) }
+}
+
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods { if r.Method == m { return true } }
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	return false
+}
+
+// maxRequestBody bounds the body of a request to a route that takes none; bodyLimits lifts it for the routes that do.
+const maxRequestBody = 1 << 10
+
+var bodyLimits = map[string]int64{"/api/notes": 64 << 10}
+
+// hardened wraps the report handlers with the checks every route needs: a Host check against DNS
+// rebinding (skipped when no hosts are given), a small request rate limit, bounded request bodies
+// and defensive response headers.
+func hardened(next http.Handler, hosts ...string) http.Handler {
+	allowedHosts := make(map[string]bool)
+	for _, h := range hosts { allowedHosts[h] = true }
+	const limit, window = 50, time.Second
+	var mu sync.Mutex
+	windowStart, count := time.Now(), 0
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(allowedHosts) > 0 && !allowedHosts[r.Host] { http.Error(w, "forbidden host", http.StatusForbidden); return }
+		mu.Lock()
+		if time.Since(windowStart) > window { windowStart, count = time.Now(), 0 }
+		count++
+		over := count > limit
+		mu.Unlock()
+		if over { http.Error(w, "too many requests", http.StatusTooManyRequests); return }
+		limit, ok := bodyLimits[r.URL.Path]
+		if !ok { limit = maxRequestBody }
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		next.ServeHTTP(w, r)
+	})
+}
+
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin": cmd = exec.Command("open", url)
+	case "linux": cmd = exec.Command("xdg-open", url)
+	case "windows": cmd = exec.Command("cmd", "/c", "start", strings.Replace(url, "&", "^&", -1))
+	default: return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	}
+	return cmd.Run()
+}
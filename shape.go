@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// shapeFormatVersion is bumped whenever ShapeStats changes shape.
+const shapeFormatVersion = 1
+
+// ShapeStats is the anonymized shape of a tree, written by `export --format shape`: counts and distributions only,
+// with no module, item, file or crate names and no paths, so it can be shared to compare architectural health
+// without revealing how the code is organized.
+type ShapeStats struct {
+	Format         int               `json:"format"`
+	Language       string            `json:"language"`
+	Modules        int               `json:"modules"`
+	Edges          int               `json:"edges"`   // module-to-module imports
+	Density        float64           `json:"density"` // edges over the possible edges between the modules
+	ModuleLOC      ShapeDistribution `json:"moduleLoc"`
+	NestingDepth   []ShapeBucket     `json:"nestingDepth"`    // modules by path segments, e.g. net::http is 2
+	TopLevelSizes  []int             `json:"topLevelSizes"`   // modules under each top-level module, largest first
+	FanIn          []ShapeBucket     `json:"fanInHistogram"`  // modules by how many modules import them
+	FanOut         []ShapeBucket     `json:"fanOutHistogram"` // modules by how many modules they import
+	Instability    ShapeStatistic    `json:"instability"`
+	ClosureDepth   ShapeDistribution `json:"closureDepth"` // hops to the furthest module each module reaches
+	Cycles         int               `json:"cycles"`       // groups of modules depending on each other
+	CycleSizes     []int             `json:"cycleSizes"`   // modules in each cycle group, largest first
+	ModulesInCycle int               `json:"modulesInCycles"`
+	ExternalCrates int               `json:"externalCrates"`
+	ExternalItems  int               `json:"externalItems"` // distinct items imported from external crates
+}
+
+type ShapeBucket struct {
+	Range string `json:"range"`
+	Count int    `json:"count"`
+}
+
+type ShapeDistribution struct {
+	Min    int     `json:"min"`
+	P25    int     `json:"p25"`
+	Median int     `json:"median"`
+	P75    int     `json:"p75"`
+	P90    int     `json:"p90"`
+	Max    int     `json:"max"`
+	Mean   float64 `json:"mean"`
+}
+
+type ShapeStatistic struct {
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+}
+
+// shapeBuckets are the histogram ranges, doubling so one tree's hub and another's leaf fall in comparable bins.
+var shapeBuckets = []struct {
+	Range    string
+	Min, Max int
+}{{"0", 0, 0}, {"1", 1, 1}, {"2", 2, 2}, {"3-4", 3, 4}, {"5-8", 5, 8}, {"9-16", 9, 16}, {"17-32", 17, 32}, {"33-64", 33, 64}, {"65+", 65, math.MaxInt}}
+
+func shapeHistogram(values []int) []ShapeBucket {
+	out := make([]ShapeBucket, len(shapeBuckets))
+	for i, b := range shapeBuckets { out[i].Range = b.Range }
+	for _, v := range values {
+		for i, b := range shapeBuckets { if v >= b.Min && v <= b.Max { out[i].Count++; break } }
+	}
+	return out
+}
+
+func shapeDistribution(values []int) ShapeDistribution {
+	if len(values) == 0 { return ShapeDistribution{} }
+	sorted := append([]int{}, values...)
+	sort.Ints(sorted)
+	at := func(q float64) int { return sorted[int(q*float64(len(sorted)-1))] }
+	sum := 0
+	for _, v := range sorted { sum += v }
+	return ShapeDistribution{Min: sorted[0], P25: at(0.25), Median: at(0.5), P75: at(0.75), P90: at(0.9), Max: sorted[len(sorted)-1], Mean: roundShape(float64(sum) / float64(len(sorted)))}
+}
+
+func roundShape(v float64) float64 { return math.Round(v*100) / 100 }
+
+// moduleSegments splits a module name into its path segments, whichever separator the language uses.
+func moduleSegments(module string) []string {
+	return strings.FieldsFunc(module, func(r rune) bool { return r == ':' || r == '/' || r == '.' })
+}
+
+func buildShapeStats(a *analyzer.Report) ShapeStats {
+	modules := make(map[string]struct{})
+	for m := range a.SymbolTable { modules[m] = struct{}{} }
+	for m := range a.Facts.LOC { modules[m] = struct{}{} }
+	moduleGraph := a.BuildModuleGraph(a.Graph.Deps)
+	for from, tos := range moduleGraph {
+		modules[from] = struct{}{}
+		for to := range tos { modules[to] = struct{}{} }
+	}
+	delete(modules, "")
+
+	stats := ShapeStats{Format: shapeFormatVersion, Language: a.Language, Modules: len(modules), CycleSizes: []int{}, TopLevelSizes: []int{}}
+	fanIn := make(map[string]int)
+	for _, tos := range moduleGraph {
+		stats.Edges += len(tos)
+		for to := range tos { fanIn[to]++ }
+	}
+	if n := len(modules); n > 1 { stats.Density = roundShape(float64(stats.Edges) / float64(n*(n-1))) }
+
+	var locs, depths, ins, outs, closureDepths []int
+	var instabilities []float64
+	topLevel := make(map[string]int)
+	for m := range modules {
+		locs = append(locs, a.Facts.LOC[m])
+		segments := moduleSegments(m)
+		depths = append(depths, len(segments))
+		if len(segments) > 0 { topLevel[segments[0]]++ }
+		in, out := fanIn[m], len(moduleGraph[m])
+		ins, outs = append(ins, in), append(outs, out)
+		if in+out > 0 { instabilities = append(instabilities, float64(out)/float64(in+out)) }
+		depth := 0
+		for _, hops := range forwardReach(moduleGraph, m) { depth = max(depth, hops) }
+		closureDepths = append(closureDepths, depth)
+	}
+	stats.ModuleLOC, stats.ClosureDepth = shapeDistribution(locs), shapeDistribution(closureDepths)
+	stats.NestingDepth = []ShapeBucket{}
+	for depth := 1; len(depths) > 0 && depth <= slices.Max(depths); depth++ {
+		count := 0
+		for _, d := range depths { if d == depth { count++ } }
+		stats.NestingDepth = append(stats.NestingDepth, ShapeBucket{Range: strconv.Itoa(depth), Count: count})
+	}
+	for _, size := range topLevel { stats.TopLevelSizes = append(stats.TopLevelSizes, size) }
+	sort.Sort(sort.Reverse(sort.IntSlice(stats.TopLevelSizes)))
+	stats.FanIn, stats.FanOut = shapeHistogram(ins), shapeHistogram(outs)
+	if len(instabilities) > 0 {
+		sort.Float64s(instabilities)
+		sum := 0.0
+		for _, v := range instabilities { sum += v }
+		stats.Instability = ShapeStatistic{Mean: roundShape(sum / float64(len(instabilities))), Median: roundShape(instabilities[len(instabilities)/2])}
+	}
+
+	for _, cycle := range findCycles(moduleGraph) {
+		stats.Cycles++
+		stats.CycleSizes = append(stats.CycleSizes, len(cycle))
+		stats.ModulesInCycle += len(cycle)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(stats.CycleSizes)))
+	for crate, items := range a.Graph.External {
+		if a.Manifest != nil && crate == a.Manifest.Name { continue }
+		stats.ExternalCrates++
+		stats.ExternalItems += len(items)
+	}
+	return stats
+}
+
+func writeShape(path string, a *analyzer.Report) error {
+	content, err := json.MarshalIndent(buildShapeStats(a), "", "  ")
+	if err != nil { return err }
+	return os.WriteFile(path, append(content, '\n'), 0o644)
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// SnapshotDiff is how the dependency structure moved between two snapshots, for reviewing a refactor's impact.
+type SnapshotDiff struct {
+	Old, New               string // what each side is, e.g. a snapshot path
+	AddedModules           []string
+	RemovedModules         []string
+	NewEdges, RemovedEdges []ModuleEdge // Items and ItemNames are those of the side the edge exists in
+	Dependents             []DependentChange
+	Items                  []ItemCountChange
+	APIAdded, APIRemoved   int // public items; `dependant api-diff` has the details
+}
+
+// DependentChange is a module whose set of importing modules changed.
+type DependentChange struct {
+	Module       string
+	Gained, Lost []string
+}
+
+// ItemCountChange is an item imported by a different number of files than before (0 when not imported at all).
+type ItemCountChange struct {
+	Module, Item  string
+	Before, After int
+}
+
+func (c ItemCountChange) Delta() int { return c.After - c.Before }
+
+func diffSnapshots(before, after *Snapshot) SnapshotDiff {
+	var d SnapshotDiff
+	oldModules, newModules := make(map[string]struct{}), make(map[string]struct{})
+	for _, m := range before.Modules { oldModules[m.Name] = struct{}{} }
+	for _, m := range after.Modules { newModules[m.Name] = struct{}{} }
+	d.AddedModules, d.RemovedModules = setChanges(oldModules, newModules)
+
+	edges := func(s *Snapshot) map[[2]string]ModuleEdge {
+		out := make(map[[2]string]ModuleEdge)
+		for _, e := range s.Edges { out[[2]string{e.From, e.To}] = e }
+		return out
+	}
+	oldEdges, newEdges := edges(before), edges(after)
+	for _, e := range after.Edges { if _, ok := oldEdges[[2]string{e.From, e.To}]; !ok { d.NewEdges = append(d.NewEdges, e) } }
+	for _, e := range before.Edges { if _, ok := newEdges[[2]string{e.From, e.To}]; !ok { d.RemovedEdges = append(d.RemovedEdges, e) } }
+
+	oldDependents, newDependents := moduleDependents(before), moduleDependents(after)
+	modules := make(map[string]struct{})
+	for m := range oldDependents { modules[m] = struct{}{} }
+	for m := range newDependents { modules[m] = struct{}{} }
+	for _, m := range sortedKeys(modules) {
+		gained, lost := setChanges(oldDependents[m], newDependents[m])
+		if len(gained) > 0 || len(lost) > 0 { d.Dependents = append(d.Dependents, DependentChange{Module: m, Gained: gained, Lost: lost}) }
+	}
+
+	type key struct{ module, item string }
+	counts := make(map[key]*ItemCountChange)
+	for side, s := range []*Snapshot{before, after} {
+		for _, m := range s.Modules {
+			for _, item := range m.Items {
+				k := key{m.Name, item.Name}
+				if counts[k] == nil { counts[k] = &ItemCountChange{Module: m.Name, Item: item.Name} }
+				if side == 0 { counts[k].Before = len(item.Files) } else { counts[k].After = len(item.Files) }
+			}
+		}
+	}
+	for _, c := range counts { if c.Before != c.After { d.Items = append(d.Items, *c) } }
+	sort.Slice(d.Items, func(i, j int) bool {
+		if abs(d.Items[i].Delta()) != abs(d.Items[j].Delta()) { return abs(d.Items[i].Delta()) > abs(d.Items[j].Delta()) }
+		if d.Items[i].Module != d.Items[j].Module { return d.Items[i].Module < d.Items[j].Module }
+		return d.Items[i].Item < d.Items[j].Item
+	})
+
+	api := diffPublicAPI(before, after, 0.5)
+	d.APIAdded, d.APIRemoved = len(api.Added), len(api.Removed)
+	return d
+}
+
+func (d SnapshotDiff) Empty() bool {
+	return len(d.AddedModules)+len(d.RemovedModules)+len(d.NewEdges)+len(d.RemovedEdges)+len(d.Dependents)+len(d.Items)+d.APIAdded+d.APIRemoved == 0
+}
+
+func writeSnapshotDiffMarkdown(w io.Writer, d SnapshotDiff) {
+	fmt.Fprintf(w, "# Dependency Changes\n\n`%s` → `%s`\n", d.Old, d.New)
+	if d.Empty() { fmt.Fprintln(w, "\nNo changes to modules, edges, dependents or item imports."); return }
+	if len(d.AddedModules) > 0 || len(d.RemovedModules) > 0 {
+		fmt.Fprintf(w, "\n## Modules\n\n")
+		for _, m := range d.AddedModules { fmt.Fprintf(w, "- ➕ `%s`\n", m) }
+		for _, m := range d.RemovedModules { fmt.Fprintf(w, "- ➖ `%s`\n", m) }
+	}
+	if d.APIAdded > 0 || d.APIRemoved > 0 {
+		fmt.Fprintf(w, "\nPublic API: %d item%s added, %d removed (see `dependant api-diff`).\n", d.APIAdded, plural(d.APIAdded), d.APIRemoved)
+	}
+	edgeTable := func(title string, edges []ModuleEdge) {
+		if len(edges) == 0 { return }
+		fmt.Fprintf(w, "\n## %s (%d)\n\n| From | To | Items |\n|---|---|---|\n", title, len(edges))
+		for _, e := range edges { fmt.Fprintf(w, "| `%s` | `%s` | %s |\n", e.From, e.To, strings.Join(e.ItemNames, ", ")) }
+	}
+	edgeTable("New Edges", d.NewEdges)
+	edgeTable("Removed Edges", d.RemovedEdges)
+	if len(d.Dependents) > 0 {
+		fmt.Fprintf(w, "\n## Dependents (%d)\n\n| Module | Gained | Lost |\n|---|---|---|\n", len(d.Dependents))
+		for _, c := range d.Dependents { fmt.Fprintf(w, "| `%s` | %s | %s |\n", c.Module, strings.Join(c.Gained, ", "), strings.Join(c.Lost, ", ")) }
+	}
+	if len(d.Items) > 0 {
+		fmt.Fprintf(w, "\n## Item Import Counts (%d)\n\n| Item | Before | After | Δ |\n|---|---:|---:|---:|\n", len(d.Items))
+		for _, c := range d.Items { fmt.Fprintf(w, "| `%s::%s` | %d | %d | %+d |\n", c.Module, c.Item, c.Before, c.After, c.Delta()) }
+	}
+}
+
+// snapshotDiffTemplate is a standalone page with no scripts or external requests, so it can be attached to a review.
+var snapshotDiffTemplate = template.Must(template.New("diff").Funcs(template.FuncMap{"join": func(s []string) string { return strings.Join(s, ", ") }}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8"><meta name="viewport" content="width=device-width, initial-scale=1.0"><title>Dependency Changes</title>
+<meta http-equiv="Content-Security-Policy" content="default-src 'none'; style-src 'unsafe-inline'">
+<style>
+	body { background-color: #1a1b26; color: #c0caf5; font-family: system-ui, -apple-system, 'Segoe UI', sans-serif; margin: 0; padding: 2rem; line-height: 1.6; }
+	main { max-width: 1000px; margin: 0 auto; }
+	h1, h2 { color: #ffffff; } h2 { border-bottom: 1px solid #3b4261; padding-bottom: 0.3rem; }
+	table { width: 100%; border-collapse: collapse; margin-bottom: 1rem; } th, td { text-align: left; padding: 0.4rem 0.6rem; border-bottom: 1px solid #3b4261; }
+	code, .mono { font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; }
+	.added { color: #9ece6a; } .removed { color: #f7768e; } .num { text-align: right; }
+</style>
+</head>
+<body><main>
+<h1>🔀 Dependency Changes</h1>
+<p><code>{{.Old}}</code> → <code>{{.New}}</code></p>
+{{if .Empty}}<p>No changes to modules, edges, dependents or item imports.</p>{{end}}
+{{if or .AddedModules .RemovedModules}}<h2>Modules</h2><ul>{{range .AddedModules}}<li class="added mono">+ {{.}}</li>{{end}}{{range .RemovedModules}}<li class="removed mono">− {{.}}</li>{{end}}</ul>{{end}}
+{{if or .APIAdded .APIRemoved}}<p>Public API: {{.APIAdded}} item(s) added, {{.APIRemoved}} removed (see <code>dependant api-diff</code>).</p>{{end}}
+{{if .NewEdges}}<h2>New Edges ({{len .NewEdges}})</h2><table><tr><th>From</th><th>To</th><th>Items</th></tr>{{range .NewEdges}}<tr class="added"><td class="mono">{{.From}}</td><td class="mono">{{.To}}</td><td class="mono">{{join .ItemNames}}</td></tr>{{end}}</table>{{end}}
+{{if .RemovedEdges}}<h2>Removed Edges ({{len .RemovedEdges}})</h2><table><tr><th>From</th><th>To</th><th>Items</th></tr>{{range .RemovedEdges}}<tr class="removed"><td class="mono">{{.From}}</td><td class="mono">{{.To}}</td><td class="mono">{{join .ItemNames}}</td></tr>{{end}}</table>{{end}}
+{{if .Dependents}}<h2>Dependents ({{len .Dependents}})</h2><table><tr><th>Module</th><th>Gained</th><th>Lost</th></tr>{{range .Dependents}}<tr><td class="mono">{{.Module}}</td><td class="added mono">{{join .Gained}}</td><td class="removed mono">{{join .Lost}}</td></tr>{{end}}</table>{{end}}
+{{if .Items}}<h2>Item Import Counts ({{len .Items}})</h2><table><tr><th>Item</th><th class="num">Before</th><th class="num">After</th><th class="num">Δ</th></tr>{{range .Items}}<tr><td class="mono">{{.Module}}::{{.Item}}</td><td class="num">{{.Before}}</td><td class="num">{{.After}}</td><td class="num {{if gt .Delta 0}}added{{else}}removed{{end}}">{{printf "%+d" .Delta}}</td></tr>{{end}}</table>{{end}}
+</main></body>
+</html>
+`))
+
+// runDiff compares two snapshots, or a baseline snapshot with a fresh analysis of a directory.
+func runDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	f := addAnalyzeFlags(fs)
+	baseline := fs.String("baseline", "", "snapshot to compare a fresh analysis of <directory> against")
+	baselineRev := fs.String("baseline-rev", "", "revision of <directory> to analyze as the baseline instead of a snapshot; with --rev, compares two branches without checking either out")
+	format := fs.String("format", "markdown", "output format: markdown or html")
+	output := fs.String("output", "", "write the delta report to this file instead of stdout")
+	fs.Usage = func() {
+		fmt.Println("Usage: dependant diff [flags] <old.json> <new.json>\n       dependant diff --baseline <old.json> [flags] <directory>\n       dependant diff --baseline-rev <rev> [--rev <rev>] [flags] <directory>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *format != "markdown" && *format != "html" { f.fatalf("Unknown --format %q: expected markdown or html", *format) }
+	var before, after *Snapshot
+	var err error
+	switch {
+	case *baseline != "" && *baselineRev == "" && fs.NArg() == 1:
+		if before, err = readSnapshot(*baseline); err != nil { f.fatalf("Error reading baseline: %v", err) }
+		after = buildSnapshot(f.analyze(fs.Arg(0)))
+	case *baselineRev != "" && *baseline == "" && fs.NArg() == 1:
+		before = buildSnapshot(f.analyzeRev(fs.Arg(0), *baselineRev))
+		after = buildSnapshot(f.analyze(fs.Arg(0)))
+	case *baseline == "" && *baselineRev == "" && fs.NArg() == 2:
+		if before, err = readSnapshot(fs.Arg(0)); err != nil { f.fatalf("Error reading snapshot: %v", err) }
+		if after, err = readSnapshot(fs.Arg(1)); err != nil { f.fatalf("Error reading snapshot: %v", err) }
+	default:
+		fs.Usage(); os.Exit(1)
+	}
+	f.close() // the snapshots hold everything needed from any --rev copies
+	d := diffSnapshots(before, after)
+	d.Old, d.New = fs.Arg(0), fs.Arg(1)
+	if *baseline != "" { d.Old, d.New = *baseline, fs.Arg(0) }
+	if *baselineRev != "" { d.Old, d.New = fs.Arg(0)+" @ "+*baselineRev, fs.Arg(0) }
+	if *f.rev != "" { d.New += " @ " + *f.rev }
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil { f.fatalf("Error writing %s: %v", *output, err) }
+		defer file.Close()
+		w = file
+	}
+	if *format == "html" {
+		if err := snapshotDiffTemplate.Execute(w, d); err != nil { f.fatalf("Error rendering diff: %v", err) }
+	} else {
+		writeSnapshotDiffMarkdown(w, d)
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/WillKirkmanM/dependant/internal/toml"
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// snapshotSchemaVersion is bumped whenever the snapshot format changes; add a migration for the previous version alongside.
+const snapshotSchemaVersion = 9
+
+// snapshotMigrations[i] upgrades a decoded snapshot from schema version i+1 to i+2.
+var snapshotMigrations = []func(map[string]any) error{
+	// v1 snapshots predate crate detection. Assume a library so api-diff keeps suggesting semver bumps.
+	func(doc map[string]any) error { doc["library"] = true; return nil },
+	// v2 snapshots have no weighted edges; rebuild them from the per-module item import lists.
+	func(doc map[string]any) error { doc["edges"] = buildWeightedEdges(&analyzer.Report{}, snapshotItemImports(doc)); return nil },
+	// v3 snapshots did not record external crates; an empty list is the honest answer.
+	func(doc map[string]any) error { doc["externalCrates"] = []any{}; return nil },
+	// v4 edges carry only counts; name the items along each edge from the per-module item import lists.
+	func(doc map[string]any) error {
+		names := make(map[[2]string][]string)
+		for _, e := range buildWeightedEdges(&analyzer.Report{}, snapshotItemImports(doc)) { names[[2]string{e.From, e.To}] = e.ItemNames }
+		edges, _ := doc["edges"].([]any)
+		for _, e := range edges {
+			edge := toml.Table(e)
+			if n := names[[2]string{toml.String(edge["from"]), toml.String(edge["to"])}]; edge != nil && n != nil { edge["itemNames"] = n }
+		}
+		return nil
+	},
+	// v5 snapshots carried no provenance; an empty methodology claims nothing about how they were produced.
+	func(doc map[string]any) error { doc["methodology"] = map[string]any{}; return nil },
+	// v6 modules have no transitive closure; derive it from their import lists.
+	func(doc map[string]any) error {
+		modules, _ := doc["modules"].([]any)
+		graph := make(map[string]map[string]struct{})
+		for _, m := range modules {
+			module := toml.Table(m)
+			for _, to := range toml.Strings(module["imports"]) {
+				if graph[toml.String(module["name"])] == nil { graph[toml.String(module["name"])] = make(map[string]struct{}) }
+				graph[toml.String(module["name"])][to] = struct{}{}
+			}
+		}
+		for _, m := range modules {
+			module := toml.Table(m)
+			reaches, depth, impactDepth := snapshotClosure(graph, toml.String(module["name"]))
+			module["reaches"], module["depth"], module["impactDepth"] = reaches, depth, impactDepth
+		}
+		return nil
+	},
+	// v7 snapshots name no revision; they describe a working tree, which is what an absent revision means.
+	func(doc map[string]any) error { return nil },
+	// v8 modules do not list their files, so nothing says which module an importing file belongs to, nor their size.
+	func(doc map[string]any) error {
+		modules, _ := doc["modules"].([]any)
+		for _, m := range modules { if module := toml.Table(m); module != nil { module["files"], module["loc"] = []any{}, 0 } }
+		return nil
+	},
+}
+
+// snapshotClosure is a module's closure as snapshots record it: the modules it reaches, the hops to the furthest of
+// them, and the hops to its furthest transitive dependent.
+func snapshotClosure(moduleGraph map[string]map[string]struct{}, module string) (reaches []string, depth, impactDepth int) {
+	reach := forwardReach(moduleGraph, module)
+	for _, hops := range reach { depth = max(depth, hops) }
+	for _, hops := range reverseReach(moduleGraph, module) { impactDepth = max(impactDepth, hops) }
+	return append([]string{}, sortedKeys(reach)...), depth, impactDepth
+}
+
+// snapshotItemImports reads module -> item -> importing files back out of a decoded snapshot.
+func snapshotItemImports(doc map[string]any) map[string]map[string]map[string]struct{} {
+	itemImports := make(map[string]map[string]map[string]struct{})
+	modules, _ := doc["modules"].([]any)
+	for _, m := range modules {
+		module := toml.Table(m)
+		name := toml.String(module["name"])
+		itemImports[name] = make(map[string]map[string]struct{})
+		items, _ := module["items"].([]any)
+		for _, it := range items {
+			item := toml.Table(it)
+			files := make(map[string]struct{})
+			for _, f := range toml.Strings(item["files"]) { files[f] = struct{}{} }
+			itemImports[name][toml.String(item["name"])] = files
+		}
+	}
+	return itemImports
+}
+
+// Snapshot is the JSON form of one analysis run, written with --snapshot and consumed by api-diff.
+type Snapshot struct {
+	SchemaVersion int                   `json:"schemaVersion"`
+	Root          string                `json:"root"`
+	Revision      string                `json:"revision,omitempty"` // the commit analyzed with --rev
+	Language      string                `json:"language,omitempty"`
+	Crate         string                `json:"crate,omitempty"`
+	Version       string                `json:"version,omitempty"`
+	Library       bool                  `json:"library"`
+	CreatedAt     time.Time             `json:"createdAt"`
+	Modules       []SnapshotModule      `json:"modules"`
+	Edges         []ModuleEdge          `json:"edges"`
+	External      []SnapshotCrate       `json:"externalCrates"`
+	Methodology   map[string]analyzer.Provenance `json:"methodology"` // how each kind of finding was produced; see methodology
+}
+
+// SnapshotCrate is a third-party crate with the items imported from it.
+type SnapshotCrate struct {
+	Name  string         `json:"name"`
+	Items []SnapshotItem `json:"items"`
+}
+
+type SnapshotModule struct {
+	Name        string         `json:"name"`
+	Tag         string         `json:"tag,omitempty"`
+	PublicItems []string       `json:"publicItems"`
+	Files       []string       `json:"files"`       // the module's files that import something, relative to the root
+	LOC         int            `json:"loc"`         // non-blank lines outside comments
+	Dependents  []string       `json:"dependents"`  // files importing the module, relative to the root
+	Imports     []string       `json:"imports"`     // modules this module imports
+	Items       []SnapshotItem `json:"items"`       // imported items with the files importing them
+	Reaches     []string       `json:"reaches"`     // modules reached through imports, directly or transitively
+	Depth       int            `json:"depth"`       // hops to the furthest module in Reaches
+	ImpactDepth int            `json:"impactDepth"` // hops to the furthest module that transitively imports this one
+}
+
+type SnapshotItem struct {
+	Name       string      `json:"name"`
+	Files      []string    `json:"files"`
+	Provenance *analyzer.Provenance `json:"provenance,omitempty"` // set when some importing files were inferred rather than read
+}
+
+func buildSnapshot(a *analyzer.Report) *Snapshot {
+	root, manifest, symbolTable, graph, facts := a.Root, a.Manifest, a.SymbolTable, a.Graph, a.Facts
+	rel := func(path string) string { if r, err := filepath.Rel(root, path); err == nil { return filepath.ToSlash(r) }; return path }
+	dependents, files := make(map[string][]string), make(map[string][]string)
+	for file, deps := range graph.Deps { for dep := range deps { dependents[dep] = append(dependents[dep], rel(file)) } }
+	for file := range importingFiles(graph) { m := a.ModuleName(file); files[m] = append(files[m], rel(file)) }
+	moduleGraph := a.BuildModuleGraph(graph.Deps)
+
+	names := make(map[string]struct{})
+	for m := range symbolTable { names[m] = struct{}{} }
+	for m := range graph.ItemImports { names[m] = struct{}{} }
+	for m := range dependents { names[m] = struct{}{} }
+	for m := range files { names[m] = struct{}{} }
+
+	snap := &Snapshot{SchemaVersion: snapshotSchemaVersion, Root: a.DisplayRoot(), Revision: a.Rev, Language: a.Language, Crate: manifest.Name, Version: manifest.Version, Library: manifest.Library, CreatedAt: time.Now().UTC(), Methodology: methodology(a)}
+	for name := range names {
+		if name == "" { continue }
+		m := SnapshotModule{Name: name, Tag: facts.Tags[name], PublicItems: []string{}, Files: uniqueSorted(files[name]), LOC: facts.LOC[name], Dependents: uniqueSorted(dependents[name]), Imports: []string{}, Items: []SnapshotItem{}}
+		for item := range symbolTable[name] { m.PublicItems = append(m.PublicItems, item) }
+		sort.Strings(m.PublicItems)
+		for to := range moduleGraph[name] { m.Imports = append(m.Imports, to) }
+		sort.Strings(m.Imports)
+		for item, files := range graph.ItemImports[name] {
+			var paths []string
+			for f := range files { paths = append(paths, rel(f)) }
+			m.Items = append(m.Items, SnapshotItem{Name: item, Files: uniqueSorted(paths), Provenance: graph.ItemProvenance(name, item)})
+		}
+		sort.Slice(m.Items, func(i, j int) bool { return m.Items[i].Name < m.Items[j].Name })
+		m.Reaches, m.Depth, m.ImpactDepth = snapshotClosure(moduleGraph, name)
+		snap.Modules = append(snap.Modules, m)
+	}
+	sort.Slice(snap.Modules, func(i, j int) bool { return snap.Modules[i].Name < snap.Modules[j].Name })
+	snap.Edges = append([]ModuleEdge{}, buildWeightedEdges(a, graph.ItemImports)...)
+	snap.External = []SnapshotCrate{}
+	for crate, items := range graph.External {
+		if crate == manifest.Name { continue } // a crate importing itself by name, e.g. from src/main.rs
+		c := SnapshotCrate{Name: crate}
+		for item, files := range items {
+			var paths []string
+			for f := range files { paths = append(paths, rel(f)) }
+			c.Items = append(c.Items, SnapshotItem{Name: item, Files: uniqueSorted(paths)})
+		}
+		sort.Slice(c.Items, func(i, j int) bool { return c.Items[i].Name < c.Items[j].Name })
+		snap.External = append(snap.External, c)
+	}
+	sort.Slice(snap.External, func(i, j int) bool { return snap.External[i].Name < snap.External[j].Name })
+	return snap
+}
+
+// importingFiles is every file the graph records an import by, of a module or an external crate.
+func importingFiles(graph *analyzer.DependencyGraph) map[string]struct{} {
+	files := make(map[string]struct{})
+	for file := range graph.Deps { files[file] = struct{}{} }
+	for _, items := range graph.External { for _, importers := range items { for file := range importers { files[file] = struct{}{} } } }
+	return files
+}
+
+func writeSnapshot(path string, snap *Snapshot) error {
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil { return err }
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// readSnapshot loads a snapshot written by any earlier version of the tool, migrating it to the current schema.
+func readSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil { return nil, err }
+	data, err = migrateSnapshot(data)
+	if err != nil { return nil, fmt.Errorf("%s: %w", path, err) }
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil { return nil, err }
+	return &snap, nil
+}
+
+func migrateSnapshot(data []byte) ([]byte, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil { return nil, err }
+	version := 1 // snapshots written before versioning carry no schemaVersion
+	if v, ok := doc["schemaVersion"].(float64); ok { version = int(v) }
+	if version > snapshotSchemaVersion { return nil, fmt.Errorf("snapshot schema version %d is newer than supported version %d; upgrade dependant", version, snapshotSchemaVersion) }
+	if version == snapshotSchemaVersion { return data, nil }
+	for ; version < snapshotSchemaVersion; version++ {
+		if err := snapshotMigrations[version-1](doc); err != nil { return nil, fmt.Errorf("migrating snapshot from schema version %d: %w", version, err) }
+	}
+	doc["schemaVersion"] = snapshotSchemaVersion
+	return json.Marshal(doc)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// snapshotSections are the report sections a snapshot holds enough to render; the others need the source (unsafe
+// blocks, cfg predicates, the mod tree), the manifest, git history or dependant.toml, none of which a snapshot carries.
+var snapshotSections = []string{"summary", "top-items", "cycles", "modules", "outbound", "graph", "treemap", "inferred-layers", "metrics", "god-modules", "closure", "interfaces", "edge-history", "per-module"}
+
+// reportFromSnapshot rebuilds the parts of an analysis a snapshot records, so `serve --from` can render a report
+// without the source. The snapshot does not tell test imports apart, so every import counts as a production one.
+func reportFromSnapshot(snap *Snapshot) (*analyzer.Report, error) {
+	a := &analyzer.Report{
+		Root: snap.Root, Language: snap.Language, Config: analyzer.DefaultConfig(),
+		Manifest:    &analyzer.CargoManifest{Name: snap.Crate, Version: snap.Version, Library: snap.Library, Dependencies: map[string]string{}},
+		SymbolTable: make(map[string]map[string]struct{}),
+		Facts:       &analyzer.ModuleFacts{Tags: make(map[string]string), UnsafeBlocks: make(map[string]int), UnsafeFns: make(map[string]int), LOC: make(map[string]int), Generated: make(map[string]bool), Restricted: make(map[string]map[string]string), ModulePaths: make(map[string]string)},
+		Graph:       &analyzer.DependencyGraph{Deps: make(map[string]map[string]struct{}), ItemImports: make(map[string]map[string]map[string]struct{}), Conditions: make(map[string]map[string]map[string]struct{}), External: make(map[string]map[string]map[string]struct{}), Inferred: make(map[string]map[string]map[string]string)},
+		FileModules: make(map[string]string),
+	}
+	if snap.Revision != "" { a.Origin, a.Rev = snap.Root, snap.Revision }
+	abs := func(rel string) string { return filepath.Join(snap.Root, filepath.FromSlash(rel)) }
+	for _, m := range snap.Modules {
+		for _, file := range m.Files { a.FileModules[file] = m.Name }
+	}
+	if len(a.FileModules) == 0 && len(snap.Modules) > 0 { return nil, fmt.Errorf("the snapshot records no module files (schema version 8 or older); export it again") }
+	for _, m := range snap.Modules {
+		if m.Tag != "" { a.Facts.Tags[m.Name] = m.Tag }
+		if m.LOC > 0 { a.Facts.LOC[m.Name] = m.LOC }
+		a.SymbolTable[m.Name] = make(map[string]struct{})
+		for _, item := range m.PublicItems { a.SymbolTable[m.Name][item] = struct{}{} }
+		for _, file := range m.Dependents {
+			if a.Graph.Deps[abs(file)] == nil { a.Graph.Deps[abs(file)] = make(map[string]struct{}) }
+			a.Graph.Deps[abs(file)][m.Name] = struct{}{}
+		}
+		a.Graph.ItemImports[m.Name] = snapshotItems(m.Items, abs)
+	}
+	for _, c := range snap.External { a.Graph.External[c.Name] = snapshotItems(c.Items, abs) }
+	a.Graph.ProdDeps = a.Graph.Deps
+	analyzer.ConfigureModuleNaming(a)
+	return a, nil
+}
+
+// snapshotItems reads item -> importing files back out of a snapshot's item list.
+func snapshotItems(items []SnapshotItem, abs func(string) string) map[string]map[string]struct{} {
+	byItem := make(map[string]map[string]struct{})
+	for _, item := range items {
+		byItem[item.Name] = make(map[string]struct{})
+		for _, file := range item.Files { byItem[item.Name][abs(file)] = struct{}{} }
+	}
+	return byItem
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// ImportSite is where a file imports an item, listed under the item in the report. In serve mode it links to the line
+// in /source/.
+type ImportSite struct {
+	File      string // relative to the root
+	Line      int    // 0 when no import statement of the file names the item or its module, e.g. a macro's import
+	Statement string // the whole statement, on one line
+}
+
+// importStatement is one import statement of a file; Rust use trees and parenthesized imports span several lines.
+type importStatement struct {
+	Line  int      // the first line, from 1
+	Lines []string // trimmed
+}
+
+// importStatements finds the import statements of a file in any language the analyzers read. A statement continues
+// while its braces or parentheses are open; each line of a Go import block is a statement of its own.
+func importStatements(content string) []importStatement {
+	var statements []importStatement
+	depth, inGoImports := 0, false
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case inGoImports && trimmed == ")": inGoImports = false
+		case inGoImports: if trimmed != "" && !strings.HasPrefix(trimmed, "//") { statements = append(statements, importStatement{Line: i + 1, Lines: []string{trimmed}}) }
+		case trimmed == "import (": inGoImports = true
+		case depth > 0:
+			s := &statements[len(statements)-1]
+			s.Lines = append(s.Lines, trimmed)
+			depth = max(0, depth+strings.Count(line, "{")+strings.Count(line, "(")-strings.Count(line, "}")-strings.Count(line, ")"))
+		case importLineRegex.MatchString(line):
+			statements = append(statements, importStatement{Line: i + 1, Lines: []string{trimmed}})
+			depth = max(0, strings.Count(line, "{")+strings.Count(line, "(")-strings.Count(line, "}")-strings.Count(line, ")"))
+		}
+	}
+	return statements
+}
+
+// lastSegment is the last segment of a module or item path: b for crate::a::b, utils for src/utils, mod for pkg.mod.
+func lastSegment(name string) string {
+	segments := strings.FieldsFunc(name, func(r rune) bool { return r == ':' || r == '/' || r == '.' })
+	if len(segments) == 0 { return name }
+	return segments[len(segments)-1]
+}
+
+// findImportSite finds the line importing item from module among a file's statements: the line naming the item in a
+// statement naming the module, else the first statement naming the module (a glob import, or Go's package import).
+func findImportSite(statements []importStatement, module, item string) (line int, statement string) {
+	moduleWord := regexp.MustCompile(`\b` + regexp.QuoteMeta(lastSegment(module)) + `\b`)
+	var itemWord *regexp.Regexp
+	if item != "" { itemWord = regexp.MustCompile(`\b` + regexp.QuoteMeta(lastSegment(item)) + `\b`) }
+	for _, s := range statements {
+		text := strings.Join(s.Lines, " ")
+		if !moduleWord.MatchString(text) { continue }
+		if line == 0 { line, statement = s.Line, text }
+		if itemWord == nil { break }
+		for i, l := range s.Lines { if itemWord.MatchString(l) { return s.Line + i, text } }
+	}
+	return line, statement
+}
+
+// importSites lists where each file imports item from module, reading each file's statements once through cache.
+// Files that cannot be read, such as those of a snapshot, are listed without a line.
+func importSites(root string, files map[string]struct{}, module, item string, cache map[string][]importStatement) []ImportSite {
+	sites := make([]ImportSite, 0, len(files))
+	for file := range files {
+		statements, ok := cache[file]
+		if !ok {
+			if content, err := os.ReadFile(file); err == nil { statements = importStatements(string(content)) }
+			cache[file] = statements
+		}
+		site := ImportSite{File: relSlash(root, file)}
+		site.Line, site.Statement = findImportSite(statements, module, item)
+		sites = append(sites, site)
+	}
+	sort.Slice(sites, func(i, j int) bool { return sites[i].File < sites[j].File })
+	return sites
+}
+
+// sourceKeywords are the keywords the /source/ view highlights, by analyzer.FileLanguage.
+var sourceKeywords = map[string]string{
+	"rust":   `as|async|await|break|const|continue|crate|dyn|else|enum|extern|false|fn|for|if|impl|in|let|loop|match|mod|move|mut|pub|ref|return|self|Self|static|struct|super|trait|true|type|unsafe|use|where|while`,
+	"go":     `break|case|chan|const|continue|default|defer|else|fallthrough|for|func|go|goto|if|import|interface|map|package|range|return|select|struct|switch|type|var`,
+	"js":     `async|await|break|case|catch|class|const|continue|default|delete|do|else|export|extends|false|finally|for|from|function|if|import|in|instanceof|let|new|null|of|return|static|super|switch|this|throw|true|try|typeof|undefined|var|void|while|yield`,
+	"python": `and|as|assert|async|await|break|class|continue|def|del|elif|else|except|False|finally|for|from|global|if|import|in|is|lambda|None|nonlocal|not|or|pass|raise|return|True|try|while|with|yield`,
+	"c":      `break|case|char|const|continue|default|do|double|else|enum|extern|float|for|if|int|long|return|short|signed|sizeof|static|struct|switch|typedef|union|unsigned|void|while|#include|#define|#ifndef|#ifdef|#endif`,
+}
+
+// highlighter marks up source lines of one language: comments, strings and keywords. Block comments may span lines.
+type highlighter struct {
+	token     *regexp.Regexp
+	inComment bool // inside a /* */ comment carried over from an earlier line
+}
+
+func newHighlighter(language string) *highlighter {
+	comment, char := `//[^\n]*|/\*.*?(?:\*/|$)`, `'(?:[^'\\]|\\.)*'`
+	if language == "python" { comment = `#[^\n]*` }
+	if language == "rust" { char = `'(?:[^'\\]|\\[^']{1,9})'` } // not a lifetime such as 'a
+	keywords := sourceKeywords[language]
+	if keywords == "" { keywords = `$^` }
+	return &highlighter{token: regexp.MustCompile(`(` + comment + `)|("(?:[^"\\]|\\.)*"?|` + char + "|`[^`]*`?" + `)|(?:^|\b)(` + keywords + `)\b`)}
+}
+
+func (h *highlighter) line(text string) template.HTML {
+	var b strings.Builder
+	if h.inComment {
+		end := strings.Index(text, "*/")
+		if end < 0 { return template.HTML(`<span class="comment">` + template.HTMLEscapeString(text) + `</span>`) }
+		b.WriteString(`<span class="comment">` + template.HTMLEscapeString(text[:end+2]) + `</span>`)
+		text, h.inComment = text[end+2:], false
+	}
+	last := 0
+	for _, m := range h.token.FindAllStringSubmatchIndex(text, -1) {
+		class := ""
+		switch {
+		case m[2] >= 0: class = "comment"; h.inComment = strings.HasPrefix(text[m[2]:], "/*") && !strings.HasSuffix(text[m[2]:m[3]], "*/")
+		case m[4] >= 0: class = "string"
+		case m[6] >= 0: class = "keyword"; m[0], m[1] = m[6], m[7]
+		default: continue
+		}
+		b.WriteString(template.HTMLEscapeString(text[last:m[0]]))
+		b.WriteString(`<span class="` + class + `">` + template.HTMLEscapeString(text[m[0]:m[1]]) + `</span>`)
+		last = m[1]
+	}
+	b.WriteString(template.HTMLEscapeString(text[last:]))
+	return template.HTML(b.String())
+}
+
+var sourceTemplate = template.Must(template.New("source").Funcs(template.FuncMap{"inc": func(i int) int { return i + 1 }}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="UTF-8"><meta name="viewport" content="width=device-width, initial-scale=1.0"><title>{{.Path}}</title><link rel="icon" href="data:,">
+	<style>
+		body { background-color: #1a1b26; color: #c0caf5; margin: 0; padding: 2rem; font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; font-size: 0.9rem; }
+		h1 { font-size: 1rem; font-weight: 500; color: #7dcfff; margin: 0 0 1rem 0; }
+		table { border-collapse: collapse; background-color: #24283b; border: 1px solid #3b4261; border-radius: 8px; width: 100%; }
+		td { padding: 0 0.75rem; vertical-align: top; white-space: pre; line-height: 1.5; }
+		td.num { text-align: right; user-select: none; border-right: 1px solid #3b4261; width: 1%; }
+		td.num a { color: #565f89; text-decoration: none; }
+		tr:target { background-color: #3b4261; }
+		tr:target td.num a { color: #e0af68; }
+		.comment { color: #565f89; font-style: italic; }
+		.string { color: #9ece6a; }
+		.keyword { color: #bb9af7; }
+	</style>
+</head>
+<body>
+	<h1>{{.Path}}</h1>
+	<table>{{range $i, $line := .Lines}}<tr id="L{{inc $i}}"><td class="num"><a href="#L{{inc $i}}">{{inc $i}}</a></td><td>{{$line}}</td></tr>{{end}}</table>
+</body>
+</html>
+`))
+
+// sourceHandler serves /source/<path>: a code file under root that the config does not exclude, as a page with
+// numbered, highlighted lines, so the report's import lines can link to #L<line>.
+func sourceHandler(root string, filter *analyzer.PathFilter) http.HandlerFunc {
+	if abs, err := filepath.Abs(root); err == nil { root = abs }
+	if real, err := filepath.EvalSymlinks(root); err == nil { root = real }
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !allowMethods(w, r, http.MethodGet, http.MethodHead) { return }
+		rel := strings.TrimPrefix(r.URL.Path, "/source/")
+		if rel == "" || pathpkg.Clean("/" + rel) != "/"+rel || !isCodeFile(pathpkg.Base(rel)) { http.NotFound(w, r); return }
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		real, err := filepath.EvalSymlinks(path)
+		if err != nil || !strings.HasPrefix(real, root+string(filepath.Separator)) || filter.Skip(path, false) { http.NotFound(w, r); return }
+		content, err := os.ReadFile(real) // the path checked, not one a symlink swapped in since
+		if err != nil { http.NotFound(w, r); return }
+		h := newHighlighter(analyzer.FileLanguage(rel))
+		var lines []template.HTML
+		for _, line := range strings.Split(strings.TrimSuffix(string(content), "\n"), "\n") { lines = append(lines, h.line(line)) }
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; img-src data:")
+		w.Header().Set("Cache-Control", "no-store")
+		if err := sourceTemplate.Execute(w, struct { Path string; Lines []template.HTML }{rel, lines}); err != nil { http.Error(w, err.Error(), http.StatusInternalServerError) }
+	}
+}
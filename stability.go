@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// StabilityChange is how a module declared stable in dependant.toml moved since a baseline snapshot.
+// Removing public items (or the module) breaks the contract; a growing surface or new dependents put it at risk.
+type StabilityChange struct {
+	Module                        string
+	Missing                       bool     // the module no longer exists
+	Unknown                       bool     // in neither snapshot, most likely a typo in the config
+	Removed, Added                []string // public items
+	NewDependents, LostDependents []string // modules importing it
+	Skipped                       bool     // generated module, exempt unless [generated] enforce is set
+}
+
+func (c StabilityChange) Broken() bool { return !c.Skipped && !c.Unknown && (c.Missing || len(c.Removed) > 0) }
+
+func (c StabilityChange) AtRisk() bool { return !c.Skipped && (len(c.Added) > 0 || len(c.NewDependents) > 0) }
+
+// moduleDependents maps each module of a snapshot to the modules importing it.
+func moduleDependents(s *Snapshot) map[string]map[string]struct{} {
+	dependents := make(map[string]map[string]struct{})
+	for _, m := range s.Modules {
+		for _, to := range m.Imports {
+			if dependents[to] == nil { dependents[to] = make(map[string]struct{}) }
+			dependents[to][m.Name] = struct{}{}
+		}
+	}
+	return dependents
+}
+
+// setChanges lists the keys only in after (added) and only in before (removed).
+func setChanges(before, after map[string]struct{}) (added, removed []string) {
+	for _, k := range sortedKeys(after) { if _, ok := before[k]; !ok { added = append(added, k) } }
+	for _, k := range sortedKeys(before) { if _, ok := after[k]; !ok { removed = append(removed, k) } }
+	return added, removed
+}
+
+func checkStability(a *analyzer.Report, before, after *Snapshot) []StabilityChange {
+	modules := func(s *Snapshot) map[string]SnapshotModule {
+		byName := make(map[string]SnapshotModule)
+		for _, m := range s.Modules { byName[m.Name] = m }
+		return byName
+	}
+	oldModules, newModules := modules(before), modules(after)
+	oldDependents, newDependents := moduleDependents(before), moduleDependents(after)
+	items := func(m SnapshotModule) map[string]struct{} {
+		set := make(map[string]struct{})
+		for _, item := range m.PublicItems { set[item] = struct{}{} }
+		return set
+	}
+	var changes []StabilityChange
+	for _, name := range a.Config.Stable {
+		c := StabilityChange{Module: name, Skipped: !a.Enforced(name)}
+		newModule, exists := newModules[name]
+		_, existed := oldModules[name]
+		c.Missing, c.Unknown = existed && !exists, !existed && !exists
+		c.Added, c.Removed = setChanges(items(oldModules[name]), items(newModule))
+		c.NewDependents, c.LostDependents = setChanges(oldDependents[name], newDependents[name])
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// writeStabilityReport prints one line per stable module, with details for any that moved, and returns how many broke.
+func writeStabilityReport(w io.Writer, changes []StabilityChange) (broken int) {
+	for _, c := range changes {
+		switch {
+		case c.Unknown: fmt.Fprintf(w, "   %s: unknown module\n", c.Module); continue
+		case c.Skipped: fmt.Fprintf(w, "   %s: skipped (generated)\n", c.Module); continue
+		case c.Broken(): fmt.Fprintf(w, "❌ %s: contract broken\n", c.Module); broken++
+		case c.AtRisk(): fmt.Fprintf(w, "⚠️  %s: contract at risk\n", c.Module)
+		default: fmt.Fprintf(w, "✅ %s: unchanged\n", c.Module)
+		}
+		detail := func(label string, values []string) { if len(values) > 0 { fmt.Fprintf(w, "     %s: %s\n", label, strings.Join(values, ", ")) } }
+		if c.Missing { fmt.Fprintln(w, "     module no longer exists") }
+		detail("removed public items", c.Removed)
+		detail("added public items", c.Added)
+		detail("new dependents", c.NewDependents)
+		detail("lost dependents", c.LostDependents)
+	}
+	return broken
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// CheckSummary is what `check --summary` writes, pass or fail, for the pipeline steps after the gate: how each rule
+// fared, the headline metrics of the tree and how long the run took, without parsing the log.
+type CheckSummary struct {
+	Passed     bool                   `json:"passed"`
+	Violations int                    `json:"violations"` // what failed the run: violations, or failed gates with --fail-on
+	Rules      map[string]RuleSummary `json:"rules"`
+	Metrics    SummaryMetrics         `json:"metrics"`
+	DurationMs int64                  `json:"durationMs"`
+}
+
+// RuleSummary counts one rule's violations, and its findings by level as SARIF would report them.
+type RuleSummary struct {
+	Violations int `json:"violations"`
+	Errors     int `json:"errors"`
+	Warnings   int `json:"warnings"`
+	Notes      int `json:"notes"`
+}
+
+type SummaryMetrics struct {
+	Modules        int    `json:"modules"`
+	LOC            int    `json:"loc"`
+	Edges          int    `json:"edges"`
+	Cycles         int    `json:"cycles"`
+	MaxFanIn       int    `json:"maxFanIn"`
+	MaxFanInModule string `json:"maxFanInModule,omitempty"`
+}
+
+func buildCheckSummary(a *analyzer.Report, deps map[string]map[string]struct{}, hits []checkHit, findings []checkFinding, violations int, took time.Duration) CheckSummary {
+	s := CheckSummary{Passed: violations == 0, Violations: violations, Rules: make(map[string]RuleSummary), DurationMs: took.Milliseconds()}
+	for _, h := range hits { r := s.Rules[h.Rule]; r.Violations++; s.Rules[h.Rule] = r }
+	for _, f := range findings {
+		r := s.Rules[f.Rule]
+		switch f.Level {
+		case "warning": r.Warnings++
+		case "note": r.Notes++
+		default: r.Errors++
+		}
+		s.Rules[f.Rule] = r
+	}
+	s.Metrics = summaryMetrics(a, deps)
+	return s
+}
+
+// summaryMetrics measures the headline metrics of the tree on deps, which both the check summary and the report's
+// summary card show.
+func summaryMetrics(a *analyzer.Report, deps map[string]map[string]struct{}) SummaryMetrics {
+	var s SummaryMetrics
+	s.Modules = len(a.Facts.LOC)
+	for _, loc := range a.Facts.LOC { s.LOC += loc }
+	for _, m := range computeModuleMetrics(a, deps) {
+		s.Edges += m.FanOut
+		if m.FanIn > s.MaxFanIn || m.FanIn == s.MaxFanIn && m.Name < s.MaxFanInModule { s.MaxFanIn, s.MaxFanInModule = m.FanIn, m.Name }
+	}
+	s.Cycles = len(computeCycles(a, deps))
+	return s
+}
+
+// ReportSummary is the card opening the HTML report, so a reader sees the size and shape of the tree before any
+// table.
+type ReportSummary struct {
+	SummaryMetrics
+	Files        int       // source files analyzed
+	AverageFanIn float64   // dependent modules per module
+	TopItem      *ItemInfo // the most imported item; nil when none is imported
+}
+
+func computeReportSummary(a *analyzer.Report, deps map[string]map[string]struct{}, topItems []ItemInfo) (ReportSummary, error) {
+	s := ReportSummary{SummaryMetrics: summaryMetrics(a, deps)}
+	if s.Modules > 0 { s.AverageFanIn = float64(s.Edges) / float64(s.Modules) }
+	if len(topItems) > 0 { s.TopItem = &topItems[0] }
+	var err error
+	s.Files, err = countSourceFiles(a)
+	return s, err
+}
+
+// countSourceFiles counts the files behind the analysis' modules: those FileModules names when it is set, else the code
+// files under the root that the config does not exclude and that belong to a module.
+func countSourceFiles(a *analyzer.Report) (int, error) {
+	if len(a.FileModules) > 0 { return len(a.FileModules), nil }
+	files, filter := 0, a.Config.PathFilter(a.Root, a.Options.Exclude...)
+	err := filepath.WalkDir(a.Root, func(path string, d os.DirEntry, err error) error {
+		if err == nil && filter.Skip(path, d.IsDir()) { if d.IsDir() { return filepath.SkipDir }; return nil }
+		if err != nil || d.IsDir() || !isCodeFile(d.Name()) { return err }
+		if _, ok := a.SymbolTable[a.ModuleName(path)]; ok { files++ }
+		return nil
+	})
+	return files, err
+}
+
+func writeCheckSummary(path string, s CheckSummary) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil { return err }
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	pathpkg "path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// TestImport is a production file importing a test module: a use line `check` fails on, since the test helpers would
+// then ship with (or fail to build into) the production code.
+type TestImport struct {
+	File      string // relative to the root
+	Line      int    // 0 when no import line names the module
+	Statement string
+	Importer  string // the importing module
+	Module    string // the test module imported
+}
+
+// isTestModule reports whether a module name, or any segment of it, matches one of the patterns.
+func isTestModule(module string, patterns []string) bool {
+	segments := strings.FieldsFunc(module, func(r rune) bool { return r == ':' || r == '/' || r == '.' })
+	for _, pattern := range patterns {
+		if ok, _ := pathpkg.Match(pattern, module); ok { return true }
+		for _, segment := range segments { if ok, _ := pathpkg.Match(pattern, segment); ok { return true } }
+	}
+	return false
+}
+
+// importLineRegex finds lines that import something, in any language the analyzers read.
+var importLineRegex = regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:use|import|from|mod|export)\b|\brequire\s*\(`)
+
+// findTestImports lists the imports of test modules by production files, with the lines that make them. Files the
+// backend reads as tests (tests/, _test.go, *.test.ts, test_*.py, ...) and test modules themselves may import them.
+func findTestImports(a *analyzer.Report) ([]TestImport, error) {
+	patterns := a.Config.TestModules
+	var found []TestImport
+	for _, file := range sortedKeys(a.Graph.ProdDeps) {
+		importer := a.ModuleName(file)
+		if isTestModule(importer, patterns) { continue }
+		var modules []string
+		for _, module := range sortedKeys(a.Graph.ProdDeps[file]) { if module != importer && isTestModule(module, patterns) { modules = append(modules, module) } }
+		if len(modules) == 0 { continue }
+		sites, err := importLines(file)
+		if err != nil { return nil, err }
+		for _, module := range modules {
+			lines := sites(module)
+			for _, site := range lines { found = append(found, TestImport{File: relSlash(a.Root, file), Line: site.Line, Statement: site.Statement, Importer: importer, Module: module}) }
+			if len(lines) == 0 { found = append(found, TestImport{File: relSlash(a.Root, file), Importer: importer, Module: module}) }
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].File < found[j].File || found[i].File == found[j].File && found[i].Line < found[j].Line })
+	return found, nil
+}
+
+// importSite is a line of a file that imports a module.
+type importSite struct {
+	Line      int
+	Statement string
+}
+
+// importLines reads file and returns a function listing the lines that import a module, outside #[cfg(test)] code.
+func importLines(file string) (func(module string) []importSite, error) {
+	content, err := os.ReadFile(file)
+	if err != nil { return nil, err }
+	lines := strings.Split(string(content), "\n")
+	inTest := func(offset int) bool { return false } // Rust use lines inside #[cfg(test)] are test code
+	if strings.HasSuffix(file, ".rs") {
+		code := analyzer.StripNonCode(string(content))
+		blocks := analyzer.CfgBlocks(code)
+		inTest = func(offset int) bool { return analyzer.AnyTestCfg(analyzer.CfgPredicates(code, offset, blocks)) }
+	}
+	return func(module string) []importSite {
+		segments := strings.FieldsFunc(module, func(r rune) bool { return r == ':' || r == '/' || r == '.' })
+		word := regexp.MustCompile(`\b` + regexp.QuoteMeta(segments[len(segments)-1]) + `\b`)
+		var sites []importSite
+		inGoImports, offset := false, 0
+		for i, line := range lines {
+			lineStart := offset
+			offset += len(line) + 1
+			trimmed := strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(trimmed, "import ("): inGoImports = true
+			case inGoImports && trimmed == ")": inGoImports = false
+			}
+			if (inGoImports || importLineRegex.MatchString(line)) && word.MatchString(line) && !inTest(lineStart+len(line)-len(strings.TrimLeft(line, " \t"))) {
+				sites = append(sites, importSite{Line: i + 1, Statement: trimmed})
+			}
+		}
+		return sites
+	}, nil
+}
+
+func writeTestImportReport(w io.Writer, found []TestImport) {
+	for _, t := range found {
+		if t.Line == 0 { fmt.Fprintf(w, "❌ %s imports test module %s (%s)\n", t.Importer, t.Module, t.File); continue }
+		fmt.Fprintf(w, "❌ %s imports test module %s\n      %s:%d: %s\n", t.Importer, t.Module, t.File, t.Line, t.Statement)
+	}
+}
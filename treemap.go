@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// treemapWidth and treemapHeight are the treemap's SVG view box; the report scales it to the page.
+const treemapWidth, treemapHeight = 1000.0, 560.0
+
+// TreemapCell is one module's rectangle: its area is proportional to the files depending on it and its color shows
+// its instability, green for stable through red for unstable.
+type TreemapCell struct {
+	Module, Tag string
+	Dependents  int
+	Instability float64
+	X, Y, W, H  float64
+	Color       string
+}
+
+// Label reports whether the cell is large enough to print the module name in.
+func (c TreemapCell) Label() bool { return c.W >= 60 && c.H >= 22 }
+
+// computeTreemap lays the modules with dependents out as a squarified treemap, the largest first.
+func computeTreemap(modules []ModuleInfo, metrics []ModuleMetrics) []TreemapCell {
+	instability := make(map[string]float64)
+	for _, m := range metrics { instability[m.Name] = m.Instability }
+	var cells []TreemapCell
+	total := 0
+	for _, m := range modules {
+		if len(m.Dependents) == 0 { continue }
+		cells = append(cells, TreemapCell{Module: m.Name, Tag: m.Tag, Dependents: len(m.Dependents), Instability: instability[m.Name], Color: instabilityColor(instability[m.Name])})
+		total += len(m.Dependents)
+	}
+	sort.SliceStable(cells, func(i, j int) bool { return cells[i].Dependents > cells[j].Dependents })
+	areas := make([]float64, len(cells))
+	for i, c := range cells { areas[i] = float64(c.Dependents) / float64(total) * treemapWidth * treemapHeight }
+	for i, r := range squarify(areas, 0, 0, treemapWidth, treemapHeight) { cells[i].X, cells[i].Y, cells[i].W, cells[i].H = r[0], r[1], r[2], r[3] }
+	return cells
+}
+
+// squarify splits the rectangle x, y, w, h into rectangles of the given areas (sorted largest first, summing to w×h),
+// filling rows along the shorter side and closing a row when the next area would make its cells less square.
+func squarify(areas []float64, x, y, w, h float64) [][4]float64 {
+	worst := func(row []float64, side float64) float64 {
+		sum, largest, smallest := 0.0, 0.0, math.Inf(1)
+		for _, a := range row { sum += a; largest = max(largest, a); smallest = min(smallest, a) }
+		return max(side*side*largest/(sum*sum), sum*sum/(side*side*smallest))
+	}
+	var rects [][4]float64
+	for len(areas) > 0 {
+		side := min(w, h)
+		n := 1
+		for n < len(areas) && worst(areas[:n+1], side) <= worst(areas[:n], side) { n++ }
+		sum := 0.0
+		for _, a := range areas[:n] { sum += a }
+		thick, pos := sum/side, 0.0
+		for _, a := range areas[:n] {
+			length := a / thick
+			if w >= h { rects = append(rects, [4]float64{x, y + pos, thick, length}) } else { rects = append(rects, [4]float64{x + pos, y, length, thick}) }
+			pos += length
+		}
+		if w >= h { x, w = x+thick, w-thick } else { y, h = y+thick, h-thick }
+		areas = areas[n:]
+	}
+	return rects
+}
+
+// instabilityColor blends green (0, stable) through yellow to red (1, unstable).
+func instabilityColor(instability float64) string {
+	stops := [][3]float64{{0x9e, 0xce, 0x6a}, {0xe0, 0xaf, 0x68}, {0xf7, 0x76, 0x8e}}
+	t, seg := math.Max(0, math.Min(1, instability))*2, 0
+	if t > 1 { t, seg = t-1, 1 }
+	from, to := stops[seg], stops[seg+1]
+	mix := func(i int) int { return int(math.Round(from[i] + (to[i]-from[i])*t)) }
+	return fmt.Sprintf("#%02x%02x%02x", mix(0), mix(1), mix(2))
+}
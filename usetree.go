@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// relUnparsed formats u for a log line, relative to root. Rust use statements are skipped when they do not parse;
+// JavaScript and Python imports always parse, and are skipped when they resolve to nothing.
+func relUnparsed(root string, u analyzer.UnparsedUse) string {
+	rel, err := filepath.Rel(root, u.File)
+	if err != nil { rel = u.File }
+	what := "unresolvable import"
+	if analyzer.FileLanguage(u.File) == "rust" { what = "unparsable use statement" }
+	return fmt.Sprintf("%s:%d: skipped %s (%s): %s", filepath.ToSlash(rel), u.Line, what, u.Err, u.Statement)
+}
+
+// UseStyleInfo is how the use statements of one module, or of the whole crate, are written.
+type UseStyleInfo struct {
+	Module, Tag string
+	analyzer.UseStyle
+}
+
+// computeUseStyles sums the use statement style of each module's files, and of the crate, busiest module first.
+func computeUseStyles(a *analyzer.Report) (total UseStyleInfo, modules []UseStyleInfo) {
+	byModule := make(map[string]analyzer.UseStyle)
+	for file, style := range a.Graph.UseStyles {
+		module := a.ModuleName(file)
+		s := byModule[module]
+		s.Add(style)
+		byModule[module] = s
+		total.Add(style)
+	}
+	for module, style := range byModule { modules = append(modules, UseStyleInfo{Module: module, Tag: a.Facts.Tags[module], UseStyle: style}) }
+	sort.Slice(modules, func(i, j int) bool { if modules[i].Statements != modules[j].Statements { return modules[i].Statements > modules[j].Statements }; return modules[i].Module < modules[j].Module })
+	return total, modules
+}
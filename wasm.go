@@ -0,0 +1,39 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// The WebAssembly build (GOOS=js GOARCH=wasm go build -o dependant.wasm) runs as a library when started without
+// arguments: it defines dependantAnalyze(root) on the global object and stays alive to serve calls. Files are read
+// through the host's fs binding, as everything on js/wasm is: Node's fs, or an in-memory polyfill in a browser.
+// wasm/dependant.js is the loader.
+func init() { embeddedMain = serveWASM }
+
+func serveWASM() {
+	js.Global().Set("dependantAnalyze", js.FuncOf(func(this js.Value, args []js.Value) any {
+		// File access on js/wasm waits for host callbacks, so the analysis runs outside the calling event and settles a
+		// promise with the snapshot JSON.
+		var executor js.Func
+		executor = js.FuncOf(func(this js.Value, settle []js.Value) any {
+			resolve, reject := settle[0], settle[1]
+			go func() {
+				defer executor.Release()
+				if len(args) != 1 || args[0].Type() != js.TypeString { reject.Invoke("usage: dependantAnalyze(root)"); return }
+				a, err := analyzer.Analyze(args[0].String(), analyzer.Options{})
+				if err != nil { reject.Invoke(err.Error()); return }
+				snapshot, err := json.Marshal(buildSnapshot(a))
+				if err != nil { reject.Invoke(err.Error()); return }
+				resolve.Invoke(string(snapshot))
+			}()
+			return nil
+		})
+		return js.Global().Get("Promise").New(executor)
+	}))
+	select {}
+}
@@ -0,0 +1,37 @@
+// Loader for the WebAssembly build of dependant. Build it next to this file with
+//
+//   GOOS=js GOARCH=wasm go build -o wasm/dependant.wasm .
+//   cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" wasm/
+//
+// then, after wasm_exec.js has defined globalThis.Go:
+//
+//   const snapshot = await (await loadDependant('dependant.wasm')).analyze('/path/to/crate');
+//
+// analyze rejects with the error message when the analysis fails.
+//
+// In Node, url is a file path and the loader binds Node's fs as globalThis.fs, which wasm_exec.js only stubs, so the
+// analyzer reads the real file system. In a browser, url is fetched, and globalThis.fs must first be replaced with an
+// in-memory implementation holding the dropped files.
+const isNode = typeof process !== 'undefined' && process.versions != null && process.versions.node != null;
+
+async function loadDependant(url) {
+  const go = new Go();
+  go.argv = ['dependant'];
+  let bytes;
+  if (isNode) {
+    const fs = require('fs');
+    globalThis.fs = fs; // read by the Go runtime when go.run starts it
+    bytes = await fs.promises.readFile(url);
+  } else {
+    bytes = await (await fetch(url)).arrayBuffer();
+  }
+  const { instance } = await WebAssembly.instantiate(bytes, go.importObject);
+  go.run(instance); // resolves only when the program exits, which the library mode never does
+  return {
+    analyze(root) {
+      return globalThis.dependantAnalyze(root).then(JSON.parse, (message) => { throw new Error(message); });
+    },
+  };
+}
+
+if (typeof module !== 'undefined') module.exports = { loadDependant };
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchSettle is how long a burst of file-system events must go quiet before it counts as one change; a save or a
+// branch switch touches many files at once.
+const watchSettle = 200 * time.Millisecond
+
+// treeChanges signals whenever something may have changed under root. It listens for file-system notifications on
+// every directory filter keeps, adding directories as they appear, and falls back to polling every interval when
+// notifications are unavailable, e.g. when the system runs out of watches. A signal only means "look again": the
+// caller compares fingerprints to tell a real change from an editor's swap file. filter is owned by the watcher.
+func treeChanges(root string, filter *analyzer.PathFilter, interval time.Duration) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+	signal := func() { select { case changes <- struct{}{}: default: } }
+	watcher, err := fsnotify.NewWatcher()
+	if err == nil { err = watchDirs(watcher, root, filter) }
+	if err != nil {
+		if watcher != nil { watcher.Close() }
+		log.Printf("File-system notifications unavailable (%v); polling every %v", err, interval)
+		go func() { for range time.Tick(interval) { signal() } }()
+		return changes
+	}
+	go func() {
+		settle := time.NewTimer(watchSettle)
+		settle.Stop()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok { return }
+				if event.Has(fsnotify.Create) {
+					if err := watchDirs(watcher, event.Name, filter); err != nil { log.Printf("Watching %s: %v", event.Name, err) }
+				}
+				settle.Reset(watchSettle)
+			case err, ok := <-watcher.Errors:
+				if !ok { return }
+				log.Printf("Watch error: %v", err) // an overflow loses events, so look again
+				settle.Reset(watchSettle)
+			case <-settle.C: signal()
+			}
+		}
+	}()
+	return changes
+}
+
+// watchDirs adds path and every directory under it that filter keeps to watcher; path may be a file, which needs
+// nothing since its directory is already watched.
+func watchDirs(watcher *fsnotify.Watcher, path string, filter *analyzer.PathFilter) error {
+	return filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) { return nil } // created and removed again before the walk got to it
+		if err != nil || !d.IsDir() { return err }
+		if filter.Skip(path, true) { return filepath.SkipDir }
+		return watcher.Add(path)
+	})
+}
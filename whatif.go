@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/WillKirkmanM/dependant/pkg/analyzer"
+)
+
+// stringList is a repeatable flag whose values are kept whole, for values with commas of their own.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, " ") }
+
+func (l *stringList) Set(value string) error { *l = append(*l, value); return nil }
+
+// moduleMerge is a --merge proposal: Modules become one module called Name.
+type moduleMerge struct {
+	Name    string
+	Modules []string
+}
+
+// moduleSplit is a --split proposal: Items move out of Module into a new module called Name.
+type moduleSplit struct {
+	Name, Module string
+	Items        []string
+}
+
+// parseMerge reads `[name=]a,b,...`; without a name the merged module is called a+b.
+func parseMerge(text string) (moduleMerge, error) {
+	name, list, named := strings.Cut(text, "=")
+	if !named { list = name }
+	var m moduleMerge
+	for _, module := range strings.Split(list, ",") { if module = strings.TrimSpace(module); module != "" { m.Modules = append(m.Modules, module) } }
+	if len(m.Modules) < 2 { return m, fmt.Errorf("--merge %s: expected two or more modules, as in core=a,b", text) }
+	if m.Name = strings.TrimSpace(name); !named { m.Name = strings.Join(m.Modules, "+") }
+	if m.Name == "" { return m, fmt.Errorf("--merge %s: empty module name", text) }
+	return m, nil
+}
+
+// parseSplit reads `name=module:Item,Item,...`.
+func parseSplit(text string) (moduleSplit, error) {
+	name, rest, ok := strings.Cut(text, "=")
+	module, items, hasItems := strings.Cut(rest, ":")
+	s := moduleSplit{Name: strings.TrimSpace(name), Module: strings.TrimSpace(module)}
+	if !ok || !hasItems || s.Name == "" || s.Module == "" { return s, fmt.Errorf("--split %s: expected new=module:Item,Item, as in io=core:Reader,Writer", text) }
+	for _, item := range strings.Split(items, ",") { if item = strings.TrimSpace(item); item != "" { s.Items = append(s.Items, item) } }
+	if len(s.Items) == 0 { return s, fmt.Errorf("--split %s: no items to move", text) }
+	return s, nil
+}
+
+// simulateRefactoring rebuilds the module graph as it would be after the splits and then the merges, without moving
+// any code. A file importing a split module imports whichever parts hold the items it uses. The split-off module
+// takes the files of the module that define its items, with their imports; when those files also define items that
+// stay, both parts keep the imports, as the code does not say which items need them.
+func simulateRefactoring(a *analyzer.Report, deps map[string]map[string]struct{}, splits []moduleSplit, merges []moduleMerge) (analyzer.ModuleGraph, error) {
+	modules := make(map[string]bool)
+	for m := range a.SymbolTable { modules[m] = true }
+	for from, tos := range a.BuildModuleGraph(deps) { modules[from] = true; for to := range tos { modules[to] = true } }
+
+	partOf := make(map[string]map[string]string) // split module -> moved item -> its new module
+	for _, s := range splits {
+		if !modules[s.Module] { return nil, fmt.Errorf("--split %s: no module %s", s.Name, s.Module) }
+		if modules[s.Name] { return nil, fmt.Errorf("--split %s: module %s already exists", s.Name, s.Name) }
+		for _, item := range s.Items {
+			if _, ok := a.SymbolTable[s.Module][item]; !ok { return nil, fmt.Errorf("--split %s: %s::%s is not a public item of the analyzed tree", s.Name, s.Module, item) }
+			if partOf[s.Module] == nil { partOf[s.Module] = make(map[string]string) }
+			if prev, ok := partOf[s.Module][item]; ok { return nil, fmt.Errorf("%s::%s is moved to both %s and %s", s.Module, item, prev, s.Name) }
+			partOf[s.Module][item] = s.Name
+		}
+		modules[s.Name] = true
+	}
+	renamed := make(map[string]string) // merged module -> the module it becomes
+	for _, m := range merges {
+		for _, module := range m.Modules {
+			if !modules[module] { return nil, fmt.Errorf("--merge %s: no module %s", m.Name, module) }
+			if prev, ok := renamed[module]; ok { return nil, fmt.Errorf("%s is merged into both %s and %s", module, prev, m.Name) }
+			renamed[module] = m.Name
+		}
+	}
+	rename := func(m string) string { if n, ok := renamed[m]; ok { return n }; return m }
+
+	// The parts a file belongs to: its module's, plus those of the split-off items it defines.
+	owners := func(file string) ([]string, error) {
+		module := a.ModuleName(file)
+		parts := []string{module}
+		if len(partOf[module]) == 0 { return parts, nil }
+		content, err := os.ReadFile(file)
+		if err != nil { return nil, err }
+		code, moved := analyzer.StripNonCode(string(content)), false
+		for item, part := range partOf[module] {
+			if definitionRegex(item).MatchString(code) {
+				if !slices.Contains(parts, part) { parts = append(parts, part) }
+				moved = true
+			}
+		}
+		if moved && !definesOtherItems(code, a.SymbolTable[module], partOf[module]) { parts = parts[1:] }
+		return parts, nil
+	}
+
+	graph := make(analyzer.ModuleGraph)
+	for file, imported := range deps {
+		from, err := owners(file)
+		if err != nil { return nil, err }
+		for to := range imported {
+			targets := []string{to}
+			if parts := partOf[to]; len(parts) > 0 {
+				targets = nil
+				for item, files := range a.Graph.ItemImports[to] {
+					if _, ok := files[file]; !ok { continue }
+					target := to
+					if part, ok := parts[item]; ok { target = part }
+					if !slices.Contains(targets, target) { targets = append(targets, target) }
+				}
+				if len(targets) == 0 { targets = []string{to} } // the module itself, with no item named
+			}
+			for _, f := range from {
+				for _, t := range targets {
+					f, t := rename(f), rename(t)
+					if f == t || t == "" { continue }
+					if graph[f] == nil { graph[f] = make(map[string]struct{}) }
+					graph[f][t] = struct{}{}
+				}
+			}
+		}
+	}
+	return graph, nil
+}
+
+// definesOtherItems reports whether code defines a public item of the module that is not moved.
+func definesOtherItems(code string, items map[string]struct{}, moved map[string]string) bool {
+	for item := range items {
+		if _, ok := moved[item]; !ok && definitionRegex(item).MatchString(code) { return true }
+	}
+	return false
+}
+
+// ModuleChange is how a module's coupling differs between the tree and the proposal; a module that only exists on
+// one side has zero counts on the other.
+type ModuleChange struct {
+	Module       string `json:"module"`
+	FanInBefore  int    `json:"fanInBefore"`
+	FanInAfter   int    `json:"fanInAfter"`
+	FanOutBefore int    `json:"fanOutBefore"`
+	FanOutAfter  int    `json:"fanOutAfter"`
+	LayerBefore  int    `json:"layerBefore"` // inferred layer, 0 the foundation; -1 for no edges or no such module
+	LayerAfter   int    `json:"layerAfter"`
+}
+
+// WhatIf compares the tree's module graph with the one a proposal would give it.
+type WhatIf struct {
+	Changes        []ModuleChange `json:"changes"` // modules whose fan-in, fan-out or layer differ
+	CyclesBefore   int            `json:"cyclesBefore"`
+	CyclesAfter    int            `json:"cyclesAfter"`
+	NewCycles      [][]string     `json:"newCycles"`
+	ResolvedCycles [][]string     `json:"resolvedCycles"`
+	LayersBefore   int            `json:"layersBefore"`
+	LayersAfter    int            `json:"layersAfter"`
+}
+
+func compareGraphs(before, after analyzer.ModuleGraph, modulesBefore, modulesAfter []string) WhatIf {
+	fan := func(g analyzer.ModuleGraph) (map[string]int, map[string]int) {
+		in, out := make(map[string]int), make(map[string]int)
+		for from, tos := range g { out[from] = len(tos); for to := range tos { in[to]++ } }
+		return in, out
+	}
+	levels := func(l InferredLayers) map[string]int {
+		level := make(map[string]int)
+		for i, layer := range l.Layers { for _, m := range layer { level[m] = i } }
+		return level
+	}
+	layersBefore, layersAfter := inferLayers(before, modulesBefore), inferLayers(after, modulesAfter)
+	inBefore, outBefore := fan(before)
+	inAfter, outAfter := fan(after)
+	levelBefore, levelAfter := levels(layersBefore), levels(layersAfter)
+	w := WhatIf{Changes: []ModuleChange{}, LayersBefore: len(layersBefore.Layers), LayersAfter: len(layersAfter.Layers)}
+	all := make(map[string]struct{})
+	for _, m := range append(slices.Clone(modulesBefore), modulesAfter...) { all[m] = struct{}{} }
+	for _, m := range sortedKeys(all) {
+		c := ModuleChange{Module: m, FanInBefore: inBefore[m], FanInAfter: inAfter[m], FanOutBefore: outBefore[m], FanOutAfter: outAfter[m], LayerBefore: -1, LayerAfter: -1}
+		if l, ok := levelBefore[m]; ok { c.LayerBefore = l }
+		if l, ok := levelAfter[m]; ok { c.LayerAfter = l }
+		if c.FanInBefore != c.FanInAfter || c.FanOutBefore != c.FanOutAfter || c.LayerBefore != c.LayerAfter || slices.Contains(modulesBefore, m) != slices.Contains(modulesAfter, m) { w.Changes = append(w.Changes, c) }
+	}
+	key := func(cycle []string) string { sorted := slices.Clone(cycle); sort.Strings(sorted); return strings.Join(sorted, " ") }
+	cyclesBefore, cyclesAfter := findCycles(before), findCycles(after)
+	w.CyclesBefore, w.CyclesAfter = len(cyclesBefore), len(cyclesAfter)
+	had, has := make(map[string]bool), make(map[string]bool)
+	for _, c := range cyclesBefore { had[key(c)] = true }
+	for _, c := range cyclesAfter { has[key(c)] = true }
+	w.NewCycles, w.ResolvedCycles = [][]string{}, [][]string{}
+	for _, c := range cyclesAfter { if !had[key(c)] { w.NewCycles = append(w.NewCycles, c) } }
+	for _, c := range cyclesBefore { if !has[key(c)] { w.ResolvedCycles = append(w.ResolvedCycles, c) } }
+	return w
+}
+
+// runWhatIf simulates merging and splitting modules and prints how fan-in, fan-out, cycles and inferred layers
+// would change, so a refactoring can be weighed before any code moves.
+func runWhatIf(args []string) {
+	fs := flag.NewFlagSet("what-if", flag.ExitOnError)
+	f := addAnalyzeFlags(fs)
+	format := fs.String("format", "text", "output format: text or json")
+	var merges, splits stringList
+	fs.Var(&merges, "merge", "merge modules, as [name=]a,b (repeatable; the merged module is called a+b without a name)")
+	fs.Var(&splits, "split", "move items out of a module into a new one, as new=module:Item,Item (repeatable; splits apply before merges)")
+	fs.Usage = func() { fmt.Println("Usage: dependant what-if [--merge a,b] [--split new=module:Item,Item] [flags] <directory>"); fs.PrintDefaults() }
+	fs.Parse(args)
+	if fs.NArg() != 1 || len(merges)+len(splits) == 0 { fs.Usage(); os.Exit(1) }
+	if *format != "text" && *format != "json" { f.fatalf("Unknown --format %q: expected text or json", *format) }
+	var proposal struct { merges []moduleMerge; splits []moduleSplit }
+	for _, text := range merges {
+		m, err := parseMerge(text)
+		if err != nil { f.fatalf("%v", err) }
+		proposal.merges = append(proposal.merges, m)
+	}
+	for _, text := range splits {
+		s, err := parseSplit(text)
+		if err != nil { f.fatalf("%v", err) }
+		proposal.splits = append(proposal.splits, s)
+	}
+	analysis := f.analyze(fs.Arg(0))
+	defer f.close()
+	deps := analysis.Graph.Deps
+	if *f.metricsScope == "prod" { deps = analysis.Graph.ProdDeps }
+	after, err := simulateRefactoring(analysis, deps, proposal.splits, proposal.merges)
+	if err != nil { f.fatalf("%v", err) }
+
+	modulesBefore := sortedKeys(analysis.SymbolTable)
+	gone := make(map[string]bool)
+	for _, m := range proposal.merges { for _, module := range m.Modules { gone[module] = true } }
+	var modulesAfter []string
+	for _, m := range modulesBefore { if !gone[m] { modulesAfter = append(modulesAfter, m) } }
+	for _, s := range proposal.splits { if !gone[s.Name] { modulesAfter = append(modulesAfter, s.Name) } }
+	for _, m := range proposal.merges { modulesAfter = append(modulesAfter, m.Name) }
+	w := compareGraphs(analysis.BuildModuleGraph(deps), after, modulesBefore, modulesAfter)
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(w); err != nil { f.fatalf("Error writing JSON: %v", err) }
+		return
+	}
+	writeWhatIf(w)
+}
+
+func writeWhatIf(w WhatIf) {
+	layer := func(l int) string { if l < 0 { return "—" }; return fmt.Sprint(l) }
+	change := func(before, after string) string { if before == after { return before }; return before + " → " + after }
+	if len(w.Changes) == 0 {
+		fmt.Println("No module's fan-in, fan-out or layer would change")
+	} else {
+		width := len("Module")
+		for _, c := range w.Changes { width = max(width, len(c.Module)) }
+		fmt.Printf("%-*s  %-10s  %-10s  %s\n", width, "Module", "Fan-in", "Fan-out", "Layer")
+		for _, c := range w.Changes {
+			fmt.Printf("%-*s  %-10s  %-10s  %s\n", width, c.Module, change(fmt.Sprint(c.FanInBefore), fmt.Sprint(c.FanInAfter)), change(fmt.Sprint(c.FanOutBefore), fmt.Sprint(c.FanOutAfter)), change(layer(c.LayerBefore), layer(c.LayerAfter)))
+		}
+	}
+	fmt.Printf("\nCycles: %d → %d\n", w.CyclesBefore, w.CyclesAfter)
+	for _, c := range w.NewCycles { fmt.Printf("❌ new: %s\n", strings.Join(c, ", ")) }
+	for _, c := range w.ResolvedCycles { fmt.Printf("✅ resolved: %s\n", strings.Join(c, ", ")) }
+	fmt.Printf("Inferred layers: %d → %d\n", w.LayersBefore, w.LayersAfter)
+}